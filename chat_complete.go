@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"iter"
+	"reflect"
+	"strings"
+	"unicode"
 
 	"github.com/invopop/jsonschema"
 )
@@ -14,7 +17,7 @@ import (
 // every other value is published per client because each provider speaks its own vocabulary.
 // See [maragu.dev/gai/clients/openai], [maragu.dev/gai/clients/google], and
 // [maragu.dev/gai/clients/anthropic] for the constants their target APIs accept; passing a
-// level a given client does not recognise will panic at the client boundary.
+// level a given client does not recognise returns a [ValidationError] at the client boundary.
 type ThinkingLevel string
 
 // ThinkingLevelNone disables thinking entirely. This is the only value defined in core because it
@@ -84,7 +87,7 @@ type ToolChoice struct {
 //   - Any other Mode value is rejected.
 //
 // Bad input is caller data rather than a programming error, so violations are returned as
-// errors instead of panicking — unlike unrecognised library constants such as [ThinkingLevel].
+// errors instead of panicking, like the [ValidationError] clients return for other request fields.
 func (tc ToolChoice) Validate(tools []Tool) error {
 	switch tc.Mode {
 	case "", ToolChoiceModeAuto, ToolChoiceModeAny:
@@ -178,6 +181,7 @@ type Part struct {
 	MIMEType string
 
 	text       *string
+	signature  []byte
 	toolCall   *ToolCall
 	toolResult *ToolResult
 }
@@ -222,6 +226,15 @@ func (m Part) Thought() string {
 	return *m.text
 }
 
+// ThoughtSignature returns the opaque, provider-specific signature of a thought part, or nil
+// if the part carries none. Panics if the part is not [PartTypeThought].
+func (m Part) ThoughtSignature() []byte {
+	if m.Type != PartTypeThought {
+		panic("not thought type")
+	}
+	return m.signature
+}
+
 // ToolCall returns the tool call. Panics if the part is not [PartTypeToolCall].
 func (m Part) ToolCall() ToolCall {
 	if m.Type != PartTypeToolCall {
@@ -246,8 +259,11 @@ const (
 	PartTypeText PartType = "text"
 	// PartTypeThought is a streamed thinking/reasoning part. Providers vary in whether they
 	// expose the model's chain-of-thought as text — Google Gemini emits Thought parts when
-	// thinking is enabled, Anthropic surfaces thinking blocks via the streaming API, and
-	// OpenAI Chat Completions does not stream reasoning text and so never produces this type.
+	// thinking is enabled, Anthropic surfaces thinking blocks via the streaming API, the OpenAI
+	// Responses API streams reasoning summaries, and OpenAI Chat Completions does not stream
+	// reasoning text and so never produces this type.
+	// A thought part may carry an opaque signature (see [ThoughtPartWithSignature]) that lets the
+	// provider restore its reasoning state when the part is sent back on a later turn.
 	PartTypeThought    PartType = "thought"
 	PartTypeToolCall   PartType = "tool_call"
 	PartTypeToolResult PartType = "tool_result"
@@ -293,6 +309,18 @@ func ThoughtPart(text string) Part {
 	}
 }
 
+// ThoughtPartWithSignature creates a thought [Part] with an opaque, provider-specific signature.
+// Clients emit these so that reasoning state survives between turns: keep the part in the
+// conversation history and the client that produced it sends it back unchanged.
+// The caller must not mutate the signature after passing it.
+func ThoughtPartWithSignature(text string, signature []byte) Part {
+	return Part{
+		Type:      PartTypeThought,
+		text:      &text,
+		signature: signature,
+	}
+}
+
 // DataPart creates a data [Part] with the given MIME type and content.
 // Data is stored as a byte slice for safe reuse across multiple reads.
 // The caller must not mutate the slice after passing it.
@@ -394,16 +422,40 @@ type Tool struct {
 	Summarize   ToolFunction
 }
 
-// ToolSchema in JSON Schema format of the arguments the tool accepts.
+// ToolSchema in JSON Schema format of the arguments the tool accepts, which are always an object.
 type ToolSchema struct {
-	Properties map[string]*Schema
+	// AdditionalProperties allowed in the arguments. Nil means they are allowed.
+	AdditionalProperties *bool
+	// Defs are definitions of recursive subschemas, referenced from Properties. See [Schema.Defs].
+	Defs map[string]*Schema
+	// Description of the arguments object. Describe the tool itself in [Tool.Description].
+	Description string
+	Properties  map[string]*Schema
+	// Required property names.
+	Required []string
+}
+
+// Schema of the arguments as an object [Schema].
+func (s ToolSchema) Schema() Schema {
+	return Schema{
+		AdditionalProperties: s.AdditionalProperties,
+		Defs:                 s.Defs,
+		Description:          s.Description,
+		Properties:           s.Properties,
+		Required:             s.Required,
+		Type:                 SchemaTypeObject,
+	}
 }
 
 func GenerateToolSchema[T any]() ToolSchema {
 	schema := GenerateSchema[T]()
 
 	return ToolSchema{
-		Properties: schema.Properties,
+		AdditionalProperties: schema.AdditionalProperties,
+		Defs:                 schema.Defs,
+		Description:          schema.Description,
+		Properties:           schema.Properties,
+		Required:             schema.Required,
 	}
 }
 
@@ -423,16 +475,35 @@ const (
 	SchemaTypeArray SchemaType = "array"
 	// SchemaTypeObject is the OpenAPI object type.
 	SchemaTypeObject SchemaType = "object"
+	// SchemaTypeNull is the JSON Schema null type. Prefer [Schema.Nullable] for values that may be null.
+	SchemaTypeNull SchemaType = "null"
 )
 
+// Schema in JSON Schema format, with the OpenAPI additions Gemini uses, like [Schema.PropertyOrdering].
+// It marshals to standard JSON Schema, see [Schema.MarshalJSON].
 type Schema struct {
+	// Optional. Whether properties not listed in Properties are allowed on Type.OBJECT. Nil means
+	// they are allowed. A schema for the values of additional properties, like the one for a map,
+	// is read as true.
+	AdditionalProperties *bool `json:"additionalProperties,omitempty"`
+
+	// Optional. The value should be validated against all of the subschemas in the list.
+	AllOf []*Schema `json:"allOf,omitempty"`
+
 	// Optional. The value should be validated against any (one or more) of the subschemas
 	// in the list.
 	AnyOf []*Schema `json:"anyOf,omitempty"`
 
+	// Optional. The only allowed value.
+	Const any `json:"const,omitempty"`
+
 	// Optional. Default value of the data.
 	Default any `json:"default,omitempty"`
 
+	// Optional. Definitions of subschemas, referenced by name with Ref as "#/$defs/Name".
+	// Only set on the root schema.
+	Defs map[string]*Schema `json:"$defs,omitempty"`
+
 	// Optional. The description of the data.
 	Description string `json:"description,omitempty"`
 
@@ -440,6 +511,7 @@ type Schema struct {
 	// 1. We can define direction as : {type:STRING, format:enum, enum:["EAST", NORTH",
 	// "SOUTH", "WEST"]} 2. We can define apartment number as : {type:INTEGER, format:enum,
 	// enum:["101", "201", "301"]}
+	// Values are marshalled as the JSON type given by Type, so the apartment numbers become numbers.
 	Enum []string `json:"enum,omitempty"`
 
 	// Optional. Example of the object. Will only populated when the object is the root.
@@ -453,17 +525,33 @@ type Schema struct {
 	Items *Schema `json:"items,omitempty"`
 
 	// Optional. Maximum number of the elements for Type.ARRAY.
-	MaxItems *int64 `json:"maxItems,omitempty,string"`
+	MaxItems *int64 `json:"maxItems,omitempty"`
+
+	// Optional. Maximum length in characters of Type.STRING.
+	MaxLength *int64 `json:"maxLength,omitempty"`
 
 	// Optional. Maximum value of the Type.INTEGER and Type.NUMBER
 	Maximum *float64 `json:"maximum,omitempty"`
 
 	// Optional. Minimum number of the elements for Type.ARRAY.
-	MinItems *int64 `json:"minItems,omitempty,string"`
+	MinItems *int64 `json:"minItems,omitempty"`
+
+	// Optional. Minimum length in characters of Type.STRING.
+	MinLength *int64 `json:"minLength,omitempty"`
 
 	// Optional. Minimum value of the Type.INTEGER and Type.NUMBER.
 	Minimum *float64 `json:"minimum,omitempty"`
 
+	// Optional. Whether the value may also be null. Marshalled as a list of types, like
+	// ["string", "null"], or as anyOf with a null schema if there is no Type.
+	Nullable bool `json:"nullable,omitempty"`
+
+	// Optional. The value should be validated against exactly one of the subschemas in the list.
+	OneOf []*Schema `json:"oneOf,omitempty"`
+
+	// Optional. Regular expression that Type.STRING values must match.
+	Pattern string `json:"pattern,omitempty"`
+
 	// Optional. SCHEMA FIELDS FOR TYPE OBJECT Properties of Type.OBJECT.
 	Properties map[string]*Schema `json:"properties,omitempty"`
 
@@ -471,6 +559,10 @@ type Schema struct {
 	// used to support the order of the properties.
 	PropertyOrdering []string `json:"propertyOrdering,omitempty"`
 
+	// Optional. Reference to a schema in the root's Defs, like "#/$defs/Node". Other fields
+	// set next to Ref, like Description, apply in addition to the referenced schema.
+	Ref string `json:"$ref,omitempty"`
+
 	// Optional. Required properties of Type.OBJECT.
 	Required []string `json:"required,omitempty"`
 
@@ -483,16 +575,60 @@ type Schema struct {
 
 // GenerateSchema from any type.
 // See github.com/invopop/jsonschema for struct tags etc.
+//
+// Named types are inlined, except recursive ones, which are put in [Schema.Defs] and referenced
+// with [Schema.Ref]. Fields tagged with jsonschema:"nullable" get [Schema.Nullable].
 func GenerateSchema[T any]() Schema {
 	reflector := jsonschema.Reflector{
 		AllowAdditionalProperties: false,
-		DoNotReference:            true,
+		Namer:                     newSchemaNamer(),
 	}
 
 	var v T
 	schema := reflector.Reflect(v)
 
-	return convertJSONSchemaToSchema(schema)
+	s := convertJSONSchemaToSchema(schema)
+	for name, def := range schema.Definitions {
+		converted := convertJSONSchemaToSchema(def)
+		if s.Defs == nil {
+			s.Defs = map[string]*Schema{}
+		}
+		s.Defs[name] = &converted
+	}
+
+	return inlineRefs(s)
+}
+
+// newSchemaNamer returns a [jsonschema.Reflector] Namer that gives types with the same name from
+// different packages different definition names, so they don't overwrite each other.
+func newSchemaNamer() func(reflect.Type) string {
+	names := map[reflect.Type]string{}
+	taken := map[string]bool{}
+
+	return func(t reflect.Type) string {
+		// Unnamed types, like slices, are not defined separately
+		if t.Name() == "" {
+			return ""
+		}
+		if name, ok := names[t]; ok {
+			return name
+		}
+
+		base := strings.Map(func(r rune) rune {
+			if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return '_'
+		}, t.Name())
+
+		name := base
+		for i := 2; taken[name]; i++ {
+			name = fmt.Sprintf("%v%v", base, i)
+		}
+		names[t] = name
+		taken[name] = true
+		return name
+	}
 }
 
 func convertJSONSchemaToSchema(js *jsonschema.Schema) Schema {
@@ -501,6 +637,9 @@ func convertJSONSchemaToSchema(js *jsonschema.Schema) Schema {
 		Title:       js.Title,
 		Default:     js.Default,
 		Format:      js.Format,
+		Pattern:     js.Pattern,
+		Ref:         js.Ref,
+		Const:       js.Const,
 	}
 
 	// Convert example (Examples is a slice, use first one if available)
@@ -510,19 +649,18 @@ func convertJSONSchemaToSchema(js *jsonschema.Schema) Schema {
 
 	// Convert type
 	if js.Type != "" {
-		switch js.Type {
-		case "string", "number", "integer", "boolean", "array", "object":
-			s.Type = SchemaType(js.Type)
-		default:
-			panic("unsupported schema type " + js.Type)
-		}
+		s.Type = SchemaType(js.Type)
 	}
 
-	// Convert enum
+	// Convert enum, keeping non-string values in their string form
 	if len(js.Enum) > 0 {
-		s.Enum = make([]string, len(js.Enum))
-		for i, v := range js.Enum {
-			s.Enum[i] = fmt.Sprint(v)
+		s.Enum = make([]string, 0, len(js.Enum))
+		for _, v := range js.Enum {
+			if v == nil {
+				s.Nullable = true
+				continue
+			}
+			s.Enum = append(s.Enum, fmt.Sprint(v))
 		}
 	}
 
@@ -538,6 +676,16 @@ func convertJSONSchemaToSchema(js *jsonschema.Schema) Schema {
 		}
 	}
 
+	// Convert string constraints
+	if js.MinLength != nil {
+		minLength := int64(*js.MinLength)
+		s.MinLength = &minLength
+	}
+	if js.MaxLength != nil {
+		maxLength := int64(*js.MaxLength)
+		s.MaxLength = &maxLength
+	}
+
 	// Convert array constraints
 	if js.MinItems != nil {
 		minItems := int64(*js.MinItems)
@@ -566,16 +714,29 @@ func convertJSONSchemaToSchema(js *jsonschema.Schema) Schema {
 	}
 	s.Required = js.Required
 
-	// Convert anyOf
-	if len(js.AnyOf) > 0 {
-		s.AnyOf = make([]*Schema, len(js.AnyOf))
-		for i, v := range js.AnyOf {
-			converted := convertJSONSchemaToSchema(v)
-			s.AnyOf[i] = &converted
-		}
+	// The reflector uses the false schema to disallow additional properties, and a schema for map values
+	if js.AdditionalProperties != nil {
+		s.AdditionalProperties = Ptr(js.AdditionalProperties != jsonschema.FalseSchema)
 	}
 
-	return s
+	// Convert subschemas
+	s.AllOf = convertJSONSchemasToSchemas(js.AllOf)
+	s.AnyOf = convertJSONSchemasToSchemas(js.AnyOf)
+	s.OneOf = convertJSONSchemasToSchemas(js.OneOf)
+
+	return collapseNull(s)
+}
+
+func convertJSONSchemasToSchemas(jss []*jsonschema.Schema) []*Schema {
+	if len(jss) == 0 {
+		return nil
+	}
+	schemas := make([]*Schema, len(jss))
+	for i, js := range jss {
+		converted := convertJSONSchemaToSchema(js)
+		schemas[i] = &converted
+	}
+	return schemas
 }
 
 type ToolFunction func(ctx context.Context, rawArgs json.RawMessage) (string, error)
@@ -1,6 +1,7 @@
 package gai_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -56,6 +57,21 @@ func TestDataPart(t *testing.T) {
 	})
 }
 
+func TestThoughtPartWithSignature(t *testing.T) {
+	t.Run("carries text and signature", func(t *testing.T) {
+		part := gai.ThoughtPartWithSignature("pondering", []byte("sig"))
+
+		is.Equal(t, gai.PartTypeThought, part.Type)
+		is.Equal(t, "pondering", part.Thought())
+		is.EqualSlice(t, []byte("sig"), part.ThoughtSignature())
+	})
+
+	t.Run("plain thought parts have no signature", func(t *testing.T) {
+		part := gai.ThoughtPart("pondering")
+		is.True(t, part.ThoughtSignature() == nil)
+	})
+}
+
 func TestGenerateSchema(t *testing.T) {
 	t.Run("simple string type", func(t *testing.T) {
 		type SimpleString struct {
@@ -354,6 +370,96 @@ func TestGenerateSchema(t *testing.T) {
 		is.NotNil(t, schema.Properties["own_field"])
 	})
 
+	t.Run("recursive type", func(t *testing.T) {
+		schema := gai.GenerateSchema[treeNode]()
+
+		is.Equal(t, schema.Type, gai.SchemaTypeObject)
+		is.Equal(t, schema.Properties["children"].Items.Ref, "#/$defs/treeNode")
+		is.Equal(t, schema.Properties["parent"].Ref, "#/$defs/treeNode")
+		is.True(t, schema.Properties["parent"].Nullable)
+
+		def := schema.Defs["treeNode"]
+		is.NotNil(t, def)
+		is.Equal(t, def.Type, gai.SchemaTypeObject)
+		is.Equal(t, def.Properties["children"].Items.Ref, "#/$defs/treeNode")
+
+		// Non-recursive named types are inlined
+		is.Equal(t, len(schema.Defs), 1)
+		is.Equal(t, schema.Properties["label"].Type, gai.SchemaTypeObject)
+		is.Equal(t, schema.Properties["label"].Properties["text"].Type, gai.SchemaTypeString)
+
+		is.NotError(t, schema.Validate(json.RawMessage(`{"name":"a","label":{"text":"x"},"parent":null,"children":[{"name":"b","label":{"text":"y"},"parent":null}]}`)))
+		is.True(t, schema.Validate(json.RawMessage(`{"name":"a","label":{"text":"x"},"parent":null,"children":[{"label":{"text":"y"},"parent":null}]}`)) != nil)
+	})
+
+	t.Run("types with the same name from different packages", func(t *testing.T) {
+		type ToolCall struct {
+			Other string `json:"other"`
+		}
+		type Calls struct {
+			Local  ToolCall     `json:"local"`
+			Remote gai.ToolCall `json:"remote"`
+		}
+
+		schema := gai.GenerateSchema[Calls]()
+
+		is.NotNil(t, schema.Properties["local"].Properties["other"])
+		is.NotNil(t, schema.Properties["remote"].Properties["ID"])
+	})
+
+	t.Run("nullable fields", func(t *testing.T) {
+		type NullableField struct {
+			Note *string `json:"note" jsonschema:"nullable"`
+		}
+
+		schema := gai.GenerateSchema[NullableField]()
+
+		noteSchema := schema.Properties["note"]
+		is.Equal(t, noteSchema.Type, gai.SchemaTypeString)
+		is.True(t, noteSchema.Nullable)
+		is.Equal(t, len(noteSchema.OneOf), 0)
+	})
+
+	t.Run("string constraints", func(t *testing.T) {
+		type StringConstraints struct {
+			Code string `json:"code" jsonschema:"pattern=^[A-Z]{3}$,minLength=3,maxLength=3"`
+		}
+
+		schema := gai.GenerateSchema[StringConstraints]()
+
+		codeSchema := schema.Properties["code"]
+		is.Equal(t, codeSchema.Pattern, "^[A-Z]{3}$")
+		is.Equal(t, *codeSchema.MinLength, int64(3))
+		is.Equal(t, *codeSchema.MaxLength, int64(3))
+	})
+
+	t.Run("additional properties", func(t *testing.T) {
+		type WithMap struct {
+			Counts map[string]int `json:"counts"`
+		}
+
+		schema := gai.GenerateSchema[WithMap]()
+
+		is.NotNil(t, schema.AdditionalProperties)
+		is.True(t, !*schema.AdditionalProperties)
+		is.NotNil(t, schema.Properties["counts"].AdditionalProperties)
+		is.True(t, *schema.Properties["counts"].AdditionalProperties)
+	})
+
+	t.Run("non-string enum values", func(t *testing.T) {
+		type IntEnum struct {
+			Level int `json:"level" jsonschema:"enum=1,enum=2,enum=3"`
+		}
+
+		schema := gai.GenerateSchema[IntEnum]()
+
+		is.EqualSlice(t, schema.Properties["level"].Enum, []string{"1", "2", "3"})
+
+		data, err := json.Marshal(schema.Properties["level"])
+		is.NotError(t, err)
+		is.Equal(t, string(data), `{"enum":[1,2,3],"type":"integer"}`)
+	})
+
 	t.Run("struct with no json tags", func(t *testing.T) {
 		type NoTags struct {
 			FirstName string
@@ -369,6 +475,26 @@ func TestGenerateSchema(t *testing.T) {
 	})
 }
 
+func TestGenerateToolSchema(t *testing.T) {
+	t.Run("carries required properties, description, and additional properties", func(t *testing.T) {
+		type SearchArgs struct {
+			Query string `json:"query"`
+			Limit int    `json:"limit,omitempty"`
+		}
+
+		schema := gai.GenerateToolSchema[SearchArgs]()
+
+		is.EqualSlice(t, []string{"query"}, schema.Required)
+		is.NotNil(t, schema.AdditionalProperties)
+		is.True(t, !*schema.AdditionalProperties)
+
+		objectSchema := schema.Schema()
+		is.Equal(t, gai.SchemaTypeObject, objectSchema.Type)
+		is.EqualSlice(t, []string{"query"}, objectSchema.Required)
+		is.Equal(t, 2, len(objectSchema.Properties))
+	})
+}
+
 func TestToolChoiceValidate(t *testing.T) {
 	tools := []gai.Tool{
 		{Name: "get_weather"},
@@ -437,3 +563,14 @@ func TestToolChoiceValidate(t *testing.T) {
 		is.Equal(t, `unknown tool choice mode "nonsense"`, err.Error())
 	})
 }
+
+type treeNode struct {
+	Name     string     `json:"name"`
+	Label    treeLabel  `json:"label"`
+	Parent   *treeNode  `json:"parent" jsonschema:"nullable"`
+	Children []treeNode `json:"children,omitempty"`
+}
+
+type treeLabel struct {
+	Text string `json:"text"`
+}
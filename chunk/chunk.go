@@ -0,0 +1,220 @@
+// Package chunk splits text into chunks small enough to embed, with their offsets in the source.
+//
+// [Recursive] splits prose at the largest boundary that makes chunks fit: paragraphs, then lines,
+// then sentences, then words, and finally characters. [Markdown] first splits at headings, keeping
+// fenced code blocks together where possible, and records the headings each chunk is under. [Code]
+// splits source code at blank lines, then lines. All splitters merge small pieces back together
+// up to the chunk size, with optional overlap between chunks, and measure size with a length
+// function, so chunks can be sized in characters or tokens.
+package chunk
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Chunk of a source text.
+type Chunk struct {
+	// Text of the chunk, with surrounding whitespace trimmed.
+	Text string
+	// Start and End are the byte offsets of Text in the source, so source[Start:End] == Text.
+	Start, End int
+	// Headings of the Markdown sections the chunk is in, from the top level down. Only set by [Markdown].
+	Headings []string
+}
+
+// Options for splitting.
+type Options struct {
+	// Size is the maximum length of a chunk, as measured by Length. Defaults to 1000.
+	Size int
+	// Overlap is the maximum length of text repeated from the end of one chunk at the start of the next,
+	// so that text near chunk boundaries has context. Must be less than Size. Defaults to 0.
+	Overlap int
+	// Length of a text. Defaults to [Runes]. Use [ApproximateTokens] or a tokenizer to size chunks in tokens.
+	Length func(text string) int
+}
+
+// Runes is the number of Unicode code points in the text.
+func Runes(text string) int {
+	return utf8.RuneCountInString(text)
+}
+
+// ApproximateTokens is a rough estimate of the number of tokens in the text,
+// as one token for every four characters, which is typical for English text and common tokenizers.
+func ApproximateTokens(text string) int {
+	return (Runes(text) + 3) / 4
+}
+
+func (o *Options) setDefaults() {
+	if o.Size <= 0 {
+		o.Size = 1000
+	}
+	if o.Overlap < 0 || o.Overlap >= o.Size {
+		panic("overlap must be at least 0 and less than size")
+	}
+	if o.Length == nil {
+		o.Length = Runes
+	}
+}
+
+// Recursive splits prose into chunks, at paragraphs, lines, sentences, words, or characters,
+// preferring the largest boundaries.
+func Recursive(text string, opts Options) []Chunk {
+	opts.setDefaults()
+	return split(text, span{0, len(text)}, []level{paragraphs, lines, sentences, words}, opts, nil)
+}
+
+// Code splits source code into chunks, at blank lines, lines, words, or characters,
+// preferring the largest boundaries, so functions and other blocks stay together where possible.
+func Code(text string, opts Options) []Chunk {
+	opts.setDefaults()
+	return split(text, span{0, len(text)}, []level{paragraphs, lines, words}, opts, nil)
+}
+
+// Markdown splits Markdown into chunks, first at headings, and then within each section at blocks
+// outside fenced code, lines, sentences, words, or characters, preferring the largest boundaries.
+// Sections with only a heading are joined with the section after them.
+func Markdown(text string, opts Options) []Chunk {
+	opts.setDefaults()
+
+	var chunks []Chunk
+	for _, sec := range markdownSections(text) {
+		chunks = append(chunks, split(text, sec.span, []level{markdownBlocks, lines, sentences, words}, opts, sec.headings)...)
+	}
+	return chunks
+}
+
+// Sentences splits text into sentences, at sentence-ending punctuation followed by whitespace.
+func Sentences(text string) []Chunk {
+	var chunks []Chunk
+	for _, s := range sentences(text, span{0, len(text)}) {
+		if c, ok := trim(text, s, nil); ok {
+			chunks = append(chunks, c)
+		}
+	}
+	return chunks
+}
+
+// span of byte offsets in the source.
+type span struct {
+	start, end int
+}
+
+// level splits a span into contiguous spans covering all of it.
+type level func(text string, s span) []span
+
+// split the span into pieces that fit, merge them into chunks, and trim them.
+func split(text string, s span, levels []level, opts Options, headings []string) []Chunk {
+	pieces := splitSpan(text, s, levels, opts)
+
+	var chunks []Chunk
+	for _, m := range merge(text, pieces, opts) {
+		if c, ok := trim(text, m, headings); ok {
+			chunks = append(chunks, c)
+		}
+	}
+	return chunks
+}
+
+// splitSpan recursively into pieces that fit in the chunk size, splitting at the first level that splits it.
+func splitSpan(text string, s span, levels []level, opts Options) []span {
+	if opts.Length(text[s.start:s.end]) <= opts.Size {
+		return []span{s}
+	}
+	if len(levels) == 0 {
+		return splitRunes(text, s, opts)
+	}
+
+	parts := levels[0](text, s)
+	if len(parts) <= 1 {
+		return splitSpan(text, s, levels[1:], opts)
+	}
+
+	var pieces []span
+	for _, p := range parts {
+		pieces = append(pieces, splitSpan(text, p, levels[1:], opts)...)
+	}
+	return pieces
+}
+
+// splitRunes into the longest pieces that fit, as a last resort.
+func splitRunes(text string, s span, opts Options) []span {
+	var pieces []span
+	start := s.start
+	for i, r := range text[s.start:s.end] {
+		i += s.start
+		end := i + utf8.RuneLen(r)
+		if end > start && i > start && opts.Length(text[start:end]) > opts.Size {
+			pieces = append(pieces, span{start, i})
+			start = i
+		}
+	}
+	if start < s.end {
+		pieces = append(pieces, span{start, s.end})
+	}
+	return pieces
+}
+
+// merge contiguous pieces into chunks up to the chunk size, starting each chunk with
+// as many pieces from the end of the previous chunk as fit in the overlap.
+func merge(text string, pieces []span, opts Options) []span {
+	var chunks []span
+	var current []span
+	for _, p := range pieces {
+		if len(current) > 0 && opts.Length(text[current[0].start:p.end]) > opts.Size {
+			last := current[len(current)-1]
+			chunks = append(chunks, span{current[0].start, last.end})
+
+			keep := len(current)
+			for keep > 0 && opts.Length(text[current[keep-1].start:last.end]) <= opts.Overlap {
+				keep--
+			}
+			current = current[keep:]
+			for len(current) > 0 && opts.Length(text[current[0].start:p.end]) > opts.Size {
+				current = current[1:]
+			}
+		}
+		current = append(current, p)
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, span{current[0].start, current[len(current)-1].end})
+	}
+	return chunks
+}
+
+// trim whitespace from the span, and report whether anything is left.
+func trim(text string, s span, headings []string) (Chunk, bool) {
+	t := text[s.start:s.end]
+	start := s.start + len(t) - len(strings.TrimLeftFunc(t, unicode.IsSpace))
+	end := s.start + len(strings.TrimRightFunc(t, unicode.IsSpace))
+	if start >= end {
+		return Chunk{}, false
+	}
+	return Chunk{Text: text[start:end], Start: start, End: end, Headings: headings}, true
+}
+
+// splitAfter returns a level splitting after every match of the regular expression.
+func splitAfter(re *regexp.Regexp) level {
+	return func(text string, s span) []span {
+		var parts []span
+		start := s.start
+		for _, m := range re.FindAllStringIndex(text[s.start:s.end], -1) {
+			end := s.start + m[1]
+			if end > start && end < s.end {
+				parts = append(parts, span{start, end})
+				start = end
+			}
+		}
+		return append(parts, span{start, s.end})
+	}
+}
+
+var (
+	paragraphs = splitAfter(regexp.MustCompile(`\n[ \t]*\n\s*`))
+	lines      = splitAfter(regexp.MustCompile(`\n`))
+	// sentences end with punctuation, optionally followed by closing quotes or brackets, and then whitespace
+	sentences = splitAfter(regexp.MustCompile(`[.!?…]+["'”’)\]]*\s+`))
+	words     = splitAfter(regexp.MustCompile(`\s+`))
+)
@@ -0,0 +1,169 @@
+package chunk_test
+
+import (
+	"strings"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai/chunk"
+)
+
+func TestRecursive(t *testing.T) {
+	t.Run("returns short text as a single trimmed chunk", func(t *testing.T) {
+		chunks := chunk.Recursive("  Hello, World!\n", chunk.Options{})
+		is.Equal(t, 1, len(chunks))
+		is.Equal(t, "Hello, World!", chunks[0].Text)
+		is.Equal(t, 2, chunks[0].Start)
+		is.Equal(t, 15, chunks[0].End)
+	})
+
+	t.Run("returns no chunks for empty text", func(t *testing.T) {
+		is.Equal(t, 0, len(chunk.Recursive(" \n\n ", chunk.Options{})))
+	})
+
+	t.Run("splits at paragraphs before sentences", func(t *testing.T) {
+		text := "First sentence. Second sentence.\n\nThird sentence. Fourth sentence."
+		chunks := chunk.Recursive(text, chunk.Options{Size: 40})
+		is.Equal(t, 2, len(chunks))
+		is.Equal(t, "First sentence. Second sentence.", chunks[0].Text)
+		is.Equal(t, "Third sentence. Fourth sentence.", chunks[1].Text)
+		requireOffsets(t, text, chunks)
+	})
+
+	t.Run("splits long paragraphs at sentences, merging them up to the size", func(t *testing.T) {
+		text := "One is first. Two is second! Three is third? Four is fourth."
+		chunks := chunk.Recursive(text, chunk.Options{Size: 30})
+		is.Equal(t, 3, len(chunks))
+		is.Equal(t, "One is first. Two is second!", chunks[0].Text)
+		is.Equal(t, "Three is third?", chunks[1].Text)
+		is.Equal(t, "Four is fourth.", chunks[2].Text)
+		requireOffsets(t, text, chunks)
+	})
+
+	t.Run("splits long words at characters", func(t *testing.T) {
+		text := "abcdefghij"
+		chunks := chunk.Recursive(text, chunk.Options{Size: 4})
+		is.Equal(t, 3, len(chunks))
+		is.Equal(t, "abcd", chunks[0].Text)
+		is.Equal(t, "efgh", chunks[1].Text)
+		is.Equal(t, "ij", chunks[2].Text)
+		requireOffsets(t, text, chunks)
+	})
+
+	t.Run("overlaps chunks", func(t *testing.T) {
+		text := "one two three four five six seven eight"
+		chunks := chunk.Recursive(text, chunk.Options{Size: 14, Overlap: 6})
+		is.Equal(t, "one two three", chunks[0].Text)
+		is.Equal(t, "three four", chunks[1].Text)
+		is.Equal(t, "four five six", chunks[2].Text)
+		requireOffsets(t, text, chunks)
+
+		for _, c := range chunks {
+			is.True(t, len(c.Text) <= 14)
+		}
+	})
+
+	t.Run("measures size with the length function", func(t *testing.T) {
+		text := strings.Repeat("word ", 100)
+		chunks := chunk.Recursive(text, chunk.Options{Size: 10, Length: chunk.ApproximateTokens})
+		for _, c := range chunks {
+			is.True(t, chunk.ApproximateTokens(c.Text) <= 10)
+		}
+		requireOffsets(t, text, chunks)
+	})
+
+	t.Run("keeps multibyte characters whole", func(t *testing.T) {
+		text := "æøåæøå"
+		chunks := chunk.Recursive(text, chunk.Options{Size: 4})
+		is.Equal(t, 2, len(chunks))
+		is.Equal(t, "æøåæ", chunks[0].Text)
+		is.Equal(t, "øå", chunks[1].Text)
+		requireOffsets(t, text, chunks)
+	})
+}
+
+func TestMarkdown(t *testing.T) {
+	t.Run("splits at headings and records them", func(t *testing.T) {
+		text := `Intro text.
+
+# Guide
+
+## Install
+
+Run go get.
+
+## Usage
+
+Import the package.
+
+# Reference
+
+See the docs.
+`
+		chunks := chunk.Markdown(text, chunk.Options{})
+		is.Equal(t, 4, len(chunks))
+
+		is.Equal(t, "Intro text.", chunks[0].Text)
+		is.Equal(t, 0, len(chunks[0].Headings))
+
+		is.Equal(t, "# Guide\n\n## Install\n\nRun go get.", chunks[1].Text)
+		is.EqualSlice(t, []string{"Guide", "Install"}, chunks[1].Headings)
+
+		is.Equal(t, "## Usage\n\nImport the package.", chunks[2].Text)
+		is.EqualSlice(t, []string{"Guide", "Usage"}, chunks[2].Headings)
+
+		is.Equal(t, "# Reference\n\nSee the docs.", chunks[3].Text)
+		is.EqualSlice(t, []string{"Reference"}, chunks[3].Headings)
+
+		requireOffsets(t, text, chunks)
+	})
+
+	t.Run("keeps fenced code blocks together and ignores headings in them", func(t *testing.T) {
+		text := "# Example\n\nSome text here.\n\n```sh\n# not a heading\n\necho hi\n```\n\nMore text here."
+		chunks := chunk.Markdown(text, chunk.Options{Size: 40})
+		is.Equal(t, 3, len(chunks))
+		is.Equal(t, "# Example\n\nSome text here.", chunks[0].Text)
+		is.Equal(t, "```sh\n# not a heading\n\necho hi\n```", chunks[1].Text)
+		is.Equal(t, "More text here.", chunks[2].Text)
+
+		for _, c := range chunks {
+			is.EqualSlice(t, []string{"Example"}, c.Headings)
+		}
+		requireOffsets(t, text, chunks)
+	})
+}
+
+func TestCode(t *testing.T) {
+	t.Run("splits at blank lines, then lines", func(t *testing.T) {
+		text := "func a() {\n\treturn\n}\n\nfunc b() {\n\treturn\n}\n"
+		chunks := chunk.Code(text, chunk.Options{Size: 25})
+		is.Equal(t, 2, len(chunks))
+		is.Equal(t, "func a() {\n\treturn\n}", chunks[0].Text)
+		is.Equal(t, "func b() {\n\treturn\n}", chunks[1].Text)
+		requireOffsets(t, text, chunks)
+	})
+}
+
+func TestSentences(t *testing.T) {
+	t.Run("splits at sentence boundaries", func(t *testing.T) {
+		text := `He said "Hi." Then he left! Did he? Yes… version 1.2 is out.`
+		chunks := chunk.Sentences(text)
+
+		var sentences []string
+		for _, c := range chunks {
+			sentences = append(sentences, c.Text)
+		}
+		is.EqualSlice(t, []string{`He said "Hi."`, "Then he left!", "Did he?", "Yes…", "version 1.2 is out."}, sentences)
+		requireOffsets(t, text, chunks)
+	})
+}
+
+// requireOffsets of the chunks to match the text.
+func requireOffsets(t *testing.T, text string, chunks []chunk.Chunk) {
+	t.Helper()
+
+	for _, c := range chunks {
+		is.Equal(t, c.Text, text[c.Start:c.End])
+	}
+}
@@ -0,0 +1,128 @@
+package chunk
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// section of Markdown under a heading, including the heading line.
+type section struct {
+	span
+	headings []string
+}
+
+var headingRE = regexp.MustCompile(`^(#{1,6})[ \t]+(.*?)[ \t#]*$`)
+
+// markdownSections splits Markdown at headings outside fenced code blocks.
+// Text before the first heading is a section without headings.
+func markdownSections(text string) []section {
+	var sections []section
+	var stack []string
+	current := section{}
+	var f fence
+
+	for start := 0; start < len(text); {
+		end := strings.IndexByte(text[start:], '\n') + 1
+		if end == 0 {
+			end = len(text)
+		} else {
+			end += start
+		}
+		line := strings.TrimRight(text[start:end], "\r\n")
+
+		if !f.update(line) {
+			if m := headingRE.FindStringSubmatch(line); m != nil {
+				current.end = start
+				sections = append(sections, current)
+
+				// A heading replaces the headings at its level and below
+				level := len(m[1])
+				stack = append(stack[:min(len(stack), level-1)], m[2])
+				current = section{span: span{start: start}, headings: slices.Clone(stack)}
+			}
+		}
+
+		start = end
+	}
+	current.end = len(text)
+	sections = append(sections, current)
+
+	// Join sections with only a heading to the next one, and drop empty ones
+	var joined []section
+	start := -1
+	for i, sec := range sections {
+		if strings.TrimSpace(text[sec.start:sec.end]) == "" {
+			continue
+		}
+		if start < 0 {
+			start = sec.start
+		}
+		if i < len(sections)-1 && sec.headings != nil && isOnlyHeading(text[sec.start:sec.end]) {
+			continue
+		}
+		joined = append(joined, section{span: span{start, sec.end}, headings: sec.headings})
+		start = -1
+	}
+	return joined
+}
+
+func isOnlyHeading(text string) bool {
+	_, rest, _ := strings.Cut(text, "\n")
+	return strings.TrimSpace(rest) == ""
+}
+
+// fence tracks whether lines are in a fenced code block.
+type fence struct {
+	marker string
+}
+
+var fenceRE = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+
+// update the fence with the line, and report whether the line is part of a fenced code block,
+// including the opening and closing lines.
+func (f *fence) update(line string) bool {
+	m := fenceRE.FindStringSubmatch(line)
+	if f.marker == "" {
+		if m != nil {
+			f.marker = m[1]
+			return true
+		}
+		return false
+	}
+
+	if m != nil && m[1][0] == f.marker[0] && len(m[1]) >= len(f.marker) && strings.TrimSpace(line[len(m[0]):]) == "" {
+		f.marker = ""
+	}
+	return true
+}
+
+// markdownBlocks splits a span at blank lines outside fenced code blocks.
+func markdownBlocks(text string, s span) []span {
+	var parts []span
+	var f fence
+	partStart := s.start
+	blank := false
+
+	for start := s.start; start < s.end; {
+		end := strings.IndexByte(text[start:s.end], '\n') + 1
+		if end == 0 {
+			end = s.end
+		} else {
+			end += start
+		}
+		line := text[start:end]
+
+		inFence := f.update(strings.TrimRight(line, "\r\n"))
+		isBlank := !inFence && strings.TrimSpace(line) == ""
+
+		// Start a new block at the first non-blank line after blank lines
+		if blank && !isBlank && start > partStart {
+			parts = append(parts, span{partStart, start})
+			partStart = start
+		}
+		blank = isBlank
+		start = end
+	}
+	return append(parts, span{partStart, s.end})
+}
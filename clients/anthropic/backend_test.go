@@ -0,0 +1,295 @@
+package anthropic_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/clients/anthropic"
+)
+
+// messageEvents is a minimal Messages API stream that answers "Hi!".
+var messageEvents = []string{
+	`{"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude","content":[],"stop_reason":null,"usage":{"input_tokens":5,"output_tokens":1}}}`,
+	`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+	`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hi!"}}`,
+	`{"type":"content_block_stop","index":0}`,
+	`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":2}}`,
+	`{"type":"message_stop"}`,
+}
+
+func TestNewClient_Bedrock(t *testing.T) {
+	t.Run("signs requests and decodes the event stream", func(t *testing.T) {
+		var got *http.Request
+		var body map[string]any
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
+			data, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(data, &body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+			for _, event := range messageEvents {
+				payload, _ := json.Marshal(map[string]string{"bytes": base64.StdEncoding.EncodeToString([]byte(event))})
+				_, _ = w.Write(encodeEventStreamMessage(map[string]string{
+					":message-type": "event",
+					":event-type":   "chunk",
+					":content-type": "application/json",
+				}, payload))
+			}
+		}))
+		t.Cleanup(srv.Close)
+
+		t.Setenv("ANTHROPIC_API_KEY", "must-not-leak")
+		c := anthropic.NewClient(anthropic.NewClientOptions{
+			Backend:         anthropic.BackendBedrock,
+			BaseURL:         srv.URL,
+			Region:          "eu-central-1",
+			AccessKeyID:     "AKIDEXAMPLE",
+			SecretAccessKey: "secret",
+			SessionToken:    "session",
+		})
+		cc := c.NewChatCompleter(anthropic.NewChatCompleterOptions{Model: "anthropic.claude-sonnet-4-5-20250929-v1:0"})
+
+		requireChatCompletes(t, cc)
+
+		is.Equal(t, "/model/anthropic.claude-sonnet-4-5-20250929-v1:0/invoke-with-response-stream", got.URL.Path)
+		is.True(t, strings.HasPrefix(got.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"))
+		is.True(t, strings.Contains(got.Header.Get("Authorization"), "/eu-central-1/bedrock/aws4_request"))
+		is.Equal(t, "session", got.Header.Get("X-Amz-Security-Token"))
+		is.Equal(t, "", got.Header.Get("X-Api-Key"))
+
+		is.Equal(t, "bedrock-2023-05-31", body["anthropic_version"])
+		_, ok := body["model"]
+		is.True(t, !ok)
+		_, ok = body["stream"]
+		is.True(t, !ok)
+	})
+
+	t.Run("keeps a path prefix in the base URL", func(t *testing.T) {
+		var got *http.Request
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
+			w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+			for _, event := range messageEvents {
+				payload, _ := json.Marshal(map[string]string{"bytes": base64.StdEncoding.EncodeToString([]byte(event))})
+				_, _ = w.Write(encodeEventStreamMessage(map[string]string{
+					":message-type": "event",
+					":event-type":   "chunk",
+					":content-type": "application/json",
+				}, payload))
+			}
+		}))
+		t.Cleanup(srv.Close)
+
+		c := anthropic.NewClient(anthropic.NewClientOptions{
+			Backend:         anthropic.BackendBedrock,
+			BaseURL:         srv.URL + "/gateway/bedrock/",
+			Region:          "eu-central-1",
+			AccessKeyID:     "AKIDEXAMPLE",
+			SecretAccessKey: "secret",
+		})
+		cc := c.NewChatCompleter(anthropic.NewChatCompleterOptions{Model: "anthropic.claude-sonnet-4-5-20250929-v1:0"})
+
+		requireChatCompletes(t, cc)
+
+		is.Equal(t, "/gateway/bedrock/model/anthropic.claude-sonnet-4-5-20250929-v1:0/invoke-with-response-stream", got.URL.Path)
+	})
+
+	t.Run("reads credentials and region from the environment", func(t *testing.T) {
+		var got *http.Request
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
+			w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+			_, _ = w.Write(encodeEventStreamMessage(map[string]string{
+				":message-type":   "exception",
+				":exception-type": "throttlingException",
+			}, []byte(`{"message":"Too many requests"}`)))
+		}))
+		t.Cleanup(srv.Close)
+
+		t.Setenv("AWS_ACCESS_KEY_ID", "AKIDENV")
+		t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+		t.Setenv("AWS_SESSION_TOKEN", "")
+		t.Setenv("AWS_REGION", "us-west-2")
+		c := anthropic.NewClient(anthropic.NewClientOptions{Backend: anthropic.BackendBedrock, BaseURL: srv.URL})
+		cc := c.NewChatCompleter(anthropic.NewChatCompleterOptions{Model: "anthropic.claude-haiku-4-5-20251001-v1:0"})
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{Messages: []gai.Message{gai.NewUserTextMessage("Hi!")}})
+		is.NotError(t, err)
+
+		var streamErr error
+		for _, err := range res.Parts() {
+			if err != nil {
+				streamErr = err
+			}
+		}
+		is.True(t, streamErr != nil)
+		is.True(t, strings.Contains(streamErr.Error(), "throttlingException: Too many requests"))
+
+		is.True(t, strings.HasPrefix(got.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDENV/"))
+		is.True(t, strings.Contains(got.Header.Get("Authorization"), "/us-west-2/bedrock/aws4_request"))
+	})
+}
+
+func TestNewClient_VertexAI(t *testing.T) {
+	t.Run("authorizes with a service account and routes to the publisher model", func(t *testing.T) {
+		var got *http.Request
+		var body map[string]any
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprint(w, `{"access_token":"vertex-token","token_type":"Bearer","expires_in":3600}`)
+		})
+		mux.HandleFunc("POST /v1/projects/my-project/locations/us-east5/publishers/anthropic/models/", func(w http.ResponseWriter, r *http.Request) {
+			got = r
+			data, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(data, &body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, event := range messageEvents {
+				var e struct {
+					Type string `json:"type"`
+				}
+				_ = json.Unmarshal([]byte(event), &e)
+				_, _ = fmt.Fprintf(w, "event: %v\ndata: %v\n\n", e.Type, event)
+			}
+		})
+		srv := httptest.NewServer(mux)
+		t.Cleanup(srv.Close)
+
+		c := anthropic.NewClient(anthropic.NewClientOptions{
+			Backend:         anthropic.BackendVertexAI,
+			BaseURL:         srv.URL,
+			CredentialsPath: writeServiceAccount(t, srv.URL+"/token"),
+			Location:        "us-east5",
+		})
+		cc := c.NewChatCompleter(anthropic.NewChatCompleterOptions{Model: "claude-sonnet-4-5@20250929"})
+
+		requireChatCompletes(t, cc)
+
+		is.Equal(t, "/v1/projects/my-project/locations/us-east5/publishers/anthropic/models/claude-sonnet-4-5@20250929:streamRawPredict", got.URL.Path)
+		is.Equal(t, "Bearer vertex-token", got.Header.Get("Authorization"))
+		is.Equal(t, "vertex-2023-10-16", body["anthropic_version"])
+		_, ok := body["model"]
+		is.True(t, !ok)
+	})
+
+	t.Run("keeps a path prefix in the base URL", func(t *testing.T) {
+		var got *http.Request
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprint(w, `{"access_token":"vertex-token","token_type":"Bearer","expires_in":3600}`)
+		})
+		mux.HandleFunc("POST /gateway/vertex/v1/projects/my-project/locations/us-east5/publishers/anthropic/models/", func(w http.ResponseWriter, r *http.Request) {
+			got = r
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, event := range messageEvents {
+				var e struct {
+					Type string `json:"type"`
+				}
+				_ = json.Unmarshal([]byte(event), &e)
+				_, _ = fmt.Fprintf(w, "event: %v\ndata: %v\n\n", e.Type, event)
+			}
+		})
+		srv := httptest.NewServer(mux)
+		t.Cleanup(srv.Close)
+
+		c := anthropic.NewClient(anthropic.NewClientOptions{
+			Backend:         anthropic.BackendVertexAI,
+			BaseURL:         srv.URL + "/gateway/vertex/",
+			CredentialsPath: writeServiceAccount(t, srv.URL+"/token"),
+			Location:        "us-east5",
+		})
+		cc := c.NewChatCompleter(anthropic.NewChatCompleterOptions{Model: "claude-sonnet-4-5@20250929"})
+
+		requireChatCompletes(t, cc)
+
+		is.Equal(t, "/gateway/vertex/v1/projects/my-project/locations/us-east5/publishers/anthropic/models/claude-sonnet-4-5@20250929:streamRawPredict", got.URL.Path)
+	})
+}
+
+func requireChatCompletes(t *testing.T, cc *anthropic.ChatCompleter) {
+	t.Helper()
+
+	res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+		Messages: []gai.Message{gai.NewUserTextMessage("Hi!")},
+	})
+	is.NotError(t, err)
+
+	var output string
+	for part, err := range res.Parts() {
+		is.NotError(t, err)
+		output += part.Text()
+	}
+	is.Equal(t, "Hi!", output)
+}
+
+// encodeEventStreamMessage encodes an AWS event stream message with string headers.
+func encodeEventStreamMessage(headers map[string]string, payload []byte) []byte {
+	var h bytes.Buffer
+	for name, value := range headers {
+		h.WriteByte(byte(len(name)))
+		h.WriteString(name)
+		h.WriteByte(7)
+		_ = binary.Write(&h, binary.BigEndian, uint16(len(value)))
+		h.WriteString(value)
+	}
+
+	var m bytes.Buffer
+	_ = binary.Write(&m, binary.BigEndian, uint32(16+h.Len()+len(payload)))
+	_ = binary.Write(&m, binary.BigEndian, uint32(h.Len()))
+	_ = binary.Write(&m, binary.BigEndian, crc32.ChecksumIEEE(m.Bytes()))
+	m.Write(h.Bytes())
+	m.Write(payload)
+	_ = binary.Write(&m, binary.BigEndian, crc32.ChecksumIEEE(m.Bytes()))
+	return m.Bytes()
+}
+
+// writeServiceAccount writes a service account key file with a fresh RSA key whose tokens are
+// minted at tokenURL, and returns its path.
+func writeServiceAccount(t *testing.T, tokenURL string) string {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	is.NotError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	is.NotError(t, err)
+
+	data, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "my-project",
+		"private_key_id": "key-1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"client_email":   "gai@my-project.iam.gserviceaccount.com",
+		"token_uri":      tokenURL,
+	})
+	is.NotError(t, err)
+
+	path := filepath.Join(t.TempDir(), "service-account.json")
+	is.NotError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
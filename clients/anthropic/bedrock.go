@@ -0,0 +1,391 @@
+package anthropic
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+// bedrockVersion is the anthropic_version Bedrock expects in the request body.
+const bedrockVersion = "bedrock-2023-05-31"
+
+// awsCredentials are static AWS credentials for SigV4 signing.
+type awsCredentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// bedrockMiddleware adapts Messages API requests to Bedrock's InvokeModel API: it moves the
+// model and stream flag from the body to the URL, moves beta flags from the header to the
+// body, signs the request with SigV4, and translates streamed responses from the AWS event
+// stream encoding back to server-sent events, so the SDK sees the first-party wire format.
+func bedrockMiddleware(region string, creds awsCredentials) option.Middleware {
+	return func(r *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		// The SDK may pick up an Anthropic key from the environment; never send it to AWS.
+		r.Header.Del("X-Api-Key")
+
+		var body []byte
+		if r.Body != nil {
+			var err error
+			body, err = io.ReadAll(r.Body)
+			if err != nil {
+				return nil, err
+			}
+			_ = r.Body.Close()
+
+			// Match by suffix, so base URLs with a path prefix, like proxies and gateways, keep it.
+			prefix, isMessages := strings.CutSuffix(r.URL.Path, "/v1/messages")
+			if r.Method == http.MethodPost && isMessages {
+				rawPrefix, _ := strings.CutSuffix(r.URL.EscapedPath(), "/v1/messages")
+
+				var fields map[string]json.RawMessage
+				if err := json.Unmarshal(body, &fields); err != nil {
+					return nil, fmt.Errorf("error parsing request body: %w", err)
+				}
+
+				var model string
+				var stream bool
+				_ = json.Unmarshal(fields["model"], &model)
+				_ = json.Unmarshal(fields["stream"], &stream)
+				delete(fields, "model")
+				delete(fields, "stream")
+
+				if _, ok := fields["anthropic_version"]; !ok {
+					fields["anthropic_version"], _ = json.Marshal(bedrockVersion)
+				}
+				if betas := r.Header.Values("anthropic-beta"); len(betas) > 0 {
+					r.Header.Del("anthropic-beta")
+					fields["anthropic_beta"], _ = json.Marshal(betas)
+				}
+
+				body, err = json.Marshal(fields)
+				if err != nil {
+					return nil, err
+				}
+
+				action := "invoke"
+				if stream {
+					action = "invoke-with-response-stream"
+				}
+				r.URL.Path = fmt.Sprintf("%v/model/%v/%v", prefix, model, action)
+				r.URL.RawPath = fmt.Sprintf("%v/model/%v/%v", rawPrefix, url.QueryEscape(model), action)
+			}
+
+			setBody(r, body)
+		}
+
+		signV4(r, body, creds, region, "bedrock", time.Now())
+
+		res, err := next(r)
+		if err != nil || res == nil {
+			return res, err
+		}
+
+		// Error responses stay untranslated, so the SDK can build its error from the raw body.
+		mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+		if res.StatusCode < 400 && mediaType == "application/vnd.amazon.eventstream" {
+			res.Body = &eventStreamSSEBody{rc: res.Body}
+			res.Header.Set("Content-Type", "text/event-stream")
+			res.Header.Del("Content-Length")
+			res.ContentLength = -1
+		}
+
+		return res, nil
+	}
+}
+
+// setBody replaces the request body with body, keeping it replayable for retries.
+func setBody(r *http.Request, body []byte) {
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	r.ContentLength = int64(len(body))
+}
+
+// signV4 signs r with AWS Signature Version 4, setting the X-Amz-Date, X-Amz-Security-Token,
+// and Authorization headers. See https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_sigv.html.
+func signV4(r *http.Request, body []byte, creds awsCredentials, region, service string, t time.Time) {
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+
+	r.Header.Set("X-Amz-Date", amzDate)
+	if creds.sessionToken != "" {
+		r.Header.Set("X-Amz-Security-Token", creds.sessionToken)
+	}
+
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+
+	headers := map[string]string{"host": host}
+	for _, name := range []string{"Content-Type", "X-Amz-Date", "X-Amz-Security-Token"} {
+		if v := r.Header.Get(name); v != "" {
+			headers[strings.ToLower(name)] = strings.TrimSpace(v)
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := r.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		awsURIEncode(path, false),
+		canonicalQuery(r.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalRequestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	r.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%v/%v, SignedHeaders=%v, Signature=%v",
+		creds.accessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalQuery encodes query parameters sorted by key and value, as SigV4 requires.
+func canonicalQuery(query url.Values) string {
+	var pairs []string
+	for k, vs := range query {
+		for _, v := range vs {
+			pairs = append(pairs, awsURIEncode(k, true)+"="+awsURIEncode(v, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// awsURIEncode percent-encodes every byte except the unreserved characters, and slashes
+// unless encodeSlash is set.
+func awsURIEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// eventStreamSSEBody reads an AWS event stream and serves it as server-sent events.
+// See https://docs.aws.amazon.com/transcribe/latest/dg/streaming-setting-up.html#streaming-event-stream
+// for the binary message format.
+type eventStreamSSEBody struct {
+	rc  io.ReadCloser
+	buf bytes.Buffer
+	err error
+}
+
+func (b *eventStreamSSEBody) Read(p []byte) (int, error) {
+	// Buffered events drain before an error surfaces, so events decoded ahead of a
+	// mid-stream exception still reach the reader.
+	for b.buf.Len() == 0 {
+		if b.err != nil {
+			return 0, b.err
+		}
+
+		headers, payload, err := readEventStreamMessage(b.rc)
+		if err != nil {
+			b.err = err
+			continue
+		}
+		b.err = b.translate(headers, payload)
+	}
+	return b.buf.Read(p)
+}
+
+func (b *eventStreamSSEBody) Close() error {
+	return b.rc.Close()
+}
+
+// translate writes an event stream message to the buffer as a server-sent event, or returns
+// the error it carries.
+func (b *eventStreamSSEBody) translate(headers map[string]string, payload []byte) error {
+	switch headers[":message-type"] {
+	case "event":
+		if headers[":event-type"] != "chunk" {
+			return nil
+		}
+
+		var chunk struct {
+			Bytes string `json:"bytes"`
+		}
+		if err := json.Unmarshal(payload, &chunk); err != nil {
+			return fmt.Errorf("error parsing event stream chunk: %w", err)
+		}
+		data, err := base64.StdEncoding.DecodeString(chunk.Bytes)
+		if err != nil {
+			return fmt.Errorf("error decoding event stream chunk: %w", err)
+		}
+		var event struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(data, &event); err != nil {
+			return fmt.Errorf("error parsing event: %w", err)
+		}
+
+		b.buf.WriteString("event: " + event.Type + "\n")
+		for line := range bytes.SplitSeq(data, []byte("\n")) {
+			b.buf.WriteString("data: ")
+			b.buf.Write(line)
+			b.buf.WriteByte('\n')
+		}
+		b.buf.WriteByte('\n')
+		return nil
+
+	case "exception":
+		var info struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(payload, &info)
+		return fmt.Errorf("bedrock exception %v: %v", headers[":exception-type"], info.Message)
+
+	case "error":
+		return fmt.Errorf("bedrock error %v: %v", headers[":error-code"], headers[":error-message"])
+
+	default:
+		return fmt.Errorf("unknown event stream message type %q", headers[":message-type"])
+	}
+}
+
+// maxEventStreamMessageLength is the largest event stream message AWS sends, 16 MiB.
+// Longer messages are rejected before allocating them.
+const maxEventStreamMessageLength = 16 * 1024 * 1024
+
+// readEventStreamMessage reads one binary event stream message, verifying its checksums.
+// Only string-valued headers are returned; others are skipped.
+func readEventStreamMessage(r io.Reader) (map[string]string, []byte, error) {
+	var prelude [12]byte
+	if _, err := io.ReadFull(r, prelude[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, nil, fmt.Errorf("truncated event stream message: %w", err)
+		}
+		return nil, nil, err
+	}
+
+	totalLength := binary.BigEndian.Uint32(prelude[0:4])
+	headersLength := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		return nil, nil, errors.New("event stream prelude checksum mismatch")
+	}
+	if totalLength < 16 || headersLength > totalLength-16 {
+		return nil, nil, errors.New("invalid event stream message length")
+	}
+	if totalLength > maxEventStreamMessageLength {
+		return nil, nil, fmt.Errorf("event stream message of %v bytes is longer than the maximum of %v bytes", totalLength, maxEventStreamMessageLength)
+	}
+
+	message := make([]byte, totalLength)
+	copy(message, prelude[:])
+	if _, err := io.ReadFull(r, message[12:]); err != nil {
+		return nil, nil, fmt.Errorf("truncated event stream message: %w", err)
+	}
+	if crc32.ChecksumIEEE(message[:totalLength-4]) != binary.BigEndian.Uint32(message[totalLength-4:]) {
+		return nil, nil, errors.New("event stream message checksum mismatch")
+	}
+
+	headers, err := parseEventStreamHeaders(message[12 : 12+headersLength])
+	if err != nil {
+		return nil, nil, err
+	}
+	return headers, message[12+headersLength : totalLength-4], nil
+}
+
+func parseEventStreamHeaders(b []byte) (map[string]string, error) {
+	headers := map[string]string{}
+	for len(b) > 0 {
+		nameLength := int(b[0])
+		if len(b) < 1+nameLength+1 {
+			return nil, errors.New("truncated event stream header")
+		}
+		name := string(b[1 : 1+nameLength])
+		valueType := b[1+nameLength]
+		b = b[2+nameLength:]
+
+		// Value sizes by type: bool true/false, byte, short, int, long, bytes, string, timestamp, UUID.
+		var size int
+		switch valueType {
+		case 0, 1:
+			size = 0
+		case 2:
+			size = 1
+		case 3:
+			size = 2
+		case 4:
+			size = 4
+		case 5, 8:
+			size = 8
+		case 9:
+			size = 16
+		case 6, 7:
+			if len(b) < 2 {
+				return nil, errors.New("truncated event stream header")
+			}
+			size = int(binary.BigEndian.Uint16(b))
+			b = b[2:]
+		default:
+			return nil, fmt.Errorf("unknown event stream header type %v", valueType)
+		}
+		if len(b) < size {
+			return nil, errors.New("truncated event stream header")
+		}
+		if valueType == 7 {
+			headers[name] = string(b[:size])
+		}
+		b = b[size:]
+	}
+	return headers, nil
+}
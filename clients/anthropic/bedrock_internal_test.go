@@ -0,0 +1,49 @@
+package anthropic
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"net/http"
+	"testing"
+	"time"
+
+	"maragu.dev/is"
+)
+
+func TestSignV4(t *testing.T) {
+	t.Run("matches the AWS get-vanilla test vector", func(t *testing.T) {
+		r, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+		is.NotError(t, err)
+
+		creds := awsCredentials{accessKeyID: "AKIDEXAMPLE", secretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+		signV4(r, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+		is.Equal(t, "20150830T123600Z", r.Header.Get("X-Amz-Date"))
+		is.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+			"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+			r.Header.Get("Authorization"))
+	})
+}
+
+func TestAWSURIEncode(t *testing.T) {
+	t.Run("double-encodes escaped path segments but keeps slashes", func(t *testing.T) {
+		is.Equal(t, "/model/anthropic.claude-v1%253A0/invoke", awsURIEncode("/model/anthropic.claude-v1%3A0/invoke", false))
+	})
+
+	t.Run("encodes slashes in query values", func(t *testing.T) {
+		is.Equal(t, "a%2Fb%20c", awsURIEncode("a/b c", true))
+	})
+}
+
+func TestReadEventStreamMessage(t *testing.T) {
+	t.Run("returns error for a message longer than the maximum without reading it", func(t *testing.T) {
+		var prelude [12]byte
+		binary.BigEndian.PutUint32(prelude[0:4], 1<<32-1)
+		binary.BigEndian.PutUint32(prelude[4:8], 0)
+		binary.BigEndian.PutUint32(prelude[8:12], crc32.ChecksumIEEE(prelude[:8]))
+
+		_, _, err := readEventStreamMessage(bytes.NewReader(prelude[:]))
+		is.Equal(t, "event stream message of 4294967295 bytes is longer than the maximum of 16777216 bytes", err.Error())
+	})
+}
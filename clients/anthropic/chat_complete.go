@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -49,7 +50,7 @@ const (
 // level — so non-`None` levels populate both. There is no Minimal: the Anthropic enum starts
 // at Low. XHigh is currently Opus-4.7-only; Sonnet 4.6 and Opus 4.6 reject it with a 400.
 // Pass [gai.ThinkingLevelNone] to opt out of thinking entirely (no fields set). Levels not
-// in this list panic at the client boundary.
+// in this list are rejected with a [gai.ValidationError] at the client boundary.
 const (
 	// ThinkingLevelLow applies low reasoning effort.
 	ThinkingLevelLow gai.ThinkingLevel = "low"
@@ -93,8 +94,16 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 		),
 	)
 
+	// invalid records a [gai.ValidationError] on the span and ends it, for caller data we cannot send.
+	invalid := func(err *gai.ValidationError) (gai.ChatCompleteResponse, error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request")
+		span.End()
+		return gai.ChatCompleteResponse{}, err
+	}
+
 	if len(req.Messages) == 0 {
-		panic("no messages")
+		return invalid(gai.NewValidationError("Messages", "no messages"))
 	}
 
 	if err := req.ToolChoice.Validate(req.Tools); err != nil {
@@ -105,10 +114,10 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 	}
 
 	var messages []anthropic.MessageParam
-	for _, m := range req.Messages {
+	for i, m := range req.Messages {
 		var parts []anthropic.ContentBlockParamUnion
 
-		for _, part := range m.Parts {
+		for j, part := range m.Parts {
 			switch part.Type {
 			case gai.PartTypeText:
 				parts = append(parts, anthropic.ContentBlockParamUnion{
@@ -124,6 +133,7 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 				err := fmt.Errorf("anthropic: %w", errThoughtRoundTripUnsupported)
 				span.RecordError(err)
 				span.SetStatus(codes.Error, "unsupported part type")
+				span.End()
 				return gai.ChatCompleteResponse{}, err
 
 			case gai.PartTypeToolCall:
@@ -160,10 +170,10 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 
 			case gai.PartTypeData:
 				if part.MIMEType == "" {
-					panic("data part has empty MIME type")
+					return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].MIMEType", i, j), "data part has empty MIME type"))
 				}
 				if len(part.Data) == 0 {
-					panic("data part has empty data")
+					return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].Data", i, j), "data part has empty data"))
 				}
 				encoded := base64.StdEncoding.EncodeToString(part.Data)
 
@@ -192,11 +202,11 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 					})
 
 				default:
-					panic("unsupported MIME type for Anthropic: " + part.MIMEType)
+					return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].MIMEType", i, j), "unsupported MIME type for Anthropic: "+part.MIMEType))
 				}
 
 			default:
-				panic("unknown part type " + string(part.Type))
+				return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].Type", i, j), "unknown part type "+string(part.Type)))
 			}
 		}
 
@@ -207,7 +217,7 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 		case gai.MessageRoleModel:
 			role = anthropic.MessageParamRoleAssistant
 		default:
-			panic("unknown role " + m.Role)
+			return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Role", i), "unknown role "+string(m.Role)))
 		}
 
 		messages = append(messages, anthropic.MessageParam{
@@ -223,9 +233,7 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 			OfTool: &anthropic.ToolParam{
 				Name:        tool.Name,
 				Description: anthropic.String(tool.Description),
-				InputSchema: anthropic.ToolInputSchemaParam{
-					Properties: tool.Schema.Properties,
-				},
+				InputSchema: toolInputSchema(tool.Schema),
 			},
 		})
 		toolNames = append(toolNames, tool.Name)
@@ -303,7 +311,7 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 			params.Thinking = anthropic.ThinkingConfigParamUnion{OfAdaptive: &anthropic.ThinkingConfigAdaptiveParam{}}
 			params.OutputConfig.Effort = anthropic.OutputConfigEffortMax
 		default:
-			panic("unsupported thinking level: " + string(*req.ThinkingLevel))
+			return invalid(gai.NewValidationError("ThinkingLevel", "unsupported thinking level: "+string(*req.ThinkingLevel)))
 		}
 		span.SetAttributes(attribute.String("ai.thinking_level", string(*req.ThinkingLevel)))
 	}
@@ -411,6 +419,28 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 	}), nil
 }
 
+// toolInputSchema converts a [gai.ToolSchema] to the input schema of an Anthropic tool.
+func toolInputSchema(schema gai.ToolSchema) anthropic.ToolInputSchemaParam {
+	inputSchema := anthropic.ToolInputSchemaParam{
+		Properties: schema.Properties,
+		Required:   schema.Required,
+	}
+	extra := map[string]any{}
+	if schema.Description != "" {
+		extra["description"] = schema.Description
+	}
+	if schema.AdditionalProperties != nil {
+		extra["additionalProperties"] = *schema.AdditionalProperties
+	}
+	if len(schema.Defs) > 0 {
+		extra["$defs"] = schema.Defs
+	}
+	if len(extra) > 0 {
+		inputSchema.ExtraFields = extra
+	}
+	return inputSchema
+}
+
 // schemaToMap converts a gai.Schema to a map[string]any for the Anthropic API.
 func schemaToMap(schema *gai.Schema) map[string]any {
 	if schema == nil {
@@ -427,71 +457,67 @@ func schemaToMap(schema *gai.Schema) map[string]any {
 		panic(err)
 	}
 
-	ensureAdditionalPropertiesFalse(obj)
-	removeUnsupportedFields(obj)
+	toStructuredOutputSchema(obj)
 	return obj
 }
 
-// ensureAdditionalPropertiesFalse recursively sets additionalProperties to false
-// on all object-type schemas, as required by the Anthropic structured output API.
-func ensureAdditionalPropertiesFalse(obj map[string]any) {
+// toStructuredOutputSchema recursively rewrites a JSON Schema object to the subset the Anthropic
+// structured output API accepts: objects never allow additional properties, oneOf becomes anyOf,
+// and unsupported fields like propertyOrdering and length constraints are removed.
+func toStructuredOutputSchema(obj map[string]any) {
 	if obj == nil {
 		return
 	}
 
-	if t, ok := obj["type"].(string); ok && t == "object" {
-		if _, ok := obj["additionalProperties"]; !ok {
-			obj["additionalProperties"] = false
-		}
-		if props, ok := obj["properties"].(map[string]any); ok {
-			for _, v := range props {
-				if child, ok := v.(map[string]any); ok {
-					ensureAdditionalPropertiesFalse(child)
-				}
-			}
-		}
-	}
-
-	if items, ok := obj["items"].(map[string]any); ok {
-		ensureAdditionalPropertiesFalse(items)
+	if isObjectType(obj["type"]) {
+		obj["additionalProperties"] = false
 	}
 
-	if anyOf, ok := obj["anyOf"].([]any); ok {
-		for _, v := range anyOf {
-			if child, ok := v.(map[string]any); ok {
-				ensureAdditionalPropertiesFalse(child)
-			}
-		}
-	}
-}
-
-// removeUnsupportedFields recursively removes fields not supported by the Anthropic schema API.
-func removeUnsupportedFields(obj map[string]any) {
-	if obj == nil {
-		return
+	if oneOf, ok := obj["oneOf"].([]any); ok {
+		anyOf, _ := obj["anyOf"].([]any)
+		obj["anyOf"] = append(anyOf, oneOf...)
+		delete(obj, "oneOf")
 	}
 
 	delete(obj, "propertyOrdering")
+	delete(obj, "minLength")
+	delete(obj, "maxLength")
 
-	if props, ok := obj["properties"].(map[string]any); ok {
-		for _, v := range props {
-			if child, ok := v.(map[string]any); ok {
-				removeUnsupportedFields(child)
+	for _, key := range []string{"properties", "$defs"} {
+		if children, ok := obj[key].(map[string]any); ok {
+			for _, v := range children {
+				if child, ok := v.(map[string]any); ok {
+					toStructuredOutputSchema(child)
+				}
 			}
 		}
 	}
 
 	if items, ok := obj["items"].(map[string]any); ok {
-		removeUnsupportedFields(items)
+		toStructuredOutputSchema(items)
 	}
 
-	if anyOf, ok := obj["anyOf"].([]any); ok {
-		for _, v := range anyOf {
-			if child, ok := v.(map[string]any); ok {
-				removeUnsupportedFields(child)
+	for _, key := range []string{"anyOf", "allOf"} {
+		if children, ok := obj[key].([]any); ok {
+			for _, v := range children {
+				if child, ok := v.(map[string]any); ok {
+					toStructuredOutputSchema(child)
+				}
 			}
 		}
 	}
 }
 
+// isObjectType reports whether a JSON Schema type, alone or in a list of types, is object.
+func isObjectType(t any) bool {
+	switch t := t.(type) {
+	case string:
+		return t == "object"
+	case []any:
+		return slices.Contains(t, any("object"))
+	default:
+		return false
+	}
+}
+
 var _ gai.ChatCompleter = (*ChatCompleter)(nil)
@@ -0,0 +1,56 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+)
+
+type category struct {
+	Name   string    `json:"name" jsonschema:"minLength=1"`
+	Parent *category `json:"parent" jsonschema:"nullable"`
+}
+
+func TestSchemaToMap(t *testing.T) {
+	t.Run("keeps definitions and rewrites the schema to the structured output subset", func(t *testing.T) {
+		schema := gai.GenerateSchema[category]()
+		schema.Properties["kind"] = &gai.Schema{OneOf: []*gai.Schema{{Const: "leaf"}, {Const: "branch"}}}
+
+		obj := schemaToMap(&schema)
+
+		is.Equal(t, false, obj["additionalProperties"])
+		_, ok := obj["propertyOrdering"]
+		is.True(t, !ok)
+
+		properties := obj["properties"].(map[string]any)
+		_, ok = properties["name"].(map[string]any)["minLength"]
+		is.True(t, !ok)
+		is.Equal(t, 2, len(properties["kind"].(map[string]any)["anyOf"].([]any)))
+
+		def := obj["$defs"].(map[string]any)["category"].(map[string]any)
+		is.Equal(t, false, def["additionalProperties"])
+		_, ok = def["propertyOrdering"]
+		is.True(t, !ok)
+	})
+}
+
+func TestToolInputSchema(t *testing.T) {
+	t.Run("sends definitions next to the properties", func(t *testing.T) {
+		data, err := json.Marshal(toolInputSchema(gai.GenerateToolSchema[category]()))
+		is.NotError(t, err)
+
+		var obj map[string]any
+		is.NotError(t, json.Unmarshal(data, &obj))
+		is.Equal(t, "object", obj["type"])
+
+		parent := obj["properties"].(map[string]any)["parent"].(map[string]any)
+		is.Equal(t, "#/$defs/category", parent["anyOf"].([]any)[0].(map[string]any)["$ref"])
+		_, ok := obj["$defs"].(map[string]any)["category"]
+		is.True(t, ok)
+		is.Equal(t, 2, len(obj["required"].([]any)))
+		is.Equal(t, false, obj["additionalProperties"])
+	})
+}
@@ -3,6 +3,7 @@ package anthropic_test
 import (
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"os"
 	"strings"
 	"testing"
@@ -348,32 +349,28 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 		is.True(t, len(output) > 0, "should have output")
 	})
 
-	t.Run("panics on unsupported MIME type", func(t *testing.T) {
+	t.Run("returns a validation error with no messages", func(t *testing.T) {
 		cc := newChatCompleter(t)
 
-		defer func() {
-			r := recover()
-			is.True(t, r != nil)
-			is.Equal(t, "unsupported MIME type for Anthropic: audio/wav", r)
-		}()
+		_, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{})
+		requireValidationError(t, err, "Messages", "no messages")
+	})
+
+	t.Run("returns a validation error on unsupported MIME type", func(t *testing.T) {
+		cc := newChatCompleter(t)
 
 		req := gai.ChatCompleteRequest{
 			Messages: []gai.Message{
 				gai.NewUserDataMessage("audio/wav", []byte("fake audio")),
 			},
 		}
-		_, _ = cc.ChatComplete(t.Context(), req)
+		_, err := cc.ChatComplete(t.Context(), req)
+		requireValidationError(t, err, "Messages[0].Parts[0].MIMEType", "unsupported MIME type for Anthropic: audio/wav")
 	})
 
-	t.Run("panics on empty MIME type", func(t *testing.T) {
+	t.Run("returns a validation error on empty MIME type", func(t *testing.T) {
 		cc := newChatCompleter(t)
 
-		defer func() {
-			r := recover()
-			is.True(t, r != nil)
-			is.Equal(t, "data part has empty MIME type", r)
-		}()
-
 		req := gai.ChatCompleteRequest{
 			Messages: []gai.Message{
 				{Role: gai.MessageRoleUser, Parts: []gai.Part{
@@ -381,18 +378,13 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 				}},
 			},
 		}
-		_, _ = cc.ChatComplete(t.Context(), req)
+		_, err := cc.ChatComplete(t.Context(), req)
+		requireValidationError(t, err, "Messages[0].Parts[0].MIMEType", "data part has empty MIME type")
 	})
 
-	t.Run("panics on empty data", func(t *testing.T) {
+	t.Run("returns a validation error on empty data", func(t *testing.T) {
 		cc := newChatCompleter(t)
 
-		defer func() {
-			r := recover()
-			is.True(t, r != nil)
-			is.Equal(t, "data part has empty data", r)
-		}()
-
 		req := gai.ChatCompleteRequest{
 			Messages: []gai.Message{
 				{Role: gai.MessageRoleUser, Parts: []gai.Part{
@@ -400,7 +392,8 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 				}},
 			},
 		}
-		_, _ = cc.ChatComplete(t.Context(), req)
+		_, err := cc.ChatComplete(t.Context(), req)
+		requireValidationError(t, err, "Messages[0].Parts[0].Data", "data part has empty data")
 	})
 
 	// Thinking-level matrix. Each row exercises a real (model, level) combination so the
@@ -512,9 +505,9 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 		is.True(t, strings.Contains(err.Error(), "PartTypeThought"), err.Error())
 	})
 
-	t.Run("panics on unsupported thinking level", func(t *testing.T) {
+	t.Run("returns a validation error on unsupported thinking level", func(t *testing.T) {
 		// The Anthropic client publishes Low/Medium/High/XHigh/Max. Anything outside
-		// that set must panic at the boundary, not silently round-trip to the API.
+		// that set must be rejected at the boundary, not silently round-trip to the API.
 		tests := []struct {
 			name  string
 			level gai.ThinkingLevel
@@ -527,19 +520,12 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 			t.Run(test.name, func(t *testing.T) {
 				cc := newChatCompleter(t)
 
-				defer func() {
-					r := recover()
-					is.True(t, r != nil, "expected a panic")
-					msg, ok := r.(string)
-					is.True(t, ok, "panic value should be a string")
-					is.Equal(t, "unsupported thinking level: "+string(test.level), msg)
-				}()
-
 				req := gai.ChatCompleteRequest{
 					Messages:      []gai.Message{gai.NewUserTextMessage("Hi!")},
 					ThinkingLevel: gai.Ptr(test.level),
 				}
-				_, _ = cc.ChatComplete(t.Context(), req)
+				_, err := cc.ChatComplete(t.Context(), req)
+				requireValidationError(t, err, "ThinkingLevel", "unsupported thinking level: "+string(test.level))
 			})
 		}
 	})
@@ -642,6 +628,15 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 	})
 }
 
+// requireValidationError fails the test unless err is a [gai.ValidationError] for the given field and message.
+func requireValidationError(t *testing.T, err error, field, message string) {
+	t.Helper()
+	var validationErr *gai.ValidationError
+	is.True(t, errors.As(err, &validationErr), "expected a validation error")
+	is.Equal(t, field, validationErr.Field)
+	is.Equal(t, message, validationErr.Err.Error())
+}
+
 // drainParts iterates the response stream, returning the first error if any.
 func drainParts(t *testing.T, res gai.ChatCompleteResponse) error {
 	t.Helper()
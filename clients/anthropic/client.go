@@ -1,33 +1,166 @@
 // Package anthropic provides a [gai.ChatCompleter] implementation backed by
-// the Anthropic Messages API. Construct a [Client] with [NewClient], then
-// derive a chat completer via [Client.NewChatCompleter]. Anthropic does not
-// expose embeddings, so this package has no Embedder.
+// the Anthropic Messages API, either first-party or via Amazon Bedrock or Google
+// Vertex AI. Construct a [Client] with [NewClient], then derive a chat completer
+// via [Client.NewChatCompleter]. Anthropic does not expose embeddings, so this
+// package has no Embedder.
 package anthropic
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"os"
+	"strings"
 
+	"cloud.google.com/go/auth/credentials"
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
 )
 
+// Backend is the API backend to reach Claude through.
+type Backend string
+
+const (
+	// BackendAnthropic is the first-party Anthropic API.
+	BackendAnthropic Backend = "anthropic"
+	// BackendBedrock is Amazon Bedrock.
+	BackendBedrock Backend = "bedrock"
+	// BackendVertexAI is Google Vertex AI.
+	BackendVertexAI Backend = "vertexai"
+)
+
 type Client struct {
 	Client anthropic.Client
 	log    *slog.Logger
 }
 
 type NewClientOptions struct {
+	// AccessKeyID, SecretAccessKey, and SessionToken are static AWS credentials for
+	// [BackendBedrock]. When AccessKeyID is empty, they are read from the AWS_ACCESS_KEY_ID,
+	// AWS_SECRET_ACCESS_KEY, and AWS_SESSION_TOKEN environment variables.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Backend defaults to [BackendAnthropic].
+	Backend Backend
+	// BaseURL overrides the backend's endpoint, for proxies and private endpoints.
+	BaseURL string
+	// CredentialsPath is the path to a service account JSON key file for [BackendVertexAI].
+	// The project ID is read from the JSON's project_id field. Required for [BackendVertexAI].
+	CredentialsPath string
+	// Key is the API key for [BackendAnthropic].
 	Key string
-	Log *slog.Logger
+	// Location is the Vertex AI location (e.g. "global", "us-east5", "europe-west1").
+	// Used only by [BackendVertexAI]. Defaults to "global" when empty.
+	Location string
+	Log      *slog.Logger
+	// Region is the AWS region for [BackendBedrock], such as "us-east-1". When empty, it is
+	// read from the AWS_REGION or AWS_DEFAULT_REGION environment variables.
+	Region string
 }
 
+// NewClient creates a [Client] for the configured backend. On [BackendBedrock] and
+// [BackendVertexAI], the model passed to [Client.NewChatCompleter] is the backend's model ID,
+// such as "anthropic.claude-sonnet-4-5-20250929-v1:0" or "claude-sonnet-4-5@20250929".
+// Panics if the backend's credentials cannot be found.
 func NewClient(opts NewClientOptions) *Client {
 	if opts.Log == nil {
 		opts.Log = slog.New(slog.DiscardHandler)
 	}
 
+	var clientOpts []option.RequestOption
+
+	switch opts.Backend {
+	case BackendBedrock:
+		creds := awsCredentials{
+			accessKeyID:     opts.AccessKeyID,
+			secretAccessKey: opts.SecretAccessKey,
+			sessionToken:    opts.SessionToken,
+		}
+		if creds.accessKeyID == "" {
+			creds = awsCredentials{
+				accessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+				secretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+				sessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			}
+		}
+		if creds.accessKeyID == "" || creds.secretAccessKey == "" {
+			panic("AWS credentials must be set for the Bedrock backend")
+		}
+
+		region := opts.Region
+		if region == "" {
+			region = os.Getenv("AWS_REGION")
+		}
+		if region == "" {
+			region = os.Getenv("AWS_DEFAULT_REGION")
+		}
+		if region == "" {
+			panic("AWS region must be set for the Bedrock backend")
+		}
+
+		baseURL := opts.BaseURL
+		if baseURL == "" {
+			baseURL = fmt.Sprintf("https://bedrock-runtime.%v.amazonaws.com/", region)
+		}
+
+		clientOpts = append(clientOpts,
+			option.WithBaseURL(baseURL),
+			option.WithMiddleware(bedrockMiddleware(region, creds)),
+		)
+
+	case BackendVertexAI:
+		if opts.CredentialsPath == "" {
+			panic("credentials path must be set for the Vertex AI backend")
+		}
+		creds, err := credentials.DetectDefault(&credentials.DetectOptions{
+			CredentialsFile: opts.CredentialsPath,
+			Scopes:          []string{"https://www.googleapis.com/auth/cloud-platform"},
+		})
+		if err != nil {
+			panic(err)
+		}
+		project, err := creds.ProjectID(context.Background())
+		if err != nil {
+			panic(err)
+		}
+
+		location := opts.Location
+		if location == "" {
+			location = "global"
+		}
+
+		baseURL := opts.BaseURL
+		if baseURL == "" {
+			baseURL = vertexBaseURL(location)
+		}
+
+		clientOpts = append(clientOpts,
+			option.WithBaseURL(baseURL),
+			option.WithMiddleware(vertexMiddleware(location, project, creds)),
+		)
+
+	default:
+		if opts.BaseURL != "" {
+			clientOpts = append(clientOpts, option.WithBaseURL(opts.BaseURL))
+		}
+		clientOpts = append(clientOpts, option.WithAPIKey(opts.Key))
+	}
+
 	return &Client{
-		Client: anthropic.NewClient(option.WithAPIKey(opts.Key)),
+		Client: anthropic.NewClient(clientOpts...),
 		log:    opts.Log,
 	}
 }
+
+// vertexBaseURL returns the Vertex AI endpoint for a location.
+func vertexBaseURL(location string) string {
+	switch location {
+	case "global":
+		return "https://aiplatform.googleapis.com/"
+	case "us", "eu":
+		return fmt.Sprintf("https://aiplatform.%v.rep.googleapis.com/", location)
+	default:
+		return fmt.Sprintf("https://%v-aiplatform.googleapis.com/", strings.ToLower(location))
+	}
+}
@@ -0,0 +1,74 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"cloud.google.com/go/auth"
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+// vertexVersion is the anthropic_version Vertex AI expects in the request body.
+const vertexVersion = "vertex-2023-10-16"
+
+// vertexMiddleware adapts Messages API requests to Vertex AI's rawPredict API: it moves the
+// model from the body to the URL and authorizes the request with an OAuth token from creds.
+// Vertex AI streams server-sent events like the first-party API, so responses pass through.
+func vertexMiddleware(location, project string, creds *auth.Credentials) option.Middleware {
+	return func(r *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		// The SDK may pick up an Anthropic key from the environment; never send it to Google.
+		r.Header.Del("X-Api-Key")
+
+		if r.Body != nil {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				return nil, err
+			}
+			_ = r.Body.Close()
+
+			// Match by suffix, so base URLs with a path prefix, like proxies and gateways, keep it.
+			prefix, isMessages := strings.CutSuffix(r.URL.Path, "/v1/messages")
+			if r.Method == http.MethodPost && isMessages {
+				var fields map[string]json.RawMessage
+				if err := json.Unmarshal(body, &fields); err != nil {
+					return nil, fmt.Errorf("error parsing request body: %w", err)
+				}
+
+				var model string
+				var stream bool
+				_ = json.Unmarshal(fields["model"], &model)
+				_ = json.Unmarshal(fields["stream"], &stream)
+				delete(fields, "model")
+
+				if _, ok := fields["anthropic_version"]; !ok {
+					fields["anthropic_version"], _ = json.Marshal(vertexVersion)
+				}
+
+				body, err = json.Marshal(fields)
+				if err != nil {
+					return nil, err
+				}
+
+				specifier := "rawPredict"
+				if stream {
+					specifier = "streamRawPredict"
+				}
+				r.URL.Path = fmt.Sprintf("%v/v1/projects/%v/locations/%v/publishers/anthropic/models/%v:%v", prefix, project, location, model, specifier)
+				r.URL.RawPath = ""
+			}
+
+			setBody(r, body)
+		}
+
+		token, err := creds.Token(r.Context())
+		if err != nil {
+			return nil, fmt.Errorf("error getting Vertex AI token: %w", err)
+		}
+		r.Header.Set("Authorization", "Bearer "+token.Value)
+
+		return next(r)
+	}
+}
@@ -0,0 +1,483 @@
+package cohere
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/internal/sse"
+)
+
+// ChatCompleteModel is a Cohere model identifier accepted by the v2 chat surface. See
+// https://docs.cohere.com/docs/models for the full list and the capabilities of each model.
+type ChatCompleteModel string
+
+const (
+	ChatCompleteModelCommandA          = ChatCompleteModel("command-a-03-2025")
+	ChatCompleteModelCommandAReasoning = ChatCompleteModel("command-a-reasoning-08-2025")
+	ChatCompleteModelCommandAVision    = ChatCompleteModel("command-a-vision-07-2025")
+	ChatCompleteModelCommandR7B        = ChatCompleteModel("command-r7b-12-2024")
+)
+
+// Per-client [gai.ThinkingLevel] constants. Cohere's reasoning models take an on/off switch
+// with an optional token budget, so the levels map onto budgets: low and medium cap thinking,
+// high leaves it to the model. Pass [gai.ThinkingLevelNone] to turn thinking off. Levels not
+// in this list are rejected with a [gai.ValidationError] at the client boundary.
+const (
+	// ThinkingLevelLow caps thinking at 1024 tokens.
+	ThinkingLevelLow gai.ThinkingLevel = "low"
+	// ThinkingLevelMedium caps thinking at 4096 tokens.
+	ThinkingLevelMedium gai.ThinkingLevel = "medium"
+	// ThinkingLevelHigh enables thinking without a budget.
+	ThinkingLevelHigh gai.ThinkingLevel = "high"
+)
+
+type ChatCompleter struct {
+	Client *Client
+	log    *slog.Logger
+	model  ChatCompleteModel
+	tracer trace.Tracer
+}
+
+type NewChatCompleterOptions struct {
+	Model ChatCompleteModel
+}
+
+func (c *Client) NewChatCompleter(opts NewChatCompleterOptions) *ChatCompleter {
+	return &ChatCompleter{
+		Client: c,
+		log:    c.log,
+		model:  opts.Model,
+		tracer: otel.Tracer("maragu.dev/gai/clients/cohere"),
+	}
+}
+
+type chatRequest struct {
+	Model          string          `json:"model"`
+	Messages       []chatMessage   `json:"messages"`
+	Stream         bool            `json:"stream"`
+	Temperature    *float64        `json:"temperature,omitempty"`
+	MaxTokens      *int            `json:"max_tokens,omitempty"`
+	Tools          []chatTool      `json:"tools,omitempty"`
+	ToolChoice     string          `json:"tool_choice,omitempty"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+	Thinking       *thinking       `json:"thinking,omitempty"`
+}
+
+type chatMessage struct {
+	Role       string         `json:"role"`
+	Content    any            `json:"content,omitempty"`
+	ToolCalls  []chatToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+type contentItem struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+}
+
+type imageURL struct {
+	URL string `json:"url"`
+}
+
+type chatTool struct {
+	Type     string       `json:"type"`
+	Function chatFunction `json:"function"`
+}
+
+type chatFunction struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Parameters  gai.Schema `json:"parameters"`
+}
+
+type chatToolCall struct {
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type responseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *gai.Schema `json:"json_schema,omitempty"`
+}
+
+type thinking struct {
+	Type        string `json:"type"`
+	TokenBudget int    `json:"token_budget,omitempty"`
+}
+
+type streamEvent struct {
+	Type  string `json:"type"`
+	Index int    `json:"index"`
+	Delta struct {
+		Message struct {
+			Content struct {
+				Text     string `json:"text"`
+				Thinking string `json:"thinking"`
+			} `json:"content"`
+			ToolCalls chatToolCall `json:"tool_calls"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+		Usage        *struct {
+			BilledUnits struct {
+				InputTokens  float64 `json:"input_tokens"`
+				OutputTokens float64 `json:"output_tokens"`
+			} `json:"billed_units"`
+			Tokens struct {
+				InputTokens  float64 `json:"input_tokens"`
+				OutputTokens float64 `json:"output_tokens"`
+			} `json:"tokens"`
+		} `json:"usage"`
+	} `json:"delta"`
+}
+
+// ChatComplete satisfies [gai.ChatCompleter].
+func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRequest) (gai.ChatCompleteResponse, error) {
+	ctx, span := c.tracer.Start(ctx, "cohere.chat_complete",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("ai.model", string(c.model)),
+			attribute.Int("ai.message_count", len(req.Messages)),
+		),
+	)
+
+	if err := req.ToolChoice.Validate(req.Tools); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid tool choice")
+		span.End()
+		return gai.ChatCompleteResponse{}, err
+	}
+
+	// invalid records a [gai.ValidationError] on the span and ends it, for caller data we cannot send.
+	invalid := func(err *gai.ValidationError) (gai.ChatCompleteResponse, error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request")
+		span.End()
+		return gai.ChatCompleteResponse{}, err
+	}
+
+	if len(req.Messages) == 0 {
+		return invalid(gai.NewValidationError("Messages", "no messages"))
+	}
+
+	// Cohere cannot force one named tool, and sending only that tool would change the tools the model sees
+	if req.ToolChoice.Mode == gai.ToolChoiceModeTool {
+		return invalid(gai.NewValidationError("ToolChoice.Mode", "Cohere cannot force a named tool, use ToolChoiceModeAny"))
+	}
+
+	var messages []chatMessage
+
+	if req.System != nil {
+		messages = append(messages, chatMessage{Role: "system", Content: *req.System})
+		span.SetAttributes(attribute.Bool("ai.has_system_prompt", true))
+	}
+
+	for i, m := range req.Messages {
+		switch m.Role {
+		case gai.MessageRoleUser:
+			var items []contentItem
+
+			for j, part := range m.Parts {
+				switch part.Type {
+				case gai.PartTypeText:
+					items = append(items, contentItem{Type: "text", Text: part.Text()})
+
+				case gai.PartTypeToolResult:
+					// Tool results are separate messages, so flush the content so far to keep the order.
+					if len(items) > 0 {
+						messages = append(messages, chatMessage{Role: "user", Content: items})
+					}
+					items = nil
+
+					toolResult := part.ToolResult()
+					content := toolResult.Content
+					if toolResult.Err != nil {
+						content = fmt.Sprintf("Error: %s", toolResult.Err)
+					}
+					messages = append(messages, chatMessage{
+						Role:       "tool",
+						Content:    content,
+						ToolCallID: toolResult.ID,
+					})
+
+				case gai.PartTypeData:
+					if part.MIMEType == "" {
+						return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].MIMEType", i, j), "data part has empty MIME type"))
+					}
+					if len(part.Data) == 0 {
+						return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].Data", i, j), "data part has empty data"))
+					}
+					if !strings.HasPrefix(part.MIMEType, "image/") {
+						return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].MIMEType", i, j), "unsupported MIME type for Cohere: "+part.MIMEType))
+					}
+					items = append(items, contentItem{
+						Type:     "image_url",
+						ImageURL: &imageURL{URL: "data:" + part.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(part.Data)},
+					})
+
+				case gai.PartTypeThought:
+					// Thinking only comes from the model, so there is nothing to send back.
+					continue
+
+				default:
+					return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].Type", i, j), "unknown part type "+string(part.Type)))
+				}
+			}
+
+			if len(items) > 0 {
+				messages = append(messages, chatMessage{Role: "user", Content: items})
+			}
+
+		case gai.MessageRoleModel:
+			var text strings.Builder
+			var toolCalls []chatToolCall
+
+			for j, part := range m.Parts {
+				switch part.Type {
+				case gai.PartTypeText:
+					text.WriteString(part.Text())
+
+				case gai.PartTypeToolCall:
+					toolCall := part.ToolCall()
+					call := chatToolCall{ID: toolCall.ID, Type: "function"}
+					call.Function.Name = toolCall.Name
+					call.Function.Arguments = string(toolCall.Args)
+					toolCalls = append(toolCalls, call)
+
+				case gai.PartTypeThought:
+					// Cohere does not take thinking back as input.
+					continue
+
+				default:
+					return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].Type", i, j), "unknown part type "+string(part.Type)))
+				}
+			}
+
+			message := chatMessage{Role: "assistant", ToolCalls: toolCalls}
+			if text.Len() > 0 {
+				message.Content = text.String()
+			}
+			if message.Content != nil || len(toolCalls) > 0 {
+				messages = append(messages, message)
+			}
+
+		default:
+			return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Role", i), "unknown role "+string(m.Role)))
+		}
+	}
+
+	var tools []chatTool
+	var toolNames []string
+	for _, tool := range req.Tools {
+		tools = append(tools, chatTool{
+			Type: "function",
+			Function: chatFunction{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Schema.Schema(),
+			},
+		})
+		toolNames = append(toolNames, tool.Name)
+	}
+	sort.Strings(toolNames)
+	span.SetAttributes(
+		attribute.Int("ai.tool_count", len(tools)),
+		attribute.StringSlice("ai.tools", toolNames),
+	)
+
+	body := chatRequest{
+		Model:    string(c.model),
+		Messages: messages,
+		Stream:   true,
+		Tools:    tools,
+	}
+
+	switch req.ToolChoice.Mode {
+	case gai.ToolChoiceModeAny:
+		body.ToolChoice = "REQUIRED"
+		span.SetAttributes(attribute.String("ai.tool_choice", string(req.ToolChoice.Mode)))
+	}
+
+	if req.Temperature != nil {
+		body.Temperature = gai.Ptr(req.Temperature.Float64())
+		span.SetAttributes(attribute.Float64("ai.temperature", req.Temperature.Float64()))
+	}
+
+	if req.MaxCompletionTokens != nil {
+		body.MaxTokens = req.MaxCompletionTokens
+		span.SetAttributes(attribute.Int("ai.max_completion_tokens", *req.MaxCompletionTokens))
+	}
+
+	if req.ThinkingLevel != nil {
+		switch *req.ThinkingLevel {
+		case gai.ThinkingLevelNone:
+			body.Thinking = &thinking{Type: "disabled"}
+		case ThinkingLevelLow:
+			body.Thinking = &thinking{Type: "enabled", TokenBudget: 1024}
+		case ThinkingLevelMedium:
+			body.Thinking = &thinking{Type: "enabled", TokenBudget: 4096}
+		case ThinkingLevelHigh:
+			body.Thinking = &thinking{Type: "enabled"}
+		default:
+			return invalid(gai.NewValidationError("ThinkingLevel", "unsupported thinking level: "+string(*req.ThinkingLevel)))
+		}
+		span.SetAttributes(attribute.String("ai.thinking_level", string(*req.ThinkingLevel)))
+	}
+
+	if req.ResponseSchema != nil {
+		body.ResponseFormat = &responseFormat{
+			Type:       "json_object",
+			JSONSchema: req.ResponseSchema,
+		}
+		span.SetAttributes(attribute.Bool("ai.has_response_schema", true))
+	}
+
+	streamStart := time.Now()
+
+	res, err := c.Client.post(ctx, "chat", body)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "chat completion request failed")
+		span.End()
+		return gai.ChatCompleteResponse{}, fmt.Errorf("error chat-completing: %w", err)
+	}
+
+	meta := &gai.ChatCompleteResponseMetadata{}
+	var firstTokenRecorded bool
+	recordFirstToken := func() {
+		if firstTokenRecorded {
+			return
+		}
+		firstTokenRecorded = true
+		span.SetAttributes(attribute.Int64("ai.time_to_first_token_ms", time.Since(streamStart).Milliseconds()))
+	}
+
+	r := gai.NewChatCompleteResponse(func(yield func(gai.Part, error) bool) {
+		defer span.End()
+
+		defer func() {
+			if err := res.Body.Close(); err != nil {
+				c.log.Info("Error closing stream", "error", err)
+			}
+		}()
+
+		// fail records err on the span and yields it as the final part.
+		fail := func(err error, description string) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, description)
+			yield(gai.Part{}, err)
+		}
+
+		// Tool call arguments stream in deltas between tool-call-start and tool-call-end.
+		var toolCall *chatToolCall
+
+		for e, err := range sse.Read(res.Body) {
+			if err != nil {
+				fail(err, "stream error")
+				return
+			}
+
+			var event streamEvent
+			if err := json.Unmarshal(e.Data, &event); err != nil {
+				fail(fmt.Errorf("error parsing event: %w", err), "stream error")
+				return
+			}
+
+			switch event.Type {
+			case "content-delta":
+				content := event.Delta.Message.Content
+				if content.Thinking != "" {
+					recordFirstToken()
+					if !yield(gai.ThoughtPart(content.Thinking), nil) {
+						return
+					}
+				}
+				if content.Text != "" {
+					recordFirstToken()
+					if !yield(gai.TextPart(content.Text), nil) {
+						return
+					}
+				}
+
+			case "tool-call-start":
+				recordFirstToken()
+				call := event.Delta.Message.ToolCalls
+				toolCall = &call
+
+			case "tool-call-delta":
+				if toolCall != nil {
+					toolCall.Function.Arguments += event.Delta.Message.ToolCalls.Function.Arguments
+				}
+
+			case "tool-call-end":
+				if toolCall != nil {
+					if !yield(gai.ToolCallPart(toolCall.ID, toolCall.Function.Name, json.RawMessage(toolCall.Function.Arguments)), nil) {
+						return
+					}
+					toolCall = nil
+				}
+
+			case "message-end":
+				if usage := event.Delta.Usage; usage != nil {
+					promptTokens := int(usage.Tokens.InputTokens)
+					completionTokens := int(usage.Tokens.OutputTokens)
+					meta.Usage = gai.ChatCompleteResponseUsage{
+						PromptTokens:     promptTokens,
+						CompletionTokens: completionTokens,
+					}
+					span.SetAttributes(
+						attribute.Int("ai.prompt_tokens", promptTokens),
+						attribute.Int("ai.completion_tokens", completionTokens),
+						attribute.Int("ai.total_tokens", promptTokens+completionTokens),
+					)
+				}
+
+				if event.Delta.FinishReason != "" {
+					mapped := mapFinishReason(event.Delta.FinishReason)
+					meta.FinishReason = gai.Ptr(mapped)
+					span.SetAttributes(attribute.String("ai.finish_reason", string(mapped)))
+
+					if event.Delta.FinishReason == "ERROR" || event.Delta.FinishReason == "TIMEOUT" {
+						fail(fmt.Errorf("generation ended with %v", event.Delta.FinishReason), "stream error")
+						return
+					}
+				}
+			}
+		}
+	})
+
+	r.Meta = meta
+
+	return r, nil
+}
+
+func mapFinishReason(reason string) gai.ChatCompleteFinishReason {
+	switch reason {
+	case "COMPLETE", "STOP_SEQUENCE":
+		return gai.ChatCompleteFinishReasonStop
+	case "MAX_TOKENS":
+		return gai.ChatCompleteFinishReasonLength
+	case "TOOL_CALL":
+		return gai.ChatCompleteFinishReasonToolCalls
+	default:
+		return gai.ChatCompleteFinishReasonUnknown
+	}
+}
+
+var _ gai.ChatCompleter = (*ChatCompleter)(nil)
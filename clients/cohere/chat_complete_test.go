@@ -0,0 +1,285 @@
+package cohere_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/clients/cohere"
+	"maragu.dev/gai/internal/oteltest"
+)
+
+func TestChatCompleter_ChatComplete(t *testing.T) {
+	t.Run("streams thinking and text and records usage", func(t *testing.T) {
+		sr := oteltest.NewSpanRecorder(t)
+
+		srv := newServer(t, "/chat",
+			`{"type":"message-start","id":"msg_1","delta":{"message":{"role":"assistant"}}}`,
+			`{"type":"content-start","index":0,"delta":{"message":{"content":{"type":"thinking","thinking":""}}}}`,
+			`{"type":"content-delta","index":0,"delta":{"message":{"content":{"thinking":"Hmm."}}}}`,
+			`{"type":"content-end","index":0}`,
+			`{"type":"content-start","index":1,"delta":{"message":{"content":{"type":"text","text":""}}}}`,
+			`{"type":"content-delta","index":1,"delta":{"message":{"content":{"text":"Hello"}}}}`,
+			`{"type":"content-delta","index":1,"delta":{"message":{"content":{"text":" there!"}}}}`,
+			`{"type":"content-end","index":1}`,
+			`{"type":"message-end","delta":{"finish_reason":"COMPLETE","usage":{"billed_units":{"input_tokens":8,"output_tokens":18},"tokens":{"input_tokens":10,"output_tokens":20}}}}`,
+		)
+		cc := newChatCompleter(t, srv.URL)
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages:            []gai.Message{gai.NewUserTextMessage("Hi!")},
+			System:              gai.Ptr("Be nice."),
+			ThinkingLevel:       gai.Ptr(cohere.ThinkingLevelMedium),
+			MaxCompletionTokens: gai.Ptr(100),
+		})
+		is.NotError(t, err)
+
+		var thought, output string
+		for part, err := range res.Parts() {
+			is.NotError(t, err)
+			switch part.Type {
+			case gai.PartTypeThought:
+				thought += part.Thought()
+			case gai.PartTypeText:
+				output += part.Text()
+			default:
+				t.Fatal("unexpected part type", part.Type)
+			}
+		}
+		is.Equal(t, "Hmm.", thought)
+		is.Equal(t, "Hello there!", output)
+
+		is.Equal(t, gai.ChatCompleteFinishReasonStop, *res.Meta.FinishReason)
+		is.Equal(t, 10, res.Meta.Usage.PromptTokens)
+		is.Equal(t, 20, res.Meta.Usage.CompletionTokens)
+
+		body := srv.body(t)
+		is.Equal(t, "command-a-03-2025", body["model"])
+		is.Equal(t, true, body["stream"])
+		thinking := body["thinking"].(map[string]any)
+		is.Equal(t, "enabled", thinking["type"])
+		is.Equal(t, any(float64(4096)), thinking["token_budget"])
+		is.Equal(t, any(float64(100)), body["max_tokens"])
+		is.Equal(t, "Bearer secret", srv.request.Header.Get("Authorization"))
+
+		span := oteltest.FindSpan(t, sr.Ended(), "cohere.chat_complete")
+		attrs := span.Attributes()
+		is.True(t, oteltest.HasAttribute(attrs, attribute.String("ai.model", "command-a-03-2025")))
+		is.True(t, oteltest.HasAttribute(attrs, attribute.String("ai.thinking_level", "medium")))
+		is.True(t, oteltest.HasAttribute(attrs, attribute.Int("ai.max_completion_tokens", 100)))
+		is.True(t, oteltest.HasAttribute(attrs, attribute.Int("ai.total_tokens", 30)))
+		is.True(t, oteltest.HasAttribute(attrs, attribute.String("ai.finish_reason", "stop")))
+	})
+
+	t.Run("streams tool calls and requires a tool call", func(t *testing.T) {
+		srv := newServer(t, "/chat",
+			`{"type":"tool-call-start","index":0,"delta":{"message":{"tool_calls":{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}}}}`,
+			`{"type":"tool-call-delta","index":0,"delta":{"message":{"tool_calls":{"function":{"arguments":"{\"city\":"}}}}}`,
+			`{"type":"tool-call-delta","index":0,"delta":{"message":{"tool_calls":{"function":{"arguments":"\"Copenhagen\"}"}}}}}`,
+			`{"type":"tool-call-end","index":0}`,
+			`{"type":"message-end","delta":{"finish_reason":"TOOL_CALL"}}`,
+		)
+		cc := newChatCompleter(t, srv.URL)
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{
+				gai.NewUserTextMessage("What's the weather?"),
+				{Role: gai.MessageRoleModel, Parts: []gai.Part{gai.ToolCallPart("call_0", "get_weather", json.RawMessage(`{"city":"Aarhus"}`))}},
+				gai.NewUserToolResultMessage(gai.ToolResult{ID: "call_0", Name: "get_weather", Content: "Sunny"}),
+			},
+			Tools: []gai.Tool{
+				{Name: "get_time", Description: "Get the time."},
+				{
+					Name:        "get_weather",
+					Description: "Get the weather.",
+					Schema:      gai.ToolSchema{Properties: map[string]*gai.Schema{"city": {Type: gai.SchemaTypeString}}},
+				},
+			},
+			ToolChoice: gai.ToolChoice{Mode: gai.ToolChoiceModeAny},
+		})
+		is.NotError(t, err)
+
+		var calls []gai.ToolCall
+		for part, err := range res.Parts() {
+			is.NotError(t, err)
+			if part.Type == gai.PartTypeToolCall {
+				calls = append(calls, part.ToolCall())
+			}
+		}
+		is.Equal(t, 1, len(calls))
+		is.Equal(t, "call_1", calls[0].ID)
+		is.Equal(t, "get_weather", calls[0].Name)
+		is.Equal(t, `{"city":"Copenhagen"}`, string(calls[0].Args))
+		is.Equal(t, gai.ChatCompleteFinishReasonToolCalls, *res.Meta.FinishReason)
+
+		body := srv.body(t)
+		is.Equal(t, "REQUIRED", body["tool_choice"])
+		tools := body["tools"].([]any)
+		is.Equal(t, 2, len(tools))
+		parameters := tools[1].(map[string]any)["function"].(map[string]any)["parameters"].(map[string]any)
+		is.Equal(t, "object", parameters["type"])
+		is.Equal(t, "string", parameters["properties"].(map[string]any)["city"].(map[string]any)["type"])
+
+		messages := body["messages"].([]any)
+		is.Equal(t, 3, len(messages))
+		tool := messages[2].(map[string]any)
+		is.Equal(t, "tool", tool["role"])
+		is.Equal(t, "call_0", tool["tool_call_id"])
+	})
+
+	t.Run("sends a JSON schema response format", func(t *testing.T) {
+		srv := newServer(t, "/chat",
+			`{"type":"content-delta","index":0,"delta":{"message":{"content":{"text":"{}"}}}}`,
+			`{"type":"message-end","delta":{"finish_reason":"COMPLETE"}}`,
+		)
+		cc := newChatCompleter(t, srv.URL)
+
+		type answer struct {
+			Answer string `json:"answer"`
+		}
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages:       []gai.Message{gai.NewUserTextMessage("Hi!")},
+			ResponseSchema: gai.Ptr(gai.GenerateSchema[answer]()),
+		})
+		is.NotError(t, err)
+		is.NotError(t, drainParts(res))
+
+		format := srv.body(t)["response_format"].(map[string]any)
+		is.Equal(t, "json_object", format["type"])
+		_, ok := format["json_schema"]
+		is.True(t, ok)
+	})
+
+	t.Run("returns a stream error when generation fails", func(t *testing.T) {
+		srv := newServer(t, "/chat",
+			`{"type":"message-end","delta":{"finish_reason":"ERROR"}}`,
+		)
+		cc := newChatCompleter(t, srv.URL)
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("Hi!")},
+		})
+		is.NotError(t, err)
+		err = drainParts(res)
+		is.True(t, err != nil)
+		is.Equal(t, "generation ended with ERROR", err.Error())
+	})
+
+	t.Run("returns a validation error for a named tool choice", func(t *testing.T) {
+		cc := newChatCompleter(t, "http://localhost")
+
+		_, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages:   []gai.Message{gai.NewUserTextMessage("Hi!")},
+			Tools:      []gai.Tool{{Name: "get_time", Description: "Get the time."}},
+			ToolChoice: gai.ToolChoice{Mode: gai.ToolChoiceModeTool, Name: "get_time"},
+		})
+		requireValidationError(t, err, "ToolChoice.Mode", "Cohere cannot force a named tool, use ToolChoiceModeAny")
+	})
+
+	t.Run("returns a validation error for an unsupported thinking level", func(t *testing.T) {
+		cc := newChatCompleter(t, "http://localhost")
+
+		_, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages:      []gai.Message{gai.NewUserTextMessage("Hi!")},
+			ThinkingLevel: gai.Ptr(gai.ThinkingLevel("max")),
+		})
+		requireValidationError(t, err, "ThinkingLevel", "unsupported thinking level: max")
+	})
+
+	t.Run("includes the status code in request errors", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"message":"too many tokens"}`, http.StatusTooManyRequests)
+		}))
+		t.Cleanup(srv.Close)
+		cc := newChatCompleter(t, srv.URL)
+
+		_, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("Hi!")},
+		})
+		is.True(t, err != nil)
+		is.True(t, strings.Contains(err.Error(), "429 Too Many Requests"))
+	})
+}
+
+type server struct {
+	*httptest.Server
+	request  *http.Request
+	lastBody []byte
+}
+
+// body returns the last request body, decoded.
+func (s *server) body(t *testing.T) map[string]any {
+	t.Helper()
+	var body map[string]any
+	is.NotError(t, json.Unmarshal(s.lastBody, &body))
+	return body
+}
+
+// newServer starts a stand-in for the Cohere API that answers requests to path with the
+// given stream events.
+func newServer(t *testing.T, path string, events ...string) *server {
+	t.Helper()
+
+	s := &server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.request = r
+		s.lastBody = body
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range events {
+			var e struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal([]byte(event), &e); err != nil {
+				panic(err)
+			}
+			_, _ = fmt.Fprintf(w, "event: %v\ndata: %v\n\n", e.Type, event)
+		}
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func newChatCompleter(t *testing.T, baseURL string) *cohere.ChatCompleter {
+	t.Helper()
+	c := cohere.NewClient(cohere.NewClientOptions{BaseURL: baseURL, Key: "secret"})
+	return c.NewChatCompleter(cohere.NewChatCompleterOptions{Model: cohere.ChatCompleteModelCommandA})
+}
+
+func requireValidationError(t *testing.T, err error, field, message string) {
+	t.Helper()
+	var validationErr *gai.ValidationError
+	is.True(t, errors.As(err, &validationErr), "expected a validation error")
+	is.Equal(t, field, validationErr.Field)
+	is.Equal(t, message, validationErr.Err.Error())
+}
+
+// drainParts iterates the response stream, returning the first error if any.
+func drainParts(res gai.ChatCompleteResponse) error {
+	for _, err := range res.Parts() {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
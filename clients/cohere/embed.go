@@ -0,0 +1,204 @@
+package cohere
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"maragu.dev/errors"
+
+	"maragu.dev/gai"
+)
+
+type EmbedModel string
+
+const (
+	EmbedModelEmbedEnglishV3      = EmbedModel("embed-english-v3.0")
+	EmbedModelEmbedMultilingualV3 = EmbedModel("embed-multilingual-v3.0")
+	EmbedModelEmbedV4             = EmbedModel("embed-v4.0")
+)
+
+type Embedder struct {
+	Client     *Client
+	dimensions int
+	log        *slog.Logger
+	model      EmbedModel
+	tracer     trace.Tracer
+}
+
+type NewEmbedderOptions struct {
+	// Dimensions must be 256, 512, 1024, or 1536 for [EmbedModelEmbedV4], and 1024 for the v3 models.
+	Dimensions int
+	Model      EmbedModel
+}
+
+func (c *Client) NewEmbedder(opts NewEmbedderOptions) *Embedder {
+	if opts.Dimensions <= 0 {
+		panic("dimensions must be greater than 0")
+	}
+
+	switch opts.Model {
+	case EmbedModelEmbedV4:
+		if !slices.Contains([]int{256, 512, 1024, 1536}, opts.Dimensions) {
+			panic("dimensions must be 256, 512, 1024, or 1536")
+		}
+	case EmbedModelEmbedEnglishV3, EmbedModelEmbedMultilingualV3:
+		if opts.Dimensions != 1024 {
+			panic("dimensions must be 1024")
+		}
+	}
+
+	return &Embedder{
+		Client:     c,
+		dimensions: opts.Dimensions,
+		log:        c.log,
+		model:      opts.Model,
+		tracer:     otel.Tracer("maragu.dev/gai/clients/cohere"),
+	}
+}
+
+type embedRequest struct {
+	Model           string   `json:"model"`
+	Texts           []string `json:"texts"`
+	InputType       string   `json:"input_type"`
+	EmbeddingTypes  []string `json:"embedding_types"`
+	OutputDimension *int     `json:"output_dimension,omitempty"`
+}
+
+type embedResponse struct {
+	Embeddings struct {
+		Float [][]float64 `json:"float"`
+	} `json:"embeddings"`
+	Meta struct {
+		BilledUnits struct {
+			InputTokens int `json:"input_tokens"`
+		} `json:"billed_units"`
+	} `json:"meta"`
+}
+
+// Embed satisfies [gai.Embedder].
+// Text is embedded with the search_document input type, unless the request task maps to another input type:
+// the query tasks to search_query, and the classification and clustering tasks to their own.
+// Only text can be embedded, either a single part, or several parts with [gai.EmbedRequest.Separate].
+func (e *Embedder) Embed(ctx context.Context, req gai.EmbedRequest) (gai.EmbedResponse[float64], error) {
+	ctx, span := e.tracer.Start(ctx, "cohere.embed",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("ai.model", string(e.model)),
+			attribute.Int("ai.dimensions", e.dimensions),
+		),
+	)
+	defer span.End()
+
+	if len(req.Parts) == 0 {
+		err := gai.NewValidationError("Parts", "no parts")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request")
+		return gai.EmbedResponse[float64]{}, err
+	}
+
+	var texts []string
+	var inputLength int
+	for _, part := range req.Parts {
+		if part.Type == gai.PartTypeText {
+			texts = append(texts, part.Text())
+			inputLength += len(part.Text())
+		}
+	}
+	if len(texts) != len(req.Parts) || (!req.Separate && len(texts) != 1) {
+		message := "Cohere embeddings only support a single text part"
+		if req.Separate {
+			message = "Cohere embeddings only support text parts"
+		}
+		err := gai.NewValidationError("Parts", message)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request")
+		return gai.EmbedResponse[float64]{}, err
+	}
+	if req.Separate {
+		span.SetAttributes(attribute.Bool("ai.embed_separate", true))
+	}
+
+	inputType := "search_document"
+	switch req.Task {
+	case "", gai.EmbedTaskDocument, gai.EmbedTaskSemanticSimilarity:
+	case gai.EmbedTaskSearchQuery, gai.EmbedTaskQuestionAnswering, gai.EmbedTaskFactChecking, gai.EmbedTaskCodeRetrievalQuery:
+		inputType = "search_query"
+	case gai.EmbedTaskClassification:
+		inputType = "classification"
+	case gai.EmbedTaskClustering:
+		inputType = "clustering"
+	default:
+		err := gai.NewValidationError("Task", "unsupported embed task: "+string(req.Task))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request")
+		return gai.EmbedResponse[float64]{}, err
+	}
+	if req.Task != "" {
+		span.SetAttributes(attribute.String("ai.embed_task", string(req.Task)))
+	}
+
+	span.SetAttributes(attribute.Int("ai.input_length", inputLength))
+
+	body := embedRequest{
+		Model:          string(e.model),
+		Texts:          texts,
+		InputType:      inputType,
+		EmbeddingTypes: []string{"float"},
+	}
+	// The v3 models reject output_dimension, so only send it where it is configurable.
+	if e.model == EmbedModelEmbedV4 {
+		body.OutputDimension = gai.Ptr(e.dimensions)
+	}
+
+	httpRes, err := e.Client.post(ctx, "embed", body)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "embedding request failed")
+		return gai.EmbedResponse[float64]{}, errors.Wrap(err, "error embedding")
+	}
+	defer func() {
+		_ = httpRes.Body.Close()
+	}()
+
+	var res embedResponse
+	if err := json.NewDecoder(httpRes.Body).Decode(&res); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "embedding request failed")
+		return gai.EmbedResponse[float64]{}, errors.Wrap(err, "error decoding embedding response")
+	}
+	if len(res.Embeddings.Float) == 0 {
+		err := errors.New("no embeddings returned")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "no embeddings in response")
+		return gai.EmbedResponse[float64]{}, err
+	}
+	if len(res.Embeddings.Float) != len(texts) {
+		err := errors.Newf("expected %v embeddings, but got %v", len(texts), len(res.Embeddings.Float))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "wrong number of embeddings in response")
+		return gai.EmbedResponse[float64]{}, err
+	}
+
+	if res.Meta.BilledUnits.InputTokens > 0 {
+		span.SetAttributes(
+			attribute.Int("ai.prompt_tokens", res.Meta.BilledUnits.InputTokens),
+			attribute.Int("ai.total_tokens", res.Meta.BilledUnits.InputTokens),
+		)
+	}
+
+	if req.Separate {
+		return gai.EmbedResponse[float64]{Embeddings: res.Embeddings.Float}, nil
+	}
+
+	return gai.EmbedResponse[float64]{
+		Embedding: res.Embeddings.Float[0],
+	}, nil
+}
+
+var _ gai.Embedder[float64] = (*Embedder)(nil)
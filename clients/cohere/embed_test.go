@@ -0,0 +1,103 @@
+package cohere_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/clients/cohere"
+	"maragu.dev/gai/internal/oteltest"
+)
+
+func TestEmbedder_Embed(t *testing.T) {
+	t.Run("embeds text as a search document", func(t *testing.T) {
+		sr := oteltest.NewSpanRecorder(t)
+
+		var body map[string]any
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			is.Equal(t, "/embed", r.URL.Path)
+			data, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(data, &body)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprint(w, `{"embeddings":{"float":[[0.1,0.2,0.3]]},"meta":{"billed_units":{"input_tokens":3}}}`)
+		}))
+		t.Cleanup(srv.Close)
+
+		c := cohere.NewClient(cohere.NewClientOptions{BaseURL: srv.URL, Key: "secret"})
+		e := c.NewEmbedder(cohere.NewEmbedderOptions{Model: cohere.EmbedModelEmbedV4, Dimensions: 256})
+
+		res, err := e.Embed(t.Context(), gai.NewTextEmbedRequest("Hi!"))
+		is.NotError(t, err)
+		is.EqualSlice(t, []float64{0.1, 0.2, 0.3}, res.Embedding)
+
+		is.Equal(t, "embed-v4.0", body["model"])
+		is.Equal(t, "search_document", body["input_type"])
+		is.Equal(t, any(float64(256)), body["output_dimension"])
+
+		span := oteltest.FindSpan(t, sr.Ended(), "cohere.embed")
+		oteltest.RequirePositiveIntAttribute(t, span.Attributes(), "ai.prompt_tokens")
+	})
+
+	t.Run("embeds text with the input type of the task", func(t *testing.T) {
+		var body map[string]any
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(data, &body)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprint(w, `{"embeddings":{"float":[[0.1,0.2,0.3]]}}`)
+		}))
+		t.Cleanup(srv.Close)
+
+		c := cohere.NewClient(cohere.NewClientOptions{BaseURL: srv.URL, Key: "secret"})
+		e := c.NewEmbedder(cohere.NewEmbedderOptions{Model: cohere.EmbedModelEmbedV4, Dimensions: 256})
+
+		_, err := e.Embed(t.Context(), gai.EmbedRequest{Parts: []gai.Part{gai.TextPart("Hi?")}, Task: gai.EmbedTaskQuestionAnswering})
+		is.NotError(t, err)
+		is.Equal(t, "search_query", body["input_type"])
+
+		_, err = e.Embed(t.Context(), gai.EmbedRequest{Parts: []gai.Part{gai.TextPart("Hi!")}, Task: gai.EmbedTaskClustering})
+		is.NotError(t, err)
+		is.Equal(t, "clustering", body["input_type"])
+
+		_, err = e.Embed(t.Context(), gai.EmbedRequest{Parts: []gai.Part{gai.TextPart("Hi!")}, Task: "summarization"})
+		requireValidationError(t, err, "Task", "unsupported embed task: summarization")
+	})
+
+	t.Run("embeds several text parts separately", func(t *testing.T) {
+		var body map[string]any
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(data, &body)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprint(w, `{"embeddings":{"float":[[0.1],[0.2]]}}`)
+		}))
+		t.Cleanup(srv.Close)
+
+		c := cohere.NewClient(cohere.NewClientOptions{BaseURL: srv.URL, Key: "secret"})
+		e := c.NewEmbedder(cohere.NewEmbedderOptions{Model: cohere.EmbedModelEmbedV4, Dimensions: 256})
+
+		res, err := e.Embed(t.Context(), gai.EmbedRequest{Parts: []gai.Part{gai.TextPart("a"), gai.TextPart("b")}, Separate: true})
+		is.NotError(t, err)
+		is.Equal(t, 0, len(res.Embedding))
+		is.Equal(t, 2, len(res.Embeddings))
+		is.EqualSlice(t, []float64{0.2}, res.Embeddings[1])
+		is.Equal(t, 2, len(body["texts"].([]any)))
+
+		_, err = e.Embed(t.Context(), gai.EmbedRequest{Parts: []gai.Part{gai.TextPart("a"), gai.TextPart("b")}})
+		requireValidationError(t, err, "Parts", "Cohere embeddings only support a single text part")
+	})
+
+	t.Run("returns a validation error for non-text parts", func(t *testing.T) {
+		c := cohere.NewClient(cohere.NewClientOptions{BaseURL: "http://localhost", Key: "secret"})
+		e := c.NewEmbedder(cohere.NewEmbedderOptions{Model: cohere.EmbedModelEmbedV4, Dimensions: 1024})
+
+		_, err := e.Embed(t.Context(), gai.EmbedRequest{Parts: []gai.Part{gai.DataPart("image/png", []byte("png"))}})
+		requireValidationError(t, err, "Parts", "Cohere embeddings only support a single text part")
+	})
+}
@@ -0,0 +1,126 @@
+package cohere
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"maragu.dev/errors"
+
+	"maragu.dev/gai"
+)
+
+type RerankModel string
+
+const (
+	RerankModelRerankV3_5           = RerankModel("rerank-v3.5")
+	RerankModelRerankEnglishV3      = RerankModel("rerank-english-v3.0")
+	RerankModelRerankMultilingualV3 = RerankModel("rerank-multilingual-v3.0")
+)
+
+type Reranker struct {
+	Client *Client
+	log    *slog.Logger
+	model  RerankModel
+	tracer trace.Tracer
+}
+
+type NewRerankerOptions struct {
+	Model RerankModel
+}
+
+func (c *Client) NewReranker(opts NewRerankerOptions) *Reranker {
+	return &Reranker{
+		Client: c,
+		log:    c.log,
+		model:  opts.Model,
+		tracer: otel.Tracer("maragu.dev/gai/clients/cohere"),
+	}
+}
+
+type rerankRequest struct {
+	Model     string   `json:"model"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+}
+
+type rerankResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"results"`
+	Meta struct {
+		BilledUnits struct {
+			SearchUnits int `json:"search_units"`
+		} `json:"billed_units"`
+	} `json:"meta"`
+}
+
+// Rerank satisfies [gai.Reranker].
+// Scores are relevance scores from 0 to 1.
+func (r *Reranker) Rerank(ctx context.Context, req gai.RerankRequest) (gai.RerankResponse, error) {
+	ctx, span := r.tracer.Start(ctx, "cohere.rerank",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("ai.model", string(r.model)),
+			attribute.Int("ai.document_count", len(req.Documents)),
+			attribute.Int("ai.query_length", len(req.Query)),
+		),
+	)
+	defer span.End()
+
+	if req.Query == "" {
+		err := gai.NewValidationError("Query", "empty query")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request")
+		return gai.RerankResponse{}, err
+	}
+	if len(req.Documents) == 0 {
+		return gai.RerankResponse{}, nil
+	}
+
+	httpRes, err := r.Client.post(ctx, "rerank", rerankRequest{
+		Model:     string(r.model),
+		Query:     req.Query,
+		Documents: req.Documents,
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "rerank request failed")
+		return gai.RerankResponse{}, errors.Wrap(err, "error reranking")
+	}
+	defer func() {
+		_ = httpRes.Body.Close()
+	}()
+
+	var res rerankResponse
+	if err := json.NewDecoder(httpRes.Body).Decode(&res); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "rerank request failed")
+		return gai.RerankResponse{}, errors.Wrap(err, "error decoding rerank response")
+	}
+
+	if res.Meta.BilledUnits.SearchUnits > 0 {
+		span.SetAttributes(attribute.Int("ai.search_units", res.Meta.BilledUnits.SearchUnits))
+	}
+
+	// Results are already sorted by relevance
+	var results []gai.RerankResult
+	for _, result := range res.Results {
+		if result.Index < 0 || result.Index >= len(req.Documents) {
+			err := errors.Newf("result index %v out of range", result.Index)
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "invalid rerank response")
+			return gai.RerankResponse{}, err
+		}
+		results = append(results, gai.RerankResult{Index: result.Index, Score: result.RelevanceScore})
+	}
+
+	return gai.RerankResponse{Results: results}, nil
+}
+
+var _ gai.Reranker = (*Reranker)(nil)
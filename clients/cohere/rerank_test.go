@@ -0,0 +1,56 @@
+package cohere_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/clients/cohere"
+	"maragu.dev/gai/internal/oteltest"
+)
+
+func TestReranker_Rerank(t *testing.T) {
+	t.Run("reranks documents", func(t *testing.T) {
+		sr := oteltest.NewSpanRecorder(t)
+
+		var body map[string]any
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			is.Equal(t, "/rerank", r.URL.Path)
+			data, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(data, &body)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprint(w, `{"results":[{"index":1,"relevance_score":0.9},{"index":0,"relevance_score":0.1}],"meta":{"billed_units":{"search_units":1}}}`)
+		}))
+		t.Cleanup(srv.Close)
+
+		c := cohere.NewClient(cohere.NewClientOptions{BaseURL: srv.URL, Key: "secret"})
+		r := c.NewReranker(cohere.NewRerankerOptions{Model: cohere.RerankModelRerankV3_5})
+
+		res, err := r.Rerank(t.Context(), gai.RerankRequest{Query: "What do cats eat?", Documents: []string{"Dogs bark.", "Cats eat mice."}})
+		is.NotError(t, err)
+		is.Equal(t, 2, len(res.Results))
+		is.Equal(t, gai.RerankResult{Index: 1, Score: 0.9}, res.Results[0])
+		is.Equal(t, gai.RerankResult{Index: 0, Score: 0.1}, res.Results[1])
+
+		is.Equal(t, "rerank-v3.5", body["model"])
+		is.Equal(t, "What do cats eat?", body["query"])
+
+		span := oteltest.FindSpan(t, sr.Ended(), "cohere.rerank")
+		oteltest.RequirePositiveIntAttribute(t, span.Attributes(), "ai.document_count")
+		oteltest.RequirePositiveIntAttribute(t, span.Attributes(), "ai.search_units")
+	})
+
+	t.Run("returns a validation error for an empty query", func(t *testing.T) {
+		c := cohere.NewClient(cohere.NewClientOptions{BaseURL: "http://localhost", Key: "secret"})
+		r := c.NewReranker(cohere.NewRerankerOptions{Model: cohere.RerankModelRerankV3_5})
+
+		_, err := r.Rerank(t.Context(), gai.RerankRequest{Documents: []string{"Cats eat mice."}})
+		requireValidationError(t, err, "Query", "empty query")
+	})
+}
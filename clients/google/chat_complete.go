@@ -46,7 +46,8 @@ const (
 // `genai.ThinkingLevel` enum used by the Gemini 3.x family. Pass [gai.ThinkingLevelNone] to
 // opt out via `ThinkingBudget=0`; this is accepted by the Flash models (`gemini-3-flash-preview`,
 // `gemini-3.1-flash-lite`, `gemini-3.5-flash`) and rejected by `gemini-3.1-pro-preview`
-// (Pro 3.x only runs in thinking mode). Levels not in this list panic at the client boundary.
+// (Pro 3.x only runs in thinking mode). Levels not in this list are rejected with a
+// [gai.ValidationError] at the client boundary.
 const (
 	// ThinkingLevelMinimal applies the cheapest thinking budget. Rejected by gemini-3.1-pro-preview.
 	ThinkingLevelMinimal gai.ThinkingLevel = "minimal"
@@ -87,12 +88,20 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 		),
 	)
 
+	// invalid records a [gai.ValidationError] on the span and ends it, for caller data we cannot send.
+	invalid := func(err *gai.ValidationError) (gai.ChatCompleteResponse, error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request")
+		span.End()
+		return gai.ChatCompleteResponse{}, err
+	}
+
 	if len(req.Messages) == 0 {
-		panic("no messages")
+		return invalid(gai.NewValidationError("Messages", "no messages"))
 	}
 
 	if req.Messages[len(req.Messages)-1].Role != gai.MessageRoleUser {
-		panic("last message must have user role")
+		return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Role", len(req.Messages)-1), "last message must have user role"))
 	}
 
 	if err := req.ToolChoice.Validate(req.Tools); err != nil {
@@ -129,7 +138,7 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 		case ThinkingLevelHigh:
 			config.ThinkingConfig = &genai.ThinkingConfig{ThinkingLevel: genai.ThinkingLevelHigh, IncludeThoughts: true}
 		default:
-			panic("unsupported thinking level: " + string(*req.ThinkingLevel))
+			return invalid(gai.NewValidationError("ThinkingLevel", "unsupported thinking level: "+string(*req.ThinkingLevel)))
 		}
 		span.SetAttributes(attribute.String("ai.thinking_level", string(*req.ThinkingLevel)))
 	}
@@ -139,6 +148,7 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, "tool conversion failed")
+			span.End()
 			return gai.ChatCompleteResponse{}, fmt.Errorf("error converting tools: %w", err)
 		}
 		config.Tools = tools
@@ -170,19 +180,25 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 	}
 
 	if req.ResponseSchema != nil {
-		responseSchema, err := schema.ConvertResponseSchema(*req.ResponseSchema)
-		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, "response schema conversion failed")
-			return gai.ChatCompleteResponse{}, fmt.Errorf("error converting response schema: %w", err)
-		}
 		config.ResponseMIMEType = "application/json"
-		config.ResponseSchema = responseSchema
+		if schema.HasRefs(req.ResponseSchema) {
+			// Recursive schemas need references, which only the JSON Schema field supports
+			config.ResponseJsonSchema = req.ResponseSchema
+		} else {
+			responseSchema, err := schema.ConvertResponseSchema(*req.ResponseSchema)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, "response schema conversion failed")
+				span.End()
+				return gai.ChatCompleteResponse{}, fmt.Errorf("error converting response schema: %w", err)
+			}
+			config.ResponseSchema = responseSchema
+		}
 		span.SetAttributes(attribute.Bool("ai.has_response_schema", true))
 	}
 
 	var history []*genai.Content
-	for _, m := range req.Messages {
+	for i, m := range req.Messages {
 		var content genai.Content
 
 		switch m.Role {
@@ -191,10 +207,10 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 		case gai.MessageRoleModel:
 			content.Role = genai.RoleModel
 		default:
-			panic("unknown role " + m.Role)
+			return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Role", i), "unknown role "+string(m.Role)))
 		}
 
-		for _, part := range m.Parts {
+		for j, part := range m.Parts {
 			switch part.Type {
 			case gai.PartTypeText:
 				content.Parts = append(content.Parts, &genai.Part{Text: part.Text()})
@@ -205,6 +221,7 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 				if err := json.Unmarshal(toolCall.Args, &args); err != nil {
 					span.RecordError(err)
 					span.SetStatus(codes.Error, "request tool call args unmarshal failed")
+					span.End()
 					return gai.ChatCompleteResponse{}, fmt.Errorf("error unmarshaling request tool call args: %w", err)
 				}
 				part := genai.NewPartFromFunctionCall(toolCall.Name, args)
@@ -223,10 +240,10 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 
 			case gai.PartTypeData:
 				if part.MIMEType == "" {
-					panic("data part has empty MIME type")
+					return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].MIMEType", i, j), "data part has empty MIME type"))
 				}
 				if len(part.Data) == 0 {
-					panic("data part has empty data")
+					return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].Data", i, j), "data part has empty data"))
 				}
 				content.Parts = append(content.Parts, &genai.Part{
 					InlineData: &genai.Blob{
@@ -243,10 +260,11 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 				err := fmt.Errorf("google: %w", errThoughtRoundTripUnsupported)
 				span.RecordError(err)
 				span.SetStatus(codes.Error, "unsupported part type")
+				span.End()
 				return gai.ChatCompleteResponse{}, err
 
 			default:
-				panic("unknown part type " + part.Type)
+				return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].Type", i, j), "unknown part type "+string(part.Type)))
 			}
 		}
 
@@ -261,6 +279,7 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "chat session creation failed")
+		span.End()
 		return gai.ChatCompleteResponse{}, err
 	}
 
@@ -3,6 +3,7 @@ package google_test
 import (
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"os"
 	"strings"
 	"testing"
@@ -474,14 +475,27 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 		is.True(t, len(fullOutput) > len(limitedOutput), "should produce more output without limit")
 	})
 
-	t.Run("panics on empty MIME type", func(t *testing.T) {
+	t.Run("returns a validation error with no messages", func(t *testing.T) {
 		cc := newChatCompleter(t)
 
-		defer func() {
-			r := recover()
-			is.True(t, r != nil)
-			is.Equal(t, "data part has empty MIME type", r)
-		}()
+		_, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{})
+		requireValidationError(t, err, "Messages", "no messages")
+	})
+
+	t.Run("returns a validation error when the last message is not from the user", func(t *testing.T) {
+		cc := newChatCompleter(t)
+
+		_, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{
+				gai.NewUserTextMessage("Hi!"),
+				gai.NewModelTextMessage("Hello!"),
+			},
+		})
+		requireValidationError(t, err, "Messages[1].Role", "last message must have user role")
+	})
+
+	t.Run("returns a validation error on empty MIME type", func(t *testing.T) {
+		cc := newChatCompleter(t)
 
 		req := gai.ChatCompleteRequest{
 			Messages: []gai.Message{
@@ -490,18 +504,13 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 				}},
 			},
 		}
-		_, _ = cc.ChatComplete(t.Context(), req)
+		_, err := cc.ChatComplete(t.Context(), req)
+		requireValidationError(t, err, "Messages[0].Parts[0].MIMEType", "data part has empty MIME type")
 	})
 
-	t.Run("panics on empty data", func(t *testing.T) {
+	t.Run("returns a validation error on empty data", func(t *testing.T) {
 		cc := newChatCompleter(t)
 
-		defer func() {
-			r := recover()
-			is.True(t, r != nil)
-			is.Equal(t, "data part has empty data", r)
-		}()
-
 		req := gai.ChatCompleteRequest{
 			Messages: []gai.Message{
 				{Role: gai.MessageRoleUser, Parts: []gai.Part{
@@ -509,7 +518,8 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 				}},
 			},
 		}
-		_, _ = cc.ChatComplete(t.Context(), req)
+		_, err := cc.ChatComplete(t.Context(), req)
+		requireValidationError(t, err, "Messages[0].Parts[0].Data", "data part has empty data")
 	})
 
 	// Thinking-level matrix. Each row exercises a real (model, level) combination so the
@@ -626,9 +636,9 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 		}
 	})
 
-	t.Run("panics on unsupported thinking level", func(t *testing.T) {
+	t.Run("returns a validation error on unsupported thinking level", func(t *testing.T) {
 		// The Google client publishes Minimal/Low/Medium/High. Anything outside that set
-		// must panic at the boundary, not silently round-trip to the API.
+		// must be rejected at the boundary, not silently round-trip to the API.
 		tests := []struct {
 			name  string
 			level gai.ThinkingLevel
@@ -642,19 +652,12 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 			t.Run(test.name, func(t *testing.T) {
 				cc := newChatCompleter(t)
 
-				defer func() {
-					r := recover()
-					is.True(t, r != nil, "expected a panic")
-					msg, ok := r.(string)
-					is.True(t, ok, "panic value should be a string")
-					is.Equal(t, "unsupported thinking level: "+string(test.level), msg)
-				}()
-
 				req := gai.ChatCompleteRequest{
 					Messages:      []gai.Message{gai.NewUserTextMessage("Hi!")},
 					ThinkingLevel: gai.Ptr(test.level),
 				}
-				_, _ = cc.ChatComplete(t.Context(), req)
+				_, err := cc.ChatComplete(t.Context(), req)
+				requireValidationError(t, err, "ThinkingLevel", "unsupported thinking level: "+string(test.level))
 			})
 		}
 	})
@@ -786,6 +789,15 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 	})
 }
 
+// requireValidationError fails the test unless err is a [gai.ValidationError] for the given field and message.
+func requireValidationError(t *testing.T, err error, field, message string) {
+	t.Helper()
+	var validationErr *gai.ValidationError
+	is.True(t, errors.As(err, &validationErr), "expected a validation error")
+	is.Equal(t, field, validationErr.Field)
+	is.Equal(t, message, validationErr.Err.Error())
+}
+
 // drainParts iterates the response stream, returning the first error if any.
 func drainParts(t *testing.T, res gai.ChatCompleteResponse) error {
 	t.Helper()
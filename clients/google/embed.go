@@ -2,6 +2,7 @@ package google
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 
 	"go.opentelemetry.io/otel"
@@ -23,7 +24,8 @@ const (
 )
 
 // EmbedTaskAsymmetric is a task-type prefix for asymmetric retrieval tasks with the [EmbedModelGeminiEmbedding2] model, where queries and documents use different formats.
-// Use [FormatEmbedTaskQuery] on the query side and [FormatEmbedTaskDocument] on the document side.
+// Use [FormatEmbedTaskQuery] on the query side and [FormatEmbedTaskDocument] on the document side,
+// or set [gai.EmbedRequest.Task] and let the [Embedder] format the text.
 type EmbedTaskAsymmetric string
 
 const (
@@ -38,7 +40,7 @@ const (
 )
 
 // EmbedTaskSymmetric is a task-type prefix for symmetric tasks with the [EmbedModelGeminiEmbedding2] model, where both sides use the same format.
-// Use [FormatEmbedTask] on all inputs.
+// Use [FormatEmbedTask] on all inputs, or set [gai.EmbedRequest.Task] and let the [Embedder] format the text.
 type EmbedTaskSymmetric string
 
 const (
@@ -105,6 +107,11 @@ func (c *Client) NewEmbedder(opts NewEmbedderOptions) *Embedder {
 }
 
 // Embed satisfies [gai.Embedder].
+// Text, images, audio, video, and PDFs can be embedded together into one embedding, or separately, with
+// [EmbedModelGeminiEmbedding2]. Older models only embed text.
+// A task in the request is sent as the native task type, and the title with it for documents, except for
+// [EmbedModelGeminiEmbedding2], where it's formatted as a prefix like with [FormatEmbedTaskQuery] and
+// [FormatEmbedTaskDocument]. Don't set a task for text that's already formatted.
 func (e *Embedder) Embed(ctx context.Context, req gai.EmbedRequest) (gai.EmbedResponse[float32], error) {
 	ctx, span := e.tracer.Start(ctx, "google.embed",
 		trace.WithSpanKind(trace.SpanKindClient),
@@ -116,32 +123,72 @@ func (e *Embedder) Embed(ctx context.Context, req gai.EmbedRequest) (gai.EmbedRe
 	defer span.End()
 
 	if len(req.Parts) == 0 {
-		panic("no parts")
+		err := gai.NewValidationError("Parts", "no parts")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request")
+		return gai.EmbedResponse[float32]{}, err
 	}
 
-	var content genai.Content
-	for _, part := range req.Parts {
+	var parts []*genai.Part
+	var inputLength int
+	for i, part := range req.Parts {
 		switch part.Type {
 		case gai.PartTypeText:
 			text := part.Text()
-			span.SetAttributes(attribute.Int("ai.input_length", len(text)))
-			content.Parts = append(content.Parts, &genai.Part{Text: text})
+			inputLength += len(text)
+			parts = append(parts, &genai.Part{Text: text})
 		case gai.PartTypeData:
-			content.Parts = append(content.Parts, &genai.Part{
+			parts = append(parts, &genai.Part{
 				InlineData: &genai.Blob{
 					MIMEType: part.MIMEType,
 					Data:     part.Data,
 				},
 			})
 		default:
-			panic("unsupported part type for embedding: " + string(part.Type))
+			err := gai.NewValidationError(fmt.Sprintf("Parts[%v].Type", i), "unsupported part type for embedding: "+string(part.Type))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "invalid request")
+			return gai.EmbedResponse[float32]{}, err
+		}
+	}
+	if inputLength > 0 {
+		span.SetAttributes(attribute.Int("ai.input_length", inputLength))
+	}
+
+	// Each content is embedded into its own embedding
+	contents := []*genai.Content{{Parts: parts}}
+	if req.Separate {
+		span.SetAttributes(attribute.Bool("ai.embed_separate", true))
+		contents = nil
+		for _, part := range parts {
+			contents = append(contents, &genai.Content{Parts: []*genai.Part{part}})
+		}
+	}
+
+	config := &genai.EmbedContentConfig{
+		OutputDimensionality: gai.Ptr(int32(e.dimensions)),
+	}
+	if req.Task != "" {
+		span.SetAttributes(attribute.String("ai.embed_task", string(req.Task)))
+
+		var err error
+		if e.model == EmbedModelGeminiEmbedding2 {
+			for _, content := range contents {
+				if err = formatEmbedTask(content, req.Task, req.Title); err != nil {
+					break
+				}
+			}
+		} else {
+			err = setEmbedTask(config, req.Task, req.Title)
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "invalid request")
+			return gai.EmbedResponse[float32]{}, err
 		}
 	}
 
-	dims := int32(e.dimensions)
-	res, err := e.Client.Models.EmbedContent(ctx, string(e.model), []*genai.Content{&content}, &genai.EmbedContentConfig{
-		OutputDimensionality: &dims,
-	})
+	res, err := e.Client.Models.EmbedContent(ctx, string(e.model), contents, config)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "embedding request failed")
@@ -153,10 +200,85 @@ func (e *Embedder) Embed(ctx context.Context, req gai.EmbedRequest) (gai.EmbedRe
 		span.SetStatus(codes.Error, "no embeddings in response")
 		return gai.EmbedResponse[float32]{}, err
 	}
+	if len(res.Embeddings) != len(contents) {
+		err := errors.Newf("expected %v embeddings, but got %v", len(contents), len(res.Embeddings))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "wrong number of embeddings in response")
+		return gai.EmbedResponse[float32]{}, err
+	}
+
+	if req.Separate {
+		var embeddings [][]float32
+		for _, embedding := range res.Embeddings {
+			embeddings = append(embeddings, embedding.Values)
+		}
+		return gai.EmbedResponse[float32]{Embeddings: embeddings}, nil
+	}
 
 	return gai.EmbedResponse[float32]{
 		Embedding: res.Embeddings[0].Values,
 	}, nil
 }
 
+// setEmbedTask on the config as the native task type and title, which all models except [EmbedModelGeminiEmbedding2] support.
+func setEmbedTask(config *genai.EmbedContentConfig, task gai.EmbedTask, title string) error {
+	switch task {
+	case gai.EmbedTaskSearchQuery:
+		config.TaskType = "RETRIEVAL_QUERY"
+	case gai.EmbedTaskQuestionAnswering:
+		config.TaskType = "QUESTION_ANSWERING"
+	case gai.EmbedTaskFactChecking:
+		config.TaskType = "FACT_VERIFICATION"
+	case gai.EmbedTaskCodeRetrievalQuery:
+		config.TaskType = "CODE_RETRIEVAL_QUERY"
+	case gai.EmbedTaskDocument:
+		config.TaskType = "RETRIEVAL_DOCUMENT"
+		config.Title = title
+	case gai.EmbedTaskClassification:
+		config.TaskType = "CLASSIFICATION"
+	case gai.EmbedTaskClustering:
+		config.TaskType = "CLUSTERING"
+	case gai.EmbedTaskSemanticSimilarity:
+		config.TaskType = "SEMANTIC_SIMILARITY"
+	default:
+		return gai.NewValidationError("Task", "unsupported embed task: "+string(task))
+	}
+	return nil
+}
+
+// formatEmbedTask in the content as a task prefix on the first text part, for [EmbedModelGeminiEmbedding2],
+// which has no native task type. Content without text gets a text part with just the prefix first.
+func formatEmbedTask(content *genai.Content, task gai.EmbedTask, title string) error {
+	var format func(text string) string
+	switch task {
+	case gai.EmbedTaskSearchQuery:
+		format = func(text string) string { return FormatEmbedTaskQuery(EmbedTaskSearchResult, text) }
+	case gai.EmbedTaskQuestionAnswering:
+		format = func(text string) string { return FormatEmbedTaskQuery(EmbedTaskQuestionAnswering, text) }
+	case gai.EmbedTaskFactChecking:
+		format = func(text string) string { return FormatEmbedTaskQuery(EmbedTaskFactChecking, text) }
+	case gai.EmbedTaskCodeRetrievalQuery:
+		format = func(text string) string { return FormatEmbedTaskQuery(EmbedTaskCodeRetrieval, text) }
+	case gai.EmbedTaskDocument:
+		format = func(text string) string { return FormatEmbedTaskDocument(EmbedTaskSearchResult, title, text) }
+	case gai.EmbedTaskClassification:
+		format = func(text string) string { return FormatEmbedTask(EmbedTaskClassification, text) }
+	case gai.EmbedTaskClustering:
+		format = func(text string) string { return FormatEmbedTask(EmbedTaskClustering, text) }
+	case gai.EmbedTaskSemanticSimilarity:
+		format = func(text string) string { return FormatEmbedTask(EmbedTaskSentenceSimilarity, text) }
+	default:
+		return gai.NewValidationError("Task", "unsupported embed task: "+string(task))
+	}
+
+	for _, part := range content.Parts {
+		if part.InlineData == nil {
+			part.Text = format(part.Text)
+			return nil
+		}
+	}
+	content.Parts = append([]*genai.Part{{Text: format("")}}, content.Parts...)
+	return nil
+}
+
 var _ gai.Embedder[float32] = (*Embedder)(nil)
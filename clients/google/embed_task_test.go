@@ -0,0 +1,153 @@
+package google_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/genai"
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/clients/google"
+)
+
+func TestEmbedder_Embed_task(t *testing.T) {
+	t.Run("sends the task and title as native config for Gemini embedding 001", func(t *testing.T) {
+		var body []byte
+		e := newFakeEmbedder(t, google.EmbedModelGeminiEmbedding001, &body)
+
+		_, err := e.Embed(t.Context(), gai.EmbedRequest{
+			Parts: []gai.Part{gai.TextPart("Paris is the capital of France.")},
+			Task:  gai.EmbedTaskDocument,
+			Title: "France",
+		})
+		is.NotError(t, err)
+		req := unmarshalEmbedRequest(t, body)
+		is.Equal(t, "RETRIEVAL_DOCUMENT", req.TaskType)
+		is.Equal(t, "France", req.Title)
+		is.Equal(t, "Paris is the capital of France.", req.Content.Parts[0].Text)
+
+		_, err = e.Embed(t.Context(), gai.EmbedRequest{
+			Parts: []gai.Part{gai.TextPart("what is the capital of France?")},
+			Task:  gai.EmbedTaskSearchQuery,
+			Title: "Ignored",
+		})
+		is.NotError(t, err)
+		req = unmarshalEmbedRequest(t, body)
+		is.Equal(t, "RETRIEVAL_QUERY", req.TaskType)
+		is.Equal(t, "", req.Title)
+		is.Equal(t, "what is the capital of France?", req.Content.Parts[0].Text)
+	})
+
+	t.Run("formats the task as a prefix for Gemini embedding 2", func(t *testing.T) {
+		var body []byte
+		e := newFakeEmbedder(t, google.EmbedModelGeminiEmbedding2, &body)
+
+		tests := []struct {
+			req      gai.EmbedRequest
+			expected string
+		}{
+			{
+				req:      gai.EmbedRequest{Parts: []gai.Part{gai.TextPart("Paris is the capital of France.")}, Task: gai.EmbedTaskDocument, Title: "France"},
+				expected: "title: France | text: Paris is the capital of France.",
+			},
+			{
+				req:      gai.EmbedRequest{Parts: []gai.Part{gai.TextPart("Paris is the capital of France.")}, Task: gai.EmbedTaskDocument},
+				expected: "title: none | text: Paris is the capital of France.",
+			},
+			{
+				req:      gai.EmbedRequest{Parts: []gai.Part{gai.TextPart("what is the capital of France?")}, Task: gai.EmbedTaskQuestionAnswering},
+				expected: "task: question answering | query: what is the capital of France?",
+			},
+			{
+				req:      gai.EmbedRequest{Parts: []gai.Part{gai.TextPart("Paris")}, Task: gai.EmbedTaskSemanticSimilarity},
+				expected: "task: sentence similarity | query: Paris",
+			},
+			{
+				req:      gai.EmbedRequest{Parts: []gai.Part{gai.DataPart("image/jpeg", image)}, Task: gai.EmbedTaskSearchQuery},
+				expected: "task: search result | query: ",
+			},
+		}
+
+		for _, test := range tests {
+			_, err := e.Embed(t.Context(), test.req)
+			is.NotError(t, err)
+
+			req := unmarshalEmbedRequest(t, body)
+			is.Equal(t, test.expected, req.Content.Parts[0].Text)
+			is.Equal(t, "", req.TaskType)
+		}
+	})
+
+	t.Run("returns a validation error for an unknown task", func(t *testing.T) {
+		var body []byte
+		e := newFakeEmbedder(t, google.EmbedModelGeminiEmbedding001, &body)
+
+		_, err := e.Embed(t.Context(), gai.EmbedRequest{Parts: []gai.Part{gai.TextPart("hi")}, Task: "summarization"})
+		requireValidationError(t, err, "Task", "unsupported embed task: summarization")
+	})
+}
+
+type embedRequest struct {
+	Content  genai.Content `json:"content"`
+	TaskType string        `json:"taskType"`
+	Title    string        `json:"title"`
+}
+
+// unmarshalEmbedRequest from the body of a batch embed request with a single request.
+func unmarshalEmbedRequest(t *testing.T, body []byte) embedRequest {
+	t.Helper()
+
+	reqs := unmarshalEmbedRequests(t, body)
+	is.Equal(t, 1, len(reqs))
+	return reqs[0]
+}
+
+// unmarshalEmbedRequests from the body of a batch embed request.
+func unmarshalEmbedRequests(t *testing.T, body []byte) []embedRequest {
+	t.Helper()
+
+	var req struct {
+		Requests []embedRequest `json:"requests"`
+	}
+	is.NotError(t, json.Unmarshal(body, &req))
+	return req.Requests
+}
+
+// newFakeEmbedder with a client against a fake server, which records the request body.
+// Embeddings from the server have the index of their request as the first component.
+func newFakeEmbedder(t *testing.T, model google.EmbedModel, body *[]byte) *google.Embedder {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*body, _ = io.ReadAll(r.Body)
+
+		// Respond with an embedding for each request
+		var req struct {
+			Requests []json.RawMessage `json:"requests"`
+		}
+		_ = json.Unmarshal(*body, &req)
+		var res struct {
+			Embeddings []genai.ContentEmbedding `json:"embeddings"`
+		}
+		for i := range req.Requests {
+			res.Embeddings = append(res.Embeddings, genai.ContentEmbedding{Values: []float32{float32(i), 0.2, 0.3}})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(srv.Close)
+
+	gc, err := genai.NewClient(t.Context(), &genai.ClientConfig{
+		APIKey:      "secret",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: srv.URL},
+	})
+	is.NotError(t, err)
+
+	c := &google.Client{Client: gc}
+	return c.NewEmbedder(google.NewEmbedderOptions{Model: model, Dimensions: 768})
+}
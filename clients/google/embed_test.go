@@ -26,7 +26,7 @@ func TestEmbedder_Embed(t *testing.T) {
 		is.Equal(t, 768, len(res.Embedding))
 	})
 
-	t.Run("panics with no parts", func(t *testing.T) {
+	t.Run("returns a validation error with no parts", func(t *testing.T) {
 		c := newClient(t)
 
 		e := c.NewEmbedder(google.NewEmbedderOptions{
@@ -34,15 +34,11 @@ func TestEmbedder_Embed(t *testing.T) {
 			Dimensions: 768,
 		})
 
-		defer func() {
-			r := recover()
-			is.Equal(t, "no parts", r)
-		}()
-
-		_, _ = e.Embed(t.Context(), gai.EmbedRequest{})
+		_, err := e.Embed(t.Context(), gai.EmbedRequest{})
+		requireValidationError(t, err, "Parts", "no parts")
 	})
 
-	t.Run("panics with unsupported part type", func(t *testing.T) {
+	t.Run("returns a validation error with unsupported part type", func(t *testing.T) {
 		c := newClient(t)
 
 		e := c.NewEmbedder(google.NewEmbedderOptions{
@@ -50,14 +46,10 @@ func TestEmbedder_Embed(t *testing.T) {
 			Dimensions: 768,
 		})
 
-		defer func() {
-			r := recover()
-			is.Equal(t, "unsupported part type for embedding: tool_call", r)
-		}()
-
-		_, _ = e.Embed(t.Context(), gai.EmbedRequest{
-			Parts: []gai.Part{gai.ToolCallPart("id", "name", nil)},
+		_, err := e.Embed(t.Context(), gai.EmbedRequest{
+			Parts: []gai.Part{gai.TextPart("hi"), gai.ToolCallPart("id", "name", nil)},
 		})
+		requireValidationError(t, err, "Parts[1].Type", "unsupported part type for embedding: tool_call")
 	})
 
 	t.Run("can embed an image", func(t *testing.T) {
@@ -224,6 +216,64 @@ func TestEmbedder_Embed(t *testing.T) {
 		is.Equal(t, 768, len(res.Embedding))
 	})
 
+	t.Run("can embed text, image, audio, and video separately", func(t *testing.T) {
+		c := newClient(t)
+
+		e := c.NewEmbedder(google.NewEmbedderOptions{
+			Model:      google.EmbedModelGeminiEmbedding2,
+			Dimensions: 768,
+		})
+
+		req := gai.EmbedRequest{
+			Parts: []gai.Part{
+				gai.TextPart("A multimedia embedding test."),
+				gai.DataPart("image/jpeg", image),
+				gai.DataPart("audio/mp4", audio),
+				gai.DataPart("video/quicktime", video),
+			},
+			Separate: true,
+		}
+
+		res, err := e.Embed(t.Context(), req)
+		is.NotError(t, err)
+
+		is.Equal(t, 0, len(res.Embedding))
+		is.Equal(t, 4, len(res.Embeddings))
+		for _, embedding := range res.Embeddings {
+			is.Equal(t, 768, len(embedding))
+		}
+	})
+
+	t.Run("sends all parts in one content, or each part in its own", func(t *testing.T) {
+		var body []byte
+		e := newFakeEmbedder(t, google.EmbedModelGeminiEmbedding2, &body)
+
+		parts := []gai.Part{gai.TextPart("A logo."), gai.DataPart("image/jpeg", image)}
+
+		res, err := e.Embed(t.Context(), gai.EmbedRequest{Parts: parts})
+		is.NotError(t, err)
+		is.EqualSlice(t, []float32{0, 0.2, 0.3}, res.Embedding)
+		is.Equal(t, 0, len(res.Embeddings))
+
+		req := unmarshalEmbedRequest(t, body)
+		is.Equal(t, 2, len(req.Content.Parts))
+
+		res, err = e.Embed(t.Context(), gai.EmbedRequest{Parts: parts, Separate: true, Task: gai.EmbedTaskDocument, Title: "Logo"})
+		is.NotError(t, err)
+		is.Equal(t, 0, len(res.Embedding))
+		is.Equal(t, 2, len(res.Embeddings))
+		is.EqualSlice(t, []float32{1, 0.2, 0.3}, res.Embeddings[1])
+
+		reqs := unmarshalEmbedRequests(t, body)
+		is.Equal(t, 2, len(reqs))
+		is.Equal(t, 1, len(reqs[0].Content.Parts))
+		is.Equal(t, "title: Logo | text: A logo.", reqs[0].Content.Parts[0].Text)
+		// The task prefix is added to media without text
+		is.Equal(t, 2, len(reqs[1].Content.Parts))
+		is.Equal(t, "title: Logo | text: ", reqs[1].Content.Parts[0].Text)
+		is.Equal(t, "image/jpeg", reqs[1].Content.Parts[1].InlineData.MIMEType)
+	})
+
 	t.Run("records standard attributes on the embed span", func(t *testing.T) {
 		sr := oteltest.NewSpanRecorder(t)
 		c := newClient(t)
@@ -2,6 +2,8 @@ package schema
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 
 	"google.golang.org/genai"
 
@@ -22,7 +24,16 @@ func ConvertTools(tools []gai.Tool) ([]*genai.Tool, error) {
 }
 
 // ConvertToolToFunction converts a gai.Tool to genai.FunctionDeclaration.
+// Schemas with references, which genai.Schema cannot express, are sent as JSON Schema instead.
 func ConvertToolToFunction(tool gai.Tool) (*genai.FunctionDeclaration, error) {
+	if len(tool.Schema.Defs) > 0 || slices.ContainsFunc(slices.Collect(maps.Values(tool.Schema.Properties)), HasRefs) {
+		return &genai.FunctionDeclaration{
+			Name:                 tool.Name,
+			Description:          tool.Description,
+			ParametersJsonSchema: tool.Schema.Schema(),
+		}, nil
+	}
+
 	schema, err := ConvertToolSchema(tool.Schema)
 	if err != nil {
 		return nil, fmt.Errorf("converting schema: %w", err)
@@ -49,13 +60,44 @@ func ConvertToolSchema(schema gai.ToolSchema) (*genai.Schema, error) {
 	}
 
 	return &genai.Schema{
-		Type:       genai.TypeObject,
-		Properties: genaiProps,
+		Type:        genai.TypeObject,
+		Description: schema.Description,
+		Properties:  genaiProps,
+		Required:    schema.Required,
 	}, nil
 }
 
+// HasRefs reports whether schema has references or definitions, which genai.Schema cannot express.
+// Such schemas must be sent as JSON Schema instead, like with genai.GenerateContentConfig.ResponseJsonSchema.
+func HasRefs(schema *gai.Schema) bool {
+	if schema == nil {
+		return false
+	}
+	if schema.Ref != "" || len(schema.Defs) > 0 {
+		return true
+	}
+	if HasRefs(schema.Items) {
+		return true
+	}
+	for _, prop := range schema.Properties {
+		if HasRefs(prop) {
+			return true
+		}
+	}
+	return slices.ContainsFunc(slices.Concat(schema.AllOf, schema.AnyOf, schema.OneOf), HasRefs)
+}
+
 // ConvertResponseSchema converts gai.Schema to genai.Schema.
+// Keywords genai.Schema has no field for are degraded: allOf is merged into the schema, oneOf
+// becomes anyOf, const becomes a single enum value, and additionalProperties is dropped.
+// Use [HasRefs] to check for references first, which are not supported.
 func ConvertResponseSchema(schema gai.Schema) (*genai.Schema, error) {
+	if schema.Ref != "" {
+		return nil, fmt.Errorf("unsupported reference %v", schema.Ref)
+	}
+
+	schema = mergeAllOf(schema)
+
 	result := &genai.Schema{}
 
 	// Convert type
@@ -68,6 +110,8 @@ func ConvertResponseSchema(schema gai.Schema) (*genai.Schema, error) {
 		result.Type = genai.TypeInteger
 	case gai.SchemaTypeBoolean:
 		result.Type = genai.TypeBoolean
+	case gai.SchemaTypeNull:
+		result.Type = genai.TypeNULL
 	case gai.SchemaTypeArray:
 		result.Type = genai.TypeArray
 		if schema.Items != nil {
@@ -91,8 +135,10 @@ func ConvertResponseSchema(schema gai.Schema) (*genai.Schema, error) {
 		}
 		result.Required = schema.Required
 	default:
-		// Default to string if type is not specified
-		result.Type = genai.TypeString
+		// Default to string if type is not specified, unless the alternatives give the type
+		if len(schema.AnyOf) == 0 && len(schema.OneOf) == 0 {
+			result.Type = genai.TypeString
+		}
 	}
 
 	// Copy all other fields
@@ -102,16 +148,28 @@ func ConvertResponseSchema(schema gai.Schema) (*genai.Schema, error) {
 	result.Example = schema.Example
 	result.Format = schema.Format
 	result.MaxItems = schema.MaxItems
+	result.MaxLength = schema.MaxLength
 	result.Maximum = schema.Maximum
 	result.MinItems = schema.MinItems
+	result.MinLength = schema.MinLength
 	result.Minimum = schema.Minimum
+	result.Pattern = schema.Pattern
 	result.PropertyOrdering = schema.PropertyOrdering
 	result.Title = schema.Title
 
-	// Handle AnyOf recursively
-	if schema.AnyOf != nil {
-		result.AnyOf = make([]*genai.Schema, len(schema.AnyOf))
-		for i, anyOfSchema := range schema.AnyOf {
+	if schema.Nullable {
+		result.Nullable = genai.Ptr(true)
+	}
+
+	if schema.Const != nil && len(schema.Enum) == 0 {
+		result.Enum = []string{fmt.Sprint(schema.Const)}
+	}
+
+	// Handle AnyOf and OneOf recursively
+	alternatives := slices.Concat(schema.AnyOf, schema.OneOf)
+	if alternatives != nil {
+		result.AnyOf = make([]*genai.Schema, len(alternatives))
+		for i, anyOfSchema := range alternatives {
 			convertedSchema, err := ConvertResponseSchema(*anyOfSchema)
 			if err != nil {
 				return nil, fmt.Errorf("converting anyOf[%d]: %w", i, err)
@@ -122,3 +180,77 @@ func ConvertResponseSchema(schema gai.Schema) (*genai.Schema, error) {
 
 	return result, nil
 }
+
+// mergeAllOf merges the subschemas of allOf into schema. Properties and required fields are
+// combined, and other fields are taken from the first subschema that has them, if schema doesn't.
+func mergeAllOf(schema gai.Schema) gai.Schema {
+	if len(schema.AllOf) == 0 {
+		return schema
+	}
+
+	allOf := schema.AllOf
+	schema.AllOf = nil
+	for _, sub := range allOf {
+		sub := mergeAllOf(*sub)
+
+		if len(sub.Properties) > 0 {
+			properties := maps.Clone(schema.Properties)
+			if properties == nil {
+				properties = map[string]*gai.Schema{}
+			}
+			for name, prop := range sub.Properties {
+				if _, ok := properties[name]; !ok {
+					properties[name] = prop
+					schema.PropertyOrdering = append(slices.Clip(schema.PropertyOrdering), name)
+				}
+			}
+			schema.Properties = properties
+		}
+		for _, name := range sub.Required {
+			if !slices.Contains(schema.Required, name) {
+				schema.Required = append(slices.Clip(schema.Required), name)
+			}
+		}
+
+		if schema.Type == "" {
+			schema.Type = sub.Type
+		}
+		if schema.Description == "" {
+			schema.Description = sub.Description
+		}
+		if schema.Items == nil {
+			schema.Items = sub.Items
+		}
+		if len(schema.Enum) == 0 {
+			schema.Enum = sub.Enum
+		}
+		if schema.Format == "" {
+			schema.Format = sub.Format
+		}
+		if schema.Pattern == "" {
+			schema.Pattern = sub.Pattern
+		}
+		if schema.Minimum == nil {
+			schema.Minimum = sub.Minimum
+		}
+		if schema.Maximum == nil {
+			schema.Maximum = sub.Maximum
+		}
+		if schema.MinLength == nil {
+			schema.MinLength = sub.MinLength
+		}
+		if schema.MaxLength == nil {
+			schema.MaxLength = sub.MaxLength
+		}
+		if schema.MinItems == nil {
+			schema.MinItems = sub.MinItems
+		}
+		if schema.MaxItems == nil {
+			schema.MaxItems = sub.MaxItems
+		}
+		if schema.Const == nil {
+			schema.Const = sub.Const
+		}
+	}
+	return schema
+}
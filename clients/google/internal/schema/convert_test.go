@@ -22,17 +22,18 @@ func TestConvertToolToFunction(t *testing.T) {
 		is.NotError(t, err)
 
 		is.Equal(t, "read_file", funcDecl.Name)
-		is.Equal(t, "Read the contents of a given relative file path. Use this when you want to see what's inside a file. Do not use this with directory names.", funcDecl.Description)
+		is.Equal(t, "Read the contents of a given relative file path. Use this when you want to see what's inside a file. Do not use this with directory names.\n\nFor large files, read a range of lines with 'offset' and 'limit'. Use 'line_numbers' to see the line number of each line.", funcDecl.Description)
 
 		// Check parameters
 		is.NotError(t, err)
 		is.Equal(t, genai.TypeObject, funcDecl.Parameters.Type)
-		is.Equal(t, 1, len(funcDecl.Parameters.Properties))
+		is.Equal(t, 4, len(funcDecl.Parameters.Properties))
 
 		pathProp, ok := funcDecl.Parameters.Properties["path"]
 		is.True(t, ok, "expected path property")
 		is.Equal(t, genai.TypeString, pathProp.Type)
 		is.Equal(t, "The relative path of a file in the working directory.", pathProp.Description)
+		is.EqualSlice(t, []string{"path"}, funcDecl.Parameters.Required)
 	})
 
 	t.Run("converts ListDir tool", func(t *testing.T) {
@@ -44,11 +45,11 @@ func TestConvertToolToFunction(t *testing.T) {
 		is.NotError(t, err)
 
 		is.Equal(t, "list_dir", funcDecl.Name)
-		is.Equal(t, "List files and directories at a given path recursively. If no path is provided, lists files and directories in the current directory.", funcDecl.Description)
+		is.Equal(t, "List files and directories at a given path recursively. If no path is provided, lists files and directories in the current directory.\n\nFiles and directories ignored by .gitignore files are left out. Limit how deep to list with 'depth', and leave out more with 'ignore'. At most 1000 entries are listed.", funcDecl.Description)
 
-		// ListDir has a path parameter
+		// ListDir has path, depth, and ignore parameters
 		is.Equal(t, genai.TypeObject, funcDecl.Parameters.Type)
-		is.Equal(t, 1, len(funcDecl.Parameters.Properties))
+		is.Equal(t, 3, len(funcDecl.Parameters.Properties))
 
 		pathProp, ok := funcDecl.Parameters.Properties["path"]
 		is.True(t, ok, "expected path property")
@@ -117,6 +118,23 @@ func TestConvertToolSchema(t *testing.T) {
 		is.Equal(t, "The age", ageProp.Description)
 	})
 
+	t.Run("converts required properties and the description", func(t *testing.T) {
+		toolSchema := gai.ToolSchema{
+			Description: "A person.",
+			Properties: map[string]*gai.Schema{
+				"name": {Type: gai.SchemaTypeString},
+				"age":  {Type: gai.SchemaTypeInteger},
+			},
+			Required: []string{"name"},
+		}
+
+		genaiSchema, err := schema.ConvertToolSchema(toolSchema)
+		is.NotError(t, err)
+
+		is.Equal(t, "A person.", genaiSchema.Description)
+		is.EqualSlice(t, []string{"name"}, genaiSchema.Required)
+	})
+
 	t.Run("converts JSON Schema format with properties wrapper", func(t *testing.T) {
 		toolSchema := gai.ToolSchema{
 			Properties: map[string]*gai.Schema{
@@ -474,3 +492,92 @@ func TestConvertResponseSchema(t *testing.T) {
 		is.Equal(t, "Integer option", genaiSchema.AnyOf[1].Description)
 	})
 }
+
+func TestConvertResponseSchema_JSONSchemaKeywords(t *testing.T) {
+	t.Run("copies nullable and string constraints", func(t *testing.T) {
+		genaiSchema, err := schema.ConvertResponseSchema(gai.Schema{
+			Type:      gai.SchemaTypeString,
+			Nullable:  true,
+			Pattern:   "^[a-z]+$",
+			MinLength: gai.Ptr(int64(1)),
+			MaxLength: gai.Ptr(int64(10)),
+		})
+		is.NotError(t, err)
+
+		is.True(t, *genaiSchema.Nullable)
+		is.Equal(t, "^[a-z]+$", genaiSchema.Pattern)
+		is.Equal(t, int64(1), *genaiSchema.MinLength)
+		is.Equal(t, int64(10), *genaiSchema.MaxLength)
+	})
+
+	t.Run("turns oneOf into anyOf and const into an enum value", func(t *testing.T) {
+		genaiSchema, err := schema.ConvertResponseSchema(gai.Schema{
+			OneOf: []*gai.Schema{
+				{Type: gai.SchemaTypeString, Const: "leaf"},
+				{Type: gai.SchemaTypeInteger},
+			},
+		})
+		is.NotError(t, err)
+
+		is.Equal(t, genai.Type(""), genaiSchema.Type)
+		is.Equal(t, 2, len(genaiSchema.AnyOf))
+		is.EqualSlice(t, []string{"leaf"}, genaiSchema.AnyOf[0].Enum)
+	})
+
+	t.Run("merges allOf into the schema", func(t *testing.T) {
+		genaiSchema, err := schema.ConvertResponseSchema(gai.Schema{
+			AllOf: []*gai.Schema{
+				{
+					Type:       gai.SchemaTypeObject,
+					Properties: map[string]*gai.Schema{"name": {Type: gai.SchemaTypeString}},
+					Required:   []string{"name"},
+				},
+				{
+					Properties: map[string]*gai.Schema{"age": {Type: gai.SchemaTypeInteger}},
+					Required:   []string{"age"},
+				},
+			},
+		})
+		is.NotError(t, err)
+
+		is.Equal(t, genai.TypeObject, genaiSchema.Type)
+		is.Equal(t, 2, len(genaiSchema.Properties))
+		is.EqualSlice(t, []string{"name", "age"}, genaiSchema.Required)
+	})
+
+	t.Run("returns an error on references", func(t *testing.T) {
+		_, err := schema.ConvertResponseSchema(gai.Schema{Ref: "#/$defs/Node"})
+		is.True(t, err != nil)
+	})
+}
+
+type node struct {
+	Name     string `json:"name"`
+	Children []node `json:"children"`
+}
+
+func TestHasRefs(t *testing.T) {
+	t.Run("reports references in recursive schemas", func(t *testing.T) {
+		recursive := gai.GenerateSchema[node]()
+		is.True(t, schema.HasRefs(&recursive))
+
+		flat := gai.GenerateSchema[struct {
+			Name string `json:"name"`
+		}]()
+		is.True(t, !schema.HasRefs(&flat))
+	})
+
+	t.Run("sends tools with references as JSON Schema", func(t *testing.T) {
+		funcDecl, err := schema.ConvertToolToFunction(gai.Tool{
+			Name:   "tree",
+			Schema: gai.GenerateToolSchema[node](),
+		})
+		is.NotError(t, err)
+
+		is.True(t, funcDecl.Parameters == nil)
+		parameters := funcDecl.ParametersJsonSchema.(gai.Schema)
+		is.Equal(t, gai.SchemaTypeObject, parameters.Type)
+		is.NotNil(t, parameters.Defs["node"])
+		is.True(t, len(parameters.Required) > 0)
+	})
+}
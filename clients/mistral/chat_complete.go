@@ -0,0 +1,540 @@
+package mistral
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/internal/sse"
+)
+
+// ChatCompleteModel is a Mistral model identifier accepted by the chat-completions
+// surface. See https://docs.mistral.ai/getting-started/models for the full list and the
+// current availability and capability matrix of each model.
+type ChatCompleteModel string
+
+const (
+	ChatCompleteModelCodestralLatest       = ChatCompleteModel("codestral-latest")
+	ChatCompleteModelMagistralMediumLatest = ChatCompleteModel("magistral-medium-latest")
+	ChatCompleteModelMagistralSmallLatest  = ChatCompleteModel("magistral-small-latest")
+	ChatCompleteModelMinistral8BLatest     = ChatCompleteModel("ministral-8b-latest")
+	ChatCompleteModelMistralLargeLatest    = ChatCompleteModel("mistral-large-latest")
+	ChatCompleteModelMistralMediumLatest   = ChatCompleteModel("mistral-medium-latest")
+	ChatCompleteModelMistralSmallLatest    = ChatCompleteModel("mistral-small-latest")
+)
+
+// Per-client [gai.ThinkingLevel] constants. These map onto the `reasoning_effort` enum of
+// Mistral's adjustable-reasoning models, which only has `none` and `high`. Magistral models
+// always reason and stream their thinking without it. Pass [gai.ThinkingLevelNone] to turn
+// reasoning off on adjustable models. Models without adjustable reasoning reject the field
+// with a 400. Levels not in this list are rejected with a [gai.ValidationError] at the
+// client boundary.
+const (
+	// ThinkingLevelHigh turns reasoning on.
+	ThinkingLevelHigh gai.ThinkingLevel = "high"
+)
+
+type ChatCompleter struct {
+	Client *Client
+	log    *slog.Logger
+	model  ChatCompleteModel
+	tracer trace.Tracer
+}
+
+type NewChatCompleterOptions struct {
+	Model ChatCompleteModel
+}
+
+func (c *Client) NewChatCompleter(opts NewChatCompleterOptions) *ChatCompleter {
+	return &ChatCompleter{
+		Client: c,
+		log:    c.log,
+		model:  opts.Model,
+		tracer: otel.Tracer("maragu.dev/gai/clients/mistral"),
+	}
+}
+
+type chatRequest struct {
+	Model           string          `json:"model"`
+	Messages        []chatMessage   `json:"messages"`
+	Stream          bool            `json:"stream"`
+	Temperature     *float64        `json:"temperature,omitempty"`
+	MaxTokens       *int            `json:"max_tokens,omitempty"`
+	Tools           []chatTool      `json:"tools,omitempty"`
+	ToolChoice      any             `json:"tool_choice,omitempty"`
+	ResponseFormat  *responseFormat `json:"response_format,omitempty"`
+	ReasoningEffort string          `json:"reasoning_effort,omitempty"`
+}
+
+type chatMessage struct {
+	Role       string         `json:"role"`
+	Content    any            `json:"content,omitempty"`
+	ToolCalls  []chatToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+	Name       string         `json:"name,omitempty"`
+}
+
+type contentChunk struct {
+	Type     string         `json:"type"`
+	Text     string         `json:"text,omitempty"`
+	ImageURL string         `json:"image_url,omitempty"`
+	Thinking []contentChunk `json:"thinking,omitempty"`
+}
+
+type chatTool struct {
+	Type     string       `json:"type"`
+	Function chatFunction `json:"function"`
+}
+
+type chatFunction struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Parameters  gai.Schema `json:"parameters"`
+}
+
+type chatToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type,omitempty"`
+	Index    int    `json:"index,omitempty"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type responseFormat struct {
+	Type       string             `json:"type"`
+	JSONSchema responseJSONSchema `json:"json_schema"`
+}
+
+type responseJSONSchema struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Schema      *gai.Schema `json:"schema"`
+	Strict      bool        `json:"strict"`
+}
+
+type chatChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content   json.RawMessage `json:"content"`
+			ToolCalls []chatToolCall  `json:"tool_calls"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// ChatComplete satisfies [gai.ChatCompleter].
+func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRequest) (gai.ChatCompleteResponse, error) {
+	ctx, span := c.tracer.Start(ctx, "mistral.chat_complete",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("ai.model", string(c.model)),
+			attribute.Int("ai.message_count", len(req.Messages)),
+		),
+	)
+
+	if err := req.ToolChoice.Validate(req.Tools); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid tool choice")
+		span.End()
+		return gai.ChatCompleteResponse{}, err
+	}
+
+	// invalid records a [gai.ValidationError] on the span and ends it, for caller data we cannot send.
+	invalid := func(err *gai.ValidationError) (gai.ChatCompleteResponse, error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request")
+		span.End()
+		return gai.ChatCompleteResponse{}, err
+	}
+
+	if len(req.Messages) == 0 {
+		return invalid(gai.NewValidationError("Messages", "no messages"))
+	}
+
+	var messages []chatMessage
+
+	if req.System != nil {
+		messages = append(messages, chatMessage{Role: "system", Content: *req.System})
+		span.SetAttributes(attribute.Bool("ai.has_system_prompt", true))
+	}
+
+	for i, m := range req.Messages {
+		switch m.Role {
+		case gai.MessageRoleUser:
+			var chunks []contentChunk
+
+			for j, part := range m.Parts {
+				switch part.Type {
+				case gai.PartTypeText:
+					chunks = append(chunks, contentChunk{Type: "text", Text: part.Text()})
+
+				case gai.PartTypeToolResult:
+					// Tool results are separate messages, so flush the content so far to keep the order.
+					if len(chunks) > 0 {
+						messages = append(messages, chatMessage{Role: "user", Content: chunks})
+					}
+					chunks = nil
+
+					toolResult := part.ToolResult()
+					content := toolResult.Content
+					if toolResult.Err != nil {
+						content = fmt.Sprintf("Error: %s", toolResult.Err)
+					}
+					messages = append(messages, chatMessage{
+						Role:       "tool",
+						Content:    content,
+						ToolCallID: toolResult.ID,
+						Name:       toolResult.Name,
+					})
+
+				case gai.PartTypeData:
+					if part.MIMEType == "" {
+						return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].MIMEType", i, j), "data part has empty MIME type"))
+					}
+					if len(part.Data) == 0 {
+						return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].Data", i, j), "data part has empty data"))
+					}
+					if !strings.HasPrefix(part.MIMEType, "image/") {
+						return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].MIMEType", i, j), "unsupported MIME type for Mistral: "+part.MIMEType))
+					}
+					chunks = append(chunks, contentChunk{
+						Type:     "image_url",
+						ImageURL: "data:" + part.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(part.Data),
+					})
+
+				case gai.PartTypeThought:
+					// Reasoning only comes from the model, so there is nothing to send back.
+					continue
+
+				default:
+					return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].Type", i, j), "unknown part type "+string(part.Type)))
+				}
+			}
+
+			if len(chunks) > 0 {
+				messages = append(messages, chatMessage{Role: "user", Content: chunks})
+			}
+
+		case gai.MessageRoleModel:
+			var text strings.Builder
+			var toolCalls []chatToolCall
+
+			for j, part := range m.Parts {
+				switch part.Type {
+				case gai.PartTypeText:
+					text.WriteString(part.Text())
+
+				case gai.PartTypeToolCall:
+					toolCall := part.ToolCall()
+					call := chatToolCall{ID: toolCall.ID, Type: "function"}
+					call.Function.Name = toolCall.Name
+					call.Function.Arguments = string(toolCall.Args)
+					toolCalls = append(toolCalls, call)
+
+				case gai.PartTypeThought:
+					// Mistral does not take reasoning back as input.
+					continue
+
+				default:
+					return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].Type", i, j), "unknown part type "+string(part.Type)))
+				}
+			}
+
+			message := chatMessage{Role: "assistant", ToolCalls: toolCalls}
+			if text.Len() > 0 {
+				message.Content = text.String()
+			}
+			if message.Content != nil || len(toolCalls) > 0 {
+				messages = append(messages, message)
+			}
+
+		default:
+			return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Role", i), "unknown role "+string(m.Role)))
+		}
+	}
+
+	var tools []chatTool
+	var toolNames []string
+	for _, tool := range req.Tools {
+		tools = append(tools, chatTool{
+			Type: "function",
+			Function: chatFunction{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Schema.Schema(),
+			},
+		})
+		toolNames = append(toolNames, tool.Name)
+	}
+	sort.Strings(toolNames)
+	span.SetAttributes(
+		attribute.Int("ai.tool_count", len(tools)),
+		attribute.StringSlice("ai.tools", toolNames),
+	)
+
+	body := chatRequest{
+		Model:    string(c.model),
+		Messages: messages,
+		Stream:   true,
+		Tools:    tools,
+	}
+
+	switch req.ToolChoice.Mode {
+	case gai.ToolChoiceModeAny:
+		body.ToolChoice = "any"
+		span.SetAttributes(attribute.String("ai.tool_choice", string(req.ToolChoice.Mode)))
+	case gai.ToolChoiceModeTool:
+		body.ToolChoice = map[string]any{
+			"type":     "function",
+			"function": map[string]string{"name": req.ToolChoice.Name},
+		}
+		span.SetAttributes(attribute.String("ai.tool_choice", string(req.ToolChoice.Mode)))
+	}
+
+	if req.Temperature != nil {
+		body.Temperature = gai.Ptr(req.Temperature.Float64())
+		span.SetAttributes(attribute.Float64("ai.temperature", req.Temperature.Float64()))
+	}
+
+	if req.MaxCompletionTokens != nil {
+		body.MaxTokens = req.MaxCompletionTokens
+		span.SetAttributes(attribute.Int("ai.max_completion_tokens", *req.MaxCompletionTokens))
+	}
+
+	if req.ThinkingLevel != nil {
+		switch *req.ThinkingLevel {
+		case gai.ThinkingLevelNone:
+			body.ReasoningEffort = "none"
+		case ThinkingLevelHigh:
+			body.ReasoningEffort = "high"
+		default:
+			return invalid(gai.NewValidationError("ThinkingLevel", "unsupported thinking level: "+string(*req.ThinkingLevel)))
+		}
+		span.SetAttributes(attribute.String("ai.thinking_level", string(*req.ThinkingLevel)))
+	}
+
+	if req.ResponseSchema != nil {
+		name := req.ResponseSchema.Title
+		if name == "" {
+			name = "response"
+		}
+		body.ResponseFormat = &responseFormat{
+			Type: "json_schema",
+			JSONSchema: responseJSONSchema{
+				Name:        name,
+				Description: req.ResponseSchema.Description,
+				Schema:      req.ResponseSchema,
+				Strict:      true,
+			},
+		}
+		span.SetAttributes(attribute.Bool("ai.has_response_schema", true))
+	}
+
+	streamStart := time.Now()
+
+	res, err := c.Client.post(ctx, "chat/completions", body)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "chat completion request failed")
+		span.End()
+		return gai.ChatCompleteResponse{}, fmt.Errorf("error chat-completing: %w", err)
+	}
+
+	meta := &gai.ChatCompleteResponseMetadata{}
+	var firstTokenRecorded bool
+	recordFirstToken := func() {
+		if firstTokenRecorded {
+			return
+		}
+		firstTokenRecorded = true
+		span.SetAttributes(attribute.Int64("ai.time_to_first_token_ms", time.Since(streamStart).Milliseconds()))
+	}
+
+	r := gai.NewChatCompleteResponse(func(yield func(gai.Part, error) bool) {
+		defer span.End()
+
+		defer func() {
+			if err := res.Body.Close(); err != nil {
+				c.log.Info("Error closing stream", "error", err)
+			}
+		}()
+
+		// fail records err on the span and yields it as the final part.
+		fail := func(err error, description string) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, description)
+			yield(gai.Part{}, err)
+		}
+
+		// Tool calls can in principle arrive over several chunks, so they are accumulated by
+		// index and yielded when the choice finishes.
+		toolCalls := map[int]*chatToolCall{}
+		yieldToolCalls := func() bool {
+			indexes := slices.Sorted(func(yield func(int) bool) {
+				for index := range toolCalls {
+					if !yield(index) {
+						return
+					}
+				}
+			})
+			for _, index := range indexes {
+				call := toolCalls[index]
+				delete(toolCalls, index)
+				if !yield(gai.ToolCallPart(call.ID, call.Function.Name, json.RawMessage(call.Function.Arguments)), nil) {
+					return false
+				}
+			}
+			return true
+		}
+
+		for event, err := range sse.Read(res.Body) {
+			if err != nil {
+				fail(err, "stream error")
+				return
+			}
+			if string(event.Data) == "[DONE]" {
+				break
+			}
+
+			var chunk chatChunk
+			if err := json.Unmarshal(event.Data, &chunk); err != nil {
+				fail(fmt.Errorf("error parsing chunk: %w", err), "stream error")
+				return
+			}
+
+			if len(chunk.Choices) > 0 {
+				choice := chunk.Choices[0]
+
+				text, thoughts, err := parseContent(choice.Delta.Content)
+				if err != nil {
+					fail(err, "stream error")
+					return
+				}
+				for _, thought := range thoughts {
+					recordFirstToken()
+					if !yield(gai.ThoughtPart(thought), nil) {
+						return
+					}
+				}
+				if text != "" {
+					recordFirstToken()
+					if !yield(gai.TextPart(text), nil) {
+						return
+					}
+				}
+
+				for _, delta := range choice.Delta.ToolCalls {
+					recordFirstToken()
+					call, ok := toolCalls[delta.Index]
+					if !ok {
+						call = &chatToolCall{}
+						toolCalls[delta.Index] = call
+					}
+					if delta.ID != "" {
+						call.ID = delta.ID
+					}
+					if delta.Function.Name != "" {
+						call.Function.Name = delta.Function.Name
+					}
+					call.Function.Arguments += delta.Function.Arguments
+				}
+
+				if choice.FinishReason != nil && *choice.FinishReason != "" {
+					if !yieldToolCalls() {
+						return
+					}
+					mapped := mapFinishReason(*choice.FinishReason)
+					meta.FinishReason = gai.Ptr(mapped)
+					span.SetAttributes(attribute.String("ai.finish_reason", string(mapped)))
+				}
+			}
+
+			if chunk.Usage != nil {
+				meta.Usage = gai.ChatCompleteResponseUsage{
+					PromptTokens:     chunk.Usage.PromptTokens,
+					CompletionTokens: chunk.Usage.CompletionTokens,
+				}
+				span.SetAttributes(
+					attribute.Int("ai.prompt_tokens", chunk.Usage.PromptTokens),
+					attribute.Int("ai.completion_tokens", chunk.Usage.CompletionTokens),
+					attribute.Int("ai.total_tokens", chunk.Usage.TotalTokens),
+				)
+			}
+		}
+
+		yieldToolCalls()
+	})
+
+	r.Meta = meta
+
+	return r, nil
+}
+
+// parseContent splits a streamed content delta, which is either a string or a list of chunks,
+// into text and thinking.
+func parseContent(raw json.RawMessage) (string, []string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil, nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, nil, nil
+	}
+
+	var chunks []contentChunk
+	if err := json.Unmarshal(raw, &chunks); err != nil {
+		return "", nil, errors.New("error parsing content: neither a string nor a list of chunks")
+	}
+
+	var b strings.Builder
+	var thoughts []string
+	for _, chunk := range chunks {
+		switch chunk.Type {
+		case "text":
+			b.WriteString(chunk.Text)
+		case "thinking":
+			for _, thinking := range chunk.Thinking {
+				if thinking.Text != "" {
+					thoughts = append(thoughts, thinking.Text)
+				}
+			}
+		}
+	}
+	return b.String(), thoughts, nil
+}
+
+func mapFinishReason(reason string) gai.ChatCompleteFinishReason {
+	switch reason {
+	case "stop":
+		return gai.ChatCompleteFinishReasonStop
+	case "length", "model_length":
+		return gai.ChatCompleteFinishReasonLength
+	case "tool_calls":
+		return gai.ChatCompleteFinishReasonToolCalls
+	default:
+		return gai.ChatCompleteFinishReasonUnknown
+	}
+}
+
+var _ gai.ChatCompleter = (*ChatCompleter)(nil)
@@ -0,0 +1,290 @@
+package mistral_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/clients/mistral"
+	"maragu.dev/gai/internal/oteltest"
+)
+
+func TestChatCompleter_ChatComplete(t *testing.T) {
+	t.Run("streams thinking and text and records usage", func(t *testing.T) {
+		sr := oteltest.NewSpanRecorder(t)
+
+		srv := newServer(t, "/chat/completions",
+			`{"choices":[{"index":0,"delta":{"role":"assistant","content":[{"type":"thinking","thinking":[{"type":"text","text":"Hmm."}]}]},"finish_reason":null}]}`,
+			`{"choices":[{"index":0,"delta":{"content":"Hello"},"finish_reason":null}]}`,
+			`{"choices":[{"index":0,"delta":{"content":" there!"},"finish_reason":"stop"}],"usage":{"prompt_tokens":10,"completion_tokens":20,"total_tokens":30}}`,
+			`[DONE]`,
+		)
+		cc := newChatCompleter(t, srv.URL)
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages:            []gai.Message{gai.NewUserTextMessage("Hi!")},
+			System:              gai.Ptr("Be nice."),
+			ThinkingLevel:       gai.Ptr(mistral.ThinkingLevelHigh),
+			Temperature:         gai.Ptr(gai.Temperature(0.5)),
+			MaxCompletionTokens: gai.Ptr(100),
+		})
+		is.NotError(t, err)
+
+		var thought, output string
+		for part, err := range res.Parts() {
+			is.NotError(t, err)
+			switch part.Type {
+			case gai.PartTypeThought:
+				thought += part.Thought()
+			case gai.PartTypeText:
+				output += part.Text()
+			default:
+				t.Fatal("unexpected part type", part.Type)
+			}
+		}
+		is.Equal(t, "Hmm.", thought)
+		is.Equal(t, "Hello there!", output)
+
+		is.Equal(t, gai.ChatCompleteFinishReasonStop, *res.Meta.FinishReason)
+		is.Equal(t, 10, res.Meta.Usage.PromptTokens)
+		is.Equal(t, 20, res.Meta.Usage.CompletionTokens)
+
+		body := srv.body(t)
+		is.Equal(t, "mistral-small-latest", body["model"])
+		is.Equal(t, true, body["stream"])
+		is.Equal(t, "high", body["reasoning_effort"])
+		is.Equal(t, 0.5, body["temperature"])
+		is.Equal(t, any(float64(100)), body["max_tokens"])
+		messages := body["messages"].([]any)
+		is.Equal(t, "system", messages[0].(map[string]any)["role"])
+		is.Equal(t, "Bearer secret", srv.request.Header.Get("Authorization"))
+
+		span := oteltest.FindSpan(t, sr.Ended(), "mistral.chat_complete")
+		attrs := span.Attributes()
+		is.True(t, oteltest.HasAttribute(attrs, attribute.String("ai.model", "mistral-small-latest")))
+		is.True(t, oteltest.HasAttribute(attrs, attribute.String("ai.thinking_level", "high")))
+		is.True(t, oteltest.HasAttribute(attrs, attribute.Int("ai.max_completion_tokens", 100)))
+		is.True(t, oteltest.HasAttribute(attrs, attribute.Bool("ai.has_system_prompt", true)))
+		is.True(t, oteltest.HasAttribute(attrs, attribute.Int("ai.prompt_tokens", 10)))
+		is.True(t, oteltest.HasAttribute(attrs, attribute.Int("ai.completion_tokens", 20)))
+		is.True(t, oteltest.HasAttribute(attrs, attribute.String("ai.finish_reason", "stop")))
+	})
+
+	t.Run("accumulates tool calls and sends tool results back", func(t *testing.T) {
+		srv := newServer(t, "/chat/completions",
+			`{"choices":[{"index":0,"delta":{"tool_calls":[{"id":"call_1","index":0,"function":{"name":"get_weather","arguments":"{\"city\":"}}]},"finish_reason":null}]}`,
+			`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Copenhagen\"}"}}]},"finish_reason":"tool_calls"}]}`,
+			`[DONE]`,
+		)
+		cc := newChatCompleter(t, srv.URL)
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{
+				gai.NewUserTextMessage("What's the weather?"),
+				{Role: gai.MessageRoleModel, Parts: []gai.Part{gai.ToolCallPart("call_0", "get_weather", json.RawMessage(`{"city":"Aarhus"}`))}},
+				gai.NewUserToolResultMessage(gai.ToolResult{ID: "call_0", Name: "get_weather", Content: "Sunny"}),
+			},
+			Tools: []gai.Tool{
+				{Name: "get_time", Description: "Get the time."},
+				{
+					Name:        "get_weather",
+					Description: "Get the weather.",
+					Schema:      gai.ToolSchema{Properties: map[string]*gai.Schema{"city": {Type: gai.SchemaTypeString}}},
+				},
+			},
+			ToolChoice: gai.ToolChoice{Mode: gai.ToolChoiceModeTool, Name: "get_weather"},
+		})
+		is.NotError(t, err)
+
+		var calls []gai.ToolCall
+		for part, err := range res.Parts() {
+			is.NotError(t, err)
+			if part.Type == gai.PartTypeToolCall {
+				calls = append(calls, part.ToolCall())
+			}
+		}
+		is.Equal(t, 1, len(calls))
+		is.Equal(t, "call_1", calls[0].ID)
+		is.Equal(t, "get_weather", calls[0].Name)
+		is.Equal(t, `{"city":"Copenhagen"}`, string(calls[0].Args))
+		is.Equal(t, gai.ChatCompleteFinishReasonToolCalls, *res.Meta.FinishReason)
+
+		body := srv.body(t)
+		messages := body["messages"].([]any)
+		is.Equal(t, 3, len(messages))
+		assistant := messages[1].(map[string]any)
+		is.Equal(t, "assistant", assistant["role"])
+		is.Equal(t, "call_0", assistant["tool_calls"].([]any)[0].(map[string]any)["id"])
+		tool := messages[2].(map[string]any)
+		is.Equal(t, "tool", tool["role"])
+		is.Equal(t, "call_0", tool["tool_call_id"])
+		is.Equal(t, "Sunny", tool["content"])
+
+		toolChoice := body["tool_choice"].(map[string]any)
+		is.Equal(t, "get_weather", toolChoice["function"].(map[string]any)["name"])
+
+		// A tool without parameters has no properties, rather than null properties
+		tools := body["tools"].([]any)
+		is.Equal(t, 2, len(tools))
+		timeParameters := tools[0].(map[string]any)["function"].(map[string]any)["parameters"].(map[string]any)
+		is.Equal(t, "object", timeParameters["type"])
+		_, ok := timeParameters["properties"]
+		is.True(t, !ok)
+		weatherParameters := tools[1].(map[string]any)["function"].(map[string]any)["parameters"].(map[string]any)
+		is.Equal(t, "string", weatherParameters["properties"].(map[string]any)["city"].(map[string]any)["type"])
+	})
+
+	t.Run("sends a strict JSON schema response format", func(t *testing.T) {
+		srv := newServer(t, "/chat/completions",
+			`{"choices":[{"index":0,"delta":{"content":"{}"},"finish_reason":"stop"}]}`,
+			`[DONE]`,
+		)
+		cc := newChatCompleter(t, srv.URL)
+
+		type answer struct {
+			Answer string `json:"answer"`
+		}
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages:       []gai.Message{gai.NewUserTextMessage("Hi!")},
+			ResponseSchema: gai.Ptr(gai.GenerateSchema[answer]()),
+		})
+		is.NotError(t, err)
+		is.NotError(t, drainParts(res))
+
+		format := srv.body(t)["response_format"].(map[string]any)
+		is.Equal(t, "json_schema", format["type"])
+		is.Equal(t, true, format["json_schema"].(map[string]any)["strict"])
+	})
+
+	t.Run("sends images as data URIs", func(t *testing.T) {
+		srv := newServer(t, "/chat/completions",
+			`{"choices":[{"index":0,"delta":{"content":"A pixel."},"finish_reason":"stop"}]}`,
+			`[DONE]`,
+		)
+		cc := newChatCompleter(t, srv.URL)
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{{Role: gai.MessageRoleUser, Parts: []gai.Part{
+				gai.TextPart("What is this?"),
+				gai.DataPart("image/png", []byte("png")),
+			}}},
+		})
+		is.NotError(t, err)
+		is.NotError(t, drainParts(res))
+
+		content := srv.body(t)["messages"].([]any)[0].(map[string]any)["content"].([]any)
+		is.Equal(t, "data:image/png;base64,cG5n", content[1].(map[string]any)["image_url"])
+	})
+
+	t.Run("returns a validation error for unsupported data", func(t *testing.T) {
+		cc := newChatCompleter(t, "http://localhost")
+
+		_, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{{Role: gai.MessageRoleUser, Parts: []gai.Part{
+				gai.DataPart("audio/wav", []byte("wav")),
+			}}},
+		})
+		requireValidationError(t, err, "Messages[0].Parts[0].MIMEType", "unsupported MIME type for Mistral: audio/wav")
+	})
+
+	t.Run("returns a validation error for an unsupported thinking level", func(t *testing.T) {
+		cc := newChatCompleter(t, "http://localhost")
+
+		_, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages:      []gai.Message{gai.NewUserTextMessage("Hi!")},
+			ThinkingLevel: gai.Ptr(gai.ThinkingLevel("medium")),
+		})
+		requireValidationError(t, err, "ThinkingLevel", "unsupported thinking level: medium")
+	})
+
+	t.Run("includes the status code in request errors", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"message":"Rate limit exceeded"}`, http.StatusTooManyRequests)
+		}))
+		t.Cleanup(srv.Close)
+		cc := newChatCompleter(t, srv.URL)
+
+		_, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("Hi!")},
+		})
+		is.True(t, err != nil)
+		is.True(t, strings.Contains(err.Error(), "429 Too Many Requests"))
+	})
+}
+
+type server struct {
+	*httptest.Server
+	request  *http.Request
+	lastBody []byte
+}
+
+// body returns the last request body, decoded.
+func (s *server) body(t *testing.T) map[string]any {
+	t.Helper()
+	var body map[string]any
+	is.NotError(t, json.Unmarshal(s.lastBody, &body))
+	return body
+}
+
+// newServer starts a stand-in for the Mistral API that answers requests to path with
+// the given server-sent event payloads.
+func newServer(t *testing.T, path string, events ...string) *server {
+	t.Helper()
+
+	s := &server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.request = r
+		s.lastBody = body
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range events {
+			_, _ = fmt.Fprintf(w, "data: %v\n\n", event)
+		}
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func newChatCompleter(t *testing.T, baseURL string) *mistral.ChatCompleter {
+	t.Helper()
+	c := mistral.NewClient(mistral.NewClientOptions{BaseURL: baseURL, Key: "secret"})
+	return c.NewChatCompleter(mistral.NewChatCompleterOptions{Model: mistral.ChatCompleteModelMistralSmallLatest})
+}
+
+func requireValidationError(t *testing.T, err error, field, message string) {
+	t.Helper()
+	var validationErr *gai.ValidationError
+	is.True(t, errors.As(err, &validationErr), "expected a validation error")
+	is.Equal(t, field, validationErr.Field)
+	is.Equal(t, message, validationErr.Err.Error())
+}
+
+// drainParts iterates the response stream, returning the first error if any.
+func drainParts(res gai.ChatCompleteResponse) error {
+	for _, err := range res.Parts() {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
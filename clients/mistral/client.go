@@ -0,0 +1,82 @@
+// Package mistral provides [gai.ChatCompleter] and [gai.Embedder] implementations
+// backed by the Mistral AI API. Construct a [Client] with [NewClient], then derive
+// a chat completer or embedder via [Client.NewChatCompleter] or [Client.NewEmbedder].
+//
+// Mistral publishes no Go SDK, so the client talks to the REST API directly.
+package mistral
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type Client struct {
+	Client  *http.Client
+	baseURL string
+	key     string
+	log     *slog.Logger
+}
+
+type NewClientOptions struct {
+	// BaseURL defaults to https://api.mistral.ai/v1/.
+	BaseURL string
+	Key     string
+	Log     *slog.Logger
+}
+
+func NewClient(opts NewClientOptions) *Client {
+	if opts.Log == nil {
+		opts.Log = slog.New(slog.DiscardHandler)
+	}
+
+	if opts.BaseURL == "" {
+		opts.BaseURL = "https://api.mistral.ai/v1/"
+	}
+	if !strings.HasSuffix(opts.BaseURL, "/") {
+		opts.BaseURL += "/"
+	}
+
+	return &Client{
+		Client:  &http.Client{},
+		baseURL: opts.BaseURL,
+		key:     opts.Key,
+		log:     opts.Log,
+	}
+}
+
+// post sends body as JSON to path. Non-2xx responses are returned as errors carrying the
+// status and response body, so status-based retry classification works on the error string.
+func (c *Client) post(ctx context.Context, path string, body any) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.key)
+
+	res, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		defer func() {
+			_ = res.Body.Close()
+		}()
+		resBody, _ := io.ReadAll(io.LimitReader(res.Body, 64*1024))
+		return nil, fmt.Errorf("POST %v: %v: %s", path, res.Status, bytes.TrimSpace(resBody))
+	}
+
+	return res, nil
+}
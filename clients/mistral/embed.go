@@ -0,0 +1,183 @@
+package mistral
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"maragu.dev/errors"
+
+	"maragu.dev/gai"
+)
+
+type EmbedModel string
+
+const (
+	EmbedModelCodestralEmbed = EmbedModel("codestral-embed")
+	EmbedModelMistralEmbed   = EmbedModel("mistral-embed")
+)
+
+type Embedder struct {
+	Client     *Client
+	dimensions int
+	log        *slog.Logger
+	model      EmbedModel
+	tracer     trace.Tracer
+}
+
+type NewEmbedderOptions struct {
+	// Dimensions must be 1024 for [EmbedModelMistralEmbed], which has a fixed output size.
+	// [EmbedModelCodestralEmbed] supports up to 3072.
+	Dimensions int
+	Model      EmbedModel
+}
+
+func (c *Client) NewEmbedder(opts NewEmbedderOptions) *Embedder {
+	if opts.Dimensions <= 0 {
+		panic("dimensions must be greater than 0")
+	}
+
+	switch opts.Model {
+	case EmbedModelMistralEmbed:
+		if opts.Dimensions != 1024 {
+			panic("dimensions must be 1024")
+		}
+	case EmbedModelCodestralEmbed:
+		if opts.Dimensions > 3072 {
+			panic("dimensions must be less than or equal to 3072")
+		}
+	}
+
+	return &Embedder{
+		Client:     c,
+		dimensions: opts.Dimensions,
+		log:        c.log,
+		model:      opts.Model,
+		tracer:     otel.Tracer("maragu.dev/gai/clients/mistral"),
+	}
+}
+
+type embedRequest struct {
+	Model           string   `json:"model"`
+	Input           []string `json:"input"`
+	OutputDimension *int     `json:"output_dimension,omitempty"`
+	OutputDType     string   `json:"output_dtype,omitempty"`
+}
+
+type embedResponse struct {
+	Data []struct {
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+	Usage struct {
+		PromptTokens int `json:"prompt_tokens"`
+		TotalTokens  int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// Embed satisfies [gai.Embedder].
+// Only text can be embedded, either a single part, or several parts with [gai.EmbedRequest.Separate].
+func (e *Embedder) Embed(ctx context.Context, req gai.EmbedRequest) (gai.EmbedResponse[float64], error) {
+	ctx, span := e.tracer.Start(ctx, "mistral.embed",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("ai.model", string(e.model)),
+			attribute.Int("ai.dimensions", e.dimensions),
+		),
+	)
+	defer span.End()
+
+	if len(req.Parts) == 0 {
+		err := gai.NewValidationError("Parts", "no parts")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request")
+		return gai.EmbedResponse[float64]{}, err
+	}
+
+	var texts []string
+	var inputLength int
+	for _, part := range req.Parts {
+		if part.Type == gai.PartTypeText {
+			texts = append(texts, part.Text())
+			inputLength += len(part.Text())
+		}
+	}
+	if len(texts) != len(req.Parts) || (!req.Separate && len(texts) != 1) {
+		message := "Mistral embeddings only support a single text part"
+		if req.Separate {
+			message = "Mistral embeddings only support text parts"
+		}
+		err := gai.NewValidationError("Parts", message)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request")
+		return gai.EmbedResponse[float64]{}, err
+	}
+	if req.Separate {
+		span.SetAttributes(attribute.Bool("ai.embed_separate", true))
+	}
+
+	span.SetAttributes(attribute.Int("ai.input_length", inputLength))
+
+	body := embedRequest{
+		Model: string(e.model),
+		Input: texts,
+	}
+	// mistral-embed rejects output_dimension, so only send it where it is configurable.
+	if e.model != EmbedModelMistralEmbed {
+		body.OutputDimension = gai.Ptr(e.dimensions)
+		body.OutputDType = "float"
+	}
+
+	httpRes, err := e.Client.post(ctx, "embeddings", body)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "embedding request failed")
+		return gai.EmbedResponse[float64]{}, errors.Wrap(err, "error embedding")
+	}
+	defer func() {
+		_ = httpRes.Body.Close()
+	}()
+
+	var res embedResponse
+	if err := json.NewDecoder(httpRes.Body).Decode(&res); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "embedding request failed")
+		return gai.EmbedResponse[float64]{}, errors.Wrap(err, "error decoding embedding response")
+	}
+	if len(res.Data) == 0 {
+		err := errors.New("no embeddings returned")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "no embeddings in response")
+		return gai.EmbedResponse[float64]{}, err
+	}
+	if len(res.Data) != len(texts) {
+		err := errors.Newf("expected %v embeddings, but got %v", len(texts), len(res.Data))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "wrong number of embeddings in response")
+		return gai.EmbedResponse[float64]{}, err
+	}
+
+	if res.Usage.PromptTokens > 0 {
+		span.SetAttributes(
+			attribute.Int("ai.prompt_tokens", res.Usage.PromptTokens),
+			attribute.Int("ai.total_tokens", res.Usage.TotalTokens),
+		)
+	}
+
+	if req.Separate {
+		var embeddings [][]float64
+		for _, d := range res.Data {
+			embeddings = append(embeddings, d.Embedding)
+		}
+		return gai.EmbedResponse[float64]{Embeddings: embeddings}, nil
+	}
+
+	return gai.EmbedResponse[float64]{
+		Embedding: res.Data[0].Embedding,
+	}, nil
+}
+
+var _ gai.Embedder[float64] = (*Embedder)(nil)
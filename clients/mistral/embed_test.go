@@ -0,0 +1,94 @@
+package mistral_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/clients/mistral"
+	"maragu.dev/gai/internal/oteltest"
+)
+
+func TestEmbedder_Embed(t *testing.T) {
+	t.Run("embeds text with the configured output dimension", func(t *testing.T) {
+		sr := oteltest.NewSpanRecorder(t)
+
+		var body map[string]any
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			is.Equal(t, "/embeddings", r.URL.Path)
+			data, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(data, &body)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprint(w, `{"data":[{"embedding":[0.1,0.2,0.3]}],"usage":{"prompt_tokens":3,"total_tokens":3}}`)
+		}))
+		t.Cleanup(srv.Close)
+
+		c := mistral.NewClient(mistral.NewClientOptions{BaseURL: srv.URL, Key: "secret"})
+		e := c.NewEmbedder(mistral.NewEmbedderOptions{Model: mistral.EmbedModelCodestralEmbed, Dimensions: 3})
+
+		res, err := e.Embed(t.Context(), gai.NewTextEmbedRequest("func main() {}"))
+		is.NotError(t, err)
+		is.EqualSlice(t, []float64{0.1, 0.2, 0.3}, res.Embedding)
+
+		is.Equal(t, "codestral-embed", body["model"])
+		is.Equal(t, any(float64(3)), body["output_dimension"])
+
+		span := oteltest.FindSpan(t, sr.Ended(), "mistral.embed")
+		oteltest.RequirePositiveIntAttribute(t, span.Attributes(), "ai.prompt_tokens")
+	})
+
+	t.Run("does not send an output dimension for mistral-embed", func(t *testing.T) {
+		var body map[string]any
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(data, &body)
+			_, _ = fmt.Fprint(w, `{"data":[{"embedding":[0.1]}]}`)
+		}))
+		t.Cleanup(srv.Close)
+
+		c := mistral.NewClient(mistral.NewClientOptions{BaseURL: srv.URL, Key: "secret"})
+		e := c.NewEmbedder(mistral.NewEmbedderOptions{Model: mistral.EmbedModelMistralEmbed, Dimensions: 1024})
+
+		_, err := e.Embed(t.Context(), gai.NewTextEmbedRequest("Hi!"))
+		is.NotError(t, err)
+		_, ok := body["output_dimension"]
+		is.True(t, !ok)
+	})
+
+	t.Run("embeds several text parts separately", func(t *testing.T) {
+		var body map[string]any
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(data, &body)
+			_, _ = fmt.Fprint(w, `{"data":[{"embedding":[0.1]},{"embedding":[0.2]}]}`)
+		}))
+		t.Cleanup(srv.Close)
+
+		c := mistral.NewClient(mistral.NewClientOptions{BaseURL: srv.URL, Key: "secret"})
+		e := c.NewEmbedder(mistral.NewEmbedderOptions{Model: mistral.EmbedModelMistralEmbed, Dimensions: 1024})
+
+		res, err := e.Embed(t.Context(), gai.EmbedRequest{Parts: []gai.Part{gai.TextPart("a"), gai.TextPart("b")}, Separate: true})
+		is.NotError(t, err)
+		is.Equal(t, 0, len(res.Embedding))
+		is.Equal(t, 2, len(res.Embeddings))
+		is.EqualSlice(t, []float64{0.2}, res.Embeddings[1])
+		is.Equal(t, 2, len(body["input"].([]any)))
+
+		_, err = e.Embed(t.Context(), gai.EmbedRequest{Parts: []gai.Part{gai.TextPart("a"), gai.DataPart("image/png", []byte("png"))}, Separate: true})
+		requireValidationError(t, err, "Parts", "Mistral embeddings only support text parts")
+	})
+
+	t.Run("returns a validation error for non-text parts", func(t *testing.T) {
+		c := mistral.NewClient(mistral.NewClientOptions{BaseURL: "http://localhost", Key: "secret"})
+		e := c.NewEmbedder(mistral.NewEmbedderOptions{Model: mistral.EmbedModelMistralEmbed, Dimensions: 1024})
+
+		_, err := e.Embed(t.Context(), gai.EmbedRequest{Parts: []gai.Part{gai.TextPart("a"), gai.TextPart("b")}})
+		requireValidationError(t, err, "Parts", "Mistral embeddings only support a single text part")
+	})
+}
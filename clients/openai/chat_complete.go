@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -61,7 +63,8 @@ const (
 //
 // Pass [gai.ThinkingLevelNone] to opt out — accepted by gpt-5.1+, gpt-5.4*, and gpt-5.5;
 // rejected by gpt-5 and by gpt-5.3-chat-latest. Using a level a given model does not
-// support surfaces a 400 from the API. Levels not in this list panic at the client boundary.
+// support surfaces a 400 from the API. Levels not in this list are rejected with a
+// [gai.ValidationError] at the client boundary.
 const (
 	// ThinkingLevelMinimal applies the cheapest reasoning effort. gpt-5 only.
 	ThinkingLevelMinimal gai.ThinkingLevel = "minimal"
@@ -76,22 +79,31 @@ const (
 )
 
 type ChatCompleter struct {
-	Client openai.Client
-	log    *slog.Logger
-	model  ChatCompleteModel
-	tracer trace.Tracer
+	Client      openai.Client
+	log         *slog.Logger
+	model       ChatCompleteModel
+	profile     Profile
+	strictTools bool
+	tracer      trace.Tracer
 }
 
 type NewChatCompleterOptions struct {
 	Model ChatCompleteModel
+	// StrictTools turns on strict mode for the request's tools, so the model's arguments always
+	// match the schemas. Strict mode requires every property, so optional properties are sent as
+	// required and nullable, and the model sends null to leave them out. Objects never allow
+	// additional properties. Strict arguments are not guaranteed for parallel tool calls.
+	StrictTools bool
 }
 
 func (c *Client) NewChatCompleter(opts NewChatCompleterOptions) *ChatCompleter {
 	return &ChatCompleter{
-		Client: c.Client,
-		log:    c.log,
-		model:  opts.Model,
-		tracer: otel.Tracer("maragu.dev/gai/clients/openai"),
+		Client:      c.Client,
+		log:         c.log,
+		model:       opts.Model,
+		profile:     c.profile,
+		strictTools: opts.StrictTools,
+		tracer:      otel.Tracer("maragu.dev/gai/clients/openai"),
 	}
 }
 
@@ -112,6 +124,14 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 		return gai.ChatCompleteResponse{}, err
 	}
 
+	// invalid records a [gai.ValidationError] on the span and ends it, for caller data we cannot send.
+	invalid := func(err *gai.ValidationError) (gai.ChatCompleteResponse, error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request")
+		span.End()
+		return gai.ChatCompleteResponse{}, err
+	}
+
 	var messages []openai.ChatCompletionMessageParamUnion
 
 	if req.System != nil {
@@ -119,12 +139,12 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 		span.SetAttributes(attribute.Bool("ai.has_system_prompt", true))
 	}
 
-	for _, m := range req.Messages {
+	for i, m := range req.Messages {
 		switch m.Role {
 		case gai.MessageRoleUser:
 			var parts []openai.ChatCompletionContentPartUnionParam
 
-			for _, part := range m.Parts {
+			for j, part := range m.Parts {
 				switch part.Type {
 				case gai.PartTypeText:
 					parts = append(parts, openai.ChatCompletionContentPartUnionParam{
@@ -150,10 +170,10 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 
 				case gai.PartTypeData:
 					if part.MIMEType == "" {
-						panic("data part has empty MIME type")
+						return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].MIMEType", i, j), "data part has empty MIME type"))
 					}
 					if len(part.Data) == 0 {
-						panic("data part has empty data")
+						return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].Data", i, j), "data part has empty data"))
 					}
 					encoded := base64.StdEncoding.EncodeToString(part.Data)
 
@@ -180,7 +200,7 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 						})
 
 					default:
-						panic("unsupported MIME type for OpenAI: " + part.MIMEType)
+						return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].MIMEType", i, j), "unsupported MIME type for OpenAI: "+part.MIMEType))
 					}
 
 				case gai.PartTypeThought:
@@ -192,7 +212,7 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 					continue
 
 				default:
-					panic("unknown part type " + string(part.Type))
+					return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].Type", i, j), "unknown part type "+string(part.Type)))
 				}
 			}
 
@@ -203,7 +223,7 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 		case gai.MessageRoleModel:
 			var parts []openai.ChatCompletionAssistantMessageParamContentArrayOfContentPartUnion
 
-			for _, part := range m.Parts {
+			for j, part := range m.Parts {
 				switch part.Type {
 				case gai.PartTypeText:
 					parts = append(parts, openai.ChatCompletionAssistantMessageParamContentArrayOfContentPartUnion{
@@ -243,7 +263,7 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 					continue
 
 				default:
-					panic("unknown part type " + string(part.Type))
+					return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].Type", i, j), "unknown part type "+string(part.Type)))
 				}
 			}
 
@@ -252,21 +272,22 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 			}
 
 		default:
-			panic("unknown role " + m.Role)
+			return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Role", i), "unknown role "+string(m.Role)))
 		}
 	}
 
 	var tools []openai.ChatCompletionToolUnionParam
 	var toolNames []string
 	for _, tool := range req.Tools {
-		tools = append(tools, openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+		function := openai.FunctionDefinitionParam{
 			Name:        tool.Name,
 			Description: openai.String(tool.Description),
-			Parameters: openai.FunctionParameters{
-				"type":       "object",
-				"properties": normalizeToolSchemaProperties(tool.Schema.Properties),
-			},
-		}))
+			Parameters:  toolParameters(tool.Schema, c.strictTools),
+		}
+		if c.strictTools {
+			function.Strict = openai.Bool(true)
+		}
+		tools = append(tools, openai.ChatCompletionFunctionTool(function))
 		toolNames = append(toolNames, tool.Name)
 	}
 	sort.Strings(toolNames)
@@ -279,9 +300,12 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 		Messages: messages,
 		Model:    openai.ChatModel(c.model),
 		Tools:    tools,
-		StreamOptions: openai.ChatCompletionStreamOptionsParam{
+	}
+
+	if !c.profile.NoStreamUsage {
+		params.StreamOptions = openai.ChatCompletionStreamOptionsParam{
 			IncludeUsage: openai.Bool(true),
-		},
+		}
 	}
 
 	switch req.ToolChoice.Mode {
@@ -320,14 +344,19 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 		case ThinkingLevelXHigh:
 			params.ReasoningEffort = shared.ReasoningEffortXhigh
 		default:
-			panic("unsupported thinking level: " + string(*req.ThinkingLevel))
+			return invalid(gai.NewValidationError("ThinkingLevel", "unsupported thinking level: "+string(*req.ThinkingLevel)))
+		}
+		if c.profile.NoReasoningEffort {
+			params.ReasoningEffort = ""
+			c.log.Debug("Not sending thinking level", "profile", c.profile.Name, "thinking_level", *req.ThinkingLevel)
+		} else {
+			span.SetAttributes(attribute.String("ai.thinking_level", string(*req.ThinkingLevel)))
 		}
-		span.SetAttributes(attribute.String("ai.thinking_level", string(*req.ThinkingLevel)))
 	}
 
 	if req.ResponseSchema != nil {
 		normalized := normalizeToolSchema(req.ResponseSchema)
-		jsonSchemaObject := schemaToJSONObject(normalized)
+		jsonSchemaObject := schemaToJSONObject(normalized, true)
 		jsonSchema := shared.ResponseFormatJSONSchemaJSONSchemaParam{
 			Name:   responseSchemaName(req.ResponseSchema),
 			Strict: openai.Bool(true),
@@ -453,6 +482,12 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 	return res, nil
 }
 
+// toolParameters converts a [gai.ToolSchema] to the JSON Schema object OpenAI takes as function parameters.
+func toolParameters(schema gai.ToolSchema, strict bool) map[string]any {
+	s := schema.Schema()
+	return schemaToJSONObject(normalizeToolSchema(&s), strict)
+}
+
 // normalizeToolSchemaProperties recursively normalizes schema properties for OpenAI compatibility
 func normalizeToolSchemaProperties(properties map[string]*gai.Schema) map[string]*gai.Schema {
 	if len(properties) == 0 {
@@ -473,41 +508,42 @@ func normalizeToolSchema(schema *gai.Schema) *gai.Schema {
 	}
 
 	// Create a copy of the schema
-	normalized := &gai.Schema{
-		AnyOf:            schema.AnyOf,
-		Default:          schema.Default,
-		Description:      schema.Description,
-		Enum:             schema.Enum,
-		Example:          schema.Example,
-		Format:           schema.Format,
-		Items:            normalizeToolSchema(schema.Items),
-		MaxItems:         schema.MaxItems,
-		Maximum:          schema.Maximum,
-		MinItems:         schema.MinItems,
-		Minimum:          schema.Minimum,
-		Properties:       normalizeToolSchemaProperties(schema.Properties),
-		PropertyOrdering: schema.PropertyOrdering,
-		Required:         schema.Required,
-		Title:            schema.Title,
-		Type:             gai.SchemaType(strings.ToLower(string(schema.Type))),
-	}
-
-	// Recursively normalize anyOf schemas
-	if len(schema.AnyOf) > 0 {
-		normalized.AnyOf = make([]*gai.Schema, len(schema.AnyOf))
-		for i, s := range schema.AnyOf {
-			normalized.AnyOf[i] = normalizeToolSchema(s)
-		}
-	}
+	normalized := *schema
+	normalized.Defs = normalizeToolSchemaProperties(schema.Defs)
+	normalized.Items = normalizeToolSchema(schema.Items)
+	normalized.Properties = normalizeToolSchemaProperties(schema.Properties)
+	normalized.Type = gai.SchemaType(strings.ToLower(string(schema.Type)))
+
+	// Recursively normalize subschemas
+	normalized.AllOf = normalizeToolSchemas(schema.AllOf)
+	normalized.AnyOf = normalizeToolSchemas(schema.AnyOf)
+	normalized.OneOf = normalizeToolSchemas(schema.OneOf)
+
+	return &normalized
+}
 
+func normalizeToolSchemas(schemas []*gai.Schema) []*gai.Schema {
+	if len(schemas) == 0 {
+		return nil
+	}
+	normalized := make([]*gai.Schema, len(schemas))
+	for i, s := range schemas {
+		normalized[i] = normalizeToolSchema(s)
+	}
 	return normalized
 }
 
-func schemaToJSONObject(schema *gai.Schema) map[string]any {
+// schemaToJSONObject converts a normalized schema to the JSON object OpenAI takes. For strict mode,
+// see [requireAllProperties] and [toStrictSchema] for how the schema is changed.
+func schemaToJSONObject(schema *gai.Schema, strict bool) map[string]any {
 	if schema == nil {
 		return nil
 	}
 
+	if strict {
+		requireAllProperties(schema)
+	}
+
 	data, err := json.Marshal(schema)
 	if err != nil {
 		panic(err)
@@ -518,36 +554,157 @@ func schemaToJSONObject(schema *gai.Schema) map[string]any {
 		panic(err)
 	}
 
-	ensureObjectSchemasDisallowAdditionalProperties(obj)
+	if strict {
+		defs, _ := obj["$defs"].(map[string]any)
+		toStrictSchema(obj, defs)
+	}
 	return obj
 }
 
-func ensureObjectSchemasDisallowAdditionalProperties(obj map[string]any) {
+// requireAllProperties recursively makes every object property required, because strict mode
+// requires it. Properties that were optional become nullable instead, so the model can still
+// leave them out by sending null.
+func requireAllProperties(schema *gai.Schema) {
+	if schema == nil {
+		return
+	}
+
+	if len(schema.Properties) > 0 {
+		required := slices.Clone(schema.Required)
+		// Keep the property order where there is one, for the model's sake
+		names := slices.Concat(schema.PropertyOrdering, slices.Sorted(maps.Keys(schema.Properties)))
+		for _, name := range names {
+			property, ok := schema.Properties[name]
+			if !ok || slices.Contains(required, name) {
+				continue
+			}
+			property.Nullable = true
+			required = append(required, name)
+		}
+		schema.Required = required
+	}
+
+	requireAllProperties(schema.Items)
+	for _, property := range schema.Properties {
+		requireAllProperties(property)
+	}
+	for _, def := range schema.Defs {
+		requireAllProperties(def)
+	}
+	for _, sub := range slices.Concat(schema.AllOf, schema.AnyOf, schema.OneOf) {
+		requireAllProperties(sub)
+	}
+}
+
+// toStrictSchema recursively rewrites a JSON Schema object to the subset strict mode accepts:
+// allOf is merged into the schema, oneOf becomes anyOf, length constraints are dropped, and
+// objects never allow additional properties.
+func toStrictSchema(obj map[string]any, defs map[string]any) {
 	if obj == nil {
 		return
 	}
 
-	if t, ok := obj["type"].(string); ok && t == "object" {
-		if _, ok := obj["additionalProperties"]; !ok {
-			obj["additionalProperties"] = false
+	for {
+		allOf, ok := obj["allOf"].([]any)
+		if !ok {
+			break
+		}
+		delete(obj, "allOf")
+		for _, v := range allOf {
+			child, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+			if ref, ok := child["$ref"].(string); ok {
+				if def, ok := defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any); ok {
+					child = def
+				}
+			}
+			mergeSchemaObjects(obj, child)
 		}
-		if props, ok := obj["properties"].(map[string]any); ok {
-			for _, v := range props {
+	}
+
+	if oneOf, ok := obj["oneOf"].([]any); ok {
+		anyOf, _ := obj["anyOf"].([]any)
+		obj["anyOf"] = append(anyOf, oneOf...)
+		delete(obj, "oneOf")
+	}
+
+	delete(obj, "minLength")
+	delete(obj, "maxLength")
+
+	if hasObjectType(obj) {
+		obj["additionalProperties"] = false
+	}
+
+	for _, key := range []string{"properties", "$defs"} {
+		if children, ok := obj[key].(map[string]any); ok {
+			for _, v := range children {
 				if child, ok := v.(map[string]any); ok {
-					ensureObjectSchemasDisallowAdditionalProperties(child)
+					toStrictSchema(child, defs)
 				}
 			}
 		}
 	}
 
 	if items, ok := obj["items"].(map[string]any); ok {
-		ensureObjectSchemasDisallowAdditionalProperties(items)
+		toStrictSchema(items, defs)
 	}
 
 	if anyOf, ok := obj["anyOf"].([]any); ok {
 		for _, v := range anyOf {
 			if child, ok := v.(map[string]any); ok {
-				ensureObjectSchemasDisallowAdditionalProperties(child)
+				toStrictSchema(child, defs)
+			}
+		}
+	}
+}
+
+// hasObjectType reports whether a JSON Schema object has the object type, alone or in a list of types.
+func hasObjectType(obj map[string]any) bool {
+	switch t := obj["type"].(type) {
+	case string:
+		return t == "object"
+	case []any:
+		return slices.Contains(t, any("object"))
+	default:
+		return false
+	}
+}
+
+// mergeSchemaObjects merges the JSON Schema object src into dst, for allOf. Properties and required
+// fields are combined, and other keywords are copied if dst doesn't have them.
+func mergeSchemaObjects(dst, src map[string]any) {
+	for key, v := range src {
+		switch key {
+		case "properties":
+			properties, _ := dst["properties"].(map[string]any)
+			if properties == nil {
+				properties = map[string]any{}
+			}
+			if srcProperties, ok := v.(map[string]any); ok {
+				for name, property := range srcProperties {
+					if _, ok := properties[name]; !ok {
+						properties[name] = property
+					}
+				}
+			}
+			dst["properties"] = properties
+
+		case "required":
+			required, _ := dst["required"].([]any)
+			if srcRequired, ok := v.([]any); ok {
+				for _, name := range srcRequired {
+					if !slices.Contains(required, name) {
+						required = append(required, name)
+					}
+				}
+			}
+			dst["required"] = required
+
+		default:
+			if _, ok := dst[key]; !ok {
+				dst[key] = v
 			}
 		}
 	}
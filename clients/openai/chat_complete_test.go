@@ -3,11 +3,13 @@ package openai_test
 import (
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"os"
 	"strings"
 	"testing"
 
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"maragu.dev/is"
 
 	"maragu.dev/gai"
@@ -350,32 +352,21 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 		is.True(t, len(output) > 0, "should have output")
 	})
 
-	t.Run("panics on unsupported MIME type", func(t *testing.T) {
+	t.Run("returns a validation error on unsupported MIME type", func(t *testing.T) {
 		cc := newChatCompleter(t)
 
-		defer func() {
-			r := recover()
-			is.True(t, r != nil)
-			is.Equal(t, "unsupported MIME type for OpenAI: application/pdf", r)
-		}()
-
 		req := gai.ChatCompleteRequest{
 			Messages: []gai.Message{
 				gai.NewUserDataMessage("application/pdf", pdf),
 			},
 		}
-		_, _ = cc.ChatComplete(t.Context(), req)
+		_, err := cc.ChatComplete(t.Context(), req)
+		requireValidationError(t, err, "Messages[0].Parts[0].MIMEType", "unsupported MIME type for OpenAI: application/pdf")
 	})
 
-	t.Run("panics on empty MIME type", func(t *testing.T) {
+	t.Run("returns a validation error on empty MIME type", func(t *testing.T) {
 		cc := newChatCompleter(t)
 
-		defer func() {
-			r := recover()
-			is.True(t, r != nil)
-			is.Equal(t, "data part has empty MIME type", r)
-		}()
-
 		req := gai.ChatCompleteRequest{
 			Messages: []gai.Message{
 				{Role: gai.MessageRoleUser, Parts: []gai.Part{
@@ -383,18 +374,13 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 				}},
 			},
 		}
-		_, _ = cc.ChatComplete(t.Context(), req)
+		_, err := cc.ChatComplete(t.Context(), req)
+		requireValidationError(t, err, "Messages[0].Parts[0].MIMEType", "data part has empty MIME type")
 	})
 
-	t.Run("panics on empty data", func(t *testing.T) {
+	t.Run("returns a validation error on empty data", func(t *testing.T) {
 		cc := newChatCompleter(t)
 
-		defer func() {
-			r := recover()
-			is.True(t, r != nil)
-			is.Equal(t, "data part has empty data", r)
-		}()
-
 		req := gai.ChatCompleteRequest{
 			Messages: []gai.Message{
 				{Role: gai.MessageRoleUser, Parts: []gai.Part{
@@ -402,7 +388,8 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 				}},
 			},
 		}
-		_, _ = cc.ChatComplete(t.Context(), req)
+		_, err := cc.ChatComplete(t.Context(), req)
+		requireValidationError(t, err, "Messages[0].Parts[0].Data", "data part has empty data")
 	})
 
 	// Reasoning-effort matrix. Each row exercises a real (model, level) combination so the
@@ -554,9 +541,9 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 		}
 	})
 
-	t.Run("panics on unsupported thinking level", func(t *testing.T) {
+	t.Run("returns a validation error on unsupported thinking level", func(t *testing.T) {
 		// The OpenAI client publishes Minimal/Low/Medium/High/XHigh. Anything outside
-		// that set must panic at the boundary, not silently round-trip to the API.
+		// that set must be rejected at the boundary, not silently round-trip to the API.
 		tests := []struct {
 			name  string
 			level gai.ThinkingLevel
@@ -569,19 +556,12 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 			t.Run(test.name, func(t *testing.T) {
 				cc := newChatCompleter(t)
 
-				defer func() {
-					r := recover()
-					is.True(t, r != nil, "expected a panic")
-					msg, ok := r.(string)
-					is.True(t, ok, "panic value should be a string")
-					is.Equal(t, "unsupported thinking level: "+string(test.level), msg)
-				}()
-
 				req := gai.ChatCompleteRequest{
 					Messages:      []gai.Message{gai.NewUserTextMessage("Hi!")},
 					ThinkingLevel: gai.Ptr(test.level),
 				}
-				_, _ = cc.ChatComplete(t.Context(), req)
+				_, err := cc.ChatComplete(t.Context(), req)
+				requireValidationError(t, err, "ThinkingLevel", "unsupported thinking level: "+string(test.level))
 			})
 		}
 	})
@@ -657,6 +637,23 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 		})
 	})
 
+	t.Run("records an error status on the span for an invalid request", func(t *testing.T) {
+		sr := oteltest.NewSpanRecorder(t)
+		cc := newChatCompleter(t)
+
+		_, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{
+				gai.NewUserTextMessage("Describe this."),
+				gai.NewUserDataMessage("video/mp4", []byte("fake video")),
+			},
+		})
+		requireValidationError(t, err, "Messages[1].Parts[0].MIMEType", "unsupported MIME type for OpenAI: video/mp4")
+
+		span := oteltest.FindSpan(t, sr.Ended(), "openai.chat_complete")
+		is.Equal(t, codes.Error, span.Status().Code)
+		is.Equal(t, "invalid request", span.Status().Description)
+	})
+
 	t.Run("records standard attributes on the chat-complete span", func(t *testing.T) {
 		sr := oteltest.NewSpanRecorder(t)
 		cc := newChatCompleter(t)
@@ -679,6 +676,99 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 		oteltest.RequirePositiveIntAttribute(t, span.Attributes(), "ai.total_tokens")
 		oteltest.RequireCacheReadSubsetOfPromptTokens(t, span.Attributes())
 	})
+
+	t.Run("sends response schemas in the form strict mode accepts", func(t *testing.T) {
+		var body map[string]any
+		srv := newChatCompletionsServer(t, nil, &body)
+
+		c := openai.NewClient(openai.NewClientOptions{Key: "secret", Profile: openai.Profile{BaseURL: srv.URL}})
+		cc := c.NewChatCompleter(openai.NewChatCompleterOptions{Model: openai.ChatCompleteModelGPT5Nano})
+
+		schema := gai.GenerateSchema[category]()
+		schema.Properties["name"].MinLength = gai.Ptr(int64(1))
+		schema.Properties["kind"] = &gai.Schema{OneOf: []*gai.Schema{
+			{Type: gai.SchemaTypeString, Const: "leaf"},
+			{Type: gai.SchemaTypeString, Const: "branch"},
+		}}
+		requireChatCompletes(t, cc, gai.ChatCompleteRequest{
+			Messages:       []gai.Message{gai.NewUserTextMessage("Hi!")},
+			ResponseSchema: &schema,
+		})
+
+		format := body["response_format"].(map[string]any)["json_schema"].(map[string]any)
+		is.Equal(t, true, format["strict"])
+
+		root := format["schema"].(map[string]any)
+		is.Equal(t, false, root["additionalProperties"])
+
+		properties := root["properties"].(map[string]any)
+		name := properties["name"].(map[string]any)
+		_, ok := name["minLength"]
+		is.True(t, !ok)
+
+		kind := properties["kind"].(map[string]any)
+		is.Equal(t, 2, len(kind["anyOf"].([]any)))
+
+		parent := properties["parent"].(map[string]any)
+		is.Equal(t, "#/$defs/category", parent["anyOf"].([]any)[0].(map[string]any)["$ref"])
+
+		def := root["$defs"].(map[string]any)["category"].(map[string]any)
+		is.Equal(t, false, def["additionalProperties"])
+	})
+
+	t.Run("sends required properties, and strict tools when asked to", func(t *testing.T) {
+		type searchArgs struct {
+			Query string `json:"query"`
+			Limit int    `json:"limit,omitempty"`
+		}
+		tool := gai.Tool{Name: "search", Schema: gai.GenerateToolSchema[searchArgs]()}
+
+		for _, strict := range []bool{false, true} {
+			var body map[string]any
+			srv := newChatCompletionsServer(t, nil, &body)
+
+			c := openai.NewClient(openai.NewClientOptions{Key: "secret", Profile: openai.Profile{BaseURL: srv.URL}})
+			cc := c.NewChatCompleter(openai.NewChatCompleterOptions{Model: openai.ChatCompleteModelGPT5Nano, StrictTools: strict})
+
+			requireChatCompletes(t, cc, gai.ChatCompleteRequest{
+				Messages: []gai.Message{gai.NewUserTextMessage("Hi!")},
+				Tools:    []gai.Tool{tool},
+			})
+
+			function := body["tools"].([]any)[0].(map[string]any)["function"].(map[string]any)
+			parameters := function["parameters"].(map[string]any)
+			is.Equal(t, "object", parameters["type"])
+			is.Equal(t, false, parameters["additionalProperties"])
+			limit := parameters["properties"].(map[string]any)["limit"].(map[string]any)
+
+			if !strict {
+				_, ok := function["strict"]
+				is.True(t, !ok)
+				is.Equal(t, 1, len(parameters["required"].([]any)))
+				is.Equal(t, "integer", limit["type"])
+				continue
+			}
+
+			is.Equal(t, true, function["strict"])
+			is.Equal(t, 2, len(parameters["required"].([]any)))
+			is.Equal(t, 2, len(limit["type"].([]any)))
+		}
+	})
+}
+
+type category struct {
+	Name   string    `json:"name"`
+	Kind   string    `json:"kind"`
+	Parent *category `json:"parent" jsonschema:"nullable"`
+}
+
+// requireValidationError fails the test unless err is a [gai.ValidationError] for the given field and message.
+func requireValidationError(t *testing.T, err error, field, message string) {
+	t.Helper()
+	var validationErr *gai.ValidationError
+	is.True(t, errors.As(err, &validationErr), "expected a validation error")
+	is.Equal(t, field, validationErr.Field)
+	is.Equal(t, message, validationErr.Err.Error())
 }
 
 // drainParts iterates the response stream, returning the first error if any.
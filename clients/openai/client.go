@@ -2,10 +2,15 @@
 // backed by the OpenAI API and OpenAI-compatible endpoints. Construct a [Client]
 // with [NewClient], then derive a chat completer or embedder via
 // [Client.NewChatCompleter] or [Client.NewEmbedder].
+//
+// To target Azure OpenAI or another OpenAI-compatible endpoint, pass a [Profile] in
+// [NewClientOptions]. It carries the base URL, extra headers and query parameters, and
+// toggles for request features the endpoint does not accept.
 package openai
 
 import (
 	"log/slog"
+	"maps"
 	"strings"
 
 	"github.com/openai/openai-go/v3"
@@ -13,14 +18,21 @@ import (
 )
 
 type Client struct {
-	Client openai.Client
-	log    *slog.Logger
+	Client  openai.Client
+	log     *slog.Logger
+	profile Profile
 }
 
 type NewClientOptions struct {
 	BaseURL string
+	// Headers are sent with every request, merged on top of the profile's.
+	Headers map[string]string
 	Key     string
 	Log     *slog.Logger
+	// Profile describes the endpoint. The zero value is the OpenAI API.
+	Profile Profile
+	// Query parameters are added to every request URL, merged on top of the profile's.
+	Query map[string]string
 }
 
 func NewClient(opts NewClientOptions) *Client {
@@ -28,6 +40,10 @@ func NewClient(opts NewClientOptions) *Client {
 		opts.Log = slog.New(slog.DiscardHandler)
 	}
 
+	if opts.BaseURL == "" {
+		opts.BaseURL = opts.Profile.BaseURL
+	}
+
 	var clientOpts []option.RequestOption
 
 	if opts.BaseURL != "" {
@@ -38,11 +54,41 @@ func NewClient(opts NewClientOptions) *Client {
 	}
 
 	if opts.Key != "" {
-		clientOpts = append(clientOpts, option.WithAPIKey(opts.Key))
+		if opts.Profile.KeyHeader != "" {
+			clientOpts = append(clientOpts,
+				option.WithHeader(opts.Profile.KeyHeader, opts.Key),
+				option.WithHeaderDel("Authorization"),
+			)
+		} else {
+			clientOpts = append(clientOpts, option.WithAPIKey(opts.Key))
+		}
+	}
+
+	headers := maps.Clone(opts.Profile.Headers)
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	maps.Copy(headers, opts.Headers)
+	for k, v := range headers {
+		clientOpts = append(clientOpts, option.WithHeader(k, v))
+	}
+
+	query := maps.Clone(opts.Profile.Query)
+	if query == nil {
+		query = map[string]string{}
+	}
+	maps.Copy(query, opts.Query)
+	for k, v := range query {
+		clientOpts = append(clientOpts, option.WithQuery(k, v))
+	}
+
+	if opts.Profile.Deployments {
+		clientOpts = append(clientOpts, option.WithMiddleware(deploymentMiddleware))
 	}
 
 	return &Client{
-		Client: openai.NewClient(clientOpts...),
-		log:    opts.Log,
+		Client:  openai.NewClient(clientOpts...),
+		log:     opts.Log,
+		profile: opts.Profile,
 	}
 }
@@ -1,8 +1,10 @@
 package openai
 
 import (
+	"cmp"
 	"context"
 	"log/slog"
+	"slices"
 
 	"github.com/openai/openai-go/v3"
 	"go.opentelemetry.io/otel"
@@ -60,6 +62,7 @@ func (c *Client) NewEmbedder(opts NewEmbedderOptions) *Embedder {
 }
 
 // Embed satisfies [gai.Embedder].
+// Only text can be embedded, either a single part, or several parts with [gai.EmbedRequest.Separate].
 func (e *Embedder) Embed(ctx context.Context, req gai.EmbedRequest) (gai.EmbedResponse[float64], error) {
 	ctx, span := e.tracer.Start(ctx, "openai.embed",
 		trace.WithSpanKind(trace.SpanKindClient),
@@ -71,17 +74,38 @@ func (e *Embedder) Embed(ctx context.Context, req gai.EmbedRequest) (gai.EmbedRe
 	defer span.End()
 
 	if len(req.Parts) == 0 {
-		panic("no parts")
+		err := gai.NewValidationError("Parts", "no parts")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request")
+		return gai.EmbedResponse[float64]{}, err
+	}
+
+	var texts []string
+	var inputLength int
+	for _, part := range req.Parts {
+		if part.Type == gai.PartTypeText {
+			texts = append(texts, part.Text())
+			inputLength += len(part.Text())
+		}
+	}
+	if len(texts) != len(req.Parts) || (!req.Separate && len(texts) != 1) {
+		message := "OpenAI embeddings only support a single text part"
+		if req.Separate {
+			message = "OpenAI embeddings only support text parts"
+		}
+		err := gai.NewValidationError("Parts", message)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request")
+		return gai.EmbedResponse[float64]{}, err
 	}
-	if len(req.Parts) != 1 || req.Parts[0].Type != gai.PartTypeText {
-		panic("OpenAI embeddings only support a single text part")
+	if req.Separate {
+		span.SetAttributes(attribute.Bool("ai.embed_separate", true))
 	}
 
-	v := req.Parts[0].Text()
-	span.SetAttributes(attribute.Int("ai.input_length", len(v)))
+	span.SetAttributes(attribute.Int("ai.input_length", inputLength))
 
 	res, err := e.Client.Embeddings.New(ctx, openai.EmbeddingNewParams{
-		Input:          openai.EmbeddingNewParamsInputUnion{OfString: openai.Opt(v)},
+		Input:          openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: texts},
 		Model:          openai.EmbeddingModel(e.model),
 		EncodingFormat: openai.EmbeddingNewParamsEncodingFormatFloat,
 		Dimensions:     openai.Opt(int64(e.dimensions)),
@@ -97,6 +121,16 @@ func (e *Embedder) Embed(ctx context.Context, req gai.EmbedRequest) (gai.EmbedRe
 		span.SetStatus(codes.Error, "no embeddings in response")
 		return gai.EmbedResponse[float64]{}, err
 	}
+	if len(res.Data) != len(texts) {
+		err := errors.Newf("expected %v embeddings, but got %v", len(texts), len(res.Data))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "wrong number of embeddings in response")
+		return gai.EmbedResponse[float64]{}, err
+	}
+	// The embeddings may not be in the order of the inputs
+	slices.SortFunc(res.Data, func(a, b openai.Embedding) int {
+		return cmp.Compare(a.Index, b.Index)
+	})
 
 	// Record token usage if available
 	if res.Usage.PromptTokens > 0 {
@@ -106,6 +140,14 @@ func (e *Embedder) Embed(ctx context.Context, req gai.EmbedRequest) (gai.EmbedRe
 		)
 	}
 
+	if req.Separate {
+		var embeddings [][]float64
+		for _, d := range res.Data {
+			embeddings = append(embeddings, d.Embedding)
+		}
+		return gai.EmbedResponse[float64]{Embeddings: embeddings}, nil
+	}
+
 	return gai.EmbedResponse[float64]{
 		Embedding: res.Data[0].Embedding,
 	}, nil
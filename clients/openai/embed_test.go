@@ -26,7 +26,7 @@ func TestEmbedder_Embed(t *testing.T) {
 		is.Equal(t, 1536, len(res.Embedding))
 	})
 
-	t.Run("panics with no parts", func(t *testing.T) {
+	t.Run("returns a validation error with no parts", func(t *testing.T) {
 		c := newClient(t)
 
 		e := c.NewEmbedder(openai.NewEmbedderOptions{
@@ -34,15 +34,11 @@ func TestEmbedder_Embed(t *testing.T) {
 			Dimensions: 1536,
 		})
 
-		defer func() {
-			r := recover()
-			is.Equal(t, "no parts", r)
-		}()
-
-		_, _ = e.Embed(t.Context(), gai.EmbedRequest{})
+		_, err := e.Embed(t.Context(), gai.EmbedRequest{})
+		requireValidationError(t, err, "Parts", "no parts")
 	})
 
-	t.Run("panics with a non-text part", func(t *testing.T) {
+	t.Run("returns a validation error with a non-text part", func(t *testing.T) {
 		c := newClient(t)
 
 		e := c.NewEmbedder(openai.NewEmbedderOptions{
@@ -50,17 +46,13 @@ func TestEmbedder_Embed(t *testing.T) {
 			Dimensions: 1536,
 		})
 
-		defer func() {
-			r := recover()
-			is.Equal(t, "OpenAI embeddings only support a single text part", r)
-		}()
-
-		_, _ = e.Embed(t.Context(), gai.EmbedRequest{
+		_, err := e.Embed(t.Context(), gai.EmbedRequest{
 			Parts: []gai.Part{gai.DataPart("image/jpeg", []byte("not an image"))},
 		})
+		requireValidationError(t, err, "Parts", "OpenAI embeddings only support a single text part")
 	})
 
-	t.Run("panics with multiple parts", func(t *testing.T) {
+	t.Run("returns a validation error with multiple parts", func(t *testing.T) {
 		c := newClient(t)
 
 		e := c.NewEmbedder(openai.NewEmbedderOptions{
@@ -68,14 +60,29 @@ func TestEmbedder_Embed(t *testing.T) {
 			Dimensions: 1536,
 		})
 
-		defer func() {
-			r := recover()
-			is.Equal(t, "OpenAI embeddings only support a single text part", r)
-		}()
-
-		_, _ = e.Embed(t.Context(), gai.EmbedRequest{
+		_, err := e.Embed(t.Context(), gai.EmbedRequest{
 			Parts: []gai.Part{gai.TextPart("one"), gai.TextPart("two")},
 		})
+		requireValidationError(t, err, "Parts", "OpenAI embeddings only support a single text part")
+	})
+
+	t.Run("can embed several texts separately", func(t *testing.T) {
+		c := newClient(t)
+
+		e := c.NewEmbedder(openai.NewEmbedderOptions{
+			Model:      openai.EmbedModelTextEmbedding3Small,
+			Dimensions: 1536,
+		})
+
+		res, err := e.Embed(t.Context(), gai.EmbedRequest{
+			Parts:    []gai.Part{gai.TextPart("one"), gai.TextPart("two")},
+			Separate: true,
+		})
+		is.NotError(t, err)
+
+		is.Equal(t, 0, len(res.Embedding))
+		is.Equal(t, 2, len(res.Embeddings))
+		is.Equal(t, 1536, len(res.Embeddings[1]))
 	})
 
 	t.Run("records standard attributes on the embed span", func(t *testing.T) {
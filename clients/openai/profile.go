@@ -0,0 +1,129 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/openai/openai-go/v3/option"
+)
+
+// Profile describes how to reach an OpenAI-compatible endpoint and which request features it
+// accepts. The zero value is the OpenAI API itself. Pass one in [NewClientOptions.Profile],
+// either a named profile like [ProfileMistral], one from [AzureProfile], or your own.
+type Profile struct {
+	// Name identifies the profile in logs, such as "mistral".
+	Name string
+	// BaseURL is the endpoint's base URL. [NewClientOptions.BaseURL] takes precedence.
+	BaseURL string
+	// Headers are sent with every request. [NewClientOptions.Headers] are merged on top.
+	Headers map[string]string
+	// Query parameters are added to every request URL. [NewClientOptions.Query] are merged on top.
+	Query map[string]string
+	// KeyHeader is the header that carries the key, such as "api-key" for Azure OpenAI.
+	// If empty, the key is sent as a bearer token in the Authorization header.
+	KeyHeader string
+	// Deployments routes chat-completion and embedding requests to
+	// deployments/{model}/..., the way Azure OpenAI addresses a deployment by name.
+	// The model passed to [Client.NewChatCompleter] or [Client.NewEmbedder] is the deployment name.
+	Deployments bool
+	// NoReasoningEffort leaves out reasoning_effort for endpoints that reject it. A request's
+	// [gai.ThinkingLevel] is still validated, but not sent.
+	NoReasoningEffort bool
+	// NoStreamUsage leaves out stream_options for endpoints that reject it. Usage is still read
+	// from the stream if the endpoint sends it anyway.
+	NoStreamUsage bool
+}
+
+var (
+	// ProfileGroq targets Groq's OpenAI-compatible API.
+	ProfileGroq = Profile{
+		Name:    "groq",
+		BaseURL: "https://api.groq.com/openai/v1/",
+	}
+
+	// ProfileMistral targets Mistral's OpenAI-compatible chat-completions API, which rejects
+	// unknown fields such as reasoning_effort and stream_options.
+	ProfileMistral = Profile{
+		Name:              "mistral",
+		BaseURL:           "https://api.mistral.ai/v1/",
+		NoReasoningEffort: true,
+		NoStreamUsage:     true,
+	}
+
+	// ProfileOpenRouter targets OpenRouter. Set the optional HTTP-Referer and X-Title headers
+	// that attribute requests to your app with [NewClientOptions.Headers].
+	ProfileOpenRouter = Profile{
+		Name:    "openrouter",
+		BaseURL: "https://openrouter.ai/api/v1/",
+	}
+
+	// ProfileTogether targets Together AI's OpenAI-compatible API, which has no reasoning_effort.
+	ProfileTogether = Profile{
+		Name:              "together",
+		BaseURL:           "https://api.together.xyz/v1/",
+		NoReasoningEffort: true,
+	}
+)
+
+// AzureProfile returns a [Profile] for an Azure OpenAI resource at endpoint, such as
+// "https://my-resource.openai.azure.com". The key is sent in the api-key header.
+//
+// If apiVersion is empty, the profile targets the versionless v1 API at /openai/v1/, where models
+// are named by deployment like any other model. Otherwise it targets the deployment-based API,
+// sending apiVersion as the api-version query parameter, and the model is the deployment name.
+func AzureProfile(endpoint, apiVersion string) Profile {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+
+	if apiVersion == "" {
+		return Profile{
+			Name:      "azure",
+			BaseURL:   endpoint + "/openai/v1/",
+			KeyHeader: "api-key",
+		}
+	}
+
+	return Profile{
+		Name:        "azure",
+		BaseURL:     endpoint + "/openai/",
+		Query:       map[string]string{"api-version": apiVersion},
+		KeyHeader:   "api-key",
+		Deployments: true,
+	}
+}
+
+// deploymentRoutes are the request paths that [Profile.Deployments] rewrites, by suffix.
+var deploymentRoutes = []string{"/chat/completions", "/embeddings"}
+
+// deploymentMiddleware rewrites requests on deployment routes from .../{route} to
+// .../deployments/{model}/{route}, reading the model from the JSON request body.
+func deploymentMiddleware(r *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+	for _, route := range deploymentRoutes {
+		if !strings.HasSuffix(r.URL.Path, route) || r.Body == nil {
+			continue
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		var v struct {
+			Model string `json:"model"`
+		}
+		if err := json.Unmarshal(body, &v); err != nil {
+			return nil, err
+		}
+
+		prefix := strings.TrimSuffix(r.URL.Path, route)
+		r.URL.Path = prefix + "/deployments/" + v.Model + route
+		r.URL.RawPath = prefix + "/deployments/" + url.PathEscape(v.Model) + route
+		break
+	}
+
+	return next(r)
+}
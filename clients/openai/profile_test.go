@@ -0,0 +1,153 @@
+package openai_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/clients/openai"
+)
+
+func TestProfile(t *testing.T) {
+	t.Run("routes Azure requests to deployments with api-version and api-key", func(t *testing.T) {
+		var got *http.Request
+		srv := newChatCompletionsServer(t, &got, nil)
+
+		c := openai.NewClient(openai.NewClientOptions{
+			Key:     "secret",
+			Profile: openai.AzureProfile(srv.URL+"/", "2024-10-21"),
+		})
+		cc := c.NewChatCompleter(openai.NewChatCompleterOptions{Model: "my-gpt"})
+
+		requireChatCompletes(t, cc, gai.ChatCompleteRequest{Messages: []gai.Message{gai.NewUserTextMessage("Hi!")}})
+
+		is.Equal(t, "/openai/deployments/my-gpt/chat/completions", got.URL.Path)
+		is.Equal(t, "2024-10-21", got.URL.Query().Get("api-version"))
+		is.Equal(t, "secret", got.Header.Get("api-key"))
+		is.Equal(t, "", got.Header.Get("Authorization"))
+	})
+
+	t.Run("uses the Azure v1 API without an api version", func(t *testing.T) {
+		var got *http.Request
+		srv := newChatCompletionsServer(t, &got, nil)
+
+		c := openai.NewClient(openai.NewClientOptions{
+			Key:     "secret",
+			Profile: openai.AzureProfile(srv.URL, ""),
+		})
+		cc := c.NewChatCompleter(openai.NewChatCompleterOptions{Model: "my-gpt"})
+
+		requireChatCompletes(t, cc, gai.ChatCompleteRequest{Messages: []gai.Message{gai.NewUserTextMessage("Hi!")}})
+
+		is.Equal(t, "/openai/v1/chat/completions", got.URL.Path)
+		is.Equal(t, "", got.URL.Query().Get("api-version"))
+		is.Equal(t, "secret", got.Header.Get("api-key"))
+	})
+
+	t.Run("merges custom headers and query parameters over the profile's", func(t *testing.T) {
+		var got *http.Request
+		srv := newChatCompletionsServer(t, &got, nil)
+
+		c := openai.NewClient(openai.NewClientOptions{
+			BaseURL: srv.URL,
+			Key:     "secret",
+			Profile: openai.Profile{
+				Headers: map[string]string{"X-Title": "Profile", "X-Team": "ai"},
+				Query:   map[string]string{"region": "eu"},
+			},
+			Headers: map[string]string{"X-Title": "My App"},
+			Query:   map[string]string{"tier": "free"},
+		})
+		cc := c.NewChatCompleter(openai.NewChatCompleterOptions{Model: openai.ChatCompleteModelGPT5Nano})
+
+		requireChatCompletes(t, cc, gai.ChatCompleteRequest{Messages: []gai.Message{gai.NewUserTextMessage("Hi!")}})
+
+		is.Equal(t, "/chat/completions", got.URL.Path)
+		is.Equal(t, "My App", got.Header.Get("X-Title"))
+		is.Equal(t, "ai", got.Header.Get("X-Team"))
+		is.Equal(t, "eu", got.URL.Query().Get("region"))
+		is.Equal(t, "free", got.URL.Query().Get("tier"))
+		is.Equal(t, "Bearer secret", got.Header.Get("Authorization"))
+	})
+
+	t.Run("leaves out reasoning_effort and stream_options when the profile disables them", func(t *testing.T) {
+		var body map[string]any
+		srv := newChatCompletionsServer(t, nil, &body)
+
+		profile := openai.ProfileMistral
+		profile.BaseURL = srv.URL
+		c := openai.NewClient(openai.NewClientOptions{Key: "secret", Profile: profile})
+		cc := c.NewChatCompleter(openai.NewChatCompleterOptions{Model: "mistral-small-latest"})
+
+		requireChatCompletes(t, cc, gai.ChatCompleteRequest{
+			Messages:      []gai.Message{gai.NewUserTextMessage("Hi!")},
+			ThinkingLevel: gai.Ptr(openai.ThinkingLevelLow),
+		})
+
+		_, ok := body["reasoning_effort"]
+		is.True(t, !ok)
+		_, ok = body["stream_options"]
+		is.True(t, !ok)
+	})
+
+	t.Run("still rejects unsupported thinking levels when reasoning_effort is disabled", func(t *testing.T) {
+		c := openai.NewClient(openai.NewClientOptions{Key: "secret", Profile: openai.ProfileMistral})
+		cc := c.NewChatCompleter(openai.NewChatCompleterOptions{Model: "mistral-small-latest"})
+
+		_, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages:      []gai.Message{gai.NewUserTextMessage("Hi!")},
+			ThinkingLevel: gai.Ptr(gai.ThinkingLevel("ultra")),
+		})
+		requireValidationError(t, err, "ThinkingLevel", "unsupported thinking level: ultra")
+	})
+}
+
+// newChatCompletionsServer starts a stand-in for a chat-completions endpoint that records the
+// request and its decoded JSON body, and streams a single text chunk.
+func newChatCompletionsServer(t *testing.T, req **http.Request, body *map[string]any) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if req != nil {
+			*req = r
+		}
+		if body != nil {
+			if err := json.Unmarshal(data, body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, `data: {"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"Hi!"},"finish_reason":"stop"}]}`+"\n\n")
+		_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func requireChatCompletes(t *testing.T, cc gai.ChatCompleter, req gai.ChatCompleteRequest) {
+	t.Helper()
+
+	res, err := cc.ChatComplete(t.Context(), req)
+	is.NotError(t, err)
+
+	var output string
+	for part, err := range res.Parts() {
+		is.NotError(t, err)
+		output += part.Text()
+	}
+	is.Equal(t, "Hi!", output)
+}
@@ -0,0 +1,492 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/responses"
+	"github.com/openai/openai-go/v3/shared"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"maragu.dev/gai"
+)
+
+// ResponsesChatCompleter is a [gai.ChatCompleter] backed by the OpenAI Responses API.
+//
+// Unlike [ChatCompleter], it streams reasoning summaries as [gai.PartTypeThought] parts, can
+// offer OpenAI's built-in tools (web search, file search, code interpreter, ...) alongside
+// [gai.Tool]s, and carries reasoning between turns.
+//
+// Requests are sent with store=false, so OpenAI keeps no conversation state. Instead, each
+// reasoning item the model produces is yielded after its summary as a thought part with empty
+// text and a signature (see [gai.ThoughtPartWithSignature]) holding the item with its encrypted
+// reasoning content. Keep the parts in the conversation history and the completer sends the
+// reasoning back on the next turn. Thought parts without a signature are dropped on input.
+//
+// Reasoning summaries and content are only requested from reasoning models (gpt-5 and the o-series),
+// or for requests with a [gai.ChatCompleteRequest.ThinkingLevel] other than [gai.ThinkingLevelNone],
+// so models like gpt-4.1 and Azure deployments with other names work without reasoning.
+type ResponsesChatCompleter struct {
+	Client       openai.Client
+	builtInTools []responses.ToolUnionParam
+	log          *slog.Logger
+	model        ChatCompleteModel
+	profile      Profile
+	strictTools  bool
+	tracer       trace.Tracer
+}
+
+type NewResponsesChatCompleterOptions struct {
+	Model ChatCompleteModel
+	// BuiltInTools are OpenAI-hosted tools offered to the model on every request, in addition to
+	// the request's [gai.Tool]s. The model runs them server-side; their calls are not yielded as parts.
+	BuiltInTools []responses.ToolUnionParam
+	// StrictTools turns on strict mode for the request's tools, like in [NewChatCompleterOptions].
+	StrictTools bool
+}
+
+func (c *Client) NewResponsesChatCompleter(opts NewResponsesChatCompleterOptions) *ResponsesChatCompleter {
+	return &ResponsesChatCompleter{
+		Client:       c.Client,
+		builtInTools: opts.BuiltInTools,
+		log:          c.log,
+		model:        opts.Model,
+		profile:      c.profile,
+		strictTools:  opts.StrictTools,
+		tracer:       otel.Tracer("maragu.dev/gai/clients/openai"),
+	}
+}
+
+// ChatComplete satisfies [gai.ChatCompleter].
+func (c *ResponsesChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRequest) (gai.ChatCompleteResponse, error) {
+	ctx, span := c.tracer.Start(ctx, "openai.responses_chat_complete",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("ai.model", string(c.model)),
+			attribute.Int("ai.message_count", len(req.Messages)),
+		),
+	)
+
+	if err := req.ToolChoice.Validate(req.Tools); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid tool choice")
+		span.End()
+		return gai.ChatCompleteResponse{}, err
+	}
+
+	// invalid records a [gai.ValidationError] on the span and ends it, for caller data we cannot send.
+	invalid := func(err *gai.ValidationError) (gai.ChatCompleteResponse, error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request")
+		span.End()
+		return gai.ChatCompleteResponse{}, err
+	}
+
+	var input responses.ResponseInputParam
+
+	for i, m := range req.Messages {
+		switch m.Role {
+		case gai.MessageRoleUser:
+			var content responses.ResponseInputMessageContentListParam
+
+			// flush appends the accumulated content as a user message, so that tool results
+			// interleaved with content keep their order.
+			flush := func() {
+				if len(content) > 0 {
+					input = append(input, responses.ResponseInputItemUnionParam{
+						OfMessage: &responses.EasyInputMessageParam{
+							Role:    responses.EasyInputMessageRoleUser,
+							Content: responses.EasyInputMessageContentUnionParam{OfInputItemContentList: content},
+						},
+					})
+				}
+				content = nil
+			}
+
+			for j, part := range m.Parts {
+				switch part.Type {
+				case gai.PartTypeText:
+					content = append(content, responses.ResponseInputContentUnionParam{
+						OfInputText: &responses.ResponseInputTextParam{Text: part.Text()},
+					})
+
+				case gai.PartTypeToolResult:
+					flush()
+
+					toolResult := part.ToolResult()
+					output := toolResult.Content
+					if toolResult.Err != nil {
+						output = fmt.Sprintf("Error: %s", toolResult.Err)
+					}
+					input = append(input, responses.ResponseInputItemUnionParam{
+						OfFunctionCallOutput: &responses.ResponseInputItemFunctionCallOutputParam{
+							CallID: toolResult.ID,
+							Output: responses.ResponseInputItemFunctionCallOutputOutputUnionParam{OfString: openai.String(output)},
+						},
+					})
+
+				case gai.PartTypeData:
+					if part.MIMEType == "" {
+						return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].MIMEType", i, j), "data part has empty MIME type"))
+					}
+					if len(part.Data) == 0 {
+						return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].Data", i, j), "data part has empty data"))
+					}
+					dataURI := "data:" + part.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(part.Data)
+
+					switch {
+					case strings.HasPrefix(part.MIMEType, "image/"):
+						content = append(content, responses.ResponseInputContentUnionParam{
+							OfInputImage: &responses.ResponseInputImageParam{
+								ImageURL: openai.String(dataURI),
+								Detail:   responses.ResponseInputImageDetailAuto,
+							},
+						})
+
+					case part.MIMEType == "application/pdf":
+						content = append(content, responses.ResponseInputContentUnionParam{
+							OfInputFile: &responses.ResponseInputFileParam{
+								FileData: openai.String(dataURI),
+								Filename: openai.String(fmt.Sprintf("file-%v-%v.pdf", i, j)),
+							},
+						})
+
+					default:
+						return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].MIMEType", i, j), "unsupported MIME type for OpenAI Responses: "+part.MIMEType))
+					}
+
+				case gai.PartTypeThought:
+					// Reasoning only comes from the model, so there is nothing to send back
+					// from a user message.
+					continue
+
+				default:
+					return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].Type", i, j), "unknown part type "+string(part.Type)))
+				}
+			}
+
+			flush()
+
+		case gai.MessageRoleModel:
+			var text strings.Builder
+
+			// flush appends the accumulated text as an assistant message, so that it keeps its
+			// place relative to reasoning items and tool calls.
+			flush := func() {
+				if text.Len() > 0 {
+					input = append(input, responses.ResponseInputItemUnionParam{
+						OfMessage: &responses.EasyInputMessageParam{
+							Role:    responses.EasyInputMessageRoleAssistant,
+							Content: responses.EasyInputMessageContentUnionParam{OfString: openai.String(text.String())},
+						},
+					})
+				}
+				text.Reset()
+			}
+
+			for j, part := range m.Parts {
+				switch part.Type {
+				case gai.PartTypeText:
+					text.WriteString(part.Text())
+
+				case gai.PartTypeToolCall:
+					flush()
+
+					toolCall := part.ToolCall()
+					input = append(input, responses.ResponseInputItemUnionParam{
+						OfFunctionCall: &responses.ResponseFunctionToolCallParam{
+							CallID:    toolCall.ID,
+							Name:      toolCall.Name,
+							Arguments: string(toolCall.Args),
+						},
+					})
+
+				case gai.PartTypeThought:
+					// Streamed summary text is for display only. The reasoning itself travels in
+					// the signature of the part yielded when the reasoning item finished.
+					signature := part.ThoughtSignature()
+					if len(signature) == 0 {
+						continue
+					}
+
+					var item responses.ResponseReasoningItem
+					if err := json.Unmarshal(signature, &item); err != nil || item.ID == "" {
+						return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].ThoughtSignature", i, j), "not an OpenAI reasoning item"))
+					}
+
+					flush()
+
+					reasoning := item.ToParam()
+					input = append(input, responses.ResponseInputItemUnionParam{OfReasoning: &reasoning})
+
+				default:
+					return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Parts[%v].Type", i, j), "unknown part type "+string(part.Type)))
+				}
+			}
+
+			flush()
+
+		default:
+			return invalid(gai.NewValidationError(fmt.Sprintf("Messages[%v].Role", i), "unknown role "+string(m.Role)))
+		}
+	}
+
+	tools := append([]responses.ToolUnionParam{}, c.builtInTools...)
+	var toolNames []string
+	for _, tool := range req.Tools {
+		tools = append(tools, responses.ToolUnionParam{
+			OfFunction: &responses.FunctionToolParam{
+				Name:        tool.Name,
+				Description: openai.String(tool.Description),
+				Parameters:  toolParameters(tool.Schema, c.strictTools),
+				Strict:      openai.Bool(c.strictTools),
+			},
+		})
+		toolNames = append(toolNames, tool.Name)
+	}
+	sort.Strings(toolNames)
+	span.SetAttributes(
+		attribute.Int("ai.tool_count", len(tools)),
+		attribute.StringSlice("ai.tools", toolNames),
+	)
+
+	params := responses.ResponseNewParams{
+		Input: responses.ResponseNewParamsInputUnion{OfInputItemList: input},
+		Model: string(c.model),
+		Tools: tools,
+		Store: openai.Bool(false),
+	}
+
+	// Non-reasoning models reject the reasoning parameters, so only ask for summaries and encrypted
+	// reasoning from reasoning models, or when the request asks for thinking.
+	if isReasoningModel(c.model) || (req.ThinkingLevel != nil && *req.ThinkingLevel != gai.ThinkingLevelNone) {
+		params.Include = []responses.ResponseIncludable{responses.ResponseIncludableReasoningEncryptedContent}
+		params.Reasoning = shared.ReasoningParam{
+			Summary: shared.ReasoningSummaryAuto,
+		}
+	}
+
+	if req.System != nil {
+		params.Instructions = openai.String(*req.System)
+		span.SetAttributes(attribute.Bool("ai.has_system_prompt", true))
+	}
+
+	switch req.ToolChoice.Mode {
+	case gai.ToolChoiceModeAny:
+		params.ToolChoice = responses.ResponseNewParamsToolChoiceUnion{
+			OfToolChoiceMode: openai.Opt(responses.ToolChoiceOptionsRequired),
+		}
+		span.SetAttributes(attribute.String("ai.tool_choice", string(req.ToolChoice.Mode)))
+	case gai.ToolChoiceModeTool:
+		params.ToolChoice = responses.ResponseNewParamsToolChoiceUnion{
+			OfFunctionTool: &responses.ToolChoiceFunctionParam{Name: req.ToolChoice.Name},
+		}
+		span.SetAttributes(attribute.String("ai.tool_choice", string(req.ToolChoice.Mode)))
+	}
+
+	if req.Temperature != nil {
+		params.Temperature = openai.Opt(req.Temperature.Float64())
+		span.SetAttributes(attribute.Float64("ai.temperature", req.Temperature.Float64()))
+	}
+	if req.ThinkingLevel != nil {
+		switch *req.ThinkingLevel {
+		case gai.ThinkingLevelNone:
+			// No reasoning means no summary to ask for.
+			params.Reasoning = shared.ReasoningParam{Effort: shared.ReasoningEffortNone}
+		case ThinkingLevelMinimal:
+			params.Reasoning.Effort = shared.ReasoningEffortMinimal
+		case ThinkingLevelLow:
+			params.Reasoning.Effort = shared.ReasoningEffortLow
+		case ThinkingLevelMedium:
+			params.Reasoning.Effort = shared.ReasoningEffortMedium
+		case ThinkingLevelHigh:
+			params.Reasoning.Effort = shared.ReasoningEffortHigh
+		case ThinkingLevelXHigh:
+			params.Reasoning.Effort = shared.ReasoningEffortXhigh
+		default:
+			return invalid(gai.NewValidationError("ThinkingLevel", "unsupported thinking level: "+string(*req.ThinkingLevel)))
+		}
+		if c.profile.NoReasoningEffort {
+			params.Reasoning.Effort = ""
+			c.log.Debug("Not sending thinking level", "profile", c.profile.Name, "thinking_level", *req.ThinkingLevel)
+		} else {
+			span.SetAttributes(attribute.String("ai.thinking_level", string(*req.ThinkingLevel)))
+		}
+	}
+
+	if req.ResponseSchema != nil {
+		normalized := normalizeToolSchema(req.ResponseSchema)
+		format := &responses.ResponseFormatTextJSONSchemaConfigParam{
+			Name:   responseSchemaName(req.ResponseSchema),
+			Strict: openai.Bool(true),
+			Schema: schemaToJSONObject(normalized, true),
+		}
+		if normalized.Description != "" {
+			format.Description = openai.String(normalized.Description)
+		}
+
+		params.Text = responses.ResponseTextConfigParam{
+			Format: responses.ResponseFormatTextConfigUnionParam{OfJSONSchema: format},
+		}
+
+		span.SetAttributes(attribute.Bool("ai.has_response_schema", true))
+	}
+
+	stream := c.Client.Responses.NewStreaming(ctx, params)
+
+	meta := &gai.ChatCompleteResponseMetadata{}
+	streamStart := time.Now()
+	var firstTokenRecorded bool
+	recordFirstToken := func() {
+		if firstTokenRecorded {
+			return
+		}
+		firstTokenRecorded = true
+		span.SetAttributes(attribute.Int64("ai.time_to_first_token_ms", time.Since(streamStart).Milliseconds()))
+	}
+
+	res := gai.NewChatCompleteResponse(func(yield func(gai.Part, error) bool) {
+		defer span.End()
+
+		defer func() {
+			if err := stream.Close(); err != nil {
+				c.log.Info("Error closing stream", "error", err)
+			}
+		}()
+
+		// fail records err on the span and yields it as the final part.
+		fail := func(err error, description string) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, description)
+			yield(gai.Part{}, err)
+		}
+
+		var hasToolCalls bool
+		for stream.Next() {
+			event := stream.Current()
+
+			switch event.Type {
+			case "response.output_text.delta":
+				recordFirstToken()
+				if !yield(gai.TextPart(event.Delta), nil) {
+					return
+				}
+
+			case "response.reasoning_summary_text.delta":
+				recordFirstToken()
+				if !yield(gai.ThoughtPart(event.Delta), nil) {
+					return
+				}
+
+			case "response.output_item.done":
+				switch event.Item.Type {
+				case "function_call":
+					recordFirstToken()
+					hasToolCalls = true
+					toolCall := event.Item.AsFunctionCall()
+					if !yield(gai.ToolCallPart(toolCall.CallID, toolCall.Name, json.RawMessage(toolCall.Arguments)), nil) {
+						return
+					}
+
+				case "reasoning":
+					reasoning := event.Item.AsReasoning()
+					if !yield(gai.ThoughtPartWithSignature("", []byte(reasoning.RawJSON())), nil) {
+						return
+					}
+				}
+
+			case "response.refusal.done":
+				meta.FinishReason = gai.Ptr(gai.ChatCompleteFinishReasonRefusal)
+				span.SetAttributes(attribute.String("ai.finish_reason", string(gai.ChatCompleteFinishReasonRefusal)))
+				fail(fmt.Errorf("refusal: %v", event.Refusal), "model refused request")
+				return
+
+			case "response.completed", "response.incomplete":
+				c.recordUsage(span, meta, event.Response.Usage)
+
+				mapped := mapResponseFinishReason(event.Response, hasToolCalls)
+				meta.FinishReason = gai.Ptr(mapped)
+				span.SetAttributes(attribute.String("ai.finish_reason", string(mapped)))
+
+			case "response.failed":
+				c.recordUsage(span, meta, event.Response.Usage)
+				fail(fmt.Errorf("response failed: %v: %v", event.Response.Error.Code, event.Response.Error.Message), "response failed")
+				return
+
+			case "error":
+				fail(fmt.Errorf("stream error: %v: %v", event.Code, event.Message), "stream error")
+				return
+			}
+		}
+
+		if err := stream.Err(); err != nil {
+			fail(err, "stream error")
+		}
+	})
+
+	res.Meta = meta
+
+	return res, nil
+}
+
+// recordUsage copies token usage from a finished response to the metadata and the span.
+func (c *ResponsesChatCompleter) recordUsage(span trace.Span, meta *gai.ChatCompleteResponseMetadata, usage responses.ResponseUsage) {
+	meta.Usage = gai.ChatCompleteResponseUsage{
+		PromptTokens:     int(usage.InputTokens),
+		ThoughtsTokens:   int(usage.OutputTokensDetails.ReasoningTokens),
+		CompletionTokens: int(usage.OutputTokens),
+	}
+	span.SetAttributes(
+		attribute.Int("ai.prompt_tokens", int(usage.InputTokens)),
+		attribute.Int("ai.thoughts_tokens", int(usage.OutputTokensDetails.ReasoningTokens)),
+		attribute.Int("ai.completion_tokens", int(usage.OutputTokens)),
+		attribute.Int("ai.total_tokens", int(usage.TotalTokens)),
+		attribute.Int("ai.cache_read_tokens", int(usage.InputTokensDetails.CachedTokens)),
+	)
+}
+
+// mapResponseFinishReason derives a finish reason from a finished response, which has a status
+// and incomplete details instead of a per-choice finish reason.
+func mapResponseFinishReason(response responses.Response, hasToolCalls bool) gai.ChatCompleteFinishReason {
+	switch response.Status {
+	case responses.ResponseStatusCompleted:
+		if hasToolCalls {
+			return gai.ChatCompleteFinishReasonToolCalls
+		}
+		return gai.ChatCompleteFinishReasonStop
+	case responses.ResponseStatusIncomplete:
+		switch response.IncompleteDetails.Reason {
+		case "max_output_tokens":
+			return gai.ChatCompleteFinishReasonLength
+		case "content_filter":
+			return gai.ChatCompleteFinishReasonContentFilter
+		}
+	}
+	return gai.ChatCompleteFinishReasonUnknown
+}
+
+var _ gai.ChatCompleter = (*ResponsesChatCompleter)(nil)
+
+// isReasoningModel reports whether the model is one of OpenAI's reasoning models, going by its name.
+// The chat variants of GPT-5, like gpt-5-chat-latest, are not reasoning models.
+func isReasoningModel(model ChatCompleteModel) bool {
+	if strings.Contains(string(model), "-chat") {
+		return false
+	}
+	for _, prefix := range []string{"gpt-5", "o1", "o3", "o4"} {
+		if strings.HasPrefix(string(model), prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,379 @@
+package openai_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/clients/openai"
+	"maragu.dev/gai/internal/oteltest"
+	"maragu.dev/gai/tools"
+)
+
+func TestResponsesChatCompleter_ChatComplete(t *testing.T) {
+	t.Run("streams reasoning summaries, text, and signed reasoning items", func(t *testing.T) {
+		srv := newResponsesServer(t,
+			`{"type":"response.reasoning_summary_text.delta","item_id":"rs_1","delta":"Thinking "}`,
+			`{"type":"response.reasoning_summary_text.delta","item_id":"rs_1","delta":"hard."}`,
+			`{"type":"response.output_item.done","item":{"type":"reasoning","id":"rs_1","summary":[{"type":"summary_text","text":"Thinking hard."}],"encrypted_content":"gAAA"}}`,
+			`{"type":"response.output_text.delta","delta":"Hello"}`,
+			`{"type":"response.output_text.delta","delta":" there!"}`,
+			`{"type":"response.completed","response":{"status":"completed","usage":{"input_tokens":10,"input_tokens_details":{"cached_tokens":2},"output_tokens":20,"output_tokens_details":{"reasoning_tokens":5},"total_tokens":30}}}`,
+		)
+		cc := newResponsesChatCompleter(t, srv.URL)
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("Hi!")},
+		})
+		is.NotError(t, err)
+
+		var thought, output string
+		var signature []byte
+		for part, err := range res.Parts() {
+			is.NotError(t, err)
+
+			switch part.Type {
+			case gai.PartTypeThought:
+				thought += part.Thought()
+				if s := part.ThoughtSignature(); s != nil {
+					signature = s
+				}
+			case gai.PartTypeText:
+				output += part.Text()
+			default:
+				t.Fatal("unexpected part type", part.Type)
+			}
+		}
+
+		is.Equal(t, "Thinking hard.", thought)
+		is.Equal(t, "Hello there!", output)
+		is.True(t, strings.Contains(string(signature), `"encrypted_content":"gAAA"`))
+
+		is.Equal(t, gai.ChatCompleteFinishReasonStop, *res.Meta.FinishReason)
+		is.Equal(t, 10, res.Meta.Usage.PromptTokens)
+		is.Equal(t, 20, res.Meta.Usage.CompletionTokens)
+		is.Equal(t, 5, res.Meta.Usage.ThoughtsTokens)
+	})
+
+	t.Run("sends reasoning items, tool calls, and tool results back in order", func(t *testing.T) {
+		srv := newResponsesServer(t,
+			`{"type":"response.output_text.delta","delta":"Done."}`,
+			`{"type":"response.completed","response":{"status":"completed"}}`,
+		)
+		cc := newResponsesChatCompleter(t, srv.URL)
+
+		signature := []byte(`{"type":"reasoning","id":"rs_1","summary":[],"encrypted_content":"gAAA"}`)
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			System: gai.Ptr("Be brief."),
+			Messages: []gai.Message{
+				gai.NewUserTextMessage("What is in readme.txt?"),
+				{
+					Role: gai.MessageRoleModel,
+					Parts: []gai.Part{
+						gai.ThoughtPart("Reading the file."),
+						gai.ThoughtPartWithSignature("", signature),
+						gai.ToolCallPart("call_1", "read_file", json.RawMessage(`{"path":"readme.txt"}`)),
+					},
+				},
+				gai.NewUserToolResultMessage(gai.ToolResult{ID: "call_1", Name: "read_file", Content: "Hi!"}),
+			},
+		})
+		is.NotError(t, err)
+		is.NotError(t, drainParts(t, res))
+
+		var body struct {
+			Instructions string           `json:"instructions"`
+			Store        bool             `json:"store"`
+			Include      []string         `json:"include"`
+			Input        []map[string]any `json:"input"`
+		}
+		is.NotError(t, json.Unmarshal(srv.body(), &body))
+
+		is.Equal(t, "Be brief.", body.Instructions)
+		is.True(t, !body.Store)
+		is.EqualSlice(t, []string{"reasoning.encrypted_content"}, body.Include)
+
+		is.Equal(t, 4, len(body.Input))
+		is.Equal(t, "user", body.Input[0]["role"])
+		is.Equal(t, "reasoning", body.Input[1]["type"])
+		is.Equal(t, "rs_1", body.Input[1]["id"])
+		is.Equal(t, "gAAA", body.Input[1]["encrypted_content"])
+		is.Equal(t, "function_call", body.Input[2]["type"])
+		is.Equal(t, "call_1", body.Input[2]["call_id"])
+		is.Equal(t, "function_call_output", body.Input[3]["type"])
+		is.Equal(t, "Hi!", body.Input[3]["output"])
+	})
+
+	t.Run("only asks non-reasoning models for reasoning when the request asks for thinking", func(t *testing.T) {
+		srv := newResponsesServer(t,
+			`{"type":"response.output_text.delta","delta":"Hi."}`,
+			`{"type":"response.completed","response":{"status":"completed"}}`,
+		)
+		c := openai.NewClient(openai.NewClientOptions{BaseURL: srv.URL, Key: "fake"})
+		cc := c.NewResponsesChatCompleter(openai.NewResponsesChatCompleterOptions{Model: "gpt-4.1"})
+
+		type body struct {
+			Include   []string       `json:"include"`
+			Reasoning map[string]any `json:"reasoning"`
+		}
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("Hi!")},
+		})
+		is.NotError(t, err)
+		is.NotError(t, drainParts(t, res))
+
+		var b body
+		is.NotError(t, json.Unmarshal(srv.body(), &b))
+		is.Equal(t, 0, len(b.Include))
+		is.Equal(t, 0, len(b.Reasoning))
+
+		res, err = cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages:      []gai.Message{gai.NewUserTextMessage("Hi!")},
+			ThinkingLevel: gai.Ptr(openai.ThinkingLevelLow),
+		})
+		is.NotError(t, err)
+		is.NotError(t, drainParts(t, res))
+
+		b = body{}
+		is.NotError(t, json.Unmarshal(srv.body(), &b))
+		is.EqualSlice(t, []string{"reasoning.encrypted_content"}, b.Include)
+		is.Equal(t, "auto", b.Reasoning["summary"])
+		is.Equal(t, "low", b.Reasoning["effort"])
+	})
+
+	t.Run("does not ask the chat variants of reasoning models for reasoning", func(t *testing.T) {
+		srv := newResponsesServer(t,
+			`{"type":"response.output_text.delta","delta":"Hi."}`,
+			`{"type":"response.completed","response":{"status":"completed"}}`,
+		)
+		c := openai.NewClient(openai.NewClientOptions{BaseURL: srv.URL, Key: "fake"})
+
+		for _, model := range []openai.ChatCompleteModel{"gpt-5-chat-latest", "gpt-5-chat"} {
+			cc := c.NewResponsesChatCompleter(openai.NewResponsesChatCompleterOptions{Model: model})
+
+			res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+				Messages: []gai.Message{gai.NewUserTextMessage("Hi!")},
+			})
+			is.NotError(t, err)
+			is.NotError(t, drainParts(t, res))
+
+			var b struct {
+				Include   []string       `json:"include"`
+				Reasoning map[string]any `json:"reasoning"`
+			}
+			is.NotError(t, json.Unmarshal(srv.body(), &b))
+			is.Equal(t, 0, len(b.Include))
+			is.Equal(t, 0, len(b.Reasoning))
+		}
+	})
+
+	t.Run("yields tool calls and reports the tool calls finish reason", func(t *testing.T) {
+		srv := newResponsesServer(t,
+			`{"type":"response.output_item.done","item":{"type":"function_call","id":"fc_1","call_id":"call_1","name":"read_file","arguments":"{\"path\":\"readme.txt\"}"}}`,
+			`{"type":"response.completed","response":{"status":"completed"}}`,
+		)
+		cc := newResponsesChatCompleter(t, srv.URL)
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages:   []gai.Message{gai.NewUserTextMessage("What is in readme.txt?")},
+			Tools:      []gai.Tool{tools.NewGetTime(nil)},
+			ToolChoice: gai.ToolChoice{Mode: gai.ToolChoiceModeAny},
+		})
+		is.NotError(t, err)
+
+		var toolCalls []gai.ToolCall
+		for part, err := range res.Parts() {
+			is.NotError(t, err)
+			toolCalls = append(toolCalls, part.ToolCall())
+		}
+
+		is.Equal(t, 1, len(toolCalls))
+		is.Equal(t, "call_1", toolCalls[0].ID)
+		is.Equal(t, "read_file", toolCalls[0].Name)
+		is.Equal(t, `{"path":"readme.txt"}`, string(toolCalls[0].Args))
+		is.Equal(t, gai.ChatCompleteFinishReasonToolCalls, *res.Meta.FinishReason)
+
+		var body struct {
+			ToolChoice string `json:"tool_choice"`
+			Tools      []struct {
+				Type string `json:"type"`
+				Name string `json:"name"`
+			} `json:"tools"`
+		}
+		is.NotError(t, json.Unmarshal(srv.body(), &body))
+		is.Equal(t, "required", body.ToolChoice)
+		is.Equal(t, 1, len(body.Tools))
+		is.Equal(t, "function", body.Tools[0].Type)
+	})
+
+	t.Run("reports the length finish reason for incomplete responses", func(t *testing.T) {
+		srv := newResponsesServer(t,
+			`{"type":"response.output_text.delta","delta":"Once upon"}`,
+			`{"type":"response.incomplete","response":{"status":"incomplete","incomplete_details":{"reason":"max_output_tokens"}}}`,
+		)
+		cc := newResponsesChatCompleter(t, srv.URL)
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("Tell me a story.")},
+		})
+		is.NotError(t, err)
+		is.NotError(t, drainParts(t, res))
+		is.Equal(t, gai.ChatCompleteFinishReasonLength, *res.Meta.FinishReason)
+	})
+
+	t.Run("returns an error on refusal", func(t *testing.T) {
+		srv := newResponsesServer(t,
+			`{"type":"response.refusal.done","refusal":"I can't help with that."}`,
+		)
+		cc := newResponsesChatCompleter(t, srv.URL)
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("Do something bad.")},
+		})
+		is.NotError(t, err)
+
+		err = drainParts(t, res)
+		is.True(t, err != nil)
+		is.Equal(t, "refusal: I can't help with that.", err.Error())
+		is.Equal(t, gai.ChatCompleteFinishReasonRefusal, *res.Meta.FinishReason)
+	})
+
+	t.Run("returns an error when the response fails", func(t *testing.T) {
+		srv := newResponsesServer(t,
+			`{"type":"response.failed","response":{"status":"failed","error":{"code":"server_error","message":"oops"}}}`,
+		)
+		cc := newResponsesChatCompleter(t, srv.URL)
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("Hi!")},
+		})
+		is.NotError(t, err)
+
+		err = drainParts(t, res)
+		is.True(t, err != nil)
+		is.Equal(t, "response failed: server_error: oops", err.Error())
+	})
+
+	t.Run("returns a validation error on unsupported MIME type", func(t *testing.T) {
+		cc := newResponsesChatCompleter(t, "http://localhost:0")
+
+		_, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserDataMessage("audio/wav", []byte("fake audio"))},
+		})
+		requireValidationError(t, err, "Messages[0].Parts[0].MIMEType", "unsupported MIME type for OpenAI Responses: audio/wav")
+	})
+
+	t.Run("returns a validation error on a foreign thought signature", func(t *testing.T) {
+		cc := newResponsesChatCompleter(t, "http://localhost:0")
+
+		_, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{
+				gai.NewUserTextMessage("Hi!"),
+				{Role: gai.MessageRoleModel, Parts: []gai.Part{gai.ThoughtPartWithSignature("", []byte("not json"))}},
+				gai.NewUserTextMessage("Hi again!"),
+			},
+		})
+		requireValidationError(t, err, "Messages[1].Parts[0].ThoughtSignature", "not an OpenAI reasoning item")
+	})
+
+	t.Run("returns a validation error on unsupported thinking level", func(t *testing.T) {
+		sr := oteltest.NewSpanRecorder(t)
+		cc := newResponsesChatCompleter(t, "http://localhost:0")
+
+		_, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages:      []gai.Message{gai.NewUserTextMessage("Hi!")},
+			ThinkingLevel: gai.Ptr(gai.ThinkingLevel("ultra")),
+		})
+		requireValidationError(t, err, "ThinkingLevel", "unsupported thinking level: ultra")
+
+		span := oteltest.FindSpan(t, sr.Ended(), "openai.responses_chat_complete")
+		is.Equal(t, codes.Error, span.Status().Code)
+		is.Equal(t, "invalid request", span.Status().Description)
+	})
+
+	t.Run("records standard attributes on the span", func(t *testing.T) {
+		sr := oteltest.NewSpanRecorder(t)
+		srv := newResponsesServer(t,
+			`{"type":"response.output_text.delta","delta":"Hi."}`,
+			`{"type":"response.completed","response":{"status":"completed","usage":{"input_tokens":3,"output_tokens":2,"total_tokens":5}}}`,
+		)
+		cc := newResponsesChatCompleter(t, srv.URL)
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			System:        gai.Ptr("Be brief."),
+			Messages:      []gai.Message{gai.NewUserTextMessage("Hi!")},
+			ThinkingLevel: gai.Ptr(openai.ThinkingLevelLow),
+		})
+		is.NotError(t, err)
+		is.NotError(t, drainParts(t, res))
+
+		span := oteltest.FindSpan(t, sr.Ended(), "openai.responses_chat_complete")
+		attrs := span.Attributes()
+		is.True(t, oteltest.HasAttribute(attrs, attribute.String("ai.model", string(openai.ChatCompleteModelGPT5Nano))))
+		is.True(t, oteltest.HasAttribute(attrs, attribute.Bool("ai.has_system_prompt", true)))
+		is.True(t, oteltest.HasAttribute(attrs, attribute.String("ai.thinking_level", "low")))
+		is.True(t, oteltest.HasAttribute(attrs, attribute.String("ai.finish_reason", "stop")))
+		is.True(t, oteltest.HasAttribute(attrs, attribute.Int("ai.total_tokens", 5)))
+	})
+}
+
+type responsesServer struct {
+	*httptest.Server
+	lastBody []byte
+}
+
+func (s *responsesServer) body() []byte {
+	return s.lastBody
+}
+
+// newResponsesServer starts a stand-in for the Responses API that records the request body and
+// streams the given events as server-sent events.
+func newResponsesServer(t *testing.T, events ...string) *responsesServer {
+	t.Helper()
+
+	s := &responsesServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/responses" {
+			http.NotFound(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.lastBody = body
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range events {
+			var e struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal([]byte(event), &e); err != nil {
+				panic(err)
+			}
+			_, _ = fmt.Fprintf(w, "event: %v\ndata: %v\n\n", e.Type, event)
+		}
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func newResponsesChatCompleter(t *testing.T, baseURL string) *openai.ResponsesChatCompleter {
+	t.Helper()
+
+	c := openai.NewClient(openai.NewClientOptions{BaseURL: baseURL, Key: "fake"})
+	return c.NewResponsesChatCompleter(openai.NewResponsesChatCompleterOptions{Model: openai.ChatCompleteModelGPT5Nano})
+}
@@ -4,9 +4,45 @@ import "context"
 
 // EmbedRequest for [Embedder].
 type EmbedRequest struct {
+	// Parts to embed. By default, all parts are embedded together into a single embedding in
+	// [EmbedResponse.Embedding], so text and media, like an image and its caption, can be fused into one vector
+	// by models that support it. Models that only embed text typically support only a single part.
 	Parts []Part
+	// Separate, if true, embeds each part on its own, into one embedding per part in [EmbedResponse.Embeddings],
+	// like a batch request. The task and title apply to every part.
+	Separate bool
+	// Task, if set, is what the embedding will be used for, which some models use to optimize the embedding.
+	// Clients map it to the provider's native task type or input format, and ignore it if the provider has neither.
+	Task EmbedTask
+	// Title of the document, for [EmbedTaskDocument]. Ignored for other tasks.
+	Title string
 }
 
+// EmbedTask is what an embedding will be used for. See [EmbedRequest.Task].
+//
+// Retrieval is asymmetric: queries use one of the query tasks, like [EmbedTaskSearchQuery], and the documents
+// they're matched against use [EmbedTaskDocument]. The other tasks are symmetric, and used for all inputs.
+type EmbedTask string
+
+const (
+	// EmbedTaskSearchQuery is for search queries matched against documents.
+	EmbedTaskSearchQuery = EmbedTask("search_query")
+	// EmbedTaskQuestionAnswering is for questions matched against documents with answers.
+	EmbedTaskQuestionAnswering = EmbedTask("question_answering")
+	// EmbedTaskFactChecking is for statements matched against documents that support or refute them.
+	EmbedTaskFactChecking = EmbedTask("fact_checking")
+	// EmbedTaskCodeRetrievalQuery is for natural-language queries matched against code.
+	EmbedTaskCodeRetrievalQuery = EmbedTask("code_retrieval_query")
+	// EmbedTaskDocument is for documents that queries are matched against, optionally with [EmbedRequest.Title].
+	EmbedTaskDocument = EmbedTask("document")
+	// EmbedTaskClassification is for classifying inputs according to preset labels.
+	EmbedTaskClassification = EmbedTask("classification")
+	// EmbedTaskClustering is for clustering inputs by their similarity.
+	EmbedTaskClustering = EmbedTask("clustering")
+	// EmbedTaskSemanticSimilarity is for assessing the similarity of inputs.
+	EmbedTaskSemanticSimilarity = EmbedTask("semantic_similarity")
+)
+
 // NewTextEmbedRequest is a convenience function to create an [EmbedRequest] with a single text part.
 func NewTextEmbedRequest(text string) EmbedRequest {
 	return EmbedRequest{
@@ -21,7 +57,10 @@ type VectorComponent interface {
 
 // EmbedResponse for [Embedder].
 type EmbedResponse[T VectorComponent] struct {
+	// Embedding of all parts together. Not set if [EmbedRequest.Separate] is true.
 	Embedding []T
+	// Embeddings of each part, in the order of the parts. Only set if [EmbedRequest.Separate] is true.
+	Embeddings [][]T
 }
 
 // Embedder is satisfied by models supporting embedding.
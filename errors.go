@@ -0,0 +1,35 @@
+package gai
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ValidationError is returned by clients when a request carries caller data the client cannot
+// send, such as no messages, a data part with an unsupported MIME type, or a [ThinkingLevel] the
+// client does not publish. The request is rejected before any network call, and retrying it
+// unchanged will fail the same way.
+//
+// Bad input is caller data rather than a programming error, so it is returned instead of
+// panicking, the same way [ToolChoice.Validate] reports problems. Match it with [errors.As].
+type ValidationError struct {
+	// Field is the offending request field, such as "Messages[2].Parts[0].MIMEType".
+	Field string
+	// Err describes what is wrong with the field.
+	Err error
+}
+
+// NewValidationError creates a [ValidationError] for the given field with a plain message.
+func NewValidationError(field, message string) *ValidationError {
+	return &ValidationError{Field: field, Err: errors.New(message)}
+}
+
+// Error satisfies [error].
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %v: %v", e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
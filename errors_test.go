@@ -0,0 +1,33 @@
+package gai_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+)
+
+func TestValidationError(t *testing.T) {
+	t.Run("includes the field and message in the error string", func(t *testing.T) {
+		err := gai.NewValidationError("Messages[0].Parts[1].MIMEType", "unsupported MIME type for OpenAI: video/mp4")
+		is.Equal(t, "invalid Messages[0].Parts[1].MIMEType: unsupported MIME type for OpenAI: video/mp4", err.Error())
+	})
+
+	t.Run("can be matched with errors.As when wrapped", func(t *testing.T) {
+		err := fmt.Errorf("outer: %w", gai.NewValidationError("ThinkingLevel", "unsupported thinking level: max"))
+
+		var validationErr *gai.ValidationError
+		is.True(t, errors.As(err, &validationErr))
+		is.Equal(t, "ThinkingLevel", validationErr.Field)
+		is.Equal(t, "unsupported thinking level: max", validationErr.Err.Error())
+	})
+
+	t.Run("unwraps to the underlying error", func(t *testing.T) {
+		inner := errors.New("no parts")
+		err := &gai.ValidationError{Field: "Parts", Err: inner}
+		is.True(t, errors.Is(err, inner))
+	})
+}
@@ -133,7 +133,9 @@ func SemanticSimilarityScorer[T gai.VectorComponent](t fataler, e gai.Embedder[T
 	}
 }
 
-// CosineSimilarity between two embedding vectors a and b, normalized to a [Score].
+// CosineSimilarity between two embedding vectors a and b, normalized to a [Score] from 0 to 1.
+// Panics if the vectors are empty, have different lengths, or either has a zero norm.
+// See [maragu.dev/gai/vector.Cosine] for the similarity from -1 to 1, which is 0 for zero vectors.
 func CosineSimilarity[T gai.VectorComponent](a, b []T) Score {
 	if len(a) != len(b) {
 		panic(fmt.Sprintf("vectors must have equal length, but are lengths %v and %v", len(a), len(b)))
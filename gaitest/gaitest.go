@@ -0,0 +1,262 @@
+// Package gaitest provides in-process fakes of [gai.ChatCompleter] and [gai.Embedder] for unit tests.
+//
+// [ChatCompleter] plays back a queue of scripted [Response]s and records every request, so tests
+// can assert on what was sent. [Embedder] returns deterministic hash-based vectors, so the same
+// input always embeds to the same vector without calling a model.
+package gaitest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"maragu.dev/gai"
+)
+
+// ErrNoResponses is returned by [ChatCompleter.ChatComplete] when the response queue is empty.
+var ErrNoResponses = errors.New("gaitest: no more scripted responses")
+
+// Response is one scripted [ChatCompleter] response.
+type Response struct {
+	// Err is returned from ChatComplete instead of a response.
+	Err error
+	// Delay before ChatComplete returns. It is cut short, with the context error, if the context is done.
+	Delay time.Duration
+
+	// Parts are yielded in order.
+	Parts []gai.Part
+	// PartDelay before yielding each part. It is cut short, with the context error, if the context is done.
+	PartDelay time.Duration
+	// Errs are yielded at the given positions in the stream, ending it. An error at position i
+	// is yielded instead of Parts[i]; position len(Parts) yields it after the last part.
+	Errs map[int]error
+
+	// Usage is set on the response metadata when the stream ends without an error.
+	Usage gai.ChatCompleteResponseUsage
+	// FinishReason is set on the response metadata when the stream ends without an error.
+	FinishReason *gai.ChatCompleteFinishReason
+}
+
+// Text is a convenience function to create a [Response] streaming the given texts as text parts,
+// finishing with [gai.ChatCompleteFinishReasonStop].
+func Text(texts ...string) Response {
+	var parts []gai.Part
+	for _, text := range texts {
+		parts = append(parts, gai.TextPart(text))
+	}
+	return Response{
+		Parts:        parts,
+		FinishReason: gai.Ptr(gai.ChatCompleteFinishReasonStop),
+	}
+}
+
+// ToolCall is a convenience function to create a [Response] with a single tool call,
+// finishing with [gai.ChatCompleteFinishReasonToolCalls].
+func ToolCall(id, name, args string) Response {
+	return Response{
+		Parts:        []gai.Part{gai.ToolCallPart(id, name, []byte(args))},
+		FinishReason: gai.Ptr(gai.ChatCompleteFinishReasonToolCalls),
+	}
+}
+
+// ChatCompleter is a fake [gai.ChatCompleter] that plays back scripted responses in order.
+// It is safe for concurrent use.
+type ChatCompleter struct {
+	mu        sync.Mutex
+	requests  []gai.ChatCompleteRequest
+	responses []Response
+}
+
+// NewChatCompleter with the given scripted responses.
+func NewChatCompleter(responses ...Response) *ChatCompleter {
+	return &ChatCompleter{responses: responses}
+}
+
+// Add responses to the end of the queue.
+func (c *ChatCompleter) Add(responses ...Response) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responses = append(c.responses, responses...)
+}
+
+// Requests returns a copy of the requests received so far, in order.
+func (c *ChatCompleter) Requests() []gai.ChatCompleteRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.requests)
+}
+
+// Remaining returns the number of responses left in the queue.
+func (c *ChatCompleter) Remaining() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.responses)
+}
+
+// ChatComplete satisfies [gai.ChatCompleter]. It records the request and plays back the next
+// scripted [Response], or returns [ErrNoResponses] if there is none.
+func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRequest) (gai.ChatCompleteResponse, error) {
+	c.mu.Lock()
+	c.requests = append(c.requests, req)
+	if len(c.responses) == 0 {
+		c.mu.Unlock()
+		return gai.ChatCompleteResponse{}, ErrNoResponses
+	}
+	r := c.responses[0]
+	c.responses = c.responses[1:]
+	c.mu.Unlock()
+
+	if err := sleep(ctx, r.Delay); err != nil {
+		return gai.ChatCompleteResponse{}, err
+	}
+
+	if r.Err != nil {
+		return gai.ChatCompleteResponse{}, r.Err
+	}
+
+	meta := &gai.ChatCompleteResponseMetadata{}
+
+	res := gai.NewChatCompleteResponse(func(yield func(gai.Part, error) bool) {
+		for i := 0; i <= len(r.Parts); i++ {
+			if err, ok := r.Errs[i]; ok {
+				yield(gai.Part{}, err)
+				return
+			}
+			if i == len(r.Parts) {
+				break
+			}
+
+			if err := sleep(ctx, r.PartDelay); err != nil {
+				yield(gai.Part{}, err)
+				return
+			}
+			if !yield(r.Parts[i], nil) {
+				return
+			}
+		}
+
+		meta.Usage = r.Usage
+		meta.FinishReason = r.FinishReason
+	})
+	res.Meta = meta
+
+	return res, nil
+}
+
+// sleep for d, or until ctx is done, in which case it returns the context error.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var _ gai.ChatCompleter = (*ChatCompleter)(nil)
+
+// Embedder is a fake [gai.Embedder] returning deterministic unit vectors derived from a hash of
+// the request parts, or of each part with [gai.EmbedRequest.Separate]. Equal requests embed to equal
+// vectors; different requests embed to vectors that are, for practical purposes, unrelated.
+// It is safe for concurrent use.
+type Embedder[T ~float32 | ~float64] struct {
+	dimensions int
+	mu         sync.Mutex
+	requests   []gai.EmbedRequest
+}
+
+// NewEmbedder with the given vector dimensions. Panics if dimensions is not positive.
+func NewEmbedder[T ~float32 | ~float64](dimensions int) *Embedder[T] {
+	if dimensions <= 0 {
+		panic("dimensions must be greater than 0")
+	}
+	return &Embedder[T]{dimensions: dimensions}
+}
+
+// Requests returns a copy of the requests received so far, in order.
+func (e *Embedder[T]) Requests() []gai.EmbedRequest {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.requests)
+}
+
+// Embed satisfies [gai.Embedder].
+func (e *Embedder[T]) Embed(ctx context.Context, req gai.EmbedRequest) (gai.EmbedResponse[T], error) {
+	e.mu.Lock()
+	e.requests = append(e.requests, req)
+	e.mu.Unlock()
+
+	if len(req.Parts) == 0 {
+		return gai.EmbedResponse[T]{}, gai.NewValidationError("Parts", "no parts")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return gai.EmbedResponse[T]{}, err
+	}
+
+	if req.Separate {
+		var embeddings [][]T
+		for _, part := range req.Parts {
+			embeddings = append(embeddings, e.embed([]gai.Part{part}))
+		}
+		return gai.EmbedResponse[T]{Embeddings: embeddings}, nil
+	}
+
+	return gai.EmbedResponse[T]{Embedding: e.embed(req.Parts)}, nil
+}
+
+// embed the parts into a unit vector derived from their hash.
+func (e *Embedder[T]) embed(parts []gai.Part) []T {
+	h := sha256.New()
+	for _, part := range parts {
+		writeField(h, []byte(part.Type))
+		writeField(h, []byte(part.MIMEType))
+		switch part.Type {
+		case gai.PartTypeText:
+			writeField(h, []byte(part.Text()))
+		default:
+			writeField(h, part.Data)
+		}
+	}
+	seed := h.Sum(nil)
+
+	// Expand the seed into components in [-1, 1] by hashing it with a counter, then normalise.
+	embedding := make([]T, e.dimensions)
+	var norm float64
+	var block [sha256.Size]byte
+	for i := range embedding {
+		if i%(sha256.Size/4) == 0 {
+			block = sha256.Sum256(binary.BigEndian.AppendUint64(seed, uint64(i)))
+		}
+		offset := (i % (sha256.Size / 4)) * 4
+		v := float64(binary.BigEndian.Uint32(block[offset:]))/math.MaxUint32*2 - 1
+		embedding[i] = T(v)
+		norm += v * v
+	}
+	norm = math.Sqrt(norm)
+	if norm > 0 {
+		for i := range embedding {
+			embedding[i] = T(float64(embedding[i]) / norm)
+		}
+	}
+	return embedding
+}
+
+// writeField writes a length-prefixed field, so adjacent fields cannot run into each other.
+func writeField(h io.Writer, b []byte) {
+	_, _ = h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(b))))
+	_, _ = h.Write(b)
+}
+
+var _ gai.Embedder[float64] = (*Embedder[float64])(nil)
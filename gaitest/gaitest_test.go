@@ -0,0 +1,179 @@
+package gaitest_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/eval"
+	"maragu.dev/gai/gaitest"
+	"maragu.dev/gai/robust"
+)
+
+func TestChatCompleter_ChatComplete(t *testing.T) {
+	t.Run("plays back scripted responses in order and records requests", func(t *testing.T) {
+		cc := gaitest.NewChatCompleter(gaitest.Text("Hello", " there!"))
+		cc.Add(gaitest.ToolCall("call_1", "get_time", `{}`))
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{Messages: []gai.Message{gai.NewUserTextMessage("Hi!")}})
+		is.NotError(t, err)
+		parts, err := collect(res)
+		is.NotError(t, err)
+		is.Equal(t, 2, len(parts))
+		is.Equal(t, "Hello", parts[0].Text())
+		is.Equal(t, gai.ChatCompleteFinishReasonStop, *res.Meta.FinishReason)
+
+		res, err = cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{Messages: []gai.Message{gai.NewUserTextMessage("Time?")}})
+		is.NotError(t, err)
+		parts, err = collect(res)
+		is.NotError(t, err)
+		is.Equal(t, "get_time", parts[0].ToolCall().Name)
+
+		requests := cc.Requests()
+		is.Equal(t, 2, len(requests))
+		is.Equal(t, "Time?", requests[1].Messages[0].Parts[0].Text())
+		is.Equal(t, 0, cc.Remaining())
+
+		_, err = cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{})
+		is.True(t, errors.Is(err, gaitest.ErrNoResponses))
+	})
+
+	t.Run("returns errors before and during the stream", func(t *testing.T) {
+		preErr := errors.New("overloaded")
+		streamErr := errors.New("connection reset")
+		cc := gaitest.NewChatCompleter(
+			gaitest.Response{Err: preErr},
+			gaitest.Response{
+				Parts: []gai.Part{gai.TextPart("a"), gai.TextPart("b")},
+				Errs:  map[int]error{1: streamErr},
+				Usage: gai.ChatCompleteResponseUsage{PromptTokens: 1},
+			},
+		)
+
+		_, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{})
+		is.Equal(t, preErr, err)
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{})
+		is.NotError(t, err)
+		parts, err := collect(res)
+		is.Equal(t, streamErr, err)
+		is.Equal(t, 1, len(parts))
+		is.Equal(t, 0, res.Meta.Usage.PromptTokens)
+	})
+
+	t.Run("sets usage when the stream completes", func(t *testing.T) {
+		cc := gaitest.NewChatCompleter(gaitest.Response{
+			Parts: []gai.Part{gai.TextPart("a")},
+			Usage: gai.ChatCompleteResponseUsage{PromptTokens: 3, CompletionTokens: 1},
+		})
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{})
+		is.NotError(t, err)
+		_, err = collect(res)
+		is.NotError(t, err)
+		is.Equal(t, 3, res.Meta.Usage.PromptTokens)
+		is.Equal(t, 1, res.Meta.Usage.CompletionTokens)
+	})
+
+	t.Run("cuts delays short when the context is done", func(t *testing.T) {
+		cc := gaitest.NewChatCompleter(gaitest.Response{Delay: time.Hour})
+
+		ctx, cancel := context.WithTimeout(t.Context(), time.Millisecond)
+		defer cancel()
+		_, err := cc.ChatComplete(ctx, gai.ChatCompleteRequest{})
+		is.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("works behind a robust chat completer", func(t *testing.T) {
+		primary := gaitest.NewChatCompleter(gaitest.Response{Err: errors.New("500 Internal Server Error")})
+		secondary := gaitest.NewChatCompleter(gaitest.Text("Hi!"))
+
+		cc := robust.NewChatCompleter(robust.NewChatCompleterOptions{
+			Completers:  []gai.ChatCompleter{primary, secondary},
+			MaxAttempts: 1,
+		})
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{Messages: []gai.Message{gai.NewUserTextMessage("Hi!")}})
+		is.NotError(t, err)
+		parts, err := collect(res)
+		is.NotError(t, err)
+		is.Equal(t, "Hi!", parts[0].Text())
+		is.Equal(t, 1, len(primary.Requests()))
+		is.Equal(t, 1, len(secondary.Requests()))
+	})
+}
+
+func TestEmbedder_Embed(t *testing.T) {
+	t.Run("returns deterministic unit vectors", func(t *testing.T) {
+		e := gaitest.NewEmbedder[float64](100)
+
+		a1, err := e.Embed(t.Context(), gai.NewTextEmbedRequest("a"))
+		is.NotError(t, err)
+		a2, err := e.Embed(t.Context(), gai.NewTextEmbedRequest("a"))
+		is.NotError(t, err)
+		b, err := e.Embed(t.Context(), gai.NewTextEmbedRequest("b"))
+		is.NotError(t, err)
+
+		is.Equal(t, 100, len(a1.Embedding))
+		is.EqualSlice(t, a1.Embedding, a2.Embedding)
+
+		var norm float64
+		for _, v := range a1.Embedding {
+			norm += v * v
+		}
+		is.True(t, math.Abs(norm-1) < 1e-9)
+
+		is.Equal(t, eval.Score(1), eval.CosineSimilarity(a1.Embedding, a2.Embedding))
+		is.True(t, eval.CosineSimilarity(a1.Embedding, b.Embedding) < 0.9)
+
+		is.Equal(t, 3, len(e.Requests()))
+	})
+
+	t.Run("supports float32", func(t *testing.T) {
+		e := gaitest.NewEmbedder[float32](3)
+
+		res, err := e.Embed(t.Context(), gai.NewTextEmbedRequest("a"))
+		is.NotError(t, err)
+		is.Equal(t, 3, len(res.Embedding))
+	})
+
+	t.Run("embeds each part separately", func(t *testing.T) {
+		e := gaitest.NewEmbedder[float64](3)
+
+		joint, err := e.Embed(t.Context(), gai.EmbedRequest{Parts: []gai.Part{gai.TextPart("a"), gai.TextPart("b")}})
+		is.NotError(t, err)
+		separate, err := e.Embed(t.Context(), gai.EmbedRequest{Parts: []gai.Part{gai.TextPart("a"), gai.TextPart("b")}, Separate: true})
+		is.NotError(t, err)
+		a, err := e.Embed(t.Context(), gai.NewTextEmbedRequest("a"))
+		is.NotError(t, err)
+
+		is.Equal(t, 0, len(separate.Embedding))
+		is.Equal(t, 2, len(separate.Embeddings))
+		is.EqualSlice(t, a.Embedding, separate.Embeddings[0])
+		is.True(t, eval.CosineSimilarity(joint.Embedding, separate.Embeddings[0]) < 0.9)
+	})
+
+	t.Run("returns a validation error without parts", func(t *testing.T) {
+		e := gaitest.NewEmbedder[float64](3)
+
+		_, err := e.Embed(t.Context(), gai.EmbedRequest{})
+		var validationErr *gai.ValidationError
+		is.True(t, errors.As(err, &validationErr))
+	})
+}
+
+func collect(res gai.ChatCompleteResponse) ([]gai.Part, error) {
+	var parts []gai.Part
+	for part, err := range res.Parts() {
+		if err != nil {
+			return parts, err
+		}
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/mcp"
+	"maragu.dev/gai/tools"
+)
+
+// This serves some of the built-in tools over MCP's stdio transport, so an editor agent can
+// start it as a subprocess and use them. Logs go to stderr, because stdout carries the protocol.
+func main() {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	root, err := os.OpenRoot(".")
+	if err != nil {
+		log.Error("Error opening root", "error", err)
+		return
+	}
+
+	s := mcp.NewServer(mcp.NewServerOptions{
+		Name: "gai-tools",
+		Log:  log,
+		Tools: []gai.Tool{
+			tools.NewGetTime(time.Now),
+			tools.NewReadFile(root),
+			tools.NewListDir(root),
+			tools.NewGlob(root),
+			tools.NewGrep(root),
+			tools.NewFetchWithOptions(tools.NewFetchOptions{
+				Client:           &http.Client{Timeout: 10 * time.Second},
+				BlockPrivateIPs:  true,
+				MaxBodyBytes:     10 * 1024 * 1024,
+				RespectRobotsTxt: true,
+			}),
+		},
+	})
+
+	if err := s.Serve(ctx, os.Stdin, os.Stdout); err != nil {
+		log.Error("Error serving", "error", err)
+	}
+}
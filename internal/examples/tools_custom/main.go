@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"math/rand/v2"
@@ -16,17 +15,13 @@ type EatArgs struct {
 	What string `json:"what" jsonschema_description:"What you'd like to eat."`
 }
 
+// NewEat uses [gai.NewTool], which generates the schema from EatArgs and validates the model's arguments
+// against it before calling Execute.
 func NewEat() gai.Tool {
-	return gai.Tool{
+	return gai.NewTool(gai.NewToolOptions[EatArgs]{
 		Name:        "eat",
 		Description: "Eat something, supplying what you eat as an argument. The result will be a string describing how it was.",
-		Schema:      gai.GenerateToolSchema[EatArgs](),
-		Execute: func(ctx context.Context, args json.RawMessage) (string, error) {
-			var eatArgs EatArgs
-			if err := json.Unmarshal(args, &eatArgs); err != nil {
-				return "", fmt.Errorf("error unmarshaling eat args from JSON: %w", err)
-			}
-
+		Execute: func(ctx context.Context, args EatArgs) (string, error) {
 			results := []string{
 				"it was okay.",
 				"it was absolutely excellent!",
@@ -34,9 +29,9 @@ func NewEat() gai.Tool {
 				"it gave you diarrhea.",
 			}
 
-			return "You ate " + eatArgs.What + " and " + results[rand.IntN(len(results))], nil
+			return "You ate " + args.What + " and " + results[rand.IntN(len(results))], nil
 		},
-	}
+	})
 }
 
 func main() {
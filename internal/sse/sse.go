@@ -0,0 +1,67 @@
+// Package sse reads server-sent event streams, for clients of providers without a Go SDK.
+// See https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation.
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"iter"
+)
+
+// Event is a single dispatched server-sent event.
+type Event struct {
+	// Name is the event type from the "event" field, or empty if the event has none.
+	Name string
+	// Data is the event data, with multiple "data" lines joined by newlines.
+	Data []byte
+}
+
+// Read yields the events in r until it is exhausted or the consumer stops. Comments and
+// fields other than "event" and "data" are ignored, as are events without data.
+func Read(r io.Reader) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
+
+		var name string
+		var data [][]byte
+		dispatch := func() bool {
+			defer func() {
+				name = ""
+				data = nil
+			}()
+			if data == nil {
+				return true
+			}
+			return yield(Event{Name: name, Data: bytes.Join(data, []byte("\n"))}, nil)
+		}
+
+		for scanner.Scan() {
+			line := scanner.Bytes()
+
+			if len(line) == 0 {
+				if !dispatch() {
+					return
+				}
+				continue
+			}
+
+			field, value, _ := bytes.Cut(line, []byte(":"))
+			value = bytes.TrimPrefix(value, []byte(" "))
+
+			switch string(field) {
+			case "event":
+				name = string(value)
+			case "data":
+				data = append(data, bytes.Clone(value))
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			yield(Event{}, err)
+			return
+		}
+		dispatch()
+	}
+}
@@ -0,0 +1,38 @@
+package sse_test
+
+import (
+	"strings"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai/internal/sse"
+)
+
+func TestRead(t *testing.T) {
+	t.Run("reads named and unnamed events, skipping comments", func(t *testing.T) {
+		stream := ": keep-alive\n\nevent: greeting\ndata: {\"text\":\"Hi\"}\n\ndata: line 1\ndata: line 2\n\ndata: [DONE]"
+
+		var events []sse.Event
+		for event, err := range sse.Read(strings.NewReader(stream)) {
+			is.NotError(t, err)
+			events = append(events, event)
+		}
+
+		is.Equal(t, 3, len(events))
+		is.Equal(t, "greeting", events[0].Name)
+		is.Equal(t, `{"text":"Hi"}`, string(events[0].Data))
+		is.Equal(t, "", events[1].Name)
+		is.Equal(t, "line 1\nline 2", string(events[1].Data))
+		is.Equal(t, "[DONE]", string(events[2].Data))
+	})
+
+	t.Run("stops when the consumer stops", func(t *testing.T) {
+		var count int
+		for range sse.Read(strings.NewReader("data: 1\n\ndata: 2\n\n")) {
+			count++
+			break
+		}
+		is.Equal(t, 1, count)
+	})
+}
@@ -0,0 +1,310 @@
+// Package mcp connects gai to the Model Context Protocol (MCP).
+//
+// A [Client] connects to an MCP server over stdio, streamable HTTP, or any pair of streams,
+// and exposes the server's tools as [gai.Tool]s with [Client.Tools]. A [Server] does the
+// reverse, and serves [gai.Tool]s to MCP clients such as editor agents.
+// See https://modelcontextprotocol.io for the protocol.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"maragu.dev/gai"
+)
+
+// Client for an MCP server.
+type Client struct {
+	log        *slog.Logger
+	nextID     atomic.Int64
+	serverInfo Implementation
+	t          transport
+}
+
+type NewClientOptions struct {
+	Log *slog.Logger
+	// Reader receives newline-delimited JSON-RPC messages from the server.
+	Reader io.Reader
+	// Writer sends newline-delimited JSON-RPC messages to the server.
+	// It is closed by [Client.Close] if it is an [io.Closer].
+	Writer io.Writer
+}
+
+// NewClient connects to an MCP server over a pair of streams and initializes the session.
+// This is useful for in-process servers; see [NewStdioClient] for servers in a subprocess.
+func NewClient(ctx context.Context, opts NewClientOptions) (*Client, error) {
+	if opts.Log == nil {
+		opts.Log = slog.New(slog.DiscardHandler)
+	}
+
+	closer := func() error {
+		if c, ok := opts.Writer.(io.Closer); ok {
+			return c.Close()
+		}
+		return nil
+	}
+
+	return connect(ctx, newStreamTransport(opts.Reader, opts.Writer, closer, opts.Log), opts.Log)
+}
+
+type NewStdioClientOptions struct {
+	Args    []string
+	Command string
+	// Dir is the working directory of the server process. Defaults to the current directory.
+	Dir string
+	// Env of the server process, in the form "key=value". Defaults to the current environment.
+	Env []string
+	Log *slog.Logger
+	// Stderr receives the server's standard error. Defaults to discarding it.
+	Stderr io.Writer
+}
+
+// NewStdioClient starts an MCP server as a subprocess and initializes the session over its
+// standard input and output. [Client.Close] stops the process.
+func NewStdioClient(ctx context.Context, opts NewStdioClientOptions) (*Client, error) {
+	if opts.Log == nil {
+		opts.Log = slog.New(slog.DiscardHandler)
+	}
+
+	cmd := exec.Command(opts.Command, opts.Args...)
+	cmd.Dir = opts.Dir
+	cmd.Env = opts.Env
+	cmd.Stderr = opts.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error starting MCP server: %w", err)
+	}
+
+	// closer closes stdin, which tells the server to exit, and kills it if it does not.
+	closer := func() error {
+		_ = stdin.Close()
+		done := make(chan error, 1)
+		go func() {
+			done <- cmd.Wait()
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-time.After(5 * time.Second):
+			_ = cmd.Process.Kill()
+			<-done
+			return nil
+		}
+	}
+
+	c, err := connect(ctx, newStreamTransport(stdout, stdin, closer, opts.Log), opts.Log)
+	if err != nil {
+		_ = closer()
+		return nil, err
+	}
+	return c, nil
+}
+
+type NewHTTPClientOptions struct {
+	// Client for requests. Defaults to [http.DefaultClient].
+	Client *http.Client
+	// Headers are sent with every request, for example for authorization.
+	Headers map[string]string
+	Log     *slog.Logger
+	// URL of the server's MCP endpoint.
+	URL string
+}
+
+// NewHTTPClient connects to an MCP server over the streamable HTTP transport and initializes
+// the session.
+func NewHTTPClient(ctx context.Context, opts NewHTTPClientOptions) (*Client, error) {
+	if opts.Log == nil {
+		opts.Log = slog.New(slog.DiscardHandler)
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	return connect(ctx, &httpTransport{
+		client:  opts.Client,
+		headers: opts.Headers,
+		log:     opts.Log,
+		url:     opts.URL,
+	}, opts.Log)
+}
+
+// connect runs the initialization handshake over t.
+func connect(ctx context.Context, t transport, log *slog.Logger) (*Client, error) {
+	c := &Client{log: log, t: t}
+
+	var res initializeResult
+	err := c.call(ctx, "initialize", initializeParams{
+		ProtocolVersion: protocolVersion,
+		Capabilities:    map[string]any{},
+		ClientInfo:      Implementation{Name: "gai", Version: "0"},
+	}, &res)
+	if err != nil {
+		_ = t.close()
+		return nil, fmt.Errorf("error initializing MCP session: %w", err)
+	}
+	c.serverInfo = res.ServerInfo
+
+	if err := t.notify(ctx, message{JSONRPC: "2.0", Method: "notifications/initialized"}); err != nil {
+		_ = t.close()
+		return nil, fmt.Errorf("error initializing MCP session: %w", err)
+	}
+
+	log.Debug("Connected to MCP server", "name", res.ServerInfo.Name, "version", res.ServerInfo.Version, "protocolVersion", res.ProtocolVersion)
+
+	return c, nil
+}
+
+// ServerInfo returns the name and version the server reported when connecting.
+func (c *Client) ServerInfo() Implementation {
+	return c.serverInfo
+}
+
+// Close the connection to the server.
+func (c *Client) Close() error {
+	return c.t.close()
+}
+
+// call the given method with params, and unmarshal the result into result.
+func (c *Client) call(ctx context.Context, method string, params, result any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.t.call(ctx, message{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(strconv.FormatInt(c.nextID.Add(1), 10)),
+		Method:  method,
+		Params:  data,
+	})
+	if err != nil {
+		return err
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if err := json.Unmarshal(res.Result, result); err != nil {
+		return fmt.Errorf("error parsing %v result: %w", method, err)
+	}
+	return nil
+}
+
+// Tools lists the server's tools as [gai.Tool]s. Executing a tool calls it on the server.
+// Tools with an input schema that can't be converted are skipped and logged, so they never reach
+// a model without their parameters.
+func (c *Client) Tools(ctx context.Context) ([]gai.Tool, error) {
+	var tools []gai.Tool
+	var cursor string
+	for {
+		var res listToolsResult
+		if err := c.call(ctx, "tools/list", listToolsParams{Cursor: cursor}, &res); err != nil {
+			return nil, fmt.Errorf("error listing MCP tools: %w", err)
+		}
+
+		for _, info := range res.Tools {
+			tool, err := c.newTool(info)
+			if err != nil {
+				c.log.Warn("Skipping MCP tool with invalid input schema", "name", info.Name, "error", err)
+				continue
+			}
+			tools = append(tools, tool)
+		}
+
+		if res.NextCursor == "" {
+			return tools, nil
+		}
+		cursor = res.NextCursor
+	}
+}
+
+func (c *Client) newTool(info toolInfo) (gai.Tool, error) {
+	schema, err := convertToolSchema(info.InputSchema)
+	if err != nil {
+		return gai.Tool{}, fmt.Errorf("error converting input schema: %w", err)
+	}
+
+	description := info.Description
+	if description == "" {
+		description = info.Title
+	}
+
+	return gai.Tool{
+		Name:        info.Name,
+		Description: description,
+		Schema:      schema,
+		Summarize: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
+			return summarize(rawArgs), nil
+		},
+		Execute: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
+			return c.CallTool(ctx, info.Name, rawArgs)
+		},
+	}, nil
+}
+
+// CallTool calls the named tool on the server and returns its result as text.
+// A tool result flagged as an error is returned as an error with the result text.
+func (c *Client) CallTool(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage(`{}`)
+	}
+
+	var res callToolResult
+	if err := c.call(ctx, "tools/call", callToolParams{Name: name, Arguments: args}, &res); err != nil {
+		return "", fmt.Errorf("error calling MCP tool %v: %w", name, err)
+	}
+
+	text := resultText(res)
+	if res.IsError {
+		return "", errors.New(text)
+	}
+	return text, nil
+}
+
+// resultText renders the content of a tool result as text. Non-text content is described
+// rather than included, because tool results are text in gai.
+func resultText(res callToolResult) string {
+	var texts []string
+	for _, c := range res.Content {
+		switch c.Type {
+		case "text":
+			texts = append(texts, c.Text)
+		case "image", "audio":
+			texts = append(texts, fmt.Sprintf("[%v: %v]", c.Type, c.MIMEType))
+		case "resource_link":
+			texts = append(texts, fmt.Sprintf("[resource: %v]", c.URI))
+		case "resource":
+			if c.Resource != nil {
+				if c.Resource.Text != "" {
+					texts = append(texts, c.Resource.Text)
+				} else {
+					texts = append(texts, fmt.Sprintf("[resource: %v]", c.Resource.URI))
+				}
+			}
+		}
+	}
+
+	if len(texts) == 0 && len(res.StructuredContent) > 0 {
+		return string(res.StructuredContent)
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,269 @@
+package mcp_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/mcp"
+)
+
+// TestMain lets the test binary double as a stdio MCP server for [mcp.NewStdioClient].
+func TestMain(m *testing.M) {
+	if os.Getenv("GAI_MCP_TEST_SERVER") == "1" {
+		serveStream(os.Stdin, os.Stdout)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestNewClient(t *testing.T) {
+	t.Run("lists tools and calls them over streams", func(t *testing.T) {
+		c := newPipeClient(t)
+
+		is.Equal(t, "test-server", c.ServerInfo().Name)
+
+		tools, err := c.Tools(t.Context())
+		is.NotError(t, err)
+		is.Equal(t, 2, len(tools))
+
+		echo := tools[0]
+		is.Equal(t, "echo", echo.Name)
+		is.Equal(t, "Echo the message back.", echo.Description)
+		is.Equal(t, gai.SchemaTypeString, echo.Schema.Properties["message"].Type)
+		is.Equal(t, gai.SchemaTypeInteger, echo.Schema.Properties["times"].Type)
+		is.EqualSlice(t, []string{"loud", "quiet"}, echo.Schema.Properties["tone"].Enum)
+
+		result, err := echo.Execute(t.Context(), json.RawMessage(`{"message":"Hi","times":2}`))
+		is.NotError(t, err)
+		is.Equal(t, "HiHi", result)
+
+		summary, err := echo.Summarize(t.Context(), json.RawMessage(`{"message":"Hi","times":2}`))
+		is.NotError(t, err)
+		is.Equal(t, `message="Hi" times=2`, summary)
+
+		summary, err = echo.Summarize(t.Context(), json.RawMessage(`{"message":"`+strings.Repeat("æ", 40)+`"}`))
+		is.NotError(t, err)
+		is.Equal(t, `message="`+strings.Repeat("æ", 30)+`..."`, summary)
+	})
+
+	t.Run("returns tool errors as errors", func(t *testing.T) {
+		c := newPipeClient(t)
+
+		tools, err := c.Tools(t.Context())
+		is.NotError(t, err)
+
+		_, err = tools[1].Execute(t.Context(), json.RawMessage(`{}`))
+		is.True(t, err != nil)
+		is.Equal(t, "something broke", err.Error())
+	})
+
+	t.Run("returns JSON-RPC errors for unknown tools", func(t *testing.T) {
+		c := newPipeClient(t)
+
+		_, err := c.CallTool(t.Context(), "nope", nil)
+		is.True(t, err != nil)
+		is.True(t, strings.Contains(err.Error(), "unknown tool nope"))
+	})
+}
+
+func TestNewStdioClient(t *testing.T) {
+	t.Run("starts the server as a subprocess", func(t *testing.T) {
+		c, err := mcp.NewStdioClient(t.Context(), mcp.NewStdioClientOptions{
+			Command: os.Args[0],
+			Env:     append(os.Environ(), "GAI_MCP_TEST_SERVER=1"),
+		})
+		is.NotError(t, err)
+		t.Cleanup(func() {
+			is.NotError(t, c.Close())
+		})
+
+		tools, err := c.Tools(t.Context())
+		is.NotError(t, err)
+
+		result, err := tools[0].Execute(t.Context(), json.RawMessage(`{"message":"Hi"}`))
+		is.NotError(t, err)
+		is.Equal(t, "Hi", result)
+	})
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	t.Run("keeps the session across JSON and event stream responses", func(t *testing.T) {
+		var sessions []string
+		var deleted bool
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			is.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+			if r.Method == http.MethodDelete {
+				deleted = r.Header.Get("Mcp-Session-Id") == "session-1"
+				return
+			}
+
+			var req map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			sessions = append(sessions, r.Header.Get("Mcp-Session-Id"))
+
+			res := handle(req)
+			if res == nil {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+
+			data, _ := json.Marshal(res)
+			if req["method"] == "initialize" {
+				w.Header().Set("Mcp-Session-Id", "session-1")
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write(data)
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", `{"jsonrpc":"2.0","method":"notifications/progress","params":{}}`)
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+		}))
+		t.Cleanup(srv.Close)
+
+		c, err := mcp.NewHTTPClient(t.Context(), mcp.NewHTTPClientOptions{
+			URL:     srv.URL,
+			Headers: map[string]string{"Authorization": "Bearer secret"},
+		})
+		is.NotError(t, err)
+
+		tools, err := c.Tools(t.Context())
+		is.NotError(t, err)
+
+		result, err := tools[0].Execute(t.Context(), json.RawMessage(`{"message":"Hi"}`))
+		is.NotError(t, err)
+		is.Equal(t, "Hi", result)
+
+		is.NotError(t, c.Close())
+		is.True(t, deleted)
+
+		is.Equal(t, "", sessions[0])
+		for _, s := range sessions[1:] {
+			is.Equal(t, "session-1", s)
+		}
+	})
+
+	t.Run("includes the status code in errors", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "nope", http.StatusUnauthorized)
+		}))
+		t.Cleanup(srv.Close)
+
+		_, err := mcp.NewHTTPClient(t.Context(), mcp.NewHTTPClientOptions{URL: srv.URL})
+		is.True(t, err != nil)
+		is.True(t, strings.Contains(err.Error(), "401 Unauthorized"))
+	})
+}
+
+func newPipeClient(t *testing.T) *mcp.Client {
+	t.Helper()
+
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+	go func() {
+		serveStream(serverR, serverW)
+		_ = serverW.Close()
+	}()
+
+	c, err := mcp.NewClient(t.Context(), mcp.NewClientOptions{Reader: clientR, Writer: clientW})
+	is.NotError(t, err)
+	t.Cleanup(func() {
+		_ = c.Close()
+	})
+	return c
+}
+
+// serveStream is a minimal MCP server over newline-delimited JSON.
+func serveStream(r io.Reader, w io.Writer) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var req map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			continue
+		}
+		if res := handle(req); res != nil {
+			data, _ := json.Marshal(res)
+			_, _ = fmt.Fprintf(w, "%s\n", data)
+		}
+	}
+}
+
+// handle a JSON-RPC request, returning nil for notifications.
+// The tool list is paginated, with one tool per page.
+func handle(req map[string]any) map[string]any {
+	id, ok := req["id"]
+	if !ok {
+		return nil
+	}
+	params, _ := req["params"].(map[string]any)
+
+	result := func(v any) map[string]any {
+		return map[string]any{"jsonrpc": "2.0", "id": id, "result": v}
+	}
+
+	switch req["method"] {
+	case "initialize":
+		return result(map[string]any{
+			"protocolVersion": "2025-06-18",
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "test-server", "version": "1.0.0"},
+		})
+
+	case "tools/list":
+		if params["cursor"] == "page-2" {
+			return result(map[string]any{"tools": []any{
+				map[string]any{"name": "fail", "inputSchema": map[string]any{"type": "object"}},
+				// Skipped by the client, because the schema has a number for a type
+				map[string]any{"name": "broken", "inputSchema": map[string]any{"type": "object", "properties": map[string]any{"x": map[string]any{"type": 5}}}},
+			}})
+		}
+		return result(map[string]any{
+			"tools": []any{
+				map[string]any{
+					"name":        "echo",
+					"description": "Echo the message back.",
+					"inputSchema": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"message": map[string]any{"type": "string"},
+							"times":   map[string]any{"type": []any{"integer", "null"}, "minimum": 1},
+							"tone":    map[string]any{"type": "string", "enum": []any{"loud", "quiet"}},
+						},
+						"required": []any{"message"},
+					},
+				},
+			},
+			"nextCursor": "page-2",
+		})
+
+	case "tools/call":
+		args, _ := params["arguments"].(map[string]any)
+		switch params["name"] {
+		case "echo":
+			times := 1
+			if v, ok := args["times"].(float64); ok {
+				times = int(v)
+			}
+			text := strings.Repeat(fmt.Sprint(args["message"]), times)
+			return result(map[string]any{"content": []any{map[string]any{"type": "text", "text": text}}})
+		case "fail":
+			return result(map[string]any{"content": []any{map[string]any{"type": "text", "text": "something broke"}}, "isError": true})
+		default:
+			return map[string]any{"jsonrpc": "2.0", "id": id, "error": map[string]any{"code": -32602, "message": fmt.Sprintf("unknown tool %v", params["name"])}}
+		}
+
+	default:
+		return map[string]any{"jsonrpc": "2.0", "id": id, "error": map[string]any{"code": -32601, "message": "method not found"}}
+	}
+}
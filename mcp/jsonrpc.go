@@ -0,0 +1,124 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// protocolVersion is the MCP revision this package speaks.
+const protocolVersion = "2025-06-18"
+
+// supportedProtocolVersions are the MCP revisions the server accepts from clients.
+// Tools work the same way in all of them.
+var supportedProtocolVersions = []string{"2024-11-05", "2025-03-26", "2025-06-18"}
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// message is a JSON-RPC 2.0 request, notification, or response.
+// Requests have an ID and a method, notifications only a method, and responses an ID and
+// either a result or an error.
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+func (m message) isRequest() bool {
+	return m.Method != "" && len(m.ID) > 0
+}
+
+func (m message) isNotification() bool {
+	return m.Method != "" && len(m.ID) == 0
+}
+
+func (m message) isResponse() bool {
+	return m.Method == "" && len(m.ID) > 0
+}
+
+// RPCError is a JSON-RPC error returned by the other side of the connection.
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("MCP error %v: %v", e.Code, e.Message)
+}
+
+// Implementation describes an MCP client or server.
+type Implementation struct {
+	Name    string `json:"name"`
+	Title   string `json:"title,omitempty"`
+	Version string `json:"version"`
+}
+
+type initializeParams struct {
+	ProtocolVersion string         `json:"protocolVersion"`
+	Capabilities    map[string]any `json:"capabilities"`
+	ClientInfo      Implementation `json:"clientInfo"`
+}
+
+type initializeResult struct {
+	ProtocolVersion string         `json:"protocolVersion"`
+	Capabilities    map[string]any `json:"capabilities"`
+	ServerInfo      Implementation `json:"serverInfo"`
+	Instructions    string         `json:"instructions,omitempty"`
+}
+
+type cancelledParams struct {
+	RequestID json.RawMessage `json:"requestId"`
+	Reason    string          `json:"reason,omitempty"`
+}
+
+type listToolsParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+type listToolsResult struct {
+	Tools      []toolInfo `json:"tools"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+type toolInfo struct {
+	Name        string          `json:"name"`
+	Title       string          `json:"title,omitempty"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+}
+
+type callToolParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+type callToolResult struct {
+	Content           []content       `json:"content"`
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	IsError           bool            `json:"isError,omitempty"`
+}
+
+// content is a content block in a tool result.
+type content struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Data     string `json:"data,omitempty"`
+	MIMEType string `json:"mimeType,omitempty"`
+	URI      string `json:"uri,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Resource *struct {
+		URI      string `json:"uri"`
+		MIMEType string `json:"mimeType,omitempty"`
+		Text     string `json:"text,omitempty"`
+	} `json:"resource,omitempty"`
+}
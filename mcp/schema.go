@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"maragu.dev/gai"
+)
+
+// convertToolSchema converts an MCP tool input schema, which is a JSON Schema object, to a [gai.ToolSchema].
+// See [gai.Schema.UnmarshalJSON] for how keywords gai.Schema has no field for are handled.
+// It fails for values of the wrong type, like a number for a type.
+func convertToolSchema(schema json.RawMessage) (gai.ToolSchema, error) {
+	if len(schema) == 0 {
+		return gai.ToolSchema{}, nil
+	}
+	var s gai.Schema
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return gai.ToolSchema{}, err
+	}
+	return gai.ToolSchema{
+		AdditionalProperties: s.AdditionalProperties,
+		Defs:                 s.Defs,
+		Description:          s.Description,
+		Properties:           s.Properties,
+		Required:             s.Required,
+	}, nil
+}
+
+// summarize renders tool arguments as sorted key=value pairs, truncating long strings.
+func summarize(rawArgs json.RawMessage) string {
+	var args map[string]any
+	if err := json.Unmarshal(rawArgs, &args); err != nil {
+		return "error parsing arguments"
+	}
+
+	var pairs []string
+	for _, key := range slices.Sorted(maps.Keys(args)) {
+		switch v := args[key].(type) {
+		case string:
+			pairs = append(pairs, fmt.Sprintf(`%v="%v"`, key, truncate(v)))
+		default:
+			data, _ := json.Marshal(v)
+			pairs = append(pairs, fmt.Sprintf("%v=%v", key, truncate(string(data))))
+		}
+	}
+	return strings.Join(pairs, " ")
+}
+
+// truncate s to 30 runes for summaries, so multi-byte characters are not split.
+func truncate(s string) string {
+	runes := []rune(s)
+	if len(runes) > 30 {
+		return string(runes[:30]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,317 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+
+	"maragu.dev/gai"
+)
+
+// Server serves a set of [gai.Tool]s as an MCP server, over streams with [Server.Serve] or over
+// the streamable HTTP transport as an [http.Handler].
+//
+// Tool errors are returned to the client as tool results flagged as errors, so the model
+// calling the tool can see what went wrong.
+type Server struct {
+	allowedOrigins []string
+	info           Implementation
+	instructions   string
+	log            *slog.Logger
+	tools          []gai.Tool
+}
+
+type NewServerOptions struct {
+	// AllowedOrigins for browser requests to the HTTP transport, like "https://example.com".
+	// Requests with an Origin header are rejected unless the origin is on the list or matches
+	// the request host, to protect local servers against DNS rebinding.
+	AllowedOrigins []string
+	// Instructions for the client on how to use the server. Optional.
+	Instructions string
+	Log          *slog.Logger
+	// Name of the server, reported to clients. Defaults to "gai".
+	Name  string
+	Tools []gai.Tool
+	// Version of the server, reported to clients. Defaults to "0".
+	Version string
+}
+
+// NewServer for the given tools. Panics if two tools have the same name.
+func NewServer(opts NewServerOptions) *Server {
+	if opts.Log == nil {
+		opts.Log = slog.New(slog.DiscardHandler)
+	}
+	if opts.Name == "" {
+		opts.Name = "gai"
+	}
+	if opts.Version == "" {
+		opts.Version = "0"
+	}
+
+	seen := map[string]bool{}
+	for _, tool := range opts.Tools {
+		if seen[tool.Name] {
+			panic("duplicate tool name " + tool.Name)
+		}
+		seen[tool.Name] = true
+	}
+
+	return &Server{
+		allowedOrigins: opts.AllowedOrigins,
+		info:           Implementation{Name: opts.Name, Version: opts.Version},
+		instructions:   opts.Instructions,
+		log:            opts.Log,
+		tools:          opts.Tools,
+	}
+}
+
+// Serve newline-delimited JSON-RPC messages read from r, writing responses to w, until r is
+// exhausted or ctx is done. This is MCP's stdio transport when r and w are standard input and
+// output. Requests are handled concurrently, and clients can cancel them.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var writeMu sync.Mutex
+	write := func(m message) {
+		data, err := json.Marshal(m)
+		if err != nil {
+			s.log.Info("Error marshalling MCP response", "error", err)
+			return
+		}
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			s.log.Info("Error writing MCP response", "error", err)
+		}
+	}
+
+	var mu sync.Mutex
+	inFlight := map[string]context.CancelFunc{}
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		br := bufio.NewReader(r)
+		for {
+			line, err := br.ReadBytes('\n')
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					err = nil
+				}
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	for {
+		var line []byte
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			return err
+		case line = <-lines:
+		}
+
+		var m message
+		if err := json.Unmarshal(line, &m); err != nil {
+			write(errorMessage(json.RawMessage("null"), codeParseError, "parse error"))
+			continue
+		}
+
+		switch {
+		case m.isRequest():
+			reqCtx, reqCancel := context.WithCancel(ctx)
+			mu.Lock()
+			inFlight[string(m.ID)] = reqCancel
+			mu.Unlock()
+
+			wg.Go(func() {
+				defer func() {
+					mu.Lock()
+					delete(inFlight, string(m.ID))
+					mu.Unlock()
+					reqCancel()
+				}()
+				write(s.handle(reqCtx, m))
+			})
+
+		case m.isNotification():
+			if m.Method == "notifications/cancelled" {
+				var params cancelledParams
+				if err := json.Unmarshal(m.Params, &params); err == nil {
+					mu.Lock()
+					if cancel, ok := inFlight[string(params.RequestID)]; ok {
+						cancel()
+					}
+					mu.Unlock()
+				}
+			}
+
+		case m.isResponse():
+			// The server sends no requests, so there are no responses to wait for.
+
+		default:
+			write(errorMessage(m.ID, codeInvalidRequest, "invalid request"))
+		}
+	}
+}
+
+// ServeHTTP satisfies [http.Handler] with MCP's streamable HTTP transport. The server is
+// stateless: it issues no session IDs, answers every request with a single JSON response, and
+// does not offer a stream for server-initiated messages.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if origin := r.Header.Get("Origin"); origin != "" && !s.allowedOrigin(origin, r.Host) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		http.Error(w, "content type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var m message
+	if err := json.NewDecoder(io.LimitReader(r.Body, 4*1024*1024)).Decode(&m); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorMessage(json.RawMessage("null"), codeParseError, "parse error"))
+		return
+	}
+
+	switch {
+	case m.isRequest():
+		writeJSON(w, http.StatusOK, s.handle(r.Context(), m))
+	case m.isNotification(), m.isResponse():
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		writeJSON(w, http.StatusBadRequest, errorMessage(m.ID, codeInvalidRequest, "invalid request"))
+	}
+}
+
+func (s *Server) allowedOrigin(origin, host string) bool {
+	if slices.Contains(s.allowedOrigins, origin) {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == host
+}
+
+func writeJSON(w http.ResponseWriter, status int, m message) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(m)
+}
+
+// handle a request and return the response.
+func (s *Server) handle(ctx context.Context, req message) message {
+	switch req.Method {
+	case "initialize":
+		var params initializeParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return errorMessage(req.ID, codeInvalidParams, "invalid params: "+err.Error())
+		}
+
+		// Answer with the client's version if supported, and our latest otherwise.
+		version := protocolVersion
+		if slices.Contains(supportedProtocolVersions, params.ProtocolVersion) {
+			version = params.ProtocolVersion
+		}
+
+		s.log.Debug("MCP client connected", "name", params.ClientInfo.Name, "version", params.ClientInfo.Version, "protocolVersion", version)
+
+		return resultMessage(req.ID, initializeResult{
+			ProtocolVersion: version,
+			Capabilities:    map[string]any{"tools": map[string]any{}},
+			ServerInfo:      s.info,
+			Instructions:    s.instructions,
+		})
+
+	case "ping":
+		return resultMessage(req.ID, struct{}{})
+
+	case "tools/list":
+		tools := []toolInfo{}
+		for _, tool := range s.tools {
+			schema, err := json.Marshal(tool.Schema.Schema())
+			if err != nil {
+				return errorMessage(req.ID, codeInternalError, "error marshalling input schema of tool "+tool.Name+": "+err.Error())
+			}
+			tools = append(tools, toolInfo{
+				Name:        tool.Name,
+				Description: tool.Description,
+				InputSchema: schema,
+			})
+		}
+		return resultMessage(req.ID, listToolsResult{Tools: tools})
+
+	case "tools/call":
+		var params callToolParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return errorMessage(req.ID, codeInvalidParams, "invalid params: "+err.Error())
+		}
+
+		i := slices.IndexFunc(s.tools, func(tool gai.Tool) bool {
+			return tool.Name == params.Name
+		})
+		if i < 0 {
+			return errorMessage(req.ID, codeInvalidParams, "unknown tool "+params.Name)
+		}
+
+		args := params.Arguments
+		if len(args) == 0 {
+			args = json.RawMessage(`{}`)
+		}
+
+		result, err := s.tools[i].Execute(ctx, args)
+		if err != nil {
+			s.log.Debug("MCP tool call failed", "tool", params.Name, "error", err)
+			return resultMessage(req.ID, callToolResult{
+				Content: []content{{Type: "text", Text: err.Error()}},
+				IsError: true,
+			})
+		}
+		return resultMessage(req.ID, callToolResult{
+			Content: []content{{Type: "text", Text: result}},
+		})
+
+	default:
+		return errorMessage(req.ID, codeMethodNotFound, "method not found: "+req.Method)
+	}
+}
+
+func resultMessage(id json.RawMessage, result any) message {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return errorMessage(id, codeInternalError, "error marshalling result: "+err.Error())
+	}
+	return message{JSONRPC: "2.0", ID: id, Result: data}
+}
+
+func errorMessage(id json.RawMessage, code int, msg string) message {
+	return message{JSONRPC: "2.0", ID: id, Error: &RPCError{Code: code, Message: msg}}
+}
@@ -0,0 +1,198 @@
+package mcp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/mcp"
+	"maragu.dev/gai/tools"
+)
+
+type greetArgs struct {
+	Name string `json:"name" jsonschema_description:"Who to greet."`
+}
+
+func newTestTools() []gai.Tool {
+	return []gai.Tool{
+		{
+			Name:        "greet",
+			Description: "Greet someone.",
+			Schema:      gai.GenerateToolSchema[greetArgs](),
+			Execute: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
+				var args greetArgs
+				if err := json.Unmarshal(rawArgs, &args); err != nil {
+					return "", err
+				}
+				return "Hello, " + args.Name + "!", nil
+			},
+		},
+		{
+			Name:        "fail",
+			Description: "Always fails.",
+			Execute: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
+				return "", errors.New("no luck")
+			},
+		},
+		{
+			Name:        "wait",
+			Description: "Waits until cancelled.",
+			Execute: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
+				<-ctx.Done()
+				return "", ctx.Err()
+			},
+		},
+		tools.NewGetTime(func() time.Time {
+			return time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+		}),
+	}
+}
+
+func TestServer_Serve(t *testing.T) {
+	t.Run("serves tools to a client over streams", func(t *testing.T) {
+		c := newServerClient(t, mcp.NewServerOptions{Name: "test", Version: "1.2.3", Tools: newTestTools()})
+
+		is.Equal(t, "test", c.ServerInfo().Name)
+		is.Equal(t, "1.2.3", c.ServerInfo().Version)
+
+		ts, err := c.Tools(t.Context())
+		is.NotError(t, err)
+		is.Equal(t, 4, len(ts))
+		is.Equal(t, "greet", ts[0].Name)
+		is.Equal(t, "Greet someone.", ts[0].Description)
+		is.Equal(t, gai.SchemaTypeString, ts[0].Schema.Properties["name"].Type)
+		is.Equal(t, "Who to greet.", ts[0].Schema.Properties["name"].Description)
+		is.EqualSlice(t, []string{"name"}, ts[0].Schema.Required)
+
+		result, err := ts[0].Execute(t.Context(), json.RawMessage(`{"name":"Gopher"}`))
+		is.NotError(t, err)
+		is.Equal(t, "Hello, Gopher!", result)
+
+		result, err = ts[3].Execute(t.Context(), json.RawMessage(`{}`))
+		is.NotError(t, err)
+		is.Equal(t, "2026-10-18T12:00:00Z", result)
+	})
+
+	t.Run("returns tool errors as error results", func(t *testing.T) {
+		c := newServerClient(t, mcp.NewServerOptions{Tools: newTestTools()})
+
+		_, err := c.CallTool(t.Context(), "fail", nil)
+		is.True(t, err != nil)
+		is.Equal(t, "no luck", err.Error())
+	})
+
+	t.Run("returns an invalid params error for unknown tools", func(t *testing.T) {
+		c := newServerClient(t, mcp.NewServerOptions{Tools: newTestTools()})
+
+		_, err := c.CallTool(t.Context(), "nope", nil)
+		var rpcErr *mcp.RPCError
+		is.True(t, errors.As(err, &rpcErr))
+		is.Equal(t, -32602, rpcErr.Code)
+	})
+
+	t.Run("handles requests concurrently", func(t *testing.T) {
+		c := newServerClient(t, mcp.NewServerOptions{Tools: newTestTools()})
+
+		ctx, cancel := context.WithCancel(t.Context())
+		done := make(chan error)
+		go func() {
+			_, err := c.CallTool(ctx, "wait", nil)
+			done <- err
+		}()
+
+		result, err := c.CallTool(t.Context(), "greet", json.RawMessage(`{"name":"you"}`))
+		is.NotError(t, err)
+		is.Equal(t, "Hello, you!", result)
+
+		cancel()
+		is.True(t, errors.Is(<-done, context.Canceled))
+	})
+}
+
+func TestServer_ServeHTTP(t *testing.T) {
+	t.Run("serves tools to a client over HTTP", func(t *testing.T) {
+		srv := httptest.NewServer(mcp.NewServer(mcp.NewServerOptions{Tools: newTestTools()}))
+		t.Cleanup(srv.Close)
+
+		c, err := mcp.NewHTTPClient(t.Context(), mcp.NewHTTPClientOptions{URL: srv.URL})
+		is.NotError(t, err)
+		t.Cleanup(func() {
+			_ = c.Close()
+		})
+
+		ts, err := c.Tools(t.Context())
+		is.NotError(t, err)
+
+		result, err := ts[0].Execute(t.Context(), json.RawMessage(`{"name":"HTTP"}`))
+		is.NotError(t, err)
+		is.Equal(t, "Hello, HTTP!", result)
+	})
+
+	t.Run("rejects foreign origins", func(t *testing.T) {
+		srv := httptest.NewServer(mcp.NewServer(mcp.NewServerOptions{
+			AllowedOrigins: []string{"https://example.com"},
+		}))
+		t.Cleanup(srv.Close)
+
+		post := func(origin string) int {
+			req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+			is.NotError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Origin", origin)
+			res, err := http.DefaultClient.Do(req)
+			is.NotError(t, err)
+			_ = res.Body.Close()
+			return res.StatusCode
+		}
+
+		is.Equal(t, http.StatusForbidden, post("https://evil.example"))
+		is.Equal(t, http.StatusOK, post("https://example.com"))
+		is.Equal(t, http.StatusOK, post(srv.URL))
+	})
+
+	t.Run("accepts notifications and rejects other methods", func(t *testing.T) {
+		h := mcp.NewServer(mcp.NewServerOptions{})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+		r.Header.Set("Content-Type", "application/json")
+		h.ServeHTTP(w, r)
+		is.Equal(t, http.StatusAccepted, w.Code)
+
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		is.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
+
+func newServerClient(t *testing.T, opts mcp.NewServerOptions) *mcp.Client {
+	t.Helper()
+
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+
+	s := mcp.NewServer(opts)
+	served := make(chan error)
+	go func() {
+		served <- s.Serve(context.Background(), serverR, serverW)
+		_ = serverW.Close()
+	}()
+
+	c, err := mcp.NewClient(t.Context(), mcp.NewClientOptions{Reader: clientR, Writer: clientW})
+	is.NotError(t, err)
+	t.Cleanup(func() {
+		is.NotError(t, c.Close())
+		// Serve returns once the client is gone and all requests, including cancelled ones, are done.
+		is.NotError(t, <-served)
+	})
+	return c
+}
@@ -0,0 +1,316 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"sync"
+
+	"maragu.dev/gai/internal/sse"
+)
+
+// transport carries JSON-RPC messages to an MCP server.
+type transport interface {
+	// call sends a request and waits for the response with the same ID.
+	call(ctx context.Context, req message) (message, error)
+	// notify sends a notification, which gets no response.
+	notify(ctx context.Context, n message) error
+	close() error
+}
+
+// streamTransport speaks newline-delimited JSON-RPC over a pair of streams, as in MCP's stdio
+// transport. A goroutine reads messages and routes responses to waiting calls by ID.
+type streamTransport struct {
+	closer  func() error
+	done    chan struct{}
+	err     error
+	log     *slog.Logger
+	mu      sync.Mutex
+	pending map[string]chan message
+	w       io.Writer
+	writeMu sync.Mutex
+}
+
+func newStreamTransport(r io.Reader, w io.Writer, closer func() error, log *slog.Logger) *streamTransport {
+	t := &streamTransport{
+		closer:  closer,
+		done:    make(chan struct{}),
+		log:     log,
+		pending: map[string]chan message{},
+		w:       w,
+	}
+	go t.read(r)
+	return t
+}
+
+func (t *streamTransport) read(r io.Reader) {
+	br := bufio.NewReader(r)
+	var err error
+	for {
+		var line []byte
+		line, err = br.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			t.handle(line)
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	if errors.Is(err, io.EOF) {
+		err = errors.New("connection closed")
+	}
+	t.mu.Lock()
+	t.err = err
+	t.mu.Unlock()
+	close(t.done)
+}
+
+func (t *streamTransport) handle(line []byte) {
+	var m message
+	if err := json.Unmarshal(line, &m); err != nil {
+		t.log.Debug("Error parsing MCP message", "error", err)
+		return
+	}
+
+	switch {
+	case m.isResponse():
+		t.mu.Lock()
+		ch, ok := t.pending[string(m.ID)]
+		delete(t.pending, string(m.ID))
+		t.mu.Unlock()
+		if ok {
+			ch <- m
+		}
+
+	case m.isRequest():
+		res := replyToServerRequest(m)
+		if err := t.write(res); err != nil {
+			t.log.Debug("Error replying to MCP server request", "method", m.Method, "error", err)
+		}
+
+	case m.isNotification():
+		t.log.Debug("Received MCP notification", "method", m.Method)
+	}
+}
+
+func (t *streamTransport) write(m message) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	_, err = t.w.Write(data)
+	return err
+}
+
+func (t *streamTransport) call(ctx context.Context, req message) (message, error) {
+	ch := make(chan message, 1)
+	t.mu.Lock()
+	t.pending[string(req.ID)] = ch
+	t.mu.Unlock()
+
+	// unregister the call if it does not complete
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, string(req.ID))
+		t.mu.Unlock()
+	}()
+
+	if err := t.write(req); err != nil {
+		return message{}, err
+	}
+
+	select {
+	case res := <-ch:
+		return res, nil
+	case <-t.done:
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return message{}, t.err
+	case <-ctx.Done():
+		// Tell the server to stop working on the request, since nobody waits for the result.
+		params, _ := json.Marshal(cancelledParams{RequestID: req.ID, Reason: ctx.Err().Error()})
+		if err := t.write(message{JSONRPC: "2.0", Method: "notifications/cancelled", Params: params}); err != nil {
+			t.log.Debug("Error cancelling MCP request", "error", err)
+		}
+		return message{}, ctx.Err()
+	}
+}
+
+func (t *streamTransport) notify(ctx context.Context, n message) error {
+	return t.write(n)
+}
+
+func (t *streamTransport) close() error {
+	if t.closer == nil {
+		return nil
+	}
+	return t.closer()
+}
+
+// httpTransport speaks MCP's streamable HTTP transport: every message is POSTed to a single
+// endpoint, and the server answers with either a JSON body or a server-sent event stream.
+type httpTransport struct {
+	client    *http.Client
+	headers   map[string]string
+	log       *slog.Logger
+	mu        sync.Mutex
+	sessionID string
+	url       string
+}
+
+func (t *httpTransport) post(ctx context.Context, m message) (*http.Response, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	t.setHeaders(req, m.Method != "initialize")
+
+	res, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if id := res.Header.Get("Mcp-Session-Id"); id != "" {
+		t.mu.Lock()
+		t.sessionID = id
+		t.mu.Unlock()
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		defer func() {
+			_ = res.Body.Close()
+		}()
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 64*1024))
+		return nil, fmt.Errorf("POST %v: %v: %s", t.url, res.Status, bytes.TrimSpace(body))
+	}
+
+	return res, nil
+}
+
+// setHeaders sets the custom headers and, once initialized, the session and protocol headers.
+func (t *httpTransport) setHeaders(req *http.Request, initialized bool) {
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	if !initialized {
+		return
+	}
+	req.Header.Set("Mcp-Protocol-Version", protocolVersion)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", t.sessionID)
+	}
+}
+
+func (t *httpTransport) call(ctx context.Context, req message) (message, error) {
+	res, err := t.post(ctx, req)
+	if err != nil {
+		return message{}, err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		var m message
+		if err := json.NewDecoder(res.Body).Decode(&m); err != nil {
+			return message{}, fmt.Errorf("error parsing response: %w", err)
+		}
+		return m, nil
+
+	case "text/event-stream":
+		for event, err := range sse.Read(res.Body) {
+			if err != nil {
+				return message{}, err
+			}
+
+			var m message
+			if err := json.Unmarshal(event.Data, &m); err != nil {
+				t.log.Debug("Error parsing MCP message", "error", err)
+				continue
+			}
+
+			switch {
+			case m.isResponse() && bytes.Equal(m.ID, req.ID):
+				return m, nil
+
+			case m.isRequest():
+				// Replies to requests on the stream are POSTed back, and get no response body.
+				reply, err := t.post(ctx, replyToServerRequest(m))
+				if err != nil {
+					t.log.Debug("Error replying to MCP server request", "method", m.Method, "error", err)
+					continue
+				}
+				_ = reply.Body.Close()
+
+			case m.isNotification():
+				t.log.Debug("Received MCP notification", "method", m.Method)
+			}
+		}
+		return message{}, errors.New("event stream ended without a response")
+
+	default:
+		return message{}, fmt.Errorf("unexpected content type %q", res.Header.Get("Content-Type"))
+	}
+}
+
+func (t *httpTransport) notify(ctx context.Context, n message) error {
+	res, err := t.post(ctx, n)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// close ends the session on the server, if there is one.
+func (t *httpTransport) close() error {
+	t.mu.Lock()
+	sessionID := t.sessionID
+	t.mu.Unlock()
+	if sessionID == "" {
+		return nil
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, t.url, nil)
+	if err != nil {
+		return err
+	}
+	t.setHeaders(req, true)
+
+	res, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// replyToServerRequest answers requests from the server. Only ping is supported, because the
+// client declares no capabilities.
+func replyToServerRequest(req message) message {
+	if req.Method == "ping" {
+		return message{JSONRPC: "2.0", ID: req.ID, Result: json.RawMessage(`{}`)}
+	}
+	return message{JSONRPC: "2.0", ID: req.ID, Error: &RPCError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}}
+}
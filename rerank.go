@@ -0,0 +1,31 @@
+package gai
+
+import (
+	"context"
+)
+
+// RerankRequest for [Reranker].
+type RerankRequest struct {
+	Query     string
+	Documents []string
+}
+
+// RerankResult is the relevance of a single document to the query.
+type RerankResult struct {
+	// Index of the document in [RerankRequest.Documents].
+	Index int
+	// Score of the document, higher is more relevant. The range depends on the reranker.
+	Score float64
+}
+
+// RerankResponse for [Reranker].
+type RerankResponse struct {
+	// Results for all documents, most relevant first.
+	Results []RerankResult
+}
+
+// Reranker is satisfied by models and other implementations scoring documents by relevance to a query,
+// typically to reorder the candidates from a vector search.
+type Reranker interface {
+	Rerank(ctx context.Context, req RerankRequest) (RerankResponse, error)
+}
@@ -0,0 +1,125 @@
+package rerank
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/vector"
+)
+
+// Embedding reranks documents by the cosine similarity of their embeddings to the query embedding.
+// The query is embedded with [gai.EmbedTaskSearchQuery] and the documents with [gai.EmbedTaskDocument].
+// Construct with [NewEmbedding].
+type Embedding[T gai.VectorComponent] struct {
+	concurrency    int
+	embedder       gai.Embedder[T]
+	formatDocument func(content string) string
+	formatQuery    func(query string) string
+	tracer         trace.Tracer
+}
+
+// NewEmbeddingOptions for [NewEmbedding].
+type NewEmbeddingOptions[T gai.VectorComponent] struct {
+	// Embedder for the query and documents. Required.
+	Embedder gai.Embedder[T]
+	// FormatDocument formats a document before embedding it, for embedding models that expect documents
+	// in a format their embedder doesn't apply from the task, like a prefix. Defaults to the document as is.
+	FormatDocument func(content string) string
+	// FormatQuery formats the query before embedding it, like FormatDocument. Defaults to the query as is.
+	FormatQuery func(query string) string
+	// Concurrency is the maximum number of embeddings made at the same time. Defaults to 4.
+	Concurrency int
+}
+
+// NewEmbedding with the given options. Panics if the embedder is nil.
+func NewEmbedding[T gai.VectorComponent](opts NewEmbeddingOptions[T]) *Embedding[T] {
+	if opts.Embedder == nil {
+		panic("embedder cannot be nil")
+	}
+	if opts.FormatDocument == nil {
+		opts.FormatDocument = func(content string) string {
+			return content
+		}
+	}
+	if opts.FormatQuery == nil {
+		opts.FormatQuery = func(query string) string {
+			return query
+		}
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	return &Embedding[T]{
+		concurrency:    opts.Concurrency,
+		embedder:       opts.Embedder,
+		formatDocument: opts.FormatDocument,
+		formatQuery:    opts.FormatQuery,
+		tracer:         newTracer(),
+	}
+}
+
+// Rerank satisfies [gai.Reranker]. Scores are cosine similarities, from -1 to 1.
+func (e *Embedding[T]) Rerank(ctx context.Context, req gai.RerankRequest) (gai.RerankResponse, error) {
+	ctx, span := startSpan(ctx, e.tracer, "rerank.embedding", req)
+	defer span.End()
+
+	if err := validate(req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request")
+		return gai.RerankResponse{}, err
+	}
+	if len(req.Documents) == 0 {
+		return gai.RerankResponse{}, nil
+	}
+
+	// The query embedding is the last one
+	embeddings := make([][]T, len(req.Documents)+1)
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(e.concurrency)
+	for i := range embeddings {
+		eg.Go(func() error {
+			embedReq := gai.EmbedRequest{Parts: []gai.Part{gai.TextPart(e.formatQuery(req.Query))}, Task: gai.EmbedTaskSearchQuery}
+			if i < len(req.Documents) {
+				embedReq = gai.EmbedRequest{Parts: []gai.Part{gai.TextPart(e.formatDocument(req.Documents[i]))}, Task: gai.EmbedTaskDocument}
+			}
+
+			res, err := e.embedder.Embed(egCtx, embedReq)
+			if err != nil {
+				if i < len(req.Documents) {
+					return fmt.Errorf("error embedding document %d: %w", i, err)
+				}
+				return fmt.Errorf("error embedding query: %w", err)
+			}
+			embeddings[i] = res.Embedding
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "embedding failed")
+		return gai.RerankResponse{}, err
+	}
+
+	query := embeddings[len(req.Documents)]
+	results := make([]gai.RerankResult, len(req.Documents))
+	for i := range results {
+		if len(embeddings[i]) != len(query) {
+			err := fmt.Errorf("embedding of document %d has %d dimensions, but the query embedding has %d", i, len(embeddings[i]), len(query))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "invalid embeddings")
+			return gai.RerankResponse{}, err
+		}
+		results[i] = gai.RerankResult{Index: i, Score: vector.Cosine(query, embeddings[i])}
+	}
+	sortResults(results)
+
+	return gai.RerankResponse{Results: results}, nil
+}
+
+var _ gai.Reranker = (*Embedding[float64])(nil)
@@ -0,0 +1,50 @@
+package rerank_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/gaitest"
+	"maragu.dev/gai/rerank"
+)
+
+func TestEmbedding_Rerank(t *testing.T) {
+	t.Run("scores documents by cosine similarity to the query", func(t *testing.T) {
+		embedder := gaitest.NewEmbedder[float32](64)
+		r := rerank.NewEmbedding(rerank.NewEmbeddingOptions[float32]{
+			Embedder: embedder,
+			FormatQuery: func(query string) string {
+				return "doc: " + query
+			},
+			FormatDocument: func(content string) string {
+				return "doc: " + content
+			},
+		})
+
+		// The fake embedder embeds equal texts to equal vectors, so the formatted query matches the second document
+		res, err := r.Rerank(t.Context(), gai.RerankRequest{Query: "Cats eat mice.", Documents: []string{"Dogs bark.", "Cats eat mice."}})
+		is.NotError(t, err)
+		is.Equal(t, 2, len(res.Results))
+		is.Equal(t, 1, res.Results[0].Index)
+		is.True(t, res.Results[0].Score > 0.999)
+		is.Equal(t, 0, res.Results[1].Index)
+		is.Equal(t, 3, len(embedder.Requests()))
+	})
+
+	t.Run("returns error if embedding fails", func(t *testing.T) {
+		r := rerank.NewEmbedding(rerank.NewEmbeddingOptions[float32]{Embedder: &failingEmbedder{}})
+
+		_, err := r.Rerank(t.Context(), gai.RerankRequest{Query: "cats", Documents: []string{"a"}})
+		is.True(t, err != nil)
+	})
+}
+
+type failingEmbedder struct{}
+
+func (f *failingEmbedder) Embed(ctx context.Context, req gai.EmbedRequest) (gai.EmbedResponse[float32], error) {
+	return gai.EmbedResponse[float32]{}, errors.New("oh no")
+}
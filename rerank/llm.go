@@ -0,0 +1,129 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"maragu.dev/gai"
+)
+
+// LLM reranks documents by asking a [gai.ChatCompleter] to score their relevance to the query
+// from 0 to 10, with a response schema. Scores are scaled to the range 0 to 1.
+// Construct with [NewLLM].
+type LLM struct {
+	completer    gai.ChatCompleter
+	instructions string
+	tracer       trace.Tracer
+}
+
+// NewLLMOptions for [NewLLM].
+type NewLLMOptions struct {
+	// Completer to score the documents with. It must support response schemas. Required.
+	Completer gai.ChatCompleter
+	// Instructions, if not empty, are added to the system prompt, to describe what makes a document relevant.
+	Instructions string
+}
+
+// NewLLM with the given options. Panics if the completer is nil.
+func NewLLM(opts NewLLMOptions) *LLM {
+	if opts.Completer == nil {
+		panic("completer cannot be nil")
+	}
+
+	return &LLM{
+		completer:    opts.Completer,
+		instructions: opts.Instructions,
+		tracer:       newTracer(),
+	}
+}
+
+// llmScores is the structured output of the model.
+type llmScores struct {
+	Scores []llmScore `json:"scores" jsonschema_description:"A score for each document."`
+}
+
+type llmScore struct {
+	Index int     `json:"index" jsonschema_description:"The index of the document."`
+	Score float64 `json:"score" jsonschema:"minimum=0,maximum=10" jsonschema_description:"How relevant the document is to the query, from 0 (irrelevant) to 10 (perfectly relevant)."`
+}
+
+// Rerank satisfies [gai.Reranker].
+// Documents the model doesn't score get a score of 0, and scores for unknown documents are ignored.
+func (l *LLM) Rerank(ctx context.Context, req gai.RerankRequest) (gai.RerankResponse, error) {
+	ctx, span := startSpan(ctx, l.tracer, "rerank.llm", req)
+	defer span.End()
+
+	if err := validate(req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request")
+		return gai.RerankResponse{}, err
+	}
+	if len(req.Documents) == 0 {
+		return gai.RerankResponse{}, nil
+	}
+
+	system := "You are a search relevance expert. Score how relevant each document is to the query, " +
+		"from 0 (irrelevant) to 10 (perfectly relevant). Judge each document on its own, by how well it answers " +
+		"or matches the query, and score every document exactly once."
+	if l.instructions != "" {
+		system += "\n\n" + l.instructions
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("<query>\n" + req.Query + "\n</query>\n")
+	for i, doc := range req.Documents {
+		fmt.Fprintf(&prompt, "\n<document index=\"%d\">\n%v\n</document>\n", i, doc)
+	}
+
+	res, err := l.completer.ChatComplete(ctx, gai.ChatCompleteRequest{
+		Messages:       []gai.Message{gai.NewUserTextMessage(prompt.String())},
+		ResponseSchema: gai.Ptr(gai.GenerateSchema[llmScores]()),
+		System:         &system,
+		Temperature:    gai.Ptr(gai.Temperature(0)),
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "chat completion failed")
+		return gai.RerankResponse{}, fmt.Errorf("error scoring documents: %w", err)
+	}
+
+	var text strings.Builder
+	for part, err := range res.Parts() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "chat completion failed")
+			return gai.RerankResponse{}, fmt.Errorf("error reading response parts: %w", err)
+		}
+		if part.Type == gai.PartTypeText {
+			text.WriteString(part.Text())
+		}
+	}
+
+	var scores llmScores
+	if err := json.Unmarshal([]byte(text.String()), &scores); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid scores")
+		return gai.RerankResponse{}, fmt.Errorf("error unmarshaling scores from JSON: %w", err)
+	}
+
+	results := make([]gai.RerankResult, len(req.Documents))
+	for i := range results {
+		results[i].Index = i
+	}
+	for _, s := range scores.Scores {
+		if s.Index < 0 || s.Index >= len(results) {
+			continue
+		}
+		results[s.Index].Score = min(max(s.Score, 0), 10) / 10
+	}
+	sortResults(results)
+
+	return gai.RerankResponse{Results: results}, nil
+}
+
+var _ gai.Reranker = (*LLM)(nil)
@@ -0,0 +1,74 @@
+package rerank_test
+
+import (
+	"strings"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/gaitest"
+	"maragu.dev/gai/internal/oteltest"
+	"maragu.dev/gai/rerank"
+)
+
+func TestLLM_Rerank(t *testing.T) {
+	t.Run("scores documents with the completer and sorts them by score", func(t *testing.T) {
+		sr := oteltest.NewSpanRecorder(t)
+
+		cc := gaitest.NewChatCompleter(gaitest.Text(`{"scores":[{"index":0,"score":2},{"index":1,"score":9},{"index":2,"score":5},{"index":7,"score":10}]}`))
+		r := rerank.NewLLM(rerank.NewLLMOptions{Completer: cc, Instructions: "Prefer documents about food."})
+
+		res, err := r.Rerank(t.Context(), gai.RerankRequest{
+			Query:     "What do cats eat?",
+			Documents: []string{"Dogs bark.", "Cats eat mice.", "Cats sleep a lot."},
+		})
+		is.NotError(t, err)
+		is.EqualSlice(t, []gai.RerankResult{{Index: 1, Score: 0.9}, {Index: 2, Score: 0.5}, {Index: 0, Score: 0.2}}, res.Results)
+
+		req := cc.Requests()[0]
+		is.True(t, req.ResponseSchema != nil)
+		is.True(t, strings.HasSuffix(*req.System, "Prefer documents about food."))
+		prompt := req.Messages[0].Parts[0].Text()
+		is.True(t, strings.Contains(prompt, "<query>\nWhat do cats eat?\n</query>"))
+		is.True(t, strings.Contains(prompt, "<document index=\"1\">\nCats eat mice.\n</document>"))
+
+		span := oteltest.FindSpan(t, sr.Ended(), "rerank.llm")
+		oteltest.RequirePositiveIntAttribute(t, span.Attributes(), "ai.document_count")
+	})
+
+	t.Run("scores documents the model leaves out as 0", func(t *testing.T) {
+		cc := gaitest.NewChatCompleter(gaitest.Text(`{"scores":[{"index":1,"score":3}]}`))
+		r := rerank.NewLLM(rerank.NewLLMOptions{Completer: cc})
+
+		res, err := r.Rerank(t.Context(), gai.RerankRequest{Query: "cats", Documents: []string{"a", "b"}})
+		is.NotError(t, err)
+		is.EqualSlice(t, []gai.RerankResult{{Index: 1, Score: 0.3}, {Index: 0, Score: 0}}, res.Results)
+	})
+
+	t.Run("returns error for invalid JSON", func(t *testing.T) {
+		cc := gaitest.NewChatCompleter(gaitest.Text(`not JSON`))
+		r := rerank.NewLLM(rerank.NewLLMOptions{Completer: cc})
+
+		_, err := r.Rerank(t.Context(), gai.RerankRequest{Query: "cats", Documents: []string{"a"}})
+		is.True(t, err != nil)
+		is.True(t, strings.HasPrefix(err.Error(), "error unmarshaling scores from JSON: "))
+	})
+
+	t.Run("returns no results without calling the completer for no documents", func(t *testing.T) {
+		cc := gaitest.NewChatCompleter()
+		r := rerank.NewLLM(rerank.NewLLMOptions{Completer: cc})
+
+		res, err := r.Rerank(t.Context(), gai.RerankRequest{Query: "cats"})
+		is.NotError(t, err)
+		is.Equal(t, 0, len(res.Results))
+		is.Equal(t, 0, len(cc.Requests()))
+	})
+
+	t.Run("returns a validation error for an empty query", func(t *testing.T) {
+		r := rerank.NewLLM(rerank.NewLLMOptions{Completer: gaitest.NewChatCompleter()})
+
+		_, err := r.Rerank(t.Context(), gai.RerankRequest{Documents: []string{"a"}})
+		is.Equal(t, "invalid Query: empty query", err.Error())
+	})
+}
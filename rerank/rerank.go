@@ -0,0 +1,51 @@
+// Package rerank provides [gai.Reranker] implementations, for reordering the candidates from a vector search
+// by how relevant they are to the query.
+//
+// [LLM] asks any [gai.ChatCompleter] to score the documents with structured output. It's the most flexible,
+// since relevance can be described in instructions, but also the slowest and most expensive. [Embedding]
+// scores documents by the cosine similarity of their embeddings to the query embedding, which is useful to
+// rerank candidates found with a cheaper or smaller embedding model. Provider rerank endpoints, like the
+// one in clients/cohere, satisfy the same interface.
+package rerank
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"maragu.dev/gai"
+)
+
+// newTracer for the rerankers, cached when they're constructed like in the clients.
+func newTracer() trace.Tracer {
+	return otel.Tracer("maragu.dev/gai/rerank")
+}
+
+// startSpan for a rerank request, with the standard attributes.
+func startSpan(ctx context.Context, tracer trace.Tracer, name string, req gai.RerankRequest) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name,
+		trace.WithAttributes(
+			attribute.Int("ai.document_count", len(req.Documents)),
+			attribute.Int("ai.query_length", len(req.Query)),
+		),
+	)
+}
+
+// validate the request.
+func validate(req gai.RerankRequest) error {
+	if req.Query == "" {
+		return gai.NewValidationError("Query", "empty query")
+	}
+	return nil
+}
+
+// sortResults by score, most relevant first, keeping the document order for equal scores.
+func sortResults(results []gai.RerankResult) {
+	slices.SortStableFunc(results, func(a, b gai.RerankResult) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+}
@@ -432,6 +432,27 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 		is.Equal(t, 1, secondary.calls)
 	})
 
+	t.Run("falls back without retrying when the default classifier gets a validation error", func(t *testing.T) {
+		validationErr := gai.NewValidationError("Messages[0].Parts[0].MIMEType", "unsupported MIME type")
+		primary := newFakeChatCompleter(t, "primary", []fakeResponse{{preStreamErr: validationErr}})
+		secondary := newFakeChatCompleter(t, "secondary", []fakeResponse{{parts: []gai.Part{gai.TextPart("from secondary")}}})
+
+		cc := robust.NewChatCompleter(robust.NewChatCompleterOptions{
+			Completers: []gai.ChatCompleter{primary, secondary},
+			BaseDelay:  time.Nanosecond,
+			MaxDelay:   time.Nanosecond,
+		})
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{})
+		is.NotError(t, err)
+		parts, err := collectParts(t, res)
+		is.NotError(t, err)
+		is.Equal(t, 1, len(parts))
+		is.Equal(t, "from secondary", parts[0].Text())
+		is.Equal(t, 1, primary.calls)
+		is.Equal(t, 1, secondary.calls)
+	})
+
 	t.Run("passes a mid-stream error through to the caller without retrying", func(t *testing.T) {
 		midStreamErr := errors.New("glitter ran out mid-sentence")
 		primary := newFakeChatCompleter(t, "primary", []fakeResponse{{
@@ -6,14 +6,18 @@ import (
 	"net/http"
 	"regexp"
 	"strconv"
+
+	"maragu.dev/gai"
 )
 
 // defaultErrorClassifier is used when [NewChatCompleterOptions.ErrorClassifier] or
 // [NewEmbedderOptions.ErrorClassifier] is nil.
 // It applies these rules in order:
 //  1. [context.Canceled] and [context.DeadlineExceeded] → [ActionFail].
-//  2. A 4xx/5xx HTTP status code found in the error string classifies by status.
-//  3. Anything else → [ActionRetry] (optimistic default).
+//  2. A [gai.ValidationError] → [ActionFallback], since retrying the same request fails the same way,
+//     but the next client may support what this one rejected, such as a MIME type or ThinkingLevel.
+//  3. A 4xx/5xx HTTP status code found in the error string classifies by status.
+//  4. Anything else → [ActionRetry] (optimistic default).
 //
 // The string-inspection step is best-effort; callers who want precise, SDK-aware behavior
 // should supply their own [ErrorClassifierFunc]. See issue #210 for a planned gai-native
@@ -23,6 +27,11 @@ func defaultErrorClassifier(err error) Action {
 		return ActionFail
 	}
 
+	var validationErr *gai.ValidationError
+	if errors.As(err, &validationErr) {
+		return ActionFallback
+	}
+
 	if code, ok := findStatusCode(err.Error()); ok {
 		return classifyStatus(code)
 	}
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"maragu.dev/is"
+
+	"maragu.dev/gai"
 )
 
 func TestDefaultErrorClassifier(t *testing.T) {
@@ -19,6 +21,8 @@ func TestDefaultErrorClassifier(t *testing.T) {
 		{"context.Canceled fails", context.Canceled, ActionFail},
 		{"context.DeadlineExceeded fails", context.DeadlineExceeded, ActionFail},
 		{"wrapped context.Canceled fails", fmt.Errorf("outer: %w", context.Canceled), ActionFail},
+		{"validation error falls back", gai.NewValidationError("Messages", "no messages"), ActionFallback},
+		{"wrapped validation error with 500 in field falls back", fmt.Errorf("outer: %w", gai.NewValidationError("Messages[500].Role", "unknown role")), ActionFallback},
 		{"string with 429 retries", errors.New("got HTTP 429 from provider"), ActionRetry},
 		{"string with 503 retries", errors.New("status 503 service unavailable"), ActionRetry},
 		{"string with 401 falls back", errors.New("401 unauthorized: bad key"), ActionFallback},
@@ -0,0 +1,613 @@
+package gai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SchemaViolation is a single way a value does not match a [Schema].
+type SchemaViolation struct {
+	// Path to the offending value, like "$.tags[2]". The root value is "$".
+	Path string
+	// Message describes the violation.
+	Message string
+}
+
+// SchemaError is returned by [Schema.Validate] and lists every violation found, so a model
+// can fix all of its mistakes in one go.
+type SchemaError struct {
+	Violations []SchemaViolation
+}
+
+// Error satisfies [error].
+func (e *SchemaError) Error() string {
+	var b strings.Builder
+	for i, v := range e.Violations {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(v.Path)
+		b.WriteString(": ")
+		b.WriteString(v.Message)
+	}
+	return b.String()
+}
+
+// Validate the JSON document data against the schema. It checks type, nullable, required properties,
+// additional properties, enum, const, minimum and maximum, minLength, maxLength, and pattern,
+// minItems and maxItems, allOf, anyOf, oneOf, and references to [Schema.Defs], and returns a
+// [*SchemaError] listing every violation, or nil if data is valid. Keywords it does not check,
+// like format, are ignored.
+//
+// A null value of a property that is not required is treated as if the property was absent,
+// because models often send null for optional arguments.
+func (s *Schema) Validate(data json.RawMessage) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return &SchemaError{Violations: []SchemaViolation{{Path: "$", Message: "invalid JSON: " + err.Error()}}}
+	}
+
+	var violations []SchemaViolation
+	s.validate(s, "$", v, 0, &violations)
+	if len(violations) > 0 {
+		return &SchemaError{Violations: violations}
+	}
+	return nil
+}
+
+// maxValidationDepth guards against references that loop without consuming any data.
+const maxValidationDepth = 1000
+
+func (s *Schema) validate(root *Schema, path string, v any, depth int, violations *[]SchemaViolation) {
+	add := func(format string, args ...any) {
+		*violations = append(*violations, SchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if depth > maxValidationDepth {
+		add("schema is nested too deeply")
+		return
+	}
+	depth++
+
+	if v == nil && s.Nullable {
+		return
+	}
+
+	if s.Ref != "" {
+		target := root.resolve(s.Ref)
+		if target == nil {
+			add("unknown schema reference %v", s.Ref)
+			return
+		}
+		target.validate(root, path, v, depth, violations)
+	}
+
+	for _, sub := range s.AllOf {
+		sub.validate(root, path, v, depth, violations)
+	}
+
+	matches := func(sub *Schema) bool {
+		var subViolations []SchemaViolation
+		sub.validate(root, path, v, depth, &subViolations)
+		return len(subViolations) == 0
+	}
+
+	if len(s.AnyOf) > 0 && !slices.ContainsFunc(s.AnyOf, matches) {
+		add("does not match any of the allowed schemas")
+	}
+
+	if len(s.OneOf) > 0 {
+		var matched int
+		for _, sub := range s.OneOf {
+			if matches(sub) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			add("must match exactly one of the allowed schemas, matched %v", matched)
+		}
+	}
+
+	if s.Const != nil && !equalJSON(v, normalizeJSON(s.Const)) {
+		data, _ := json.Marshal(s.Const)
+		add("must be %s", data)
+	}
+
+	if s.Type != "" && !hasType(v, s.Type) {
+		add("expected %v, got %v", s.Type, typeOf(v))
+		return
+	}
+
+	if len(s.Enum) > 0 {
+		if str, ok := enumString(v); !ok || !slices.Contains(s.Enum, str) {
+			add("must be one of %v", strings.Join(s.Enum, ", "))
+		}
+	}
+
+	switch v := v.(type) {
+	case string:
+		length := int64(utf8.RuneCountInString(v))
+		if s.MinLength != nil && length < *s.MinLength {
+			add("must be at least %v characters long", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			add("must be at most %v characters long", *s.MaxLength)
+		}
+		// Patterns that are not valid Go regular expressions are not checked
+		if s.Pattern != "" {
+			if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(v) {
+				add("must match pattern %v", s.Pattern)
+			}
+		}
+
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			add("invalid number %v", v)
+			return
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			add("must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			add("must be at most %v", *s.Maximum)
+		}
+
+	case []any:
+		if s.MinItems != nil && int64(len(v)) < *s.MinItems {
+			add("must have at least %v items", *s.MinItems)
+		}
+		if s.MaxItems != nil && int64(len(v)) > *s.MaxItems {
+			add("must have at most %v items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(root, fmt.Sprintf("%v[%v]", path, i), item, depth, violations)
+			}
+		}
+
+	case map[string]any:
+		for _, name := range s.Required {
+			// null counts as missing, unless the property may be null
+			property := s.Properties[name]
+			nullable := property != nil && (property.Nullable || property.Type == SchemaTypeNull)
+			if value, ok := v[name]; !ok || (value == nil && !nullable) {
+				*violations = append(*violations, SchemaViolation{Path: path + "." + name, Message: "is required"})
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(s.Properties)) {
+			// A null property is either reported as missing above, or treated as absent.
+			value, ok := v[name]
+			if !ok || value == nil {
+				continue
+			}
+			s.Properties[name].validate(root, path+"."+name, value, depth, violations)
+		}
+		if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+			for _, name := range slices.Sorted(maps.Keys(v)) {
+				if _, ok := s.Properties[name]; !ok {
+					*violations = append(*violations, SchemaViolation{Path: path + "." + name, Message: "is not allowed"})
+				}
+			}
+		}
+	}
+}
+
+// resolve a reference like "#/$defs/Node" against the root schema. It returns nil if there is no such schema.
+func (s *Schema) resolve(ref string) *Schema {
+	if ref == "#" {
+		return s
+	}
+	name, ok := defName(ref)
+	if !ok {
+		return nil
+	}
+	return s.Defs[name]
+}
+
+// defName returns the definition name of a local reference, like "Node" for "#/$defs/Node".
+// The "#/definitions/" prefix of older JSON Schema drafts is also accepted.
+func defName(ref string) (string, bool) {
+	for _, prefix := range []string{"#/$defs/", "#/definitions/"} {
+		if name, ok := strings.CutPrefix(ref, prefix); ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// hasType reports whether the decoded JSON value v is of type t.
+func hasType(v any, t SchemaType) bool {
+	switch v := v.(type) {
+	case string:
+		return t == SchemaTypeString
+	case bool:
+		return t == SchemaTypeBoolean
+	case json.Number:
+		if t == SchemaTypeNumber {
+			return true
+		}
+		if t != SchemaTypeInteger {
+			return false
+		}
+		f, err := v.Float64()
+		return err == nil && f == math.Trunc(f)
+	case []any:
+		return t == SchemaTypeArray
+	case map[string]any:
+		return t == SchemaTypeObject
+	case nil:
+		return t == SchemaTypeNull
+	default:
+		return false
+	}
+}
+
+// typeOf describes the type of the decoded JSON value v.
+func typeOf(v any) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return "null"
+	}
+}
+
+// enumString renders a scalar the way [Schema.Enum] values are stored.
+func enumString(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return fmt.Sprint(v), true
+	default:
+		return "", false
+	}
+}
+
+// normalizeJSON converts v to the values [json.Decoder] produces with UseNumber, so it can be compared
+// with decoded data.
+func normalizeJSON(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var normalized any
+	if err := d.Decode(&normalized); err != nil {
+		return v
+	}
+	return normalized
+}
+
+// equalJSON reports whether the decoded JSON values a and b are equal, comparing numbers by value.
+func equalJSON(a, b any) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fa, errA := a.Float64()
+		fb, errB := b.Float64()
+		return errA == nil && errB == nil && fa == fb
+	case []any:
+		b, ok := b.([]any)
+		return ok && slices.EqualFunc(a, b, equalJSON)
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		return ok && maps.EqualFunc(a, b, equalJSON)
+	default:
+		return a == b
+	}
+}
+
+// MarshalJSON satisfies [json.Marshaler]. It renders standard JSON Schema: [Schema.Nullable] becomes
+// a list of types like ["string", "null"], or anyOf with a null schema if there is no type, and
+// [Schema.Enum] values are rendered as the JSON type given by [Schema.Type].
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type schema Schema
+
+	if s.Nullable && s.Type == "" {
+		s.Nullable = false
+		switch {
+		case len(s.Enum) > 0:
+			// null is added to the enum values below
+			return json.Marshal(struct {
+				schema
+				Enum []any `json:"enum"`
+			}{schema: schema(s), Enum: append(s.enumValues(), nil)})
+
+		case len(s.AnyOf) > 0 && s.Ref == "":
+			s.AnyOf = append(slices.Clip(s.AnyOf), &Schema{Type: SchemaTypeNull})
+			return json.Marshal(schema(s))
+
+		default:
+			// Keep definitions and annotations at the top, so references still resolve against the root
+			outer := Schema{
+				Defs:        s.Defs,
+				Description: s.Description,
+				Title:       s.Title,
+				Default:     s.Default,
+			}
+			s.Defs, s.Description, s.Title, s.Default = nil, "", "", nil
+			outer.AnyOf = []*Schema{&s, {Type: SchemaTypeNull}}
+			return json.Marshal(outer)
+		}
+	}
+
+	var types any
+	if s.Type != "" {
+		types = s.Type
+		if s.Nullable && s.Type != SchemaTypeNull {
+			types = []SchemaType{s.Type, SchemaTypeNull}
+		}
+	}
+
+	var enum []any
+	if len(s.Enum) > 0 {
+		enum = s.enumValues()
+		if s.Nullable {
+			enum = append(enum, nil)
+		}
+	}
+
+	s.Nullable = false
+	return json.Marshal(struct {
+		schema
+		Enum []any `json:"enum,omitempty"`
+		Type any   `json:"type,omitempty"`
+	}{schema: schema(s), Enum: enum, Type: types})
+}
+
+// enumValues converts [Schema.Enum] to the JSON type given by [Schema.Type]. Values that don't
+// parse as that type stay strings.
+func (s Schema) enumValues() []any {
+	values := make([]any, len(s.Enum))
+	for i, v := range s.Enum {
+		values[i] = v
+		switch s.Type {
+		case SchemaTypeInteger, SchemaTypeNumber:
+			if _, err := strconv.ParseFloat(v, 64); err == nil && json.Valid([]byte(v)) {
+				values[i] = json.Number(v)
+			}
+		case SchemaTypeBoolean:
+			if b, err := strconv.ParseBool(v); err == nil {
+				values[i] = b
+			}
+		}
+	}
+	return values
+}
+
+// UnmarshalJSON satisfies [json.Unmarshaler]. It reads standard JSON Schema: a list of types is
+// narrowed to the first non-null type, with null setting [Schema.Nullable], enum values of any type
+// are kept in their string form, a schema for additional properties is read as allowing them, and
+// definitions are read into [Schema.Defs]. Boolean schemas are read as the empty schema.
+// A null alternative in anyOf or oneOf also sets [Schema.Nullable].
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	if v := string(bytes.TrimSpace(data)); v == "true" || v == "false" {
+		*s = Schema{}
+		return nil
+	}
+
+	type schema Schema
+	var raw struct {
+		schema
+		AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+		Definitions          map[string]*Schema `json:"definitions"`
+		Enum                 []any              `json:"enum"`
+		Type                 json.RawMessage    `json:"type"`
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&raw); err != nil {
+		return err
+	}
+
+	result := Schema(raw.schema)
+
+	if len(raw.Type) > 0 {
+		var types []SchemaType
+		if err := json.Unmarshal(raw.Type, &types); err != nil {
+			var t SchemaType
+			if err := json.Unmarshal(raw.Type, &t); err != nil {
+				return fmt.Errorf("invalid type: %w", err)
+			}
+			types = []SchemaType{t}
+		}
+		for _, t := range types {
+			switch {
+			case t == SchemaTypeNull && len(types) > 1:
+				result.Nullable = true
+			case result.Type == "":
+				result.Type = t
+			}
+		}
+	}
+
+	if len(raw.AdditionalProperties) > 0 {
+		result.AdditionalProperties = Ptr(string(bytes.TrimSpace(raw.AdditionalProperties)) != "false")
+	}
+
+	if result.Defs == nil {
+		result.Defs = raw.Definitions
+	}
+
+	for _, v := range raw.Enum {
+		if v == nil {
+			result.Nullable = true
+			continue
+		}
+		result.Enum = append(result.Enum, fmt.Sprint(v))
+	}
+
+	*s = collapseNull(result)
+	return nil
+}
+
+// collapseNull turns null alternatives in [Schema.AnyOf] and [Schema.OneOf] into [Schema.Nullable].
+// If a single alternative is left, and s has nothing else that constrains the value, the
+// alternative takes the place of the list, like {"oneOf": [{"type": "string"}, {"type": "null"}]}
+// becoming a nullable string.
+func collapseNull(s Schema) Schema {
+	isNull := func(sub *Schema) bool {
+		return sub.Type == SchemaTypeNull && sub.Ref == "" && len(sub.Enum) == 0 && sub.Const == nil
+	}
+
+	for _, list := range []*[]*Schema{&s.AnyOf, &s.OneOf} {
+		if !slices.ContainsFunc(*list, isNull) {
+			continue
+		}
+		*list = slices.DeleteFunc(slices.Clone(*list), isNull)
+		s.Nullable = true
+
+		if len(*list) != 1 || s.Type != "" || s.Ref != "" || len(s.AllOf) > 0 || len(s.AnyOf)+len(s.OneOf) > 1 {
+			continue
+		}
+
+		alternative := annotate(*(*list)[0], s)
+		alternative.Defs = s.Defs
+		return alternative
+	}
+	return s
+}
+
+// annotate s with the nullability and annotations of from, which is where s is used, like a
+// reference to s or a list with s as its only alternative.
+func annotate(s, from Schema) Schema {
+	s.Nullable = s.Nullable || from.Nullable
+	if from.Description != "" {
+		s.Description = from.Description
+	}
+	if from.Title != "" {
+		s.Title = from.Title
+	}
+	if from.Default != nil {
+		s.Default = from.Default
+	}
+	if from.Example != nil {
+		s.Example = from.Example
+	}
+	return s
+}
+
+// inlineRefs replaces references to [Schema.Defs] in root with the referenced schemas, because
+// most providers handle inline schemas better. Only recursive definitions, which cannot be
+// inlined, are kept.
+func inlineRefs(root Schema) Schema {
+	defs := root.Defs
+	if len(defs) == 0 {
+		return root
+	}
+
+	recursive := map[string]bool{}
+	for name := range defs {
+		if refersTo(defs, defs[name], name, map[string]bool{}) {
+			recursive[name] = true
+		}
+	}
+
+	var inline func(s *Schema) *Schema
+	inline = func(s *Schema) *Schema {
+		if s == nil {
+			return nil
+		}
+
+		c := *s
+		if name, ok := defName(c.Ref); ok && defs[name] != nil && !recursive[name] {
+			def := annotate(*inline(defs[name]), c)
+			return &def
+		}
+
+		c.Items = inline(c.Items)
+		if c.Properties != nil {
+			c.Properties = make(map[string]*Schema, len(s.Properties))
+			for name, property := range s.Properties {
+				c.Properties[name] = inline(property)
+			}
+		}
+		for _, list := range []*[]*Schema{&c.AllOf, &c.AnyOf, &c.OneOf} {
+			if *list != nil {
+				*list = slices.Clone(*list)
+				for i, sub := range *list {
+					(*list)[i] = inline(sub)
+				}
+			}
+		}
+		return &c
+	}
+
+	root.Defs = nil
+	result := *inline(&root)
+
+	// A root that is only a reference, like the one the reflector makes for a recursive struct,
+	// gets the content of the definition, because providers expect an object at the root.
+	if name, ok := defName(result.Ref); ok && recursive[name] {
+		result = annotate(*inline(defs[name]), result)
+	}
+
+	for name := range recursive {
+		if result.Defs == nil {
+			result.Defs = map[string]*Schema{}
+		}
+		result.Defs[name] = inline(defs[name])
+	}
+
+	return result
+}
+
+// refersTo reports whether s refers to the definition called name, directly or through other definitions.
+func refersTo(defs map[string]*Schema, s *Schema, name string, visited map[string]bool) bool {
+	if s == nil {
+		return false
+	}
+
+	if ref, ok := defName(s.Ref); ok {
+		if ref == name {
+			return true
+		}
+		if !visited[ref] {
+			visited[ref] = true
+			if refersTo(defs, defs[ref], name, visited) {
+				return true
+			}
+		}
+	}
+
+	if refersTo(defs, s.Items, name, visited) {
+		return true
+	}
+	for _, property := range s.Properties {
+		if refersTo(defs, property, name, visited) {
+			return true
+		}
+	}
+	for _, sub := range slices.Concat(s.AllOf, s.AnyOf, s.OneOf) {
+		if refersTo(defs, sub, name, visited) {
+			return true
+		}
+	}
+	return false
+}
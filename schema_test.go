@@ -0,0 +1,213 @@
+package gai_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+)
+
+func TestSchema_Validate(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Args struct {
+		Name    string   `json:"name"`
+		Age     int      `json:"age,omitempty" jsonschema:"minimum=0,maximum=150"`
+		Score   float64  `json:"score,omitempty"`
+		Status  string   `json:"status,omitempty" jsonschema:"enum=active,enum=inactive"`
+		Tags    []string `json:"tags,omitempty" jsonschema:"minItems=1,maxItems=2"`
+		Address *Address `json:"address,omitempty"`
+		Admin   bool     `json:"admin,omitempty"`
+	}
+	schema := gai.GenerateSchema[Args]()
+
+	tests := []struct {
+		name       string
+		data       string
+		violations []gai.SchemaViolation
+	}{
+		{"valid minimal", `{"name":"Ada"}`, nil},
+		{"valid full", `{"name":"Ada","age":36,"score":1.5,"status":"active","tags":["a"],"address":{"city":"London"},"admin":true}`, nil},
+		{"null optional property", `{"name":"Ada","age":null}`, nil},
+		{"missing required", `{}`, []gai.SchemaViolation{{Path: "$.name", Message: "is required"}}},
+		{"null required", `{"name":null}`, []gai.SchemaViolation{{Path: "$.name", Message: "is required"}}},
+		{"wrong type", `{"name":42}`, []gai.SchemaViolation{{Path: "$.name", Message: "expected string, got number"}}},
+		{"fractional integer", `{"name":"Ada","age":1.5}`, []gai.SchemaViolation{{Path: "$.age", Message: "expected integer, got number"}}},
+		{"below minimum", `{"name":"Ada","age":-1}`, []gai.SchemaViolation{{Path: "$.age", Message: "must be at least 0"}}},
+		{"above maximum", `{"name":"Ada","age":200}`, []gai.SchemaViolation{{Path: "$.age", Message: "must be at most 150"}}},
+		{"not in enum", `{"name":"Ada","status":"gone"}`, []gai.SchemaViolation{{Path: "$.status", Message: "must be one of active, inactive"}}},
+		{"too few items", `{"name":"Ada","tags":[]}`, []gai.SchemaViolation{{Path: "$.tags", Message: "must have at least 1 items"}}},
+		{"too many items", `{"name":"Ada","tags":["a","b","c"]}`, []gai.SchemaViolation{{Path: "$.tags", Message: "must have at most 2 items"}}},
+		{"wrong item type", `{"name":"Ada","tags":[1]}`, []gai.SchemaViolation{{Path: "$.tags[0]", Message: "expected string, got number"}}},
+		{"nested", `{"name":"Ada","address":{}}`, []gai.SchemaViolation{{Path: "$.address.city", Message: "is required"}}},
+		{"not an object", `[]`, []gai.SchemaViolation{{Path: "$", Message: "expected object, got array"}}},
+		{"several", `{"age":"old","admin":"yes"}`, []gai.SchemaViolation{
+			{Path: "$.name", Message: "is required"},
+			{Path: "$.admin", Message: "expected boolean, got string"},
+			{Path: "$.age", Message: "expected integer, got string"},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := schema.Validate(json.RawMessage(test.data))
+			if test.violations == nil {
+				is.NotError(t, err)
+				return
+			}
+
+			var schemaErr *gai.SchemaError
+			is.True(t, errors.As(err, &schemaErr), "expected a schema error")
+			is.EqualSlice(t, test.violations, schemaErr.Violations)
+		})
+	}
+
+	t.Run("anyOf", func(t *testing.T) {
+		schema := gai.Schema{AnyOf: []*gai.Schema{
+			{Type: gai.SchemaTypeString},
+			{Type: gai.SchemaTypeInteger},
+		}}
+
+		is.NotError(t, schema.Validate(json.RawMessage(`"a"`)))
+		is.NotError(t, schema.Validate(json.RawMessage(`1`)))
+		err := schema.Validate(json.RawMessage(`true`))
+		is.Equal(t, "$: does not match any of the allowed schemas", err.Error())
+	})
+
+	t.Run("JSON Schema keywords", func(t *testing.T) {
+		schema := gai.Schema{
+			Type:                 gai.SchemaTypeObject,
+			AdditionalProperties: gai.Ptr(false),
+			Required:             []string{"code", "note"},
+			Properties: map[string]*gai.Schema{
+				"code": {Type: gai.SchemaTypeString, Pattern: "^[A-Z]+$", MinLength: gai.Ptr(int64(2)), MaxLength: gai.Ptr(int64(3))},
+				"note": {Type: gai.SchemaTypeString, Nullable: true},
+				"kind": {Const: "leaf"},
+				"size": {OneOf: []*gai.Schema{
+					{Type: gai.SchemaTypeInteger},
+					{Type: gai.SchemaTypeNumber, Minimum: gai.Ptr(0.0)},
+				}},
+				"both": {AllOf: []*gai.Schema{
+					{Type: gai.SchemaTypeInteger},
+					{Minimum: gai.Ptr(10.0)},
+				}},
+			},
+		}
+
+		is.NotError(t, schema.Validate(json.RawMessage(`{"code":"AB","note":null,"kind":"leaf","size":1.5,"both":10}`)))
+
+		err := schema.Validate(json.RawMessage(`{"code":"abcd","kind":"branch","size":1,"both":9,"extra":true}`))
+		var schemaErr *gai.SchemaError
+		is.True(t, errors.As(err, &schemaErr))
+		is.EqualSlice(t, []gai.SchemaViolation{
+			{Path: "$.note", Message: "is required"},
+			{Path: "$.both", Message: "must be at least 10"},
+			{Path: "$.code", Message: "must be at most 3 characters long"},
+			{Path: "$.code", Message: "must match pattern ^[A-Z]+$"},
+			{Path: "$.kind", Message: `must be "leaf"`},
+			{Path: "$.size", Message: "must match exactly one of the allowed schemas, matched 2"},
+			{Path: "$.extra", Message: "is not allowed"},
+		}, schemaErr.Violations)
+	})
+
+	t.Run("references", func(t *testing.T) {
+		schema := gai.Schema{
+			Ref: "#/$defs/node",
+			Defs: map[string]*gai.Schema{
+				"node": {
+					Type:     gai.SchemaTypeObject,
+					Required: []string{"name"},
+					Properties: map[string]*gai.Schema{
+						"name":     {Type: gai.SchemaTypeString},
+						"children": {Type: gai.SchemaTypeArray, Items: &gai.Schema{Ref: "#/$defs/node"}},
+					},
+				},
+			},
+		}
+
+		is.NotError(t, schema.Validate(json.RawMessage(`{"name":"a","children":[{"name":"b","children":[{"name":"c"}]}]}`)))
+		err := schema.Validate(json.RawMessage(`{"name":"a","children":[{"children":[{"name":1}]}]}`))
+		is.Equal(t, "$.children[0].name: is required; $.children[0].children[0].name: expected string, got number", err.Error())
+
+		err = (&gai.Schema{Ref: "#/$defs/missing"}).Validate(json.RawMessage(`{}`))
+		is.Equal(t, "$: unknown schema reference #/$defs/missing", err.Error())
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		err := schema.Validate(json.RawMessage(`{`))
+		var schemaErr *gai.SchemaError
+		is.True(t, errors.As(err, &schemaErr))
+		is.Equal(t, "$", schemaErr.Violations[0].Path)
+	})
+}
+
+func TestSchema_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   gai.Schema
+		expected string
+	}{
+		{"nullable type", gai.Schema{Type: gai.SchemaTypeString, Nullable: true}, `{"type":["string","null"]}`},
+		{"nullable enum", gai.Schema{Type: gai.SchemaTypeInteger, Enum: []string{"1", "2"}, Nullable: true}, `{"enum":[1,2,null],"type":["integer","null"]}`},
+		{"nullable reference", gai.Schema{Ref: "#/$defs/node", Nullable: true, Description: "Parent."}, `{"anyOf":[{"$ref":"#/$defs/node"},{"type":"null"}],"description":"Parent."}`},
+		{"boolean enum", gai.Schema{Type: gai.SchemaTypeBoolean, Enum: []string{"true"}}, `{"enum":[true],"type":"boolean"}`},
+		{"array constraints as numbers", gai.Schema{Type: gai.SchemaTypeArray, MinItems: gai.Ptr(int64(1))}, `{"minItems":1,"type":"array"}`},
+		{"false const", gai.Schema{Const: false}, `{"const":false}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := json.Marshal(test.schema)
+			is.NotError(t, err)
+			is.Equal(t, test.expected, string(data))
+		})
+	}
+}
+
+func TestSchema_UnmarshalJSON(t *testing.T) {
+	t.Run("reads type lists, enums, additional properties, and definitions", func(t *testing.T) {
+		var schema gai.Schema
+		err := json.Unmarshal([]byte(`{
+			"type": "object",
+			"additionalProperties": false,
+			"properties": {
+				"note": {"type": ["string", "null"]},
+				"level": {"type": "integer", "enum": [1, 2]},
+				"tags": {"type": "object", "additionalProperties": {"type": "string"}},
+				"parent": {"anyOf": [{"$ref": "#/definitions/node"}, {"type": "null"}], "description": "Parent."},
+				"maxed": {"type": "array", "maxItems": 3}
+			},
+			"definitions": {"node": {"type": "object"}}
+		}`), &schema)
+		is.NotError(t, err)
+
+		is.True(t, !*schema.AdditionalProperties)
+		is.Equal(t, gai.SchemaTypeString, schema.Properties["note"].Type)
+		is.True(t, schema.Properties["note"].Nullable)
+		is.EqualSlice(t, []string{"1", "2"}, schema.Properties["level"].Enum)
+		is.True(t, *schema.Properties["tags"].AdditionalProperties)
+		is.Equal(t, "#/definitions/node", schema.Properties["parent"].Ref)
+		is.True(t, schema.Properties["parent"].Nullable)
+		is.Equal(t, "Parent.", schema.Properties["parent"].Description)
+		is.Equal(t, int64(3), *schema.Properties["maxed"].MaxItems)
+		is.Equal(t, gai.SchemaTypeObject, schema.Defs["node"].Type)
+	})
+
+	t.Run("round-trips generated schemas", func(t *testing.T) {
+		schema := gai.GenerateSchema[treeNode]()
+
+		data, err := json.Marshal(schema)
+		is.NotError(t, err)
+
+		var decoded gai.Schema
+		is.NotError(t, json.Unmarshal(data, &decoded))
+
+		roundTripped, err := json.Marshal(decoded)
+		is.NotError(t, err)
+		is.Equal(t, string(data), string(roundTripped))
+	})
+}
@@ -0,0 +1,69 @@
+package gai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// NewToolOptions for [NewTool].
+type NewToolOptions[Args any] struct {
+	Name        string
+	Description string
+	// Execute the tool with validated arguments. Required.
+	Execute func(ctx context.Context, args Args) (string, error)
+	// Summarize the arguments for display. Optional; defaults to an empty summary.
+	Summarize func(ctx context.Context, args Args) (string, error)
+}
+
+// NewTool creates a [Tool] whose arguments are of type Args, with a schema generated from Args.
+//
+// Raw arguments are validated against the schema before they are unmarshalled and passed to
+// Execute. Invalid arguments, like a missing required field or a wrong type, are returned as an
+// error listing every problem, so the model can correct its call, and Execute does not run.
+// See [GenerateSchema] for the struct tags that shape the schema.
+func NewTool[Args any](opts NewToolOptions[Args]) Tool {
+	if opts.Execute == nil {
+		panic("execute function must be set")
+	}
+
+	schema := GenerateToolSchema[Args]()
+	objectSchema := schema.Schema()
+
+	parse := func(rawArgs json.RawMessage) (Args, error) {
+		var args Args
+		if len(rawArgs) == 0 || string(rawArgs) == "null" {
+			rawArgs = json.RawMessage(`{}`)
+		}
+		if err := objectSchema.Validate(rawArgs); err != nil {
+			return args, fmt.Errorf("invalid arguments for %v: %w", opts.Name, err)
+		}
+		if err := json.Unmarshal(rawArgs, &args); err != nil {
+			return args, fmt.Errorf("invalid arguments for %v: %w", opts.Name, err)
+		}
+		return args, nil
+	}
+
+	return Tool{
+		Name:        opts.Name,
+		Description: opts.Description,
+		Schema:      schema,
+		Summarize: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
+			if opts.Summarize == nil {
+				return "", nil
+			}
+			args, err := parse(rawArgs)
+			if err != nil {
+				return "error parsing arguments", nil
+			}
+			return opts.Summarize(ctx, args)
+		},
+		Execute: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
+			args, err := parse(rawArgs)
+			if err != nil {
+				return "", err
+			}
+			return opts.Execute(ctx, args)
+		},
+	}
+}
@@ -0,0 +1,81 @@
+package gai_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+)
+
+func TestNewTool(t *testing.T) {
+	type GreetArgs struct {
+		Name  string `json:"name"`
+		Times int    `json:"times,omitempty" jsonschema:"minimum=1"`
+	}
+
+	newGreet := func(called *bool) gai.Tool {
+		return gai.NewTool(gai.NewToolOptions[GreetArgs]{
+			Name:        "greet",
+			Description: "Greet someone.",
+			Execute: func(ctx context.Context, args GreetArgs) (string, error) {
+				*called = true
+				return strings.Repeat("Hello, "+args.Name+"! ", max(args.Times, 1)), nil
+			},
+			Summarize: func(ctx context.Context, args GreetArgs) (string, error) {
+				return `name="` + args.Name + `"`, nil
+			},
+		})
+	}
+
+	t.Run("generates the schema and executes with typed arguments", func(t *testing.T) {
+		var called bool
+		tool := newGreet(&called)
+
+		is.Equal(t, "greet", tool.Name)
+		is.Equal(t, gai.SchemaTypeString, tool.Schema.Properties["name"].Type)
+
+		result, err := tool.Execute(t.Context(), json.RawMessage(`{"name":"Ada","times":2}`))
+		is.NotError(t, err)
+		is.Equal(t, "Hello, Ada! Hello, Ada! ", result)
+		is.True(t, called)
+
+		summary, err := tool.Summarize(t.Context(), json.RawMessage(`{"name":"Ada"}`))
+		is.NotError(t, err)
+		is.Equal(t, `name="Ada"`, summary)
+	})
+
+	t.Run("returns a model-readable error and does not execute on invalid arguments", func(t *testing.T) {
+		var called bool
+		tool := newGreet(&called)
+
+		_, err := tool.Execute(t.Context(), json.RawMessage(`{"times":0}`))
+		is.True(t, err != nil)
+		is.Equal(t, "invalid arguments for greet: $.name: is required; $.times: must be at least 1", err.Error())
+		is.True(t, !called)
+
+		summary, err := tool.Summarize(t.Context(), json.RawMessage(`{"name":1}`))
+		is.NotError(t, err)
+		is.Equal(t, "error parsing arguments", summary)
+	})
+
+	t.Run("treats empty arguments as an empty object", func(t *testing.T) {
+		tool := gai.NewTool(gai.NewToolOptions[struct{}]{
+			Name: "noop",
+			Execute: func(ctx context.Context, args struct{}) (string, error) {
+				return "OK", nil
+			},
+		})
+
+		result, err := tool.Execute(t.Context(), nil)
+		is.NotError(t, err)
+		is.Equal(t, "OK", result)
+
+		summary, err := tool.Summarize(t.Context(), nil)
+		is.NotError(t, err)
+		is.Equal(t, "", summary)
+	})
+}
@@ -3,10 +3,12 @@ package tools
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -15,15 +17,70 @@ import (
 
 // ExecArgs holds the arguments for the Exec tool.
 type ExecArgs struct {
-	Command string   `json:"command" jsonschema_description:"The command to execute."`
+	Command string   `json:"command" jsonschema:"minLength=1" jsonschema_description:"The command to execute."`
 	Args    []string `json:"args,omitempty" jsonschema_description:"Arguments to pass to the command."`
 	Input   string   `json:"input,omitempty" jsonschema_description:"Optional input to provide to stdin."`
 	Timeout int      `json:"timeout,omitempty" jsonschema_description:"Optional timeout in seconds. Default is 30 seconds."`
 }
 
+// NewExecOptions for [NewExecWithOptions]. The zero value runs any command in the current working directory,
+// with the host environment and no limits other than the timeout, like [NewExec].
+type NewExecOptions struct {
+	// AllowedCommands the model may run. Commands are matched exactly as given, so allowing "go"
+	// does not allow "/tmp/go". Nil allows all commands not in DeniedCommands.
+	// Note that allowing a shell or interpreter allows it to run anything.
+	AllowedCommands []string
+	// DeniedCommands the model may not run. Commands are matched by name, so denying "rm" also
+	// denies "/bin/rm". Prefer AllowedCommands, since a denylist is easy to get around.
+	DeniedCommands []string
+
+	// Dir is the working directory of the commands. Empty means the current working directory.
+	// It is not a sandbox: commands can still read and write files outside it, with relative
+	// paths like "../file" or absolute paths. Use [NewExecOptions.AllowedCommands] and OS-level
+	// isolation to confine them.
+	Dir string
+
+	// AllowedEnv are the names of host environment variables passed to the commands.
+	// Nil passes all variables not in DeniedEnv.
+	AllowedEnv []string
+	// DeniedEnv are the names of host environment variables not passed to the commands.
+	DeniedEnv []string
+	// Env are additional variables in the form "key=value", set after filtering the host environment.
+	Env []string
+
+	// MaxOutputBytes for each of stdout and stderr. Output after that is dropped and marked as truncated.
+	// Zero means no limit.
+	MaxOutputBytes int
+
+	// MaxTimeout caps the timeout the model asks for, including the default of 30 seconds.
+	// Zero means no cap.
+	MaxTimeout time.Duration
+
+	// MaxCPUTime of a command, after which it is killed. Zero means no limit. Linux only.
+	MaxCPUTime time.Duration
+	// MaxMemoryBytes of a command's address space, after which allocations fail. Zero means no limit. Linux only.
+	// The limits are set before the command starts, and are inherited by processes it starts.
+	MaxMemoryBytes uint64
+
+	// Approve is called before a command runs. If it returns an error, the command does not run,
+	// and the error is returned to the model. Nil means all commands are approved.
+	Approve func(ctx context.Context, args ExecArgs) error
+}
+
 // NewExec creates a new tool for executing shell commands.
+// Use [NewExecWithOptions] to restrict which commands run and how.
 func NewExec() gai.Tool {
-	return gai.Tool{
+	return NewExecWithOptions(NewExecOptions{})
+}
+
+// NewExecWithOptions creates a new tool for executing shell commands, restricted by the given options.
+// It panics if resource limits are set on a platform other than Linux.
+func NewExecWithOptions(opts NewExecOptions) gai.Tool {
+	if (opts.MaxCPUTime > 0 || opts.MaxMemoryBytes > 0) && !resourceLimitsSupported {
+		panic("resource limits are only supported on Linux")
+	}
+
+	return gai.NewTool(gai.NewToolOptions[ExecArgs]{
 		Name: "exec",
 		Description: `Execute a shell command and capture its output.
 
@@ -32,13 +89,7 @@ Executes the provided command with the specified arguments and returns the outpu
 - Timeout can be specified in seconds (default is 30 seconds)
 - Both stdout and stderr are captured and included in the output
 - Command arguments are properly escaped`,
-		Schema: gai.GenerateToolSchema[ExecArgs](),
-		Summarize: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
-			var args ExecArgs
-			if err := json.Unmarshal(rawArgs, &args); err != nil {
-				return "error parsing arguments", nil
-			}
-
+		Summarize: func(ctx context.Context, args ExecArgs) (string, error) {
 			// Start with command
 			summary := fmt.Sprintf(`command="%s"`, args.Command)
 
@@ -69,33 +120,48 @@ Executes the provided command with the specified arguments and returns the outpu
 
 			return summary, nil
 		},
-		Execute: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
-			var args ExecArgs
-			if err := json.Unmarshal(rawArgs, &args); err != nil {
-				return "", fmt.Errorf("error unmarshaling exec args from JSON: %w", err)
+		Execute: func(ctx context.Context, args ExecArgs) (string, error) {
+			if opts.AllowedCommands != nil && !slices.Contains(opts.AllowedCommands, args.Command) {
+				return "", fmt.Errorf("command %v is not allowed, allowed commands are: %v", args.Command, strings.Join(opts.AllowedCommands, ", "))
+			}
+			if slices.Contains(opts.DeniedCommands, args.Command) || slices.Contains(opts.DeniedCommands, filepath.Base(args.Command)) {
+				return "", fmt.Errorf("command %v is not allowed", args.Command)
 			}
 
-			if args.Command == "" {
-				return "", errors.New("command cannot be empty")
+			if opts.Approve != nil {
+				if err := opts.Approve(ctx, args); err != nil {
+					return "", fmt.Errorf("command not approved: %w", err)
+				}
 			}
 
-			// Set default timeout if not provided
-			timeout := 30
+			// Set default timeout if not provided, and keep it within the maximum
+			timeout := 30 * time.Second
 			if args.Timeout > 0 {
-				timeout = args.Timeout
+				timeout = time.Duration(args.Timeout) * time.Second
+			}
+			if opts.MaxTimeout > 0 {
+				timeout = min(timeout, opts.MaxTimeout)
 			}
 
 			// Create a context with timeout
-			execCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+			execCtx, cancel := context.WithTimeout(ctx, timeout)
 			defer cancel()
 
-			// Create the command with provided arguments
-			cmd := exec.CommandContext(execCtx, args.Command, args.Args...)
+			// Create the command with provided arguments, limiting its resources from the start
+			cmd := limitedCommand(execCtx, args.Command, args.Args, opts.MaxCPUTime, opts.MaxMemoryBytes)
+
+			cmd.Dir = opts.Dir
+
+			if opts.AllowedEnv != nil || opts.DeniedEnv != nil || opts.Env != nil {
+				cmd.Env = filterEnv(os.Environ(), opts.AllowedEnv, opts.DeniedEnv)
+				cmd.Env = append(cmd.Env, opts.Env...)
+			}
 
 			// Buffer for stdout and stderr
-			var stdout, stderr bytes.Buffer
-			cmd.Stdout = &stdout
-			cmd.Stderr = &stderr
+			stdout := &limitedBuffer{max: opts.MaxOutputBytes}
+			stderr := &limitedBuffer{max: opts.MaxOutputBytes}
+			cmd.Stdout = stdout
+			cmd.Stderr = stderr
 
 			// If input is provided, set up stdin
 			if args.Input != "" {
@@ -107,8 +173,8 @@ Executes the provided command with the specified arguments and returns the outpu
 
 			// Check if the error was due to the context being canceled (timeout)
 			if err != nil && execCtx.Err() == context.DeadlineExceeded {
-				return fmt.Sprintf("Command timed out after %d seconds", timeout),
-					fmt.Errorf("command timed out after %d seconds", timeout)
+				return fmt.Sprintf("Command timed out after %v seconds", timeout.Seconds()),
+					fmt.Errorf("command timed out after %v seconds", timeout.Seconds())
 			}
 
 			// Format the output
@@ -146,7 +212,11 @@ Executes the provided command with the specified arguments and returns the outpu
 					if result.Len() > 0 {
 						result.WriteString("\n")
 					}
-					fmt.Fprintf(&result, "Command exited with status %d\n", exitErr.ExitCode())
+					if exitErr.Exited() {
+						fmt.Fprintf(&result, "Command exited with status %d\n", exitErr.ExitCode())
+					} else {
+						fmt.Fprintf(&result, "Command was terminated: %v\n", exitErr)
+					}
 				} else {
 					if result.Len() > 0 {
 						result.WriteString("\n")
@@ -163,5 +233,48 @@ Executes the provided command with the specified arguments and returns the outpu
 
 			return result.String(), nil
 		},
+	})
+}
+
+// filterEnv returns the environment variables in env whose names are allowed and not denied.
+// A nil allowed means all names are allowed.
+func filterEnv(env, allowed, denied []string) []string {
+	filtered := []string{}
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		if allowed != nil && !slices.Contains(allowed, name) {
+			continue
+		}
+		if slices.Contains(denied, name) {
+			continue
+		}
+		filtered = append(filtered, kv)
+	}
+	return filtered
+}
+
+// limitedBuffer is a [bytes.Buffer] that keeps at most max bytes, counting the bytes dropped after that.
+// A max of zero means no limit.
+type limitedBuffer struct {
+	buf     bytes.Buffer
+	max     int
+	dropped int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.max > 0 && b.buf.Len()+len(p) > b.max {
+		keep := b.max - b.buf.Len()
+		b.buf.Write(p[:keep])
+		b.dropped += len(p) - keep
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// String of the kept output, with a marker if output was truncated.
+func (b *limitedBuffer) String() string {
+	if b.dropped == 0 {
+		return b.buf.String()
 	}
+	return fmt.Sprintf("%v\n[output truncated, %d bytes dropped]\n", b.buf.String(), b.dropped)
 }
@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const resourceLimitsSupported = true
+
+// limitedCommand creates a command running name with args under the given resource limits.
+// A shell sets the limits with ulimit and then replaces itself with the command, so the limits
+// are in place before the command's first instruction. Zero values mean no limit.
+func limitedCommand(ctx context.Context, name string, args []string, maxCPUTime time.Duration, maxMemoryBytes uint64) *exec.Cmd {
+	if maxCPUTime <= 0 && maxMemoryBytes == 0 {
+		return exec.CommandContext(ctx, name, args...)
+	}
+
+	// Look up the command like exec.Command does, so a missing command fails to start the same way
+	path := name
+	if !strings.Contains(name, "/") {
+		var err error
+		if path, err = exec.LookPath(name); err != nil {
+			return exec.CommandContext(ctx, name, args...)
+		}
+	}
+
+	var script strings.Builder
+	if maxCPUTime > 0 {
+		// The CPU time limit is in whole seconds, so round up
+		fmt.Fprintf(&script, "ulimit -t %d && ", uint64(math.Ceil(maxCPUTime.Seconds())))
+	}
+	if maxMemoryBytes > 0 {
+		// The address space limit is in KiB, so round down to stay within the limit
+		fmt.Fprintf(&script, "ulimit -v %d && ", max(maxMemoryBytes/1024, 1))
+	}
+	script.WriteString(`exec "$0" "$@"`)
+
+	return exec.CommandContext(ctx, "/bin/sh", append([]string{"-c", script.String(), path}, args...)...)
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+
+package tools
+
+import (
+	"context"
+	"os/exec"
+	"time"
+)
+
+const resourceLimitsSupported = false
+
+// limitedCommand creates a command running name with args, ignoring the resource limits,
+// since [NewExecWithOptions] only allows them on Linux.
+func limitedCommand(ctx context.Context, name string, args []string, maxCPUTime time.Duration, maxMemoryBytes uint64) *exec.Cmd {
+	return exec.CommandContext(ctx, name, args...)
+}
@@ -1,6 +1,11 @@
 package tools_test
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -76,7 +81,7 @@ func TestNewExec(t *testing.T) {
 		}))
 
 		is.True(t, err != nil)
-		is.Equal(t, "command cannot be empty", err.Error())
+		is.Equal(t, "invalid arguments for exec: $.command: must be at least 1 characters long", err.Error())
 	})
 
 	t.Run("handles command with multiple arguments", func(t *testing.T) {
@@ -276,4 +281,125 @@ func TestNewExec(t *testing.T) {
 		is.Equal(t, "error parsing arguments", summary)
 	})
 
+	t.Run("only runs allowed commands", func(t *testing.T) {
+		tool := tools.NewExecWithOptions(tools.NewExecOptions{AllowedCommands: []string{"echo"}})
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ExecArgs{Command: "echo", Args: []string{"hi"}}))
+		is.NotError(t, err)
+
+		_, err = tool.Execute(t.Context(), mustMarshalJSON(tools.ExecArgs{Command: "/bin/echo", Args: []string{"hi"}}))
+		is.Equal(t, "command /bin/echo is not allowed, allowed commands are: echo", err.Error())
+	})
+
+	t.Run("does not run denied commands, also by path", func(t *testing.T) {
+		tool := tools.NewExecWithOptions(tools.NewExecOptions{DeniedCommands: []string{"rm"}})
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ExecArgs{Command: "/bin/rm", Args: []string{"nonexistent"}}))
+		is.Equal(t, "command /bin/rm is not allowed", err.Error())
+	})
+
+	t.Run("runs commands in the working directory", func(t *testing.T) {
+		dir := t.TempDir()
+		is.NotError(t, os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hi"), 0644))
+
+		tool := tools.NewExecWithOptions(tools.NewExecOptions{Dir: dir})
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ExecArgs{Command: "ls"}))
+		is.NotError(t, err)
+		is.True(t, strings.Contains(result, "hello.txt"))
+	})
+
+	t.Run("filters the environment", func(t *testing.T) {
+		t.Setenv("GAI_ALLOWED", "yes")
+		t.Setenv("GAI_DENIED", "no")
+
+		tool := tools.NewExecWithOptions(tools.NewExecOptions{
+			AllowedEnv: []string{"GAI_ALLOWED", "GAI_DENIED"},
+			DeniedEnv:  []string{"GAI_DENIED"},
+			Env:        []string{"GAI_EXTRA=extra"},
+		})
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ExecArgs{Command: "env"}))
+		is.NotError(t, err)
+		is.True(t, strings.Contains(result, "GAI_ALLOWED=yes"))
+		is.True(t, strings.Contains(result, "GAI_EXTRA=extra"))
+		is.True(t, !strings.Contains(result, "GAI_DENIED"))
+		is.True(t, !strings.Contains(result, "PATH="))
+	})
+
+	t.Run("truncates output over the limit", func(t *testing.T) {
+		tool := tools.NewExecWithOptions(tools.NewExecOptions{MaxOutputBytes: 5})
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ExecArgs{Command: "echo", Args: []string{"Hello, World!"}}))
+		is.NotError(t, err)
+		is.True(t, strings.Contains(result, "Hello\n[output truncated, 9 bytes dropped]"))
+		is.True(t, !strings.Contains(result, "World"))
+	})
+
+	t.Run("does not run commands that are not approved", func(t *testing.T) {
+		var approved []string
+		tool := tools.NewExecWithOptions(tools.NewExecOptions{
+			Approve: func(ctx context.Context, args tools.ExecArgs) error {
+				if args.Command == "rm" {
+					return errors.New("no deleting")
+				}
+				approved = append(approved, args.Command)
+				return nil
+			},
+		})
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ExecArgs{Command: "echo"}))
+		is.NotError(t, err)
+
+		_, err = tool.Execute(t.Context(), mustMarshalJSON(tools.ExecArgs{Command: "rm", Args: []string{"nonexistent"}}))
+		is.Equal(t, "command not approved: no deleting", err.Error())
+		is.EqualSlice(t, []string{"echo"}, approved)
+	})
+
+	t.Run("caps the timeout at the maximum", func(t *testing.T) {
+		tool := tools.NewExecWithOptions(tools.NewExecOptions{MaxTimeout: time.Second})
+
+		start := time.Now()
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ExecArgs{
+			Command: "sleep",
+			Args:    []string{"5"},
+			Timeout: 60,
+		}))
+		is.True(t, err != nil)
+		is.Equal(t, "Command timed out after 1 seconds", result)
+		is.True(t, time.Since(start) < 3*time.Second)
+	})
+
+	t.Run("sets resource limits before the command starts", func(t *testing.T) {
+		if runtime.GOOS != "linux" {
+			t.Skip("resource limits are only supported on Linux")
+		}
+
+		tool := tools.NewExecWithOptions(tools.NewExecOptions{MaxCPUTime: 1500 * time.Millisecond, MaxMemoryBytes: 512 * 1024 * 1024})
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ExecArgs{
+			Command: "sh",
+			Args:    []string{"-c", "ulimit -t; ulimit -v"},
+		}))
+		is.NotError(t, err)
+		is.Equal(t, "STDOUT:\n2\n524288\n", result)
+	})
+
+	t.Run("kills commands over the CPU time limit", func(t *testing.T) {
+		if runtime.GOOS != "linux" {
+			t.Skip("resource limits are only supported on Linux")
+		}
+
+		tool := tools.NewExecWithOptions(tools.NewExecOptions{MaxCPUTime: time.Second})
+
+		start := time.Now()
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ExecArgs{
+			Command: "sh",
+			Args:    []string{"-c", "while :; do :; done"},
+			Timeout: 10,
+		}))
+		is.True(t, err != nil)
+		is.True(t, strings.Contains(result, "Command was terminated"))
+		is.True(t, time.Since(start) < 5*time.Second)
+	})
 }
@@ -1,13 +1,21 @@
 package tools
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"net"
 	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"maragu.dev/gai"
@@ -19,7 +27,7 @@ const (
 	outputFormatMarkdown = "markdown"
 )
 
-// chatCompleterConverter handles HTML to Markdown conversion using ChatCompleter
+// chatCompleterConverter cleans up and converts content to Markdown using a ChatCompleter
 type chatCompleterConverter struct {
 	completer gai.ChatCompleter
 }
@@ -31,80 +39,203 @@ func newChatCompleterConverter(completer gai.ChatCompleter) *chatCompleterConver
 	}
 }
 
-// ConvertHTMLToMarkdown converts HTML to Markdown using the ChatCompleter
-func (c *chatCompleterConverter) ConvertHTMLToMarkdown(ctx context.Context, html string) (string, error) {
-	systemPrompt := "You are a helpful assistant that converts HTML to Markdown. " +
-		"Preserve the semantic structure of the document. " +
+// CleanUpMarkdown cleans up Markdown converted from HTML using the ChatCompleter
+func (c *chatCompleterConverter) CleanUpMarkdown(ctx context.Context, markdown string) (string, error) {
+	systemPrompt := "You are a helpful assistant that cleans up Markdown converted from a web page. " +
+		"Remove leftover navigation, ads, cookie notices, and other boilerplate, and fix broken formatting. " +
+		"Preserve the content, links, tables, and code blocks. " +
 		"Only respond with the Markdown content, with no additional text."
 
+	markdown, err := c.complete(ctx, systemPrompt, gai.TextPart(markdown))
+	if err != nil {
+		return "", fmt.Errorf("error cleaning up Markdown: %w", err)
+	}
+	return markdown, nil
+}
+
+// ConvertPDFToMarkdown converts a PDF document to Markdown using the ChatCompleter,
+// by sending it as a data part.
+func (c *chatCompleterConverter) ConvertPDFToMarkdown(ctx context.Context, pdf []byte) (string, error) {
+	systemPrompt := "You are a helpful assistant that converts PDF documents to Markdown. " +
+		"Preserve the text, headings, lists, tables, and links of the document. " +
+		"Only respond with the Markdown content, with no additional text."
+
+	markdown, err := c.complete(ctx, systemPrompt, gai.DataPart("application/pdf", pdf))
+	if err != nil {
+		return "", fmt.Errorf("error converting PDF to Markdown: %w", err)
+	}
+	return markdown, nil
+}
+
+// complete sends the parts in a user message with the system prompt, and returns the text of the response.
+func (c *chatCompleterConverter) complete(ctx context.Context, systemPrompt string, parts ...gai.Part) (string, error) {
 	// Create chat complete request
 	req := gai.ChatCompleteRequest{
 		System: &systemPrompt,
 		Messages: []gai.Message{
-			gai.NewUserTextMessage(html),
+			{Role: gai.MessageRoleUser, Parts: parts},
 		},
 	}
 
 	// Send the request to the ChatCompleter
 	resp, err := c.completer.ChatComplete(ctx, req)
 	if err != nil {
-		return "", fmt.Errorf("error converting HTML to Markdown: %w", err)
+		return "", err
 	}
 
 	// Collect all text parts from the response
-	var markdown strings.Builder
-	var convErr error
-
-	// Iterate over all parts and collect text parts
+	var text strings.Builder
 	for part, err := range resp.Parts() {
 		if err != nil {
-			convErr = fmt.Errorf("error reading response parts: %w", err)
-			break
+			return "", fmt.Errorf("error reading response parts: %w", err)
 		}
 		if part.Type == gai.PartTypeText {
-			markdown.WriteString(part.Text())
+			text.WriteString(part.Text())
 		}
 	}
 
-	if convErr != nil {
-		return "", convErr
-	}
-
-	return markdown.String(), nil
+	return text.String(), nil
 }
 
 // FetchArgs holds the arguments for the Fetch tool.
 type FetchArgs struct {
-	URL          string `json:"url" jsonschema_description:"The URL to fetch."`
-	OutputFormat string `json:"output_format,omitempty" jsonschema_description:"Format for the output: 'html' or 'markdown' (default is markdown if a converter is available, otherwise html)."`
+	URL          string `json:"url" jsonschema:"minLength=1" jsonschema_description:"The URL to fetch."`
+	OutputFormat string `json:"output_format,omitempty" jsonschema:"enum=html,enum=markdown" jsonschema_description:"Format for the output: 'html' or 'markdown' (default is markdown)."`
+}
+
+// NewFetchOptions for [NewFetchWithOptions]. The zero value fetches any http or https URL with a default client,
+// reads the whole response, and converts HTML to Markdown locally, like [NewFetch] without a client and completer.
+type NewFetchOptions struct {
+	// Client for the requests. Nil means a client with a 30 second timeout.
+	Client *http.Client
+	// Completer, if not nil, cleans up the Markdown converted from HTML, and converts PDFs to Markdown.
+	// Without it, fetching a PDF fails.
+	Completer gai.ChatCompleter
+
+	// BlockPrivateIPs refuses connections to private, loopback, link-local, and unspecified addresses,
+	// such as 127.0.0.1, 10.0.0.1, and the cloud metadata address 169.254.169.254.
+	// Addresses are checked when connecting, after DNS resolution, so a host can't resolve to a public
+	// address when checked and a private one when fetched. The client's transport must be nil
+	// or an [*http.Transport], which is cloned without its proxy and with its own dialer.
+	// With any other transport, every fetch fails with an error.
+	BlockPrivateIPs bool
+
+	// MaxBodyBytes of a response, above which the fetch fails. Zero means no limit.
+	MaxBodyBytes int64
+
+	// AllowedSchemes of URLs, including redirects. Nil allows "http" and "https".
+	AllowedSchemes []string
+	// AllowedDomains of URLs, including redirects. A domain also allows its subdomains,
+	// so "example.com" allows "docs.example.com". Nil allows all domains.
+	AllowedDomains []string
+
+	// RespectRobotsTxt fetches the robots.txt of each host before fetching from it, including redirects,
+	// and refuses URLs it disallows. The robots.txt files are cached for the lifetime of the tool.
+	RespectRobotsTxt bool
 }
 
+// fetchUserAgent is sent with every request, and its product token is matched against robots.txt user agents.
+const fetchUserAgent = "gai-fetch-tool/1.0"
+
+// errFetchNotAllowed is wrapped by errors for URLs and addresses the options don't allow.
+// These errors are not retried.
+var errFetchNotAllowed = errors.New("not allowed")
+
 // NewFetch creates a new tool for fetching content from a URL.
-// If completer is provided, it will be used to convert HTML to Markdown when requested.
+// If client is nil, a client with a 30 second timeout is used.
+// If completer is not nil, it cleans up the Markdown converted from HTML, and converts PDFs to Markdown.
+// Use [NewFetchWithOptions] to restrict what can be fetched.
 func NewFetch(client *http.Client, completer gai.ChatCompleter) gai.Tool {
+	return NewFetchWithOptions(NewFetchOptions{Client: client, Completer: completer})
+}
+
+// NewFetchWithOptions creates a new tool for fetching content from a URL, restricted by the given options.
+// HTML is converted to Markdown locally, keeping the main content of the page, and cleaned up
+// with the completer if there is one. JSON is pretty-printed, and other text is returned as is.
+func NewFetchWithOptions(opts NewFetchOptions) gai.Tool {
 	// If no client is provided, create one with default settings
+	client := opts.Client
 	if client == nil {
 		client = &http.Client{
 			Timeout: 30 * time.Second,
 		}
 	}
 
+	// Copy the client, so the options don't change the one passed in
+	c := *client
+	client = &c
+
+	// transportErr is returned by every fetch, so a transport that can't block private IPs never fetches anything
+	var transportErr error
+	if opts.BlockPrivateIPs {
+		client.Transport, transportErr = newBlockPrivateIPsTransport(client.Transport)
+	}
+
+	allowedSchemes := opts.AllowedSchemes
+	if allowedSchemes == nil {
+		allowedSchemes = []string{"http", "https"}
+	}
+
+	// checkURL reports an error if the URL may not be fetched, not considering robots.txt
+	checkURL := func(u *url.URL) error {
+		if !slices.Contains(allowedSchemes, u.Scheme) {
+			return fmt.Errorf("scheme %q is %w, allowed schemes are: %v", u.Scheme, errFetchNotAllowed, strings.Join(allowedSchemes, ", "))
+		}
+		if opts.AllowedDomains != nil && !domainAllowed(u.Hostname(), opts.AllowedDomains) {
+			return fmt.Errorf("domain %q is %w, allowed domains are: %v", u.Hostname(), errFetchNotAllowed, strings.Join(opts.AllowedDomains, ", "))
+		}
+		return nil
+	}
+
+	var robots *robotsCache
+	if opts.RespectRobotsTxt {
+		// The robots.txt client follows redirects with the same checks, but without consulting robots.txt
+		robotsClient := *client
+		robotsClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("stopped after 5 redirects")
+			}
+			return checkURL(req.URL)
+		}
+		robots = &robotsCache{client: &robotsClient, rules: map[string]*robotsRules{}}
+	}
+
+	// checkURLAndRobots additionally checks robots.txt, if enabled
+	checkURLAndRobots := func(ctx context.Context, u *url.URL) error {
+		if err := checkURL(u); err != nil {
+			return err
+		}
+		if robots == nil {
+			return nil
+		}
+		return robots.check(ctx, u)
+	}
+
+	checkRedirect := client.CheckRedirect
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := checkURLAndRobots(req.Context(), req.URL); err != nil {
+			return err
+		}
+		if checkRedirect != nil {
+			return checkRedirect(req, via)
+		}
+		// Same as the default policy of http.Client
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+
 	// Create a converter from the ChatCompleter if one is provided
 	var converter *chatCompleterConverter
-	if completer != nil {
-		converter = newChatCompleterConverter(completer)
+	if opts.Completer != nil {
+		converter = newChatCompleterConverter(opts.Completer)
 	}
 
-	return gai.Tool{
+	return gai.NewTool(gai.NewToolOptions[FetchArgs]{
 		Name:        "fetch",
-		Description: "Fetch an HTML site and output the results as a string or Markdown. Follows redirects automatically.",
-		Schema:      gai.GenerateToolSchema[FetchArgs](),
-		Summarize: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
-			var args FetchArgs
-			if err := json.Unmarshal(rawArgs, &args); err != nil {
-				return "error parsing arguments", nil
-			}
-
+		Description: "Fetch a web page or document and output the main content as Markdown, or the raw HTML. JSON is pretty-printed, and plain text is returned as is. Follows redirects automatically.",
+		Summarize: func(ctx context.Context, args FetchArgs) (string, error) {
 			// Start with URL
 			summary := fmt.Sprintf(`url="%s"`, args.URL)
 
@@ -115,32 +246,23 @@ func NewFetch(client *http.Client, completer gai.ChatCompleter) gai.Tool {
 
 			return summary, nil
 		},
-		Execute: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
-			var args FetchArgs
-			if err := json.Unmarshal(rawArgs, &args); err != nil {
-				return "", fmt.Errorf("error unmarshaling fetch args from JSON: %w", err)
-			}
-
-			if args.URL == "" {
-				return "", errors.New("url cannot be empty")
+		Execute: func(ctx context.Context, args FetchArgs) (string, error) {
+			if transportErr != nil {
+				return "", transportErr
 			}
 
-			// Set default output format to markdown if a converter is available, otherwise html
+			// Set default output format to markdown
 			outputFormat := args.OutputFormat
-			if outputFormat != "" && outputFormat != outputFormatHTML && outputFormat != outputFormatMarkdown {
-				return "", fmt.Errorf("unsupported output format: %s. Supported formats are '%s' and '%s'", outputFormat, outputFormatHTML, outputFormatMarkdown)
-			}
 			if outputFormat == "" {
-				if converter != nil {
-					outputFormat = outputFormatMarkdown
-				} else {
-					outputFormat = outputFormatHTML
-				}
+				outputFormat = outputFormatMarkdown
 			}
 
-			// Error if markdown is requested but no converter is available
-			if outputFormat == outputFormatMarkdown && converter == nil {
-				return "", errors.New("markdown output requested but no converter is available")
+			u, err := url.Parse(args.URL)
+			if err != nil {
+				return "", fmt.Errorf("error parsing URL: %w", err)
+			}
+			if err := checkURLAndRobots(ctx, u); err != nil {
+				return "", err
 			}
 
 			// Maximum number of retries for transient errors
@@ -149,7 +271,6 @@ func NewFetch(client *http.Client, completer gai.ChatCompleter) gai.Tool {
 			const baseDelayMs = 500
 
 			var res *http.Response
-			var err error
 
 			// Retry logic for transient errors
 			for attempt := range maxRetries {
@@ -160,13 +281,17 @@ func NewFetch(client *http.Client, completer gai.ChatCompleter) gai.Tool {
 				}
 
 				// Set common headers
-				req.Header.Set("User-Agent", "gai-fetch-tool/1.0")
+				req.Header.Set("User-Agent", fetchUserAgent)
 
 				// Execute the request
 				res, err = client.Do(req)
 
 				// Check for errors that might be temporary (connection issues, server errors)
 				if err != nil {
+					// Fetching something that's not allowed won't be allowed on the next attempt either
+					if errors.Is(err, errFetchNotAllowed) {
+						return "", fmt.Errorf("error fetching URL: %w", err)
+					}
 					// Network errors are often temporary, retry
 					if attempt < maxRetries-1 {
 						// Exponential backoff: delay = baseDelay * 2^attempt
@@ -209,27 +334,205 @@ func NewFetch(client *http.Client, completer gai.ChatCompleter) gai.Tool {
 				return "", fmt.Errorf("received HTTP error: %s (status code: %d)", res.Status, res.StatusCode)
 			}
 
-			// Read the response body
-			body, err := io.ReadAll(res.Body)
+			// Read the response body, up to one byte over the limit to know if it's exceeded
+			var body []byte
+			if opts.MaxBodyBytes > 0 {
+				if res.ContentLength > opts.MaxBodyBytes {
+					return "", fmt.Errorf("response body of %d bytes exceeds the maximum of %d bytes", res.ContentLength, opts.MaxBodyBytes)
+				}
+				body, err = io.ReadAll(io.LimitReader(res.Body, opts.MaxBodyBytes+1))
+				if err == nil && int64(len(body)) > opts.MaxBodyBytes {
+					return "", fmt.Errorf("response body exceeds the maximum of %d bytes", opts.MaxBodyBytes)
+				}
+			} else {
+				body, err = io.ReadAll(res.Body)
+			}
 			if err != nil {
 				return "", fmt.Errorf("error reading response body: %w", err)
 			}
 
-			// Get the content as string
-			htmlContent := string(body)
+			mediaType := fetchMediaType(res.Header.Get("Content-Type"), body)
+
+			switch {
+			case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+				// Convert HTML to Markdown if requested, resolving links against the URL after redirects
+				if outputFormat == outputFormatHTML {
+					return string(body), nil
+				}
 
-			// Convert HTML to Markdown if requested
-			if outputFormat == outputFormatMarkdown {
-				// We already checked earlier that converter is not nil when markdown is requested
-				markdownContent, err := converter.ConvertHTMLToMarkdown(ctx, htmlContent)
+				markdownContent, err := htmlToMarkdown(bytes.NewReader(body), res.Request.URL)
 				if err != nil {
 					return "", fmt.Errorf("error converting HTML to Markdown: %w", err)
 				}
+
+				if converter != nil {
+					markdownContent, err = converter.CleanUpMarkdown(ctx, markdownContent)
+					if err != nil {
+						return "", err
+					}
+				}
 				return markdownContent, nil
+
+			case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+				// Pretty-print JSON, or return it as is if it's invalid
+				var b bytes.Buffer
+				if err := json.Indent(&b, body, "", "  "); err != nil {
+					return string(body), nil
+				}
+				return b.String(), nil
+
+			case strings.HasPrefix(mediaType, "text/") || mediaType == "application/xml" || strings.HasSuffix(mediaType, "+xml") ||
+				mediaType == "application/javascript" || mediaType == "application/x-yaml" || mediaType == "application/yaml":
+				return string(body), nil
+
+			case mediaType == "application/pdf":
+				if converter == nil {
+					return "", errors.New("fetching PDFs requires a chat completer to convert them to Markdown")
+				}
+				return converter.ConvertPDFToMarkdown(ctx, body)
+
+			default:
+				return "", fmt.Errorf("unsupported content type: %v", mediaType)
 			}
+		},
+	})
+}
+
+// fetchMediaType from the Content-Type header, or detected from the body if there is no header.
+func fetchMediaType(contentType string, body []byte) string {
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mediaType
+}
+
+// domainAllowed reports whether the host is one of the domains or a subdomain of one.
+func domainAllowed(host string, domains []string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, domain := range domains {
+		domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// newBlockPrivateIPsTransport clones the transport, with a dialer that refuses private addresses.
+// The proxy is removed, since connections through it would not be checked.
+// It returns an error if the transport is not nil or an [*http.Transport].
+func newBlockPrivateIPsTransport(rt http.RoundTripper) (*http.Transport, error) {
+	var transport *http.Transport
+	switch rt := rt.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = rt.Clone()
+	default:
+		return nil, fmt.Errorf("blocking private IPs requires the client transport to be nil or an *http.Transport, but it is %T", rt)
+	}
 
-			// Otherwise return HTML content
-			return htmlContent, nil
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		// Control is called after DNS resolution, with the address actually connected to
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("error parsing address %v: %w", address, err)
+			}
+			if isPrivateAddr(addrPort.Addr()) {
+				return fmt.Errorf("connecting to private address %v is %w", addrPort.Addr(), errFetchNotAllowed)
+			}
+			return nil
 		},
 	}
+
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	transport.DialTLSContext = nil
+	return transport, nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range from RFC 6598, which some clouds use for metadata services.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// isPrivateAddr reports whether the address is private, loopback, link-local, or unspecified.
+func isPrivateAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsUnspecified() || sharedAddressSpace.Contains(addr)
+}
+
+// robotsCache fetches and caches robots.txt rules by scheme and host.
+type robotsCache struct {
+	client *http.Client
+	lock   sync.Mutex
+	rules  map[string]*robotsRules
+}
+
+// maxRobotsTxtBytes read from a robots.txt file, as recommended by RFC 9309.
+const maxRobotsTxtBytes = 500 * 1024
+
+// check reports an error if robots.txt disallows fetching the URL.
+func (r *robotsCache) check(ctx context.Context, u *url.URL) error {
+	rules, err := r.get(ctx, u)
+	if err != nil {
+		return err
+	}
+	if !rules.allowed(u.RequestURI()) {
+		return fmt.Errorf("fetching %v is %w by robots.txt", u, errFetchNotAllowed)
+	}
+	return nil
+}
+
+// get the rules for the host of the URL, fetching them if they're not cached.
+// A missing robots.txt allows everything. Server and network errors are returned and not cached.
+func (r *robotsCache) get(ctx context.Context, u *url.URL) (*robotsRules, error) {
+	key := u.Scheme + "://" + u.Host
+
+	r.lock.Lock()
+	rules, ok := r.rules[key]
+	r.lock.Unlock()
+	if ok {
+		return rules, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, key+"/robots.txt", nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating robots.txt request: %w", err)
+	}
+	req.Header.Set("User-Agent", fetchUserAgent)
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching robots.txt: %w", err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		body, err := io.ReadAll(io.LimitReader(res.Body, maxRobotsTxtBytes))
+		if err != nil {
+			return nil, fmt.Errorf("error reading robots.txt: %w", err)
+		}
+		userAgent, _, _ := strings.Cut(fetchUserAgent, "/")
+		rules = parseRobots(body, userAgent)
+	case res.StatusCode >= 400 && res.StatusCode < 500:
+		rules = &robotsRules{}
+	default:
+		return nil, fmt.Errorf("error fetching robots.txt: %s (status code: %d)", res.Status, res.StatusCode)
+	}
+
+	r.lock.Lock()
+	r.rules[key] = rules
+	r.lock.Unlock()
+
+	return rules, nil
 }
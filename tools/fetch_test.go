@@ -1,7 +1,7 @@
 package tools_test
 
 import (
-	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -11,29 +11,10 @@ import (
 	"maragu.dev/is"
 
 	"maragu.dev/gai"
+	"maragu.dev/gai/gaitest"
 	"maragu.dev/gai/tools"
 )
 
-// mockChatCompleter implements gai.ChatCompleter for testing
-type mockChatCompleter struct{}
-
-func (m *mockChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRequest) (gai.ChatCompleteResponse, error) {
-	// Extract the HTML from the user message
-	html := req.Messages[0].Parts[0].Text()
-
-	// Add "MARKDOWN:" prefix for simple testing
-	markdownText := "MARKDOWN: " + html
-
-	// Create a sequence function that yields a single markdown part
-	partsFunc := func(yield func(gai.Part, error) bool) {
-		part := gai.TextPart(markdownText)
-		yield(part, nil)
-		// No need to signal end with EOF
-	}
-
-	return gai.NewChatCompleteResponse(partsFunc), nil
-}
-
 func TestNewFetch(t *testing.T) {
 	t.Run("successfully fetches content from a URL as HTML", func(t *testing.T) {
 		// Create a test server that serves a simple response
@@ -44,7 +25,6 @@ func TestNewFetch(t *testing.T) {
 		defer server.Close()
 
 		client := &http.Client{Timeout: 5 * time.Second}
-		// Pass nil for the completer
 		tool := tools.NewFetch(client, nil)
 
 		// Check tool name
@@ -60,7 +40,7 @@ func TestNewFetch(t *testing.T) {
 		is.Equal(t, "<p>Hello, World!</p>", result)
 	})
 
-	t.Run("successfully fetches content and converts to Markdown", func(t *testing.T) {
+	t.Run("successfully fetches content and cleans up the converted Markdown", func(t *testing.T) {
 		// Create a test server that serves HTML content
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
@@ -69,7 +49,7 @@ func TestNewFetch(t *testing.T) {
 		defer server.Close()
 
 		client := &http.Client{Timeout: 5 * time.Second}
-		completer := &mockChatCompleter{}
+		completer := gaitest.NewChatCompleter(gaitest.Text("# Hello, World!"))
 		tool := tools.NewFetch(client, completer)
 
 		// Execute the tool with the test server URL and Markdown output format
@@ -79,10 +59,11 @@ func TestNewFetch(t *testing.T) {
 		}))
 
 		is.NotError(t, err)
-		is.Equal(t, "MARKDOWN: <p>Hello, World!</p>", result)
+		is.Equal(t, "# Hello, World!", result)
+		is.Equal(t, "Hello, World!", completer.Requests()[0].Messages[0].Parts[0].Text())
 	})
 
-	t.Run("uses Markdown as default output format when converter is available", func(t *testing.T) {
+	t.Run("uses Markdown as default output format", func(t *testing.T) {
 		// Create a test server that serves HTML content
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
@@ -91,7 +72,7 @@ func TestNewFetch(t *testing.T) {
 		defer server.Close()
 
 		client := &http.Client{Timeout: 5 * time.Second}
-		completer := &mockChatCompleter{}
+		completer := gaitest.NewChatCompleter(gaitest.Text("# Hello, World!"))
 		tool := tools.NewFetch(client, completer)
 
 		// Execute the tool with the test server URL without specifying format
@@ -100,7 +81,7 @@ func TestNewFetch(t *testing.T) {
 		}))
 
 		is.NotError(t, err)
-		is.Equal(t, "MARKDOWN: <p>Hello, World!</p>", result)
+		is.Equal(t, "# Hello, World!", result)
 	})
 
 	t.Run("follows redirects correctly", func(t *testing.T) {
@@ -188,7 +169,19 @@ func TestNewFetch(t *testing.T) {
 		}))
 
 		is.True(t, err != nil)
-		is.Equal(t, "url cannot be empty", err.Error())
+		is.Equal(t, "invalid arguments for fetch: $.url: must be at least 1 characters long", err.Error())
+	})
+
+	t.Run("returns error for an unsupported output format", func(t *testing.T) {
+		tool := tools.NewFetch(nil, nil)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{
+			URL:          "https://example.com",
+			OutputFormat: "pdf",
+		}))
+
+		is.True(t, err != nil)
+		is.True(t, strings.HasPrefix(err.Error(), "invalid arguments for fetch: $.output_format: "))
 	})
 
 	t.Run("returns error for invalid URL", func(t *testing.T) {
@@ -211,7 +204,7 @@ func TestNewFetch(t *testing.T) {
 		}))
 		defer server.Close()
 
-		// Pass nil as the client and completer
+		// Use the zero options, which creates a default client
 		tool := tools.NewFetch(nil, nil)
 
 		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{
@@ -223,25 +216,341 @@ func TestNewFetch(t *testing.T) {
 		is.Equal(t, "Default client works!", result)
 	})
 
-	t.Run("returns error when markdown is requested but no converter is available", func(t *testing.T) {
+	t.Run("converts HTML to Markdown locally without a completer", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
-			_, _ = w.Write([]byte("<p>Hello, no converter!</p>"))
+			_, _ = w.Write([]byte("<p>Hello, <strong>no</strong> converter!</p>"))
 		}))
 		defer server.Close()
 
 		client := &http.Client{Timeout: 5 * time.Second}
-		// Pass nil for the completer
 		tool := tools.NewFetch(client, nil)
 
-		// Request Markdown but with no converter available
-		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{
-			URL:          server.URL,
-			OutputFormat: "markdown",
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{
+			URL: server.URL,
+		}))
+
+		is.NotError(t, err)
+		is.Equal(t, "Hello, **no** converter!", result)
+	})
+
+	t.Run("keeps the main content and leaves out page chrome", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`<!doctype html>
+<html>
+<head><title>Ignored</title><style>p { color: red; }</style></head>
+<body>
+	<nav><a href="/">Home</a></nav>
+	<main>
+		<h1>Article</h1>
+		<p>Some   <em>important</em>
+			text.</p>
+		<script>alert("hi")</script>
+		<div hidden>Hidden</div>
+	</main>
+	<footer>Copyright</footer>
+</body>
+</html>`))
+		}))
+		defer server.Close()
+
+		tool := tools.NewFetch(nil, nil)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: server.URL}))
+
+		is.NotError(t, err)
+		is.Equal(t, "# Article\n\nSome *important* text.", result)
+	})
+
+	t.Run("adds the page title as a heading and strips header and footer from the body", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`<html><head><title>My Page</title></head>
+<body><header>Logo</header><div><p>Content</p></div><footer>Copyright</footer></body></html>`))
+		}))
+		defer server.Close()
+
+		tool := tools.NewFetch(nil, nil)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: server.URL}))
+
+		is.NotError(t, err)
+		is.Equal(t, "# My Page\n\nContent", result)
+	})
+
+	t.Run("resolves links and images against the URL after redirects", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/docs/page", http.StatusFound)
+		})
+		mux.HandleFunc("/docs/page", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`<main><p>See <a href="other">the other page</a> and <a href="#top">top</a>.</p><img src="/logo.png" alt="Logo"></main>`))
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		tool := tools.NewFetch(nil, nil)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: server.URL}))
+
+		is.NotError(t, err)
+		is.Equal(t, "See [the other page]("+server.URL+"/docs/other) and top.\n\n![Logo]("+server.URL+"/logo.png)", result)
+	})
+
+	t.Run("converts lists, code blocks, tables, and block quotes", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`<main>
+<ul><li>One</li><li>Two<ol><li>Nested</li></ol></li></ul>
+<pre><code class="language-go">func main() {
+	fmt.Println("hi")
+}
+</code></pre>
+<p>Use <code>go test</code>.</p>
+<table>
+	<thead><tr><th>Name</th><th>Value</th></tr></thead>
+	<tbody><tr><td>a|b</td><td>1</td></tr></tbody>
+</table>
+<blockquote><p>Quoted</p></blockquote>
+</main>`))
 		}))
+		defer server.Close()
+
+		tool := tools.NewFetch(nil, nil)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: server.URL}))
+
+		is.NotError(t, err)
+		is.Equal(t, "- One\n- Two\n  1. Nested\n\n"+
+			"```go\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n```\n\n"+
+			"Use `go test`.\n\n"+
+			"| Name | Value |\n| --- | --- |\n| a\\|b | 1 |\n\n"+
+			"> Quoted", result)
+	})
+
+	t.Run("blocks private IPs when connecting", func(t *testing.T) {
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			_, _ = w.Write([]byte("Secret"))
+		}))
+		defer server.Close()
+
+		tool := tools.NewFetchWithOptions(tools.NewFetchOptions{BlockPrivateIPs: true})
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: server.URL}))
+
+		is.True(t, err != nil)
+		is.True(t, strings.Contains(err.Error(), "connecting to private address 127.0.0.1 is not allowed"))
+		is.Equal(t, 0, requests)
+	})
+
+	t.Run("returns error when blocking private IPs with an unsupported transport", func(t *testing.T) {
+		client := &http.Client{Transport: &recordingTransport{}}
+		tool := tools.NewFetchWithOptions(tools.NewFetchOptions{Client: client, BlockPrivateIPs: true})
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: "https://example.com"}))
 
 		is.True(t, err != nil)
-		is.Equal(t, "markdown output requested but no converter is available", err.Error())
+		is.Equal(t, "blocking private IPs requires the client transport to be nil or an *http.Transport, but it is *tools_test.recordingTransport", err.Error())
+		is.True(t, !client.Transport.(*recordingTransport).called)
+	})
+
+	t.Run("returns error when the body exceeds the maximum size", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusOK)
+			// Flush before writing, so the content length is unknown
+			w.(http.Flusher).Flush()
+			_, _ = w.Write([]byte("Hello, World!"))
+		}))
+		defer server.Close()
+
+		tool := tools.NewFetchWithOptions(tools.NewFetchOptions{MaxBodyBytes: 5})
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: server.URL}))
+		is.True(t, err != nil)
+		is.Equal(t, "response body exceeds the maximum of 5 bytes", err.Error())
+
+		tool = tools.NewFetchWithOptions(tools.NewFetchOptions{MaxBodyBytes: 13})
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: server.URL}))
+		is.NotError(t, err)
+		is.Equal(t, "Hello, World!", result)
+	})
+
+	t.Run("returns error for schemes that are not allowed", func(t *testing.T) {
+		tool := tools.NewFetch(nil, nil)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: "file:///etc/passwd"}))
+		is.True(t, err != nil)
+		is.Equal(t, `scheme "file" is not allowed, allowed schemes are: http, https`, err.Error())
+
+		tool = tools.NewFetchWithOptions(tools.NewFetchOptions{AllowedSchemes: []string{"https"}})
+
+		_, err = tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: "http://example.com"}))
+		is.True(t, err != nil)
+		is.Equal(t, `scheme "http" is not allowed, allowed schemes are: https`, err.Error())
+	})
+
+	t.Run("returns error for domains that are not allowed, including after redirects", func(t *testing.T) {
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			http.Redirect(w, r, "http://internal.example/", http.StatusFound)
+		}))
+		defer server.Close()
+
+		tool := tools.NewFetchWithOptions(tools.NewFetchOptions{AllowedDomains: []string{"example.com"}})
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: server.URL}))
+		is.True(t, err != nil)
+		is.Equal(t, `domain "127.0.0.1" is not allowed, allowed domains are: example.com`, err.Error())
+		is.Equal(t, 0, requests)
+
+		tool = tools.NewFetchWithOptions(tools.NewFetchOptions{AllowedDomains: []string{"127.0.0.1", "docs.example"}})
+
+		_, err = tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: server.URL}))
+		is.True(t, err != nil)
+		is.True(t, strings.HasSuffix(err.Error(), `domain "internal.example" is not allowed, allowed domains are: 127.0.0.1, docs.example`))
+		is.Equal(t, 1, requests)
+	})
+
+	t.Run("respects robots.txt for the fetch tool", func(t *testing.T) {
+		var robotsRequests int
+		mux := http.NewServeMux()
+		mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+			robotsRequests++
+			_, _ = w.Write([]byte(`# Keep out
+User-agent: *
+Disallow: /
+
+User-agent: Gai-Fetch-Tool
+Disallow: /private
+Allow: /private/public$
+`))
+		})
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("Hello from " + r.URL.Path))
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		tool := tools.NewFetchWithOptions(tools.NewFetchOptions{RespectRobotsTxt: true})
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: server.URL + "/private/secret"}))
+		is.True(t, err != nil)
+		is.Equal(t, "fetching "+server.URL+"/private/secret is not allowed by robots.txt", err.Error())
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: server.URL + "/private/public"}))
+		is.NotError(t, err)
+		is.Equal(t, "Hello from /private/public", result)
+
+		result, err = tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: server.URL + "/page"}))
+		is.NotError(t, err)
+		is.Equal(t, "Hello from /page", result)
+
+		is.Equal(t, 1, robotsRequests)
+	})
+
+	t.Run("allows everything when there is no robots.txt", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/robots.txt", http.NotFound)
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("Hello"))
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		tool := tools.NewFetchWithOptions(tools.NewFetchOptions{RespectRobotsTxt: true})
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: server.URL}))
+		is.NotError(t, err)
+		is.Equal(t, "Hello", result)
+	})
+
+	t.Run("pretty-prints JSON", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/problem+json")
+			_, _ = w.Write([]byte(`{"title":"Oops","status":400}`))
+		}))
+		defer server.Close()
+
+		tool := tools.NewFetch(nil, nil)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: server.URL}))
+		is.NotError(t, err)
+		is.Equal(t, "{\n  \"title\": \"Oops\",\n  \"status\": 400\n}", result)
+	})
+
+	t.Run("returns plain text as is", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, _ = w.Write([]byte("<p>Not HTML</p>"))
+		}))
+		defer server.Close()
+
+		tool := tools.NewFetch(nil, nil)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: server.URL}))
+		is.NotError(t, err)
+		is.Equal(t, "<p>Not HTML</p>", result)
+	})
+
+	t.Run("converts PDFs to Markdown with the completer, as a data part", func(t *testing.T) {
+		pdf := []byte("%PDF-1.7\n...")
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(pdf)
+		}))
+		defer server.Close()
+
+		completer := gaitest.NewChatCompleter(gaitest.Text("# Document"))
+		tool := tools.NewFetch(nil, completer)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: server.URL}))
+		is.NotError(t, err)
+		is.Equal(t, "# Document", result)
+
+		part := completer.Requests()[0].Messages[0].Parts[0]
+		is.Equal(t, gai.PartTypeData, part.Type)
+		is.Equal(t, "application/pdf", part.MIMEType)
+		is.EqualSlice(t, pdf, part.Data)
+	})
+
+	t.Run("returns error for PDFs without a completer", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/pdf")
+			_, _ = w.Write([]byte("%PDF-1.7\n..."))
+		}))
+		defer server.Close()
+
+		tool := tools.NewFetch(nil, nil)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: server.URL}))
+		is.True(t, err != nil)
+		is.Equal(t, "fetching PDFs requires a chat completer to convert them to Markdown", err.Error())
+	})
+
+	t.Run("returns error for unsupported content types", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("\x89PNG"))
+		}))
+		defer server.Close()
+
+		tool := tools.NewFetch(nil, nil)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.FetchArgs{URL: server.URL}))
+		is.True(t, err != nil)
+		is.Equal(t, "unsupported content type: image/png", err.Error())
 	})
 
 	t.Run("summarize with URL only", func(t *testing.T) {
@@ -288,3 +597,13 @@ func TestNewFetch(t *testing.T) {
 		is.Equal(t, "error parsing arguments", summary)
 	})
 }
+
+// recordingTransport is an [http.RoundTripper] that records whether it was called.
+type recordingTransport struct {
+	called bool
+}
+
+func (r *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.called = true
+	return nil, errors.New("not implemented")
+}
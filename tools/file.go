@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -10,138 +11,251 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strings"
 
 	"maragu.dev/gai"
 )
 
+const (
+	// maxListEntries is the maximum number of entries returned by list_dir and glob.
+	maxListEntries = 1000
+	// maxGrepMatches is the maximum number of matching lines returned by grep.
+	maxGrepMatches = 200
+	// maxGrepFileSize is the size above which files are skipped by grep.
+	maxGrepFileSize = 10 * 1024 * 1024
+	// maxGrepLineLength is the length above which lines are truncated in grep results.
+	maxGrepLineLength = 500
+)
+
 // ReadFileArgs holds the arguments for the ReadFile tool.
 type ReadFileArgs struct {
-	Path string `json:"path" jsonschema_description:"The relative path of a file in the working directory."`
+	Path        string `json:"path" jsonschema:"minLength=1" jsonschema_description:"The relative path of a file in the working directory."`
+	Offset      int    `json:"offset,omitempty" jsonschema_description:"Optional line number to start reading from, starting at 1. Defaults to the first line."`
+	Limit       int    `json:"limit,omitempty" jsonschema_description:"Optional maximum number of lines to read. Defaults to the rest of the file."`
+	LineNumbers bool   `json:"line_numbers,omitempty" jsonschema_description:"Whether to prefix each line with its line number."`
 }
 
 // NewReadFile creates a new tool that reads the contents of a file relative to the given [os.Root].
 func NewReadFile(root *os.Root) gai.Tool {
-	return gai.Tool{
-		Name:        "read_file",
-		Description: "Read the contents of a given relative file path. Use this when you want to see what's inside a file. Do not use this with directory names.",
-		Schema:      gai.GenerateToolSchema[ReadFileArgs](),
-		Summarize: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
-			var args ReadFileArgs
-			if err := json.Unmarshal(rawArgs, &args); err != nil {
-				return "error parsing arguments", nil
+	return gai.NewTool(gai.NewToolOptions[ReadFileArgs]{
+		Name: "read_file",
+		Description: `Read the contents of a given relative file path. Use this when you want to see what's inside a file. Do not use this with directory names.
+
+For large files, read a range of lines with 'offset' and 'limit'. Use 'line_numbers' to see the line number of each line.`,
+		Summarize: func(ctx context.Context, args ReadFileArgs) (string, error) {
+			summary := fmt.Sprintf(`path="%s"`, args.Path)
+			if args.Offset > 0 {
+				summary += fmt.Sprintf(" offset=%d", args.Offset)
 			}
-			return fmt.Sprintf(`path="%s"`, args.Path), nil
-		},
-		Execute: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
-			var args ReadFileArgs
-			if err := json.Unmarshal(rawArgs, &args); err != nil {
-				return "", fmt.Errorf("error unmarshaling read_file args from JSON: %w", err)
+			if args.Limit > 0 {
+				summary += fmt.Sprintf(" limit=%d", args.Limit)
 			}
-
+			return summary, nil
+		},
+		Execute: func(ctx context.Context, args ReadFileArgs) (string, error) {
 			f, err := fs.ReadFile(root.FS(), args.Path)
 			if err != nil {
 				return "", err
 			}
 
-			return string(f), nil
+			if args.Offset <= 0 && args.Limit <= 0 && !args.LineNumbers {
+				return string(f), nil
+			}
+
+			lines := strings.SplitAfter(string(f), "\n")
+			if lines[len(lines)-1] == "" {
+				lines = lines[:len(lines)-1]
+			}
+
+			start := max(args.Offset, 1) - 1
+			if start > 0 && start >= len(lines) {
+				return "", fmt.Errorf("offset %d is past the end of the file, which has %d lines", args.Offset, len(lines))
+			}
+			end := len(lines)
+			if args.Limit > 0 {
+				end = min(start+args.Limit, len(lines))
+			}
+
+			var result strings.Builder
+			for i, line := range lines[start:end] {
+				if args.LineNumbers {
+					fmt.Fprintf(&result, "%6d\t", start+i+1)
+				}
+				result.WriteString(line)
+			}
+
+			if end < len(lines) {
+				// Add newline if the last line doesn't end with one
+				if !strings.HasSuffix(result.String(), "\n") {
+					result.WriteString("\n")
+				}
+				fmt.Fprintf(&result, "\n[Showing lines %d-%d of %d. Use offset to read more.]\n", start+1, end, len(lines))
+			}
+
+			return result.String(), nil
 		},
-	}
+	})
 }
 
 // ListDirArgs holds the arguments for the ListDir tool.
 type ListDirArgs struct {
-	Path string `json:"path,omitempty" jsonschema_description:"Optional relative path to list files and directories from. Defaults to current directory if not provided."`
+	Path   string   `json:"path,omitempty" jsonschema_description:"Optional relative path to list files and directories from. Defaults to current directory if not provided."`
+	Depth  int      `json:"depth,omitempty" jsonschema_description:"Optional maximum depth to list, where 1 lists only the entries of the directory itself. Defaults to no limit."`
+	Ignore []string `json:"ignore,omitempty" jsonschema_description:"Optional .gitignore-style patterns of files and directories to leave out, relative to the path."`
 }
 
 // NewListDir creates a new tool that recursively lists files and directories relative to the given [os.Root].
+// Files and directories ignored by .gitignore files are left out, and so is the .git directory.
 func NewListDir(root *os.Root) gai.Tool {
-	return gai.Tool{
-		Name:        "list_dir",
-		Description: "List files and directories at a given path recursively. If no path is provided, lists files and directories in the current directory.",
-		Schema:      gai.GenerateToolSchema[ListDirArgs](),
-		Summarize: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
-			var args ListDirArgs
-			if err := json.Unmarshal(rawArgs, &args); err != nil {
-				return "error parsing arguments", nil
-			}
-			if args.Path == "" || args.Path == "." {
-				return "", nil
+	return gai.NewTool(gai.NewToolOptions[ListDirArgs]{
+		Name: "list_dir",
+		Description: fmt.Sprintf(`List files and directories at a given path recursively. If no path is provided, lists files and directories in the current directory.
+
+Files and directories ignored by .gitignore files are left out. Limit how deep to list with 'depth', and leave out more with 'ignore'. At most %d entries are listed.`, maxListEntries),
+		Summarize: func(ctx context.Context, args ListDirArgs) (string, error) {
+			var summary []string
+			if args.Path != "" && args.Path != "." {
+				summary = append(summary, fmt.Sprintf(`path="%s"`, args.Path))
 			}
-			return fmt.Sprintf(`path="%s"`, args.Path), nil
-		},
-		Execute: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
-			var args ListDirArgs
-			if err := json.Unmarshal(rawArgs, &args); err != nil {
-				return "", fmt.Errorf("error unmarshaling list_dir args from JSON: %w", err)
+			if args.Depth > 0 {
+				summary = append(summary, fmt.Sprintf("depth=%d", args.Depth))
 			}
-
+			if len(args.Ignore) > 0 {
+				summary = append(summary, fmt.Sprintf("ignore=%v", args.Ignore))
+			}
+			return strings.Join(summary, " "), nil
+		},
+		Execute: func(ctx context.Context, args ListDirArgs) (string, error) {
 			if args.Path == "" {
 				args.Path = "."
 			}
 
-			var files []string
-			err := fs.WalkDir(root.FS(), args.Path, func(path string, d fs.DirEntry, err error) error {
+			ig, err := newIgnorer(root.FS(), args.Path, args.Ignore)
+			if err != nil {
+				return "", err
+			}
+
+			var files entryLimiter
+			err = fs.WalkDir(root.FS(), args.Path, func(path string, d fs.DirEntry, err error) error {
 				if err != nil {
 					return err
 				}
 
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+
 				relPath, err := filepath.Rel(args.Path, path)
 				if err != nil {
 					return err
 				}
 
-				if relPath != "." {
+				if relPath == "." {
+					return ig.load(root.FS(), path)
+				}
+
+				if ig.ignored(path, d.IsDir()) {
 					if d.IsDir() {
-						files = append(files, relPath+"/")
-					} else {
-						files = append(files, relPath)
+						return fs.SkipDir
 					}
+					return nil
 				}
 
-				return nil
+				if !d.IsDir() {
+					return files.add(relPath)
+				}
+
+				if err := files.add(relPath + "/"); err != nil {
+					return err
+				}
+				if args.Depth > 0 && strings.Count(relPath, "/")+1 >= args.Depth {
+					return fs.SkipDir
+				}
+				return ig.load(root.FS(), path)
 			})
 			if err != nil {
 				return "", err
 			}
 
-			slices.Sort(files)
+			return files.marshal()
+		},
+	})
+}
 
-			result, err := json.Marshal(files)
-			if err != nil {
-				return "", err
-			}
+// entryLimiter collects at most [maxListEntries] entries from a walk, and counts the entries after that,
+// up to [maxListEntries] more, so walks over large trees stop early.
+type entryLimiter struct {
+	entries []string
+	more    int
+}
 
-			return string(result), nil
-		},
+// add an entry, returning [fs.SkipAll] once enough entries have been seen to stop the walk.
+func (l *entryLimiter) add(entry string) error {
+	if len(l.entries) < maxListEntries {
+		l.entries = append(l.entries, entry)
+		return nil
+	}
+	l.more++
+	if l.more >= maxListEntries {
+		return fs.SkipAll
+	}
+	return nil
+}
+
+// marshal the sorted entries as a JSON array, with a note about the entries left out.
+func (l *entryLimiter) marshal() (string, error) {
+	entries := l.entries
+	if entries == nil {
+		entries = []string{}
+	}
+	slices.Sort(entries)
+
+	switch {
+	case l.more >= maxListEntries:
+		entries = append(entries, fmt.Sprintf("... and at least %d more entries, narrow down the path to see them", l.more))
+	case l.more > 0:
+		entries = append(entries, fmt.Sprintf("... and %d more entries, narrow down the path to see them", l.more))
+	}
+
+	result, err := json.Marshal(entries)
+	if err != nil {
+		return "", err
 	}
+
+	return string(result), nil
+}
+
+// FileEdit is a single search and replace in a file.
+type FileEdit struct {
+	SearchStr  string `json:"search_str" jsonschema_description:"Text to search for. Must match exactly, and must have exactly one match unless replace_all is set."`
+	ReplaceStr string `json:"replace_str" jsonschema_description:"Text to replace search_str with."`
+	ReplaceAll bool   `json:"replace_all,omitempty" jsonschema_description:"Whether to replace every match of search_str instead of exactly one."`
 }
 
 // EditFileArgs holds the arguments for the EditFile tool.
 type EditFileArgs struct {
-	Path       string `json:"path" jsonschema_description:"The path to the file."`
-	SearchStr  string `json:"search_str" jsonschema_description:"Text to search for. Must match exactly and must have one match exactly."`
-	ReplaceStr string `json:"replace_str" jsonschema_description:"Text to replace search_str with."`
+	Path       string     `json:"path" jsonschema:"minLength=1" jsonschema_description:"The path to the file."`
+	SearchStr  string     `json:"search_str" jsonschema_description:"Text to search for. Must match exactly and must have one match exactly."`
+	ReplaceStr string     `json:"replace_str" jsonschema_description:"Text to replace search_str with."`
+	ReplaceAll bool       `json:"replace_all,omitempty" jsonschema_description:"Whether to replace every match of search_str instead of exactly one."`
+	Edits      []FileEdit `json:"edits,omitempty" jsonschema_description:"Optional further edits, applied in order after search_str and replace_str. If any edit fails, the file is not changed."`
 }
 
 // NewEditFile creates a new tool that edits or creates a file relative to the given [os.Root].
 func NewEditFile(root *os.Root) gai.Tool {
-	return gai.Tool{
+	return gai.NewTool(gai.NewToolOptions[EditFileArgs]{
 		Name: "edit_file",
 		Description: `Make edits to a text file.
 
 Replaces 'search_str' with 'replace_str' in the given file. 'search_str' and 'replace_str' MUST be different from each other.
+'search_str' must match exactly once, unless 'replace_all' is set to replace every match.
+Make several edits to the same file at once with 'edits'. Either all edits are made, or none are.
 
 If the file specified with 'path' doesn't exist, it will be created.
 `,
-		Schema: gai.GenerateToolSchema[EditFileArgs](),
-		Summarize: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
-			var args EditFileArgs
-			if err := json.Unmarshal(rawArgs, &args); err != nil {
-				return "error parsing arguments", nil
-			}
-
+		Summarize: func(ctx context.Context, args EditFileArgs) (string, error) {
 			// Truncate search and replace strings
 			searchStr := args.SearchStr
 			if len(searchStr) > 20 {
@@ -152,20 +266,24 @@ If the file specified with 'path' doesn't exist, it will be created.
 				replaceStr = replaceStr[:20] + "..."
 			}
 
-			return fmt.Sprintf(`path="%s" search="%s" replace="%s"`, args.Path, searchStr, replaceStr), nil
-		},
-		Execute: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
-			var args EditFileArgs
-			if err := json.Unmarshal(rawArgs, &args); err != nil {
-				return "", fmt.Errorf("error unmarshaling edit_file args from JSON: %w", err)
+			summary := fmt.Sprintf(`path="%s" search="%s" replace="%s"`, args.Path, searchStr, replaceStr)
+			if len(args.Edits) > 0 {
+				summary += fmt.Sprintf(" (%d more edits)", len(args.Edits))
 			}
-
-			if args.Path == "" {
-				return "", errors.New("path cannot be empty")
+			return summary, nil
+		},
+		Execute: func(ctx context.Context, args EditFileArgs) (string, error) {
+			edits := []FileEdit{{SearchStr: args.SearchStr, ReplaceStr: args.ReplaceStr, ReplaceAll: args.ReplaceAll}}
+			// With only further edits, the first edit is left empty
+			if args.SearchStr == "" && args.ReplaceStr == "" && len(args.Edits) > 0 {
+				edits = nil
 			}
+			edits = append(edits, args.Edits...)
 
-			if args.SearchStr == args.ReplaceStr {
-				return "", errors.New("search_str and replace_str cannot be the same")
+			for i, edit := range edits {
+				if edit.SearchStr == edit.ReplaceStr {
+					return "", editError(i, len(edits), errors.New("search_str and replace_str cannot be the same"))
+				}
 			}
 
 			// Check if the file exists, writing new_str if it doesn't
@@ -174,28 +292,12 @@ If the file specified with 'path' doesn't exist, it will be created.
 					return "", fmt.Errorf("error getting file info: %w", err)
 				}
 
-				dir := path.Dir(args.Path)
-				if dir != "." {
-					// Create all directories in the path
-					parts := strings.Split(dir, "/")
-					currentPath := ""
-					for _, part := range parts {
-						if part == "" {
-							continue
-						}
-						if currentPath != "" {
-							currentPath += "/"
-						}
-						currentPath += part
-						_, err := root.Stat(currentPath)
-						if err != nil && errors.Is(err, fs.ErrNotExist) {
-							if err := root.Mkdir(currentPath, 0755); err != nil {
-								return "", fmt.Errorf("error creating directory: %w", err)
-							}
-						} else if err != nil {
-							return "", fmt.Errorf("error checking directory: %w", err)
-						}
-					}
+				if len(edits) > 1 {
+					return "", errors.New("cannot make several edits to a file that doesn't exist")
+				}
+
+				if err := root.MkdirAll(path.Dir(args.Path), 0755); err != nil {
+					return "", fmt.Errorf("error creating directory: %w", err)
 				}
 				f, err := root.Create(args.Path)
 				if err != nil {
@@ -205,7 +307,7 @@ If the file specified with 'path' doesn't exist, it will be created.
 					_ = f.Close()
 				}()
 
-				if _, err := f.WriteString(args.ReplaceStr); err != nil {
+				if _, err := f.WriteString(edits[0].ReplaceStr); err != nil {
 					return "", fmt.Errorf("error writing to file: %w", err)
 				}
 				return "Created new file at " + args.Path, nil
@@ -229,11 +331,28 @@ If the file specified with 'path' doesn't exist, it will be created.
 				return "", fmt.Errorf("error closing file: %w", err)
 			}
 
-			beforeContent := string(content)
-			afterContent := strings.Replace(beforeContent, args.SearchStr, args.ReplaceStr, 1)
+			// Make all edits in memory first, so the file is only written if they all succeed
+			afterContent := string(content)
+			for i, edit := range edits {
+				if edit.SearchStr == "" {
+					if len(edits) > 1 {
+						return "", editError(i, len(edits), errors.New("search_str cannot be empty"))
+					}
+					afterContent = edit.ReplaceStr + afterContent
+					continue
+				}
 
-			if beforeContent == afterContent && args.SearchStr != "" {
-				return "", fmt.Errorf("search_str not found in file")
+				count := strings.Count(afterContent, edit.SearchStr)
+				switch {
+				case count == 0:
+					return "", editError(i, len(edits), errors.New("search_str not found in file"))
+				case count > 1 && !edit.ReplaceAll:
+					return "", editError(i, len(edits), fmt.Errorf("search_str found %d times in file, add more surrounding text to make it unique, or set replace_all", count))
+				case edit.ReplaceAll:
+					afterContent = strings.ReplaceAll(afterContent, edit.SearchStr, edit.ReplaceStr)
+				default:
+					afterContent = strings.Replace(afterContent, edit.SearchStr, edit.ReplaceStr, 1)
+				}
 			}
 
 			f, err = root.Create(args.Path)
@@ -250,5 +369,378 @@ If the file specified with 'path' doesn't exist, it will be created.
 
 			return "Edited file at " + args.Path, nil
 		},
+	})
+}
+
+// editError prefixes err with which edit failed, if there are several.
+func editError(i, count int, err error) error {
+	if count == 1 {
+		return err
+	}
+	return fmt.Errorf("edit %d: %w", i+1, err)
+}
+
+// WriteFileArgs holds the arguments for the WriteFile tool.
+type WriteFileArgs struct {
+	Path    string `json:"path" jsonschema:"minLength=1" jsonschema_description:"The relative path of the file to write."`
+	Content string `json:"content" jsonschema_description:"The full content of the file."`
+}
+
+// NewWriteFile creates a new tool that writes a file relative to the given [os.Root],
+// creating it and its directories if they don't exist, and overwriting it if it does.
+func NewWriteFile(root *os.Root) gai.Tool {
+	return gai.NewTool(gai.NewToolOptions[WriteFileArgs]{
+		Name: "write_file",
+		Description: `Write a text file with the given content, creating the file and its directories if they don't exist.
+
+If the file exists, its content is replaced. Prefer edit_file for changing parts of an existing file.`,
+		Summarize: func(ctx context.Context, args WriteFileArgs) (string, error) {
+			return fmt.Sprintf(`path="%s" bytes=%d`, args.Path, len(args.Content)), nil
+		},
+		Execute: func(ctx context.Context, args WriteFileArgs) (string, error) {
+			if err := root.MkdirAll(path.Dir(args.Path), 0755); err != nil {
+				return "", fmt.Errorf("error creating directory: %w", err)
+			}
+
+			if err := root.WriteFile(args.Path, []byte(args.Content), 0644); err != nil {
+				return "", fmt.Errorf("error writing file: %w", err)
+			}
+
+			return "Wrote file at " + args.Path, nil
+		},
+	})
+}
+
+// DeleteFileArgs holds the arguments for the DeleteFile tool.
+type DeleteFileArgs struct {
+	Path string `json:"path" jsonschema:"minLength=1" jsonschema_description:"The relative path of the file to delete."`
+}
+
+// NewDeleteFile creates a new tool that deletes a file relative to the given [os.Root].
+// It does not delete directories.
+func NewDeleteFile(root *os.Root) gai.Tool {
+	return gai.NewTool(gai.NewToolOptions[DeleteFileArgs]{
+		Name:        "delete_file",
+		Description: "Delete a file at a given relative path. Directories cannot be deleted.",
+		Summarize: func(ctx context.Context, args DeleteFileArgs) (string, error) {
+			return fmt.Sprintf(`path="%s"`, args.Path), nil
+		},
+		Execute: func(ctx context.Context, args DeleteFileArgs) (string, error) {
+			info, err := root.Lstat(args.Path)
+			if err != nil {
+				return "", err
+			}
+			if info.IsDir() {
+				return "", fmt.Errorf("%v is a directory, not a file", args.Path)
+			}
+
+			if err := root.Remove(args.Path); err != nil {
+				return "", fmt.Errorf("error deleting file: %w", err)
+			}
+
+			return "Deleted file at " + args.Path, nil
+		},
+	})
+}
+
+// MoveFileArgs holds the arguments for the MoveFile tool.
+type MoveFileArgs struct {
+	Source      string `json:"source" jsonschema:"minLength=1" jsonschema_description:"The relative path of the file or directory to move."`
+	Destination string `json:"destination" jsonschema:"minLength=1" jsonschema_description:"The relative path to move it to. Must not exist."`
+}
+
+// NewMoveFile creates a new tool that moves or renames a file or directory relative to the given [os.Root].
+// It creates the directories of the destination, and does not overwrite it if it exists.
+func NewMoveFile(root *os.Root) gai.Tool {
+	return gai.NewTool(gai.NewToolOptions[MoveFileArgs]{
+		Name:        "move_file",
+		Description: "Move or rename a file or directory. Directories of the destination are created if they don't exist. The destination must not exist.",
+		Summarize: func(ctx context.Context, args MoveFileArgs) (string, error) {
+			return fmt.Sprintf(`source="%s" destination="%s"`, args.Source, args.Destination), nil
+		},
+		Execute: func(ctx context.Context, args MoveFileArgs) (string, error) {
+			if _, err := root.Lstat(args.Source); err != nil {
+				return "", err
+			}
+
+			if _, err := root.Lstat(args.Destination); err == nil {
+				return "", fmt.Errorf("destination %v already exists", args.Destination)
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				return "", fmt.Errorf("error getting destination info: %w", err)
+			}
+
+			if err := root.MkdirAll(path.Dir(args.Destination), 0755); err != nil {
+				return "", fmt.Errorf("error creating directory: %w", err)
+			}
+
+			if err := root.Rename(args.Source, args.Destination); err != nil {
+				return "", fmt.Errorf("error moving file: %w", err)
+			}
+
+			return fmt.Sprintf("Moved %v to %v", args.Source, args.Destination), nil
+		},
+	})
+}
+
+// GlobArgs holds the arguments for the Glob tool.
+type GlobArgs struct {
+	Pattern string `json:"pattern" jsonschema:"minLength=1" jsonschema_description:"The pattern to match paths against, like '**/*.go'. Use * for any part of a name, and ** for any number of directories."`
+	Path    string `json:"path,omitempty" jsonschema_description:"Optional relative path of the directory to search in, which the pattern is relative to. Defaults to current directory if not provided."`
+}
+
+// NewGlob creates a new tool that finds files and directories by pattern relative to the given [os.Root].
+// Files and directories ignored by .gitignore files are left out, and so is the .git directory.
+func NewGlob(root *os.Root) gai.Tool {
+	return gai.NewTool(gai.NewToolOptions[GlobArgs]{
+		Name: "glob",
+		Description: fmt.Sprintf(`Find files and directories whose paths match a pattern, like '**/*.go' or 'cmd/*/main.go'.
+
+'*' matches any part of a name, '?' any single character, and '**' any number of directories.
+Files and directories ignored by .gitignore files are left out. At most %d paths are returned.`, maxListEntries),
+		Summarize: func(ctx context.Context, args GlobArgs) (string, error) {
+			summary := fmt.Sprintf(`pattern="%s"`, args.Pattern)
+			if args.Path != "" && args.Path != "." {
+				summary += fmt.Sprintf(` path="%s"`, args.Path)
+			}
+			return summary, nil
+		},
+		Execute: func(ctx context.Context, args GlobArgs) (string, error) {
+			if _, err := path.Match(strings.ReplaceAll(args.Pattern, "**", "*"), ""); err != nil {
+				return "", fmt.Errorf("invalid pattern: %w", err)
+			}
+
+			if args.Path == "" {
+				args.Path = "."
+			}
+
+			ig, err := newIgnorer(root.FS(), args.Path, nil)
+			if err != nil {
+				return "", err
+			}
+
+			var matches entryLimiter
+			err = fs.WalkDir(root.FS(), args.Path, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+
+				relPath, err := filepath.Rel(args.Path, path)
+				if err != nil {
+					return err
+				}
+
+				if relPath == "." {
+					return ig.load(root.FS(), path)
+				}
+
+				if ig.ignored(path, d.IsDir()) {
+					if d.IsDir() {
+						return fs.SkipDir
+					}
+					return nil
+				}
+
+				if matchPath(args.Pattern, relPath) {
+					match := relPath
+					if d.IsDir() {
+						match += "/"
+					}
+					if err := matches.add(match); err != nil {
+						return err
+					}
+				}
+
+				if d.IsDir() {
+					return ig.load(root.FS(), path)
+				}
+				return nil
+			})
+			if err != nil {
+				return "", err
+			}
+
+			return matches.marshal()
+		},
+	})
+}
+
+// GrepArgs holds the arguments for the Grep tool.
+type GrepArgs struct {
+	Pattern    string `json:"pattern" jsonschema:"minLength=1" jsonschema_description:"The regular expression to search for, in Go RE2 syntax."`
+	Path       string `json:"path,omitempty" jsonschema_description:"Optional relative path of a file or directory to search in. Defaults to current directory if not provided."`
+	Include    string `json:"include,omitempty" jsonschema_description:"Optional pattern of file names to search, like '*.go'."`
+	Context    int    `json:"context,omitempty" jsonschema_description:"Optional number of lines to show before and after each matching line."`
+	IgnoreCase bool   `json:"ignore_case,omitempty" jsonschema_description:"Whether to match case-insensitively."`
+}
+
+// NewGrep creates a new tool that searches file contents by regular expression relative to the given [os.Root].
+// Files ignored by .gitignore files, binary files, and files over 10 MiB are skipped.
+func NewGrep(root *os.Root) gai.Tool {
+	return gai.NewTool(gai.NewToolOptions[GrepArgs]{
+		Name: "grep",
+		Description: fmt.Sprintf(`Search the contents of files for lines matching a regular expression.
+
+Results are in the form 'path:line number:line' for matching lines, and 'path-line number-line' for context lines, with '--' between groups of lines.
+Files ignored by .gitignore files and binary files are skipped. At most %d matching lines are returned.`, maxGrepMatches),
+		Summarize: func(ctx context.Context, args GrepArgs) (string, error) {
+			summary := fmt.Sprintf(`pattern="%s"`, args.Pattern)
+			if args.Path != "" && args.Path != "." {
+				summary += fmt.Sprintf(` path="%s"`, args.Path)
+			}
+			if args.Include != "" {
+				summary += fmt.Sprintf(` include="%s"`, args.Include)
+			}
+			return summary, nil
+		},
+		Execute: func(ctx context.Context, args GrepArgs) (string, error) {
+
+			pattern := args.Pattern
+			if args.IgnoreCase {
+				pattern = "(?i)" + pattern
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return "", fmt.Errorf("invalid pattern: %w", err)
+			}
+
+			if args.Include != "" {
+				if _, err := path.Match(args.Include, ""); err != nil {
+					return "", fmt.Errorf("invalid include pattern: %w", err)
+				}
+			}
+
+			if args.Path == "" {
+				args.Path = "."
+			}
+
+			ig, err := newIgnorer(root.FS(), args.Path, nil)
+			if err != nil {
+				return "", err
+			}
+
+			g := &grepper{re: re, context: max(args.Context, 0)}
+			err = fs.WalkDir(root.FS(), args.Path, func(p string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+
+				if g.matches >= maxGrepMatches {
+					return fs.SkipAll
+				}
+
+				if d.IsDir() {
+					if p != args.Path && ig.ignored(p, true) {
+						return fs.SkipDir
+					}
+					return ig.load(root.FS(), p)
+				}
+
+				// A file given as the path is always searched
+				if p != args.Path {
+					if ig.ignored(p, false) {
+						return nil
+					}
+					if args.Include != "" {
+						if ok, _ := path.Match(args.Include, d.Name()); !ok {
+							return nil
+						}
+					}
+				}
+
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+				if !info.Mode().IsRegular() || info.Size() > maxGrepFileSize {
+					return nil
+				}
+
+				content, err := fs.ReadFile(root.FS(), p)
+				if err != nil {
+					return err
+				}
+				g.search(p, content)
+
+				return nil
+			})
+			if err != nil {
+				return "", err
+			}
+
+			if g.matches == 0 {
+				return "No matches found", nil
+			}
+
+			result := g.result.String()
+			if g.matches >= maxGrepMatches {
+				result += fmt.Sprintf("\n[Stopped after %d matching lines. Narrow down the search to see more.]\n", maxGrepMatches)
+			}
+			return result, nil
+		},
+	})
+}
+
+// grepper collects lines matching a regular expression, with context lines, in the format of grep -n.
+type grepper struct {
+	re      *regexp.Regexp
+	context int
+	matches int
+	result  strings.Builder
+}
+
+// search the file content, if it's not binary, adding matching lines until [maxGrepMatches].
+func (g *grepper) search(path string, content []byte) {
+	// Like grep, consider content with a NUL byte near the start binary
+	if bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0 {
+		return
+	}
+
+	lines := strings.Split(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	// last is the index of the last line written from this file, or -1
+	last := -1
+	for i, line := range lines {
+		if g.matches >= maxGrepMatches {
+			return
+		}
+		if !g.re.MatchString(line) {
+			continue
+		}
+
+		// Like grep, separate groups of lines with context, also across files
+		start := max(i-g.context, last+1)
+		if g.context > 0 && g.result.Len() > 0 && (last == -1 || start > last+1) {
+			g.result.WriteString("--\n")
+		}
+		for j := start; j < i; j++ {
+			g.writeLine(path, j, lines[j], '-')
+		}
+		g.writeLine(path, i, line, ':')
+		g.matches++
+		last = i
+
+		// Write context lines after, up to the next match
+		for j := i + 1; j <= min(i+g.context, len(lines)-1) && !g.re.MatchString(lines[j]); j++ {
+			g.writeLine(path, j, lines[j], '-')
+			last = j
+		}
+	}
+}
+
+func (g *grepper) writeLine(path string, i int, line string, sep byte) {
+	if len(line) > maxGrepLineLength {
+		line = line[:maxGrepLineLength] + "..."
 	}
+	fmt.Fprintf(&g.result, "%v%c%d%c%v\n", path, sep, i+1, sep, line)
 }
@@ -1,10 +1,15 @@
 package tools_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 	"testing"
 
 	"maragu.dev/is"
@@ -26,6 +31,27 @@ func TestNewReadFile(t *testing.T) {
 		is.Equal(t, "Hi!\n", result)
 	})
 
+	t.Run("reads a range of lines with line numbers", func(t *testing.T) {
+		root := newTestRoot(t, map[string]string{"lines.txt": "one\ntwo\nthree\nfour\n"})
+		tool := tools.NewReadFile(root)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ReadFileArgs{Path: "lines.txt", Offset: 2, Limit: 2, LineNumbers: true}))
+		is.NotError(t, err)
+		is.Equal(t, "     2\ttwo\n     3\tthree\n\n[Showing lines 2-3 of 4. Use offset to read more.]\n", result)
+
+		result, err = tool.Execute(t.Context(), mustMarshalJSON(tools.ReadFileArgs{Path: "lines.txt", Offset: 4}))
+		is.NotError(t, err)
+		is.Equal(t, "four\n", result)
+	})
+
+	t.Run("errors if offset is past the end of the file", func(t *testing.T) {
+		root := newTestRoot(t, map[string]string{"lines.txt": "one\ntwo\n"})
+		tool := tools.NewReadFile(root)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ReadFileArgs{Path: "lines.txt", Offset: 3}))
+		is.Equal(t, "offset 3 is past the end of the file, which has 2 lines", err.Error())
+	})
+
 	t.Run("errors if file does not exist", func(t *testing.T) {
 		tool := tools.NewReadFile(testdata)
 
@@ -78,6 +104,255 @@ func TestNewListDir(t *testing.T) {
 
 		is.Equal(t, "statat nonexistent: no such file or directory", err.Error())
 	})
+
+	t.Run("limits the depth", func(t *testing.T) {
+		tool := tools.NewListDir(testdata)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ListDirArgs{Depth: 1}))
+		is.NotError(t, err)
+		is.Equal(t, `["dir1/","readme.txt"]`, result)
+	})
+
+	t.Run("leaves out files ignored by .gitignore files, ignore patterns, and the .git directory", func(t *testing.T) {
+		root := newTestRoot(t, map[string]string{
+			".gitignore":           "*.log\nbuild/\n!keep.log\n",
+			".git/HEAD":            "ref: refs/heads/main\n",
+			"app.log":              "",
+			"keep.log":             "",
+			"build/out":            "",
+			"src/main.go":          "",
+			"src/.gitignore":       "/generated.go\n",
+			"src/generated.go":     "",
+			"src/sub/generated.go": "",
+			"docs/readme.md":       "",
+		})
+		tool := tools.NewListDir(root)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ListDirArgs{Ignore: []string{"docs"}}))
+		is.NotError(t, err)
+		is.Equal(t, `[".gitignore","keep.log","src/","src/.gitignore","src/main.go","src/sub/","src/sub/generated.go"]`, result)
+
+		result, err = tool.Execute(t.Context(), mustMarshalJSON(tools.ListDirArgs{Path: "src"}))
+		is.NotError(t, err)
+		is.Equal(t, `[".gitignore","main.go","sub/","sub/generated.go"]`, result)
+	})
+
+	t.Run("stops listing after the entry limit", func(t *testing.T) {
+		tool := tools.NewListDir(newManyFilesRoot(t, 1500))
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ListDirArgs{}))
+		is.NotError(t, err)
+		var entries []string
+		is.NotError(t, json.Unmarshal([]byte(result), &entries))
+		is.Equal(t, 1001, len(entries))
+		is.Equal(t, "... and 500 more entries, narrow down the path to see them", entries[1000])
+
+		tool = tools.NewListDir(newManyFilesRoot(t, 2500))
+
+		result, err = tool.Execute(t.Context(), mustMarshalJSON(tools.ListDirArgs{}))
+		is.NotError(t, err)
+		entries = nil
+		is.NotError(t, json.Unmarshal([]byte(result), &entries))
+		is.Equal(t, 1001, len(entries))
+		is.Equal(t, "... and at least 1000 more entries, narrow down the path to see them", entries[1000])
+	})
+
+	t.Run("stops when the context is cancelled", func(t *testing.T) {
+		tool := tools.NewListDir(testdata)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		_, err := tool.Execute(ctx, mustMarshalJSON(tools.ListDirArgs{}))
+		is.True(t, errors.Is(err, context.Canceled))
+	})
+}
+
+func TestNewWriteFile(t *testing.T) {
+	t.Run("writes a file, creating directories, and overwrites it", func(t *testing.T) {
+		root := newTestRoot(t, nil)
+		tool := tools.NewWriteFile(root)
+
+		is.Equal(t, "write_file", tool.Name)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.WriteFileArgs{Path: "dir/file.txt", Content: "Hi!"}))
+		is.NotError(t, err)
+		is.Equal(t, "Wrote file at dir/file.txt", result)
+
+		_, err = tool.Execute(t.Context(), mustMarshalJSON(tools.WriteFileArgs{Path: "dir/file.txt", Content: "Hello!"}))
+		is.NotError(t, err)
+
+		content, err := root.ReadFile("dir/file.txt")
+		is.NotError(t, err)
+		is.Equal(t, "Hello!", string(content))
+	})
+
+	t.Run("returns error for missing content without writing", func(t *testing.T) {
+		root := newTestRoot(t, nil)
+		tool := tools.NewWriteFile(root)
+
+		_, err := tool.Execute(t.Context(), json.RawMessage(`{"path":"file.txt"}`))
+		is.True(t, err != nil)
+		is.True(t, strings.HasPrefix(err.Error(), "invalid arguments for write_file: "))
+
+		_, err = root.Stat("file.txt")
+		is.True(t, errors.Is(err, fs.ErrNotExist))
+	})
+
+	t.Run("errors outside the root", func(t *testing.T) {
+		root := newTestRoot(t, nil)
+		tool := tools.NewWriteFile(root)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.WriteFileArgs{Path: "../file.txt", Content: "Hi!"}))
+		is.True(t, err != nil)
+	})
+}
+
+func TestNewDeleteFile(t *testing.T) {
+	t.Run("deletes a file", func(t *testing.T) {
+		root := newTestRoot(t, map[string]string{"file.txt": "Hi!"})
+		tool := tools.NewDeleteFile(root)
+
+		is.Equal(t, "delete_file", tool.Name)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.DeleteFileArgs{Path: "file.txt"}))
+		is.NotError(t, err)
+		is.Equal(t, "Deleted file at file.txt", result)
+
+		_, err = root.Stat("file.txt")
+		is.True(t, errors.Is(err, fs.ErrNotExist))
+	})
+
+	t.Run("errors on a directory", func(t *testing.T) {
+		root := newTestRoot(t, map[string]string{"dir/file.txt": "Hi!"})
+		tool := tools.NewDeleteFile(root)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.DeleteFileArgs{Path: "dir"}))
+		is.Equal(t, "dir is a directory, not a file", err.Error())
+	})
+}
+
+func TestNewMoveFile(t *testing.T) {
+	t.Run("moves a file, creating directories", func(t *testing.T) {
+		root := newTestRoot(t, map[string]string{"file.txt": "Hi!"})
+		tool := tools.NewMoveFile(root)
+
+		is.Equal(t, "move_file", tool.Name)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.MoveFileArgs{Source: "file.txt", Destination: "dir/moved.txt"}))
+		is.NotError(t, err)
+		is.Equal(t, "Moved file.txt to dir/moved.txt", result)
+
+		content, err := root.ReadFile("dir/moved.txt")
+		is.NotError(t, err)
+		is.Equal(t, "Hi!", string(content))
+	})
+
+	t.Run("errors if the destination exists", func(t *testing.T) {
+		root := newTestRoot(t, map[string]string{"a.txt": "a", "b.txt": "b"})
+		tool := tools.NewMoveFile(root)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.MoveFileArgs{Source: "a.txt", Destination: "b.txt"}))
+		is.Equal(t, "destination b.txt already exists", err.Error())
+	})
+}
+
+func TestNewGlob(t *testing.T) {
+	root := newTestRoot(t, map[string]string{
+		".gitignore":        "vendor/\n",
+		"main.go":           "",
+		"cmd/app/main.go":   "",
+		"cmd/app/readme.md": "",
+		"vendor/lib/lib.go": "",
+	})
+
+	t.Run("finds paths matching a pattern at any depth", func(t *testing.T) {
+		tool := tools.NewGlob(root)
+
+		is.Equal(t, "glob", tool.Name)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.GlobArgs{Pattern: "**/*.go"}))
+		is.NotError(t, err)
+		is.Equal(t, `["cmd/app/main.go","main.go"]`, result)
+	})
+
+	t.Run("finds paths relative to a path", func(t *testing.T) {
+		tool := tools.NewGlob(root)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.GlobArgs{Pattern: "*/readme.md", Path: "cmd"}))
+		is.NotError(t, err)
+		is.Equal(t, `["app/readme.md"]`, result)
+	})
+
+	t.Run("returns an empty list if nothing matches", func(t *testing.T) {
+		tool := tools.NewGlob(root)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.GlobArgs{Pattern: "*.txt"}))
+		is.NotError(t, err)
+		is.Equal(t, `[]`, result)
+	})
+
+	t.Run("stops matching after the entry limit", func(t *testing.T) {
+		tool := tools.NewGlob(newManyFilesRoot(t, 2500))
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.GlobArgs{Pattern: "*.txt"}))
+		is.NotError(t, err)
+		var entries []string
+		is.NotError(t, json.Unmarshal([]byte(result), &entries))
+		is.Equal(t, 1001, len(entries))
+		is.Equal(t, "... and at least 1000 more entries, narrow down the path to see them", entries[1000])
+	})
+}
+
+func TestNewGrep(t *testing.T) {
+	root := newTestRoot(t, map[string]string{
+		".gitignore":  "ignored.txt\n",
+		"a.go":        "package a\n\nfunc Hello() {}\n\nfunc hello() {}\n",
+		"b.txt":       "one\ntwo\nHello\nfour\nfive\nsix\nhello\n",
+		"ignored.txt": "Hello\n",
+		"binary.bin":  "Hello\x00\n",
+	})
+
+	t.Run("finds matching lines with line numbers", func(t *testing.T) {
+		tool := tools.NewGrep(root)
+
+		is.Equal(t, "grep", tool.Name)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.GrepArgs{Pattern: "Hello"}))
+		is.NotError(t, err)
+		is.Equal(t, "a.go:3:func Hello() {}\nb.txt:3:Hello\n", result)
+	})
+
+	t.Run("filters file names, ignores case, and adds context lines", func(t *testing.T) {
+		tool := tools.NewGrep(root)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.GrepArgs{Pattern: "hello", Include: "*.txt", IgnoreCase: true, Context: 1}))
+		is.NotError(t, err)
+		is.Equal(t, "b.txt-2-two\nb.txt:3:Hello\nb.txt-4-four\n--\nb.txt-6-six\nb.txt:7:hello\n", result)
+	})
+
+	t.Run("searches a single file", func(t *testing.T) {
+		tool := tools.NewGrep(root)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.GrepArgs{Pattern: "^func", Path: "a.go"}))
+		is.NotError(t, err)
+		is.Equal(t, "a.go:3:func Hello() {}\na.go:5:func hello() {}\n", result)
+	})
+
+	t.Run("reports no matches", func(t *testing.T) {
+		tool := tools.NewGrep(root)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.GrepArgs{Pattern: "nonexistent"}))
+		is.NotError(t, err)
+		is.Equal(t, "No matches found", result)
+	})
+
+	t.Run("errors on an invalid pattern", func(t *testing.T) {
+		tool := tools.NewGrep(root)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.GrepArgs{Pattern: "("}))
+		is.Equal(t, "invalid pattern: error parsing regexp: missing closing ): `(`", err.Error())
+	})
 }
 
 func TestNewEditFile(t *testing.T) {
@@ -129,6 +404,15 @@ func TestNewEditFile(t *testing.T) {
 		is.Equal(t, "New content", string(content))
 	})
 
+	t.Run("returns error for an empty path without creating a file", func(t *testing.T) {
+		root := newTestRoot(t, nil)
+		tool := tools.NewEditFile(root)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.EditFileArgs{ReplaceStr: "Hello"}))
+		is.True(t, err != nil)
+		is.Equal(t, "invalid arguments for edit_file: $.path: must be at least 1 characters long", err.Error())
+	})
+
 	t.Run("creates a new file in subdirectories", func(t *testing.T) {
 		tempDir := t.TempDir()
 		root, err := os.OpenRoot(tempDir)
@@ -193,6 +477,61 @@ func TestNewEditFile(t *testing.T) {
 		is.Equal(t, "search_str not found in file", err.Error())
 	})
 
+	t.Run("replaces all matches", func(t *testing.T) {
+		root := newTestRoot(t, map[string]string{"file.txt": "a b a"})
+		tool := tools.NewEditFile(root)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.EditFileArgs{Path: "file.txt", SearchStr: "a", ReplaceStr: "c", ReplaceAll: true}))
+		is.NotError(t, err)
+
+		content, err := root.ReadFile("file.txt")
+		is.NotError(t, err)
+		is.Equal(t, "c b c", string(content))
+	})
+
+	t.Run("errors if search_str matches more than once", func(t *testing.T) {
+		root := newTestRoot(t, map[string]string{"file.txt": "a b a"})
+		tool := tools.NewEditFile(root)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.EditFileArgs{Path: "file.txt", SearchStr: "a", ReplaceStr: "c"}))
+		is.Equal(t, "search_str found 2 times in file, add more surrounding text to make it unique, or set replace_all", err.Error())
+	})
+
+	t.Run("makes several edits", func(t *testing.T) {
+		root := newTestRoot(t, map[string]string{"file.txt": "one two three"})
+		tool := tools.NewEditFile(root)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.EditFileArgs{
+			Path: "file.txt",
+			Edits: []tools.FileEdit{
+				{SearchStr: "one", ReplaceStr: "1"},
+				{SearchStr: "three", ReplaceStr: "3"},
+			},
+		}))
+		is.NotError(t, err)
+
+		content, err := root.ReadFile("file.txt")
+		is.NotError(t, err)
+		is.Equal(t, "1 two 3", string(content))
+	})
+
+	t.Run("makes no edits if one fails", func(t *testing.T) {
+		root := newTestRoot(t, map[string]string{"file.txt": "one two three"})
+		tool := tools.NewEditFile(root)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.EditFileArgs{
+			Path:       "file.txt",
+			SearchStr:  "one",
+			ReplaceStr: "1",
+			Edits:      []tools.FileEdit{{SearchStr: "four", ReplaceStr: "4"}},
+		}))
+		is.Equal(t, "edit 2: search_str not found in file", err.Error())
+
+		content, err := root.ReadFile("file.txt")
+		is.NotError(t, err)
+		is.Equal(t, "one two three", string(content))
+	})
+
 	t.Run("summarize read_file", func(t *testing.T) {
 		testdata, err := os.OpenRoot("testdata")
 		is.NotError(t, err)
@@ -307,6 +646,32 @@ func TestNewEditFile(t *testing.T) {
 	})
 }
 
+// newTestRoot in a temporary directory, with the given files and their directories.
+func newTestRoot(t *testing.T, files map[string]string) *os.Root {
+	t.Helper()
+
+	root, err := os.OpenRoot(t.TempDir())
+	is.NotError(t, err)
+	t.Cleanup(func() { _ = root.Close() })
+
+	for name, content := range files {
+		is.NotError(t, root.MkdirAll(filepath.Dir(name), 0755))
+		is.NotError(t, root.WriteFile(name, []byte(content), 0644))
+	}
+	return root
+}
+
+// newManyFilesRoot in a temporary directory, with count empty text files.
+func newManyFilesRoot(t *testing.T, count int) *os.Root {
+	t.Helper()
+
+	root := newTestRoot(t, nil)
+	for i := range count {
+		is.NotError(t, root.WriteFile(fmt.Sprintf("%04d.txt", i), nil, 0644))
+	}
+	return root
+}
+
 func mustMarshalJSON(v any) json.RawMessage {
 	d, err := json.Marshal(v)
 	if err != nil {
@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// ignoreRule is a single pattern from a .gitignore file, or given by the model.
+type ignoreRule struct {
+	// base directory the pattern is relative to, "." for the root
+	base    string
+	pattern string
+	negate  bool
+	dirOnly bool
+}
+
+// ignorer matches paths against .gitignore-style rules, where the last matching rule wins.
+// It supports the common subset of gitignore syntax: comments, negation with "!", directory-only
+// patterns with a trailing "/", anchoring with a leading or inner "/", and "*", "?", "[...]" and "**" wildcards.
+// The .git directory is always ignored.
+type ignorer struct {
+	rules []ignoreRule
+}
+
+// newIgnorer for walking dir, with the .gitignore files of the directories above it loaded,
+// and the given extra patterns relative to dir. The .gitignore files in dir and below are loaded
+// with [ignorer.load] while walking.
+func newIgnorer(fsys fs.FS, dir string, patterns []string) (*ignorer, error) {
+	ig := &ignorer{}
+	if dir != "." {
+		parts := strings.Split(dir, "/")
+		for i := range parts {
+			if err := ig.load(fsys, path.Join(parts[:i]...)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, p := range patterns {
+		ig.add(dir, p)
+	}
+	return ig, nil
+}
+
+// load the .gitignore file in dir, if there is one.
+func (ig *ignorer) load(fsys fs.FS, dir string) error {
+	dir = path.Clean(dir)
+	data, err := fs.ReadFile(fsys, path.Join(dir, ".gitignore"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		ig.add(dir, s.Text())
+	}
+	return s.Err()
+}
+
+// add a rule from a single gitignore line, relative to base.
+func (ig *ignorer) add(base, line string) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	// Patterns without a slash match at any depth
+	if !strings.Contains(line, "/") {
+		line = "**/" + line
+	}
+	rule.pattern = strings.TrimPrefix(line, "/")
+	if rule.pattern == "" {
+		return
+	}
+
+	ig.rules = append(ig.rules, rule)
+}
+
+// ignored reports whether the path p, relative to the root, is ignored.
+func (ig *ignorer) ignored(p string, isDir bool) bool {
+	if path.Base(p) == ".git" {
+		return true
+	}
+
+	ignored := false
+	for _, rule := range ig.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		rel := p
+		if rule.base != "." {
+			if !strings.HasPrefix(p, rule.base+"/") {
+				continue
+			}
+			rel = strings.TrimPrefix(p, rule.base+"/")
+		}
+		if matchPath(rule.pattern, rel) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// matchPath reports whether the slash-separated path name matches the pattern.
+// Each pattern element is matched with [path.Match], and "**" matches zero or more elements.
+// Malformed patterns match nothing.
+func matchPath(pattern, name string) bool {
+	return matchElements(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchElements(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Try matching the rest of the pattern at every remaining position
+			for i := 0; i <= len(name); i++ {
+				if matchElements(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+	return len(name) == 0
+}
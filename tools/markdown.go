@@ -0,0 +1,526 @@
+package tools
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// htmlToMarkdown converts an HTML document to Markdown, keeping only the main content of the page.
+// Relative links and images are resolved against base, if it's not nil.
+//
+// The main content is the main element, or the longest article element, or else the body
+// without its header, footer, and asides. Scripts, styles, navigation, forms, and hidden elements
+// are always left out. Headings, paragraphs, lists, block quotes, code blocks, tables, links,
+// images, and inline formatting are converted. The title of the page is added as a heading
+// if the content doesn't have one.
+func htmlToMarkdown(r io.Reader, base *url.URL) (string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", fmt.Errorf("error parsing HTML: %w", err)
+	}
+
+	c := &markdownConverter{base: base}
+
+	content := findMainContent(doc)
+	c.stripPageChrome = content.DataAtom == atom.Body || content == doc
+
+	markdown := c.blocks(content, "\n\n")
+
+	if title := findElement(doc, atom.Title); title != nil {
+		text := collapseWhitespace(strings.TrimSpace(textContent(title)))
+		if text != "" && !headingRE.MatchString(markdown) {
+			markdown = "# " + text + "\n\n" + markdown
+		}
+	}
+
+	return cleanMarkdown(markdown), nil
+}
+
+var headingRE = regexp.MustCompile(`(?m)^# `)
+
+// markdownConverter converts HTML nodes to Markdown.
+type markdownConverter struct {
+	base *url.URL
+	// stripPageChrome leaves out header, footer, and aside elements, when the content is the whole body
+	stripPageChrome bool
+}
+
+// skipped reports whether the node and its children are left out of the Markdown.
+func (c *markdownConverter) skipped(n *html.Node) bool {
+	if n.Type == html.CommentNode || n.Type == html.DoctypeNode {
+		return true
+	}
+	if n.Type != html.ElementNode {
+		return false
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Iframe, atom.Object, atom.Embed,
+		atom.Svg, atom.Canvas, atom.Nav, atom.Form, atom.Button, atom.Input, atom.Select, atom.Textarea,
+		atom.Head, atom.Link, atom.Meta, atom.Dialog:
+		return true
+	case atom.Header, atom.Footer, atom.Aside:
+		if c.stripPageChrome {
+			return true
+		}
+	}
+
+	if hasAttr(n, "hidden") || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	switch attr(n, "role") {
+	case "navigation", "banner", "contentinfo", "search", "dialog":
+		return true
+	}
+	return strings.Contains(strings.ReplaceAll(attr(n, "style"), " ", ""), "display:none")
+}
+
+// blocks converts the children of n to Markdown blocks joined by sep.
+// Consecutive inline children are joined into a paragraph.
+func (c *markdownConverter) blocks(n *html.Node, sep string) string {
+	var blocks []string
+	var paragraph strings.Builder
+
+	flush := func() {
+		if text := trimLines(paragraph.String()); text != "" {
+			blocks = append(blocks, text)
+		}
+		paragraph.Reset()
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if c.skipped(child) {
+			continue
+		}
+		if isInline(child) {
+			paragraph.WriteString(c.inline(child))
+			continue
+		}
+		flush()
+		if block := c.block(child); strings.TrimSpace(block) != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	flush()
+
+	return strings.Join(blocks, sep)
+}
+
+// block converts a block element to Markdown.
+func (c *markdownConverter) block(n *html.Node) string {
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		text := strings.ReplaceAll(trimLines(c.inlineChildren(n)), "\n", " ")
+		if text == "" {
+			return ""
+		}
+		return strings.Repeat("#", level) + " " + text
+
+	case atom.P:
+		return trimLines(c.inlineChildren(n))
+
+	case atom.Pre:
+		return c.codeBlock(n)
+
+	case atom.Ul, atom.Ol:
+		return c.list(n)
+
+	case atom.Blockquote:
+		return prefixLines(c.blocks(n, "\n\n"), "> ", ">")
+
+	case atom.Table:
+		return c.table(n)
+
+	case atom.Hr:
+		return "---"
+
+	case atom.Dt:
+		text := trimLines(c.inlineChildren(n))
+		if text == "" {
+			return ""
+		}
+		return "**" + text + "**"
+
+	case atom.Figcaption:
+		text := trimLines(c.inlineChildren(n))
+		if text == "" {
+			return ""
+		}
+		return "*" + text + "*"
+
+	default:
+		return c.blocks(n, "\n\n")
+	}
+}
+
+// codeBlock converts a pre element to a fenced code block, with the language from its class if there is one.
+func (c *markdownConverter) codeBlock(n *html.Node) string {
+	code := strings.TrimRight(preformattedText(n), "\n")
+	code = strings.TrimLeft(code, "\n")
+
+	language := codeLanguage(n)
+	if language == "" {
+		if child := findElement(n, atom.Code); child != nil {
+			language = codeLanguage(child)
+		}
+	}
+
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fence + language + "\n" + code + "\n" + fence
+}
+
+var codeLanguageRE = regexp.MustCompile(`(?:^|\s)(?:language|lang)-([\w+#-]+)`)
+
+// codeLanguage from a class like "language-go" or "lang-go".
+func codeLanguage(n *html.Node) string {
+	m := codeLanguageRE.FindStringSubmatch(attr(n, "class"))
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// list converts a ul or ol element, with nested lists indented.
+func (c *markdownConverter) list(n *html.Node) string {
+	number := 1
+	if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+		number = start
+	}
+
+	var items []string
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.DataAtom != atom.Li || c.skipped(child) {
+			continue
+		}
+
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+
+		content := c.blocks(child, "\n")
+		if content == "" {
+			continue
+		}
+		indent := strings.Repeat(" ", len(marker))
+		items = append(items, marker+strings.TrimPrefix(prefixLines(content, indent, ""), indent))
+	}
+	return strings.Join(items, "\n")
+}
+
+// table converts a table element to a GitHub-flavored Markdown table, with the first row as the header.
+// Tables with a single column are usually for layout, so their cells are converted as blocks instead.
+func (c *markdownConverter) table(n *html.Node) string {
+	var rows [][]*html.Node
+	var findRows func(n *html.Node)
+	findRows = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			switch child.DataAtom {
+			case atom.Tr:
+				var cells []*html.Node
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.DataAtom == atom.Td || cell.DataAtom == atom.Th {
+						cells = append(cells, cell)
+					}
+				}
+				rows = append(rows, cells)
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				findRows(child)
+			}
+		}
+	}
+	findRows(n)
+
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	if columns == 0 {
+		return ""
+	}
+
+	if columns == 1 {
+		var blocks []string
+		for _, row := range rows {
+			if len(row) == 0 {
+				continue
+			}
+			if block := c.blocks(row[0], "\n\n"); block != "" {
+				blocks = append(blocks, block)
+			}
+		}
+		return strings.Join(blocks, "\n\n")
+	}
+
+	var b strings.Builder
+	for i, row := range rows {
+		b.WriteString("|")
+		for j := range columns {
+			text := ""
+			if j < len(row) {
+				text = strings.Join(strings.Fields(trimLines(c.inlineChildren(row[j]))), " ")
+				text = strings.ReplaceAll(text, "|", `\|`)
+			}
+			b.WriteString(" " + text + " |")
+		}
+		b.WriteString("\n")
+
+		if i == 0 {
+			b.WriteString("|" + strings.Repeat(" --- |", columns) + "\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// inlineChildren converts the children of n as inline content.
+func (c *markdownConverter) inlineChildren(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if !c.skipped(child) {
+			b.WriteString(c.inline(child))
+		}
+	}
+	return b.String()
+}
+
+// inline converts a node as inline content, with collapsed whitespace.
+func (c *markdownConverter) inline(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return collapseWhitespace(n.Data)
+	}
+	if n.Type != html.ElementNode {
+		return c.inlineChildren(n)
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		return "\n"
+
+	case atom.A:
+		text := c.inlineChildren(n)
+		href := strings.TrimSpace(attr(n, "href"))
+		if strings.TrimSpace(text) == "" || href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+			return text
+		}
+		return wrapInline(text, "[", "]("+c.resolve(href)+")")
+
+	case atom.Img:
+		alt := collapseWhitespace(attr(n, "alt"))
+		src := strings.TrimSpace(attr(n, "src"))
+		if src == "" || strings.HasPrefix(src, "data:") {
+			return alt
+		}
+		return "![" + alt + "](" + c.resolve(src) + ")"
+
+	case atom.Strong, atom.B:
+		return wrapInline(c.inlineChildren(n), "**", "**")
+
+	case atom.Em, atom.I:
+		return wrapInline(c.inlineChildren(n), "*", "*")
+
+	case atom.Del, atom.S, atom.Strike:
+		return wrapInline(c.inlineChildren(n), "~~", "~~")
+
+	case atom.Code, atom.Kbd, atom.Samp:
+		code := collapseWhitespace(textContent(n))
+		if strings.TrimSpace(code) == "" {
+			return code
+		}
+		fence := "`"
+		for strings.Contains(code, fence) {
+			fence += "`"
+		}
+		if len(fence) > 1 {
+			return fence + " " + code + " " + fence
+		}
+		return fence + code + fence
+
+	default:
+		if isInline(n) {
+			return c.inlineChildren(n)
+		}
+		// A block inside inline content, like a div in a link, is separated by spaces
+		return " " + c.inlineChildren(n) + " "
+	}
+}
+
+// resolve a link against the base URL.
+func (c *markdownConverter) resolve(ref string) string {
+	if c.base == nil {
+		return ref
+	}
+	u, err := c.base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return u.String()
+}
+
+// inlineAtoms are the elements converted as inline content.
+var inlineAtoms = []atom.Atom{
+	atom.A, atom.Abbr, atom.B, atom.Bdi, atom.Bdo, atom.Br, atom.Cite, atom.Code, atom.Data, atom.Del,
+	atom.Dfn, atom.Em, atom.I, atom.Img, atom.Ins, atom.Kbd, atom.Label, atom.Mark, atom.Q, atom.S,
+	atom.Samp, atom.Small, atom.Span, atom.Strike, atom.Strong, atom.Sub, atom.Sup, atom.Time, atom.U,
+	atom.Var, atom.Wbr, atom.Font,
+}
+
+// isInline reports whether the node is text or an inline element.
+func isInline(n *html.Node) bool {
+	return n.Type == html.TextNode || n.Type == html.ElementNode && slices.Contains(inlineAtoms, n.DataAtom)
+}
+
+// findMainContent returns the main element, or the article element with the most text,
+// or the body, or the document itself if there is no body.
+func findMainContent(doc *html.Node) *html.Node {
+	var main, body *html.Node
+	var articles []*html.Node
+	var find func(n *html.Node)
+	find = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch {
+			case main == nil && (n.DataAtom == atom.Main || attr(n, "role") == "main"):
+				main = n
+			case n.DataAtom == atom.Article:
+				articles = append(articles, n)
+			case n.DataAtom == atom.Body:
+				body = n
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			find(child)
+		}
+	}
+	find(doc)
+
+	switch {
+	case main != nil:
+		return main
+	case len(articles) > 0:
+		return slices.MaxFunc(articles, func(a, b *html.Node) int {
+			return len(textContent(a)) - len(textContent(b))
+		})
+	case body != nil:
+		return body
+	default:
+		return doc
+	}
+}
+
+// findElement returns the first element of the given type in n, or nil.
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if found := findElement(child, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// textContent of n and its children, without any changes to whitespace.
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+// preformattedText of n and its children, with br elements as newlines.
+func preformattedText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	if n.Type == html.ElementNode && n.DataAtom == atom.Br {
+		return "\n"
+	}
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(preformattedText(child))
+	}
+	return b.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	return slices.ContainsFunc(n.Attr, func(a html.Attribute) bool { return a.Key == key })
+}
+
+var whitespaceRE = regexp.MustCompile(`\s+`)
+
+// collapseWhitespace to single spaces, like browsers do outside preformatted text.
+func collapseWhitespace(s string) string {
+	return whitespaceRE.ReplaceAllString(s, " ")
+}
+
+// wrapInline wraps the text in the prefix and suffix, keeping any surrounding spaces outside,
+// so "<b> bold </b>" becomes " **bold** " and not "** bold **".
+func wrapInline(s, prefix, suffix string) string {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	leading := s[:strings.Index(s, trimmed)]
+	trailing := s[len(leading)+len(trimmed):]
+	return leading + prefix + trimmed + suffix + trailing
+}
+
+// trimLines trims spaces around each line, and empty lines around the text.
+func trimLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// prefixLines prefixes each line, using emptyPrefix for empty lines.
+func prefixLines(s, prefix, emptyPrefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = emptyPrefix
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+var blankLinesRE = regexp.MustCompile(`\n{3,}`)
+
+// cleanMarkdown removes trailing spaces and extra blank lines.
+func cleanMarkdown(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	s = strings.Join(lines, "\n")
+	return strings.TrimSpace(blankLinesRE.ReplaceAllString(s, "\n\n"))
+}
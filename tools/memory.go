@@ -2,7 +2,7 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 
 	"maragu.dev/gai"
@@ -13,62 +13,43 @@ type SaveMemoryArgs struct {
 	Memory string `json:"memory"`
 }
 
-type memorySaver interface {
+// MemorySaver saves memories. See [MemoryStore] and [SemanticMemoryStore] for implementations.
+type MemorySaver interface {
 	SaveMemory(ctx context.Context, memory string) error
 }
 
 // NewSaveMemory creates a new tool that stores a memory via the given memory saver.
-func NewSaveMemory(ms memorySaver) gai.Tool {
-	return gai.Tool{
+func NewSaveMemory(ms MemorySaver) gai.Tool {
+	return gai.NewTool(gai.NewToolOptions[SaveMemoryArgs]{
 		Name:        "save_memory",
 		Description: "Save a memory, something you would like to remember for later conversations.",
-		Schema:      gai.GenerateToolSchema[SaveMemoryArgs](),
-		Summarize: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
-			var args SaveMemoryArgs
-			if err := json.Unmarshal(rawArgs, &args); err != nil {
-				return "error parsing arguments", nil
-			}
-
-			// Truncate memory content
-			memory := args.Memory
-			if len(memory) > 30 {
-				memory = memory[:30] + "..."
-			}
-
-			return fmt.Sprintf(`memory="%s"`, memory), nil
+		Summarize: func(ctx context.Context, args SaveMemoryArgs) (string, error) {
+			return fmt.Sprintf(`memory="%s"`, truncateMemory(args.Memory)), nil
 		},
-		Execute: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
-			var args SaveMemoryArgs
-			if err := json.Unmarshal(rawArgs, &args); err != nil {
-				return "", fmt.Errorf("error unmarshaling save_memory args from JSON: %w", err)
-			}
-
+		Execute: func(ctx context.Context, args SaveMemoryArgs) (string, error) {
 			if err := ms.SaveMemory(ctx, args.Memory); err != nil {
 				return "", fmt.Errorf("error saving memory: %w", err)
 			}
 
 			return "OK", nil
 		},
-	}
+	})
 }
 
 // GetMemoryArgs holds the arguments for the GetMemories tool.
 type GetMemoryArgs struct{}
 
-type memoryGetter interface {
+// MemoryGetter gets all saved memories.
+type MemoryGetter interface {
 	GetMemories(ctx context.Context) ([]string, error)
 }
 
 // NewGetMemories creates a new tool that returns all saved memories via the given memory getter.
-func NewGetMemories(mg memoryGetter) gai.Tool {
-	return gai.Tool{
+func NewGetMemories(mg MemoryGetter) gai.Tool {
+	return gai.NewTool(gai.NewToolOptions[GetMemoryArgs]{
 		Name:        "get_memories",
 		Description: "Get all saved memories.",
-		Schema:      gai.GenerateToolSchema[GetMemoryArgs](),
-		Summarize: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
-			return "", nil
-		},
-		Execute: func(ctx context.Context, _ json.RawMessage) (string, error) {
+		Execute: func(ctx context.Context, _ GetMemoryArgs) (string, error) {
 			memories, err := mg.GetMemories(ctx)
 			if err != nil {
 				return "", fmt.Errorf("error getting memories: %w", err)
@@ -76,7 +57,7 @@ func NewGetMemories(mg memoryGetter) gai.Tool {
 
 			return fmt.Sprintf("Memories: %v", memories), nil
 		},
-	}
+	})
 }
 
 // SearchMemoriesArgs holds the arguments for the SearchMemories tool.
@@ -84,29 +65,20 @@ type SearchMemoriesArgs struct {
 	Query string `json:"query"`
 }
 
-type memorySearcher interface {
+// MemorySearcher searches saved memories, returning the most relevant first.
+type MemorySearcher interface {
 	SearchMemories(ctx context.Context, query string) ([]string, error)
 }
 
 // NewSearchMemories creates a new tool that searches saved memories by query via the given memory searcher.
-func NewSearchMemories(ms memorySearcher) gai.Tool {
-	return gai.Tool{
+func NewSearchMemories(ms MemorySearcher) gai.Tool {
+	return gai.NewTool(gai.NewToolOptions[SearchMemoriesArgs]{
 		Name:        "search_memories",
 		Description: "Search saved memories using a query string.",
-		Schema:      gai.GenerateToolSchema[SearchMemoriesArgs](),
-		Summarize: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
-			var args SearchMemoriesArgs
-			if err := json.Unmarshal(rawArgs, &args); err != nil {
-				return "error parsing arguments", nil
-			}
+		Summarize: func(ctx context.Context, args SearchMemoriesArgs) (string, error) {
 			return fmt.Sprintf(`query="%s"`, args.Query), nil
 		},
-		Execute: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
-			var args SearchMemoriesArgs
-			if err := json.Unmarshal(rawArgs, &args); err != nil {
-				return "", fmt.Errorf("error unmarshaling search_memories args from JSON: %w", err)
-			}
-
+		Execute: func(ctx context.Context, args SearchMemoriesArgs) (string, error) {
 			memories, err := ms.SearchMemories(ctx, args.Query)
 			if err != nil {
 				return "", fmt.Errorf("error searching memories: %w", err)
@@ -118,5 +90,75 @@ func NewSearchMemories(ms memorySearcher) gai.Tool {
 
 			return fmt.Sprintf("Found memories: %v", memories), nil
 		},
+	})
+}
+
+// ErrMemoryNotFound is returned by a [MemoryDeleter] or [MemoryUpdater] when there is no such memory.
+var ErrMemoryNotFound = errors.New("memory not found")
+
+// DeleteMemoryArgs holds the arguments for the DeleteMemory tool.
+type DeleteMemoryArgs struct {
+	Memory string `json:"memory" jsonschema_description:"The memory to delete, exactly as saved."`
+}
+
+// MemoryDeleter deletes a saved memory, identified by its content.
+// It returns [ErrMemoryNotFound] if there is no such memory.
+type MemoryDeleter interface {
+	DeleteMemory(ctx context.Context, memory string) error
+}
+
+// NewDeleteMemory creates a new tool that deletes a saved memory via the given memory deleter.
+func NewDeleteMemory(md MemoryDeleter) gai.Tool {
+	return gai.NewTool(gai.NewToolOptions[DeleteMemoryArgs]{
+		Name:        "delete_memory",
+		Description: "Delete a saved memory that is wrong or no longer relevant. Get or search memories first to find it.",
+		Summarize: func(ctx context.Context, args DeleteMemoryArgs) (string, error) {
+			return fmt.Sprintf(`memory="%s"`, truncateMemory(args.Memory)), nil
+		},
+		Execute: func(ctx context.Context, args DeleteMemoryArgs) (string, error) {
+			if err := md.DeleteMemory(ctx, args.Memory); err != nil {
+				return "", fmt.Errorf("error deleting memory: %w", err)
+			}
+
+			return "OK", nil
+		},
+	})
+}
+
+// UpdateMemoryArgs holds the arguments for the UpdateMemory tool.
+type UpdateMemoryArgs struct {
+	Memory    string `json:"memory" jsonschema_description:"The memory to update, exactly as saved."`
+	NewMemory string `json:"new_memory" jsonschema_description:"The new content of the memory."`
+}
+
+// MemoryUpdater replaces the content of a saved memory, identified by its content.
+// It returns [ErrMemoryNotFound] if there is no such memory.
+type MemoryUpdater interface {
+	UpdateMemory(ctx context.Context, memory, newMemory string) error
+}
+
+// NewUpdateMemory creates a new tool that updates a saved memory via the given memory updater.
+func NewUpdateMemory(mu MemoryUpdater) gai.Tool {
+	return gai.NewTool(gai.NewToolOptions[UpdateMemoryArgs]{
+		Name:        "update_memory",
+		Description: "Update a saved memory with new content, for example when it has changed. Get or search memories first to find it.",
+		Summarize: func(ctx context.Context, args UpdateMemoryArgs) (string, error) {
+			return fmt.Sprintf(`memory="%s" new_memory="%s"`, truncateMemory(args.Memory), truncateMemory(args.NewMemory)), nil
+		},
+		Execute: func(ctx context.Context, args UpdateMemoryArgs) (string, error) {
+			if err := mu.UpdateMemory(ctx, args.Memory, args.NewMemory); err != nil {
+				return "", fmt.Errorf("error updating memory: %w", err)
+			}
+
+			return "OK", nil
+		},
+	})
+}
+
+// truncateMemory content for summaries.
+func truncateMemory(memory string) string {
+	if len(memory) > 30 {
+		return memory[:30] + "..."
 	}
+	return memory
 }
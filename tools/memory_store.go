@@ -0,0 +1,393 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/eval"
+	"maragu.dev/gai/vector"
+)
+
+// MemoryStore keeps memories in memory, optionally backed by a JSON file, and searches them by keyword.
+// Saving a memory that already exists does nothing. It is safe for concurrent use.
+type MemoryStore struct {
+	lock     sync.RWMutex
+	memories []string
+	path     string
+}
+
+// NewMemoryStoreOptions for [NewMemoryStore].
+type NewMemoryStoreOptions struct {
+	// Path of a JSON file to load the memories from, if it exists, and save them to after every change.
+	// Empty means the memories are only kept in memory.
+	Path string
+}
+
+// NewMemoryStore with the given options. It returns an error if the file can't be read or parsed.
+func NewMemoryStore(opts NewMemoryStoreOptions) (*MemoryStore, error) {
+	s := &MemoryStore{path: opts.Path}
+	if opts.Path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(opts.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("error reading memories: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.memories); err != nil {
+		return nil, fmt.Errorf("error unmarshaling memories from JSON: %w", err)
+	}
+	return s, nil
+}
+
+// SaveMemory satisfies [MemorySaver].
+func (s *MemoryStore) SaveMemory(ctx context.Context, memory string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if slices.Contains(s.memories, memory) {
+		return nil
+	}
+	return s.change(append(slices.Clone(s.memories), memory))
+}
+
+// GetMemories satisfies [MemoryGetter], returning the memories in the order they were saved.
+func (s *MemoryStore) GetMemories(ctx context.Context) ([]string, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return slices.Clone(s.memories), nil
+}
+
+// SearchMemories satisfies [MemorySearcher]. It returns the memories containing any of the words of the query,
+// ignoring case, with the memories containing the most distinct query words first.
+func (s *MemoryStore) SearchMemories(ctx context.Context, query string) ([]string, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	words := keywords(query)
+
+	type match struct {
+		memory string
+		score  int
+	}
+	var matches []match
+	for _, memory := range s.memories {
+		memoryWords := keywords(memory)
+		score := 0
+		for _, word := range words {
+			if slices.Contains(memoryWords, word) {
+				score++
+			}
+		}
+		if score > 0 {
+			matches = append(matches, match{memory: memory, score: score})
+		}
+	}
+
+	// Stable, so memories with equal scores stay in the order they were saved
+	slices.SortStableFunc(matches, func(a, b match) int {
+		return cmp.Compare(b.score, a.score)
+	})
+
+	var memories []string
+	for _, m := range matches {
+		memories = append(memories, m.memory)
+	}
+	return memories, nil
+}
+
+// DeleteMemory satisfies [MemoryDeleter].
+func (s *MemoryStore) DeleteMemory(ctx context.Context, memory string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	i := slices.Index(s.memories, memory)
+	if i < 0 {
+		return ErrMemoryNotFound
+	}
+	return s.change(slices.Delete(slices.Clone(s.memories), i, i+1))
+}
+
+// UpdateMemory satisfies [MemoryUpdater]. If the new memory already exists, the old one is just deleted.
+func (s *MemoryStore) UpdateMemory(ctx context.Context, memory, newMemory string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	i := slices.Index(s.memories, memory)
+	if i < 0 {
+		return ErrMemoryNotFound
+	}
+
+	memories := slices.Clone(s.memories)
+	if slices.Contains(memories, newMemory) {
+		memories = slices.Delete(memories, i, i+1)
+	} else {
+		memories[i] = newMemory
+	}
+	return s.change(memories)
+}
+
+// change the memories to the given ones, saving them to the file first if there is one.
+// The caller must hold the write lock.
+func (s *MemoryStore) change(memories []string) error {
+	if s.path != "" {
+		if err := writeMemories(s.path, memories); err != nil {
+			return err
+		}
+	}
+	s.memories = memories
+	return nil
+}
+
+// writeMemories to the file at path as JSON, replacing it atomically.
+func writeMemories(path string, memories []string) error {
+	data, err := json.MarshalIndent(memories, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling memories to JSON: %w", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating memories file: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("error writing memories file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing memories file: %w", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("error writing memories file: %w", err)
+	}
+	return nil
+}
+
+// keywords in s, lowercased and split on anything that's not a letter or number.
+func keywords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// SemanticMemoryStore keeps memories in memory with their embeddings, and searches them by
+// the cosine similarity of their embeddings to the embedding of the query. Memories and queries
+// with a zero embedding match nothing, since their similarity is undefined.
+// Saving a memory that already exists does nothing. It is safe for concurrent use.
+type SemanticMemoryStore[T gai.VectorComponent] struct {
+	embedder gai.Embedder[T]
+	limit    int
+	lock     sync.RWMutex
+	memories []semanticMemory[T]
+	minScore eval.Score
+}
+
+type semanticMemory[T gai.VectorComponent] struct {
+	content   string
+	embedding []T
+}
+
+// NewSemanticMemoryStoreOptions for [NewSemanticMemoryStore].
+type NewSemanticMemoryStoreOptions[T gai.VectorComponent] struct {
+	// Embedder for memories and queries. Required.
+	Embedder gai.Embedder[T]
+	// Limit of memories returned from a search. Zero means 5.
+	Limit int
+	// MinScore of the similarity between a memory and the query for the memory to be returned.
+	// Zero means all memories are returned, up to the limit.
+	MinScore eval.Score
+}
+
+// NewSemanticMemoryStore with the given options. Panics if the embedder is nil.
+func NewSemanticMemoryStore[T gai.VectorComponent](opts NewSemanticMemoryStoreOptions[T]) *SemanticMemoryStore[T] {
+	if opts.Embedder == nil {
+		panic("embedder cannot be nil")
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 5
+	}
+
+	return &SemanticMemoryStore[T]{
+		embedder: opts.Embedder,
+		limit:    opts.Limit,
+		minScore: opts.MinScore,
+	}
+}
+
+// SaveMemory satisfies [MemorySaver].
+func (s *SemanticMemoryStore[T]) SaveMemory(ctx context.Context, memory string) error {
+	s.lock.RLock()
+	exists := s.index(memory) >= 0
+	s.lock.RUnlock()
+	if exists {
+		return nil
+	}
+
+	// Embed without holding the lock, since it may take a while
+	embedding, err := s.embed(ctx, memory, gai.EmbedTaskDocument)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.index(memory) >= 0 {
+		return nil
+	}
+	s.memories = append(s.memories, semanticMemory[T]{content: memory, embedding: embedding})
+	return nil
+}
+
+// GetMemories satisfies [MemoryGetter], returning the memories in the order they were saved.
+func (s *SemanticMemoryStore[T]) GetMemories(ctx context.Context) ([]string, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	memories := make([]string, 0, len(s.memories))
+	for _, m := range s.memories {
+		memories = append(memories, m.content)
+	}
+	return memories, nil
+}
+
+// SearchMemories satisfies [MemorySearcher].
+func (s *SemanticMemoryStore[T]) SearchMemories(ctx context.Context, query string) ([]string, error) {
+	embedding, err := s.embed(ctx, query, gai.EmbedTaskSearchQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cosine similarity is undefined for a zero vector, so it matches nothing
+	if vector.Norm(embedding) == 0 {
+		return nil, nil
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	type match struct {
+		memory string
+		score  eval.Score
+	}
+	var matches []match
+	for _, m := range s.memories {
+		if len(m.embedding) != len(embedding) {
+			return nil, fmt.Errorf("embedding of memory has %v dimensions, but query has %v", len(m.embedding), len(embedding))
+		}
+		if vector.Norm(m.embedding) == 0 {
+			continue
+		}
+		score := eval.CosineSimilarity(embedding, m.embedding)
+		if score >= s.minScore {
+			matches = append(matches, match{memory: m.content, score: score})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b match) int {
+		return cmp.Compare(b.score, a.score)
+	})
+
+	var memories []string
+	for _, m := range matches[:min(len(matches), s.limit)] {
+		memories = append(memories, m.memory)
+	}
+	return memories, nil
+}
+
+// DeleteMemory satisfies [MemoryDeleter].
+func (s *SemanticMemoryStore[T]) DeleteMemory(ctx context.Context, memory string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	i := s.index(memory)
+	if i < 0 {
+		return ErrMemoryNotFound
+	}
+	s.memories = slices.Delete(s.memories, i, i+1)
+	return nil
+}
+
+// UpdateMemory satisfies [MemoryUpdater], embedding the new memory.
+// If the new memory already exists, the old one is just deleted.
+func (s *SemanticMemoryStore[T]) UpdateMemory(ctx context.Context, memory, newMemory string) error {
+	s.lock.RLock()
+	exists := s.index(memory) >= 0
+	s.lock.RUnlock()
+	if !exists {
+		return ErrMemoryNotFound
+	}
+
+	embedding, err := s.embed(ctx, newMemory, gai.EmbedTaskDocument)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// The memory may have changed while embedding
+	i := s.index(memory)
+	if i < 0 {
+		return ErrMemoryNotFound
+	}
+	if s.index(newMemory) >= 0 {
+		s.memories = slices.Delete(s.memories, i, i+1)
+		return nil
+	}
+	s.memories[i] = semanticMemory[T]{content: newMemory, embedding: embedding}
+	return nil
+}
+
+// index of the memory, or -1 if it doesn't exist. The caller must hold the lock.
+func (s *SemanticMemoryStore[T]) index(memory string) int {
+	return slices.IndexFunc(s.memories, func(m semanticMemory[T]) bool {
+		return m.content == memory
+	})
+}
+
+// embed the text for the given task, [gai.EmbedTaskDocument] for memories and [gai.EmbedTaskSearchQuery] for queries.
+func (s *SemanticMemoryStore[T]) embed(ctx context.Context, text string, task gai.EmbedTask) ([]T, error) {
+	req := gai.NewTextEmbedRequest(text)
+	req.Task = task
+	res, err := s.embedder.Embed(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("error embedding: %w", err)
+	}
+	if len(res.Embedding) == 0 {
+		return nil, errors.New("error embedding: empty embedding")
+	}
+	return res.Embedding, nil
+}
+
+var (
+	_ MemorySaver    = (*MemoryStore)(nil)
+	_ MemoryGetter   = (*MemoryStore)(nil)
+	_ MemorySearcher = (*MemoryStore)(nil)
+	_ MemoryDeleter  = (*MemoryStore)(nil)
+	_ MemoryUpdater  = (*MemoryStore)(nil)
+
+	_ MemorySaver    = (*SemanticMemoryStore[float64])(nil)
+	_ MemoryGetter   = (*SemanticMemoryStore[float64])(nil)
+	_ MemorySearcher = (*SemanticMemoryStore[float64])(nil)
+	_ MemoryDeleter  = (*SemanticMemoryStore[float64])(nil)
+	_ MemoryUpdater  = (*SemanticMemoryStore[float64])(nil)
+)
@@ -0,0 +1,196 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/gaitest"
+	"maragu.dev/gai/tools"
+)
+
+func TestMemoryStore(t *testing.T) {
+	t.Run("saves, gets, updates, and deletes memories", func(t *testing.T) {
+		s, err := tools.NewMemoryStore(tools.NewMemoryStoreOptions{})
+		is.NotError(t, err)
+
+		is.NotError(t, s.SaveMemory(t.Context(), "The user likes tea"))
+		is.NotError(t, s.SaveMemory(t.Context(), "The user lives in Copenhagen"))
+		is.NotError(t, s.SaveMemory(t.Context(), "The user likes tea"))
+
+		memories, err := s.GetMemories(t.Context())
+		is.NotError(t, err)
+		is.EqualSlice(t, []string{"The user likes tea", "The user lives in Copenhagen"}, memories)
+
+		is.NotError(t, s.UpdateMemory(t.Context(), "The user likes tea", "The user likes coffee"))
+		is.NotError(t, s.DeleteMemory(t.Context(), "The user lives in Copenhagen"))
+
+		memories, err = s.GetMemories(t.Context())
+		is.NotError(t, err)
+		is.EqualSlice(t, []string{"The user likes coffee"}, memories)
+	})
+
+	t.Run("returns ErrMemoryNotFound for unknown memories", func(t *testing.T) {
+		s, err := tools.NewMemoryStore(tools.NewMemoryStoreOptions{})
+		is.NotError(t, err)
+
+		is.True(t, errors.Is(s.DeleteMemory(t.Context(), "Nope"), tools.ErrMemoryNotFound))
+		is.True(t, errors.Is(s.UpdateMemory(t.Context(), "Nope", "Yes"), tools.ErrMemoryNotFound))
+	})
+
+	t.Run("searches by keywords, with the most matching words first", func(t *testing.T) {
+		s, err := tools.NewMemoryStore(tools.NewMemoryStoreOptions{})
+		is.NotError(t, err)
+
+		is.NotError(t, s.SaveMemory(t.Context(), "The user likes tea"))
+		is.NotError(t, s.SaveMemory(t.Context(), "The user's cat is called Tea-Rex"))
+		is.NotError(t, s.SaveMemory(t.Context(), "The user has a green cat"))
+		is.NotError(t, s.SaveMemory(t.Context(), "The user teaches math"))
+
+		memories, err := s.SearchMemories(t.Context(), "Cat tea?")
+		is.NotError(t, err)
+		is.EqualSlice(t, []string{"The user's cat is called Tea-Rex", "The user likes tea", "The user has a green cat"}, memories)
+
+		memories, err = s.SearchMemories(t.Context(), "dogs")
+		is.NotError(t, err)
+		is.Equal(t, 0, len(memories))
+	})
+
+	t.Run("saves to and loads from a file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "memories.json")
+
+		s, err := tools.NewMemoryStore(tools.NewMemoryStoreOptions{Path: path})
+		is.NotError(t, err)
+		is.NotError(t, s.SaveMemory(t.Context(), "The user likes tea"))
+		is.NotError(t, s.SaveMemory(t.Context(), "The user lives in Copenhagen"))
+		is.NotError(t, s.DeleteMemory(t.Context(), "The user likes tea"))
+
+		s, err = tools.NewMemoryStore(tools.NewMemoryStoreOptions{Path: path})
+		is.NotError(t, err)
+		memories, err := s.GetMemories(t.Context())
+		is.NotError(t, err)
+		is.EqualSlice(t, []string{"The user lives in Copenhagen"}, memories)
+
+		entries, err := os.ReadDir(filepath.Dir(path))
+		is.NotError(t, err)
+		is.Equal(t, 1, len(entries))
+	})
+
+	t.Run("returns error for an invalid file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "memories.json")
+		is.NotError(t, os.WriteFile(path, []byte("{"), 0600))
+
+		_, err := tools.NewMemoryStore(tools.NewMemoryStoreOptions{Path: path})
+		is.True(t, err != nil)
+	})
+
+	t.Run("works with the memory tools", func(t *testing.T) {
+		s, err := tools.NewMemoryStore(tools.NewMemoryStoreOptions{})
+		is.NotError(t, err)
+
+		_, err = tools.NewSaveMemory(s).Execute(t.Context(), mustMarshalJSON(tools.SaveMemoryArgs{Memory: "The user likes tea"}))
+		is.NotError(t, err)
+
+		result, err := tools.NewSearchMemories(s).Execute(t.Context(), mustMarshalJSON(tools.SearchMemoriesArgs{Query: "tea"}))
+		is.NotError(t, err)
+		is.Equal(t, "Found memories: [The user likes tea]", result)
+	})
+}
+
+func TestSemanticMemoryStore(t *testing.T) {
+	t.Run("searches by similarity, up to the limit", func(t *testing.T) {
+		embedder := gaitest.NewEmbedder[float64](64)
+		s := tools.NewSemanticMemoryStore(tools.NewSemanticMemoryStoreOptions[float64]{Embedder: embedder, Limit: 2})
+
+		is.NotError(t, s.SaveMemory(t.Context(), "The user likes tea"))
+		is.NotError(t, s.SaveMemory(t.Context(), "The user lives in Copenhagen"))
+		is.NotError(t, s.SaveMemory(t.Context(), "The user has a cat"))
+		is.NotError(t, s.SaveMemory(t.Context(), "The user has a cat"))
+
+		// The fake embedder embeds equal texts to equal vectors, so an exact match is the most similar
+		memories, err := s.SearchMemories(t.Context(), "The user lives in Copenhagen")
+		is.NotError(t, err)
+		is.Equal(t, 2, len(memories))
+		is.Equal(t, "The user lives in Copenhagen", memories[0])
+
+		memories, err = s.GetMemories(t.Context())
+		is.NotError(t, err)
+		is.EqualSlice(t, []string{"The user likes tea", "The user lives in Copenhagen", "The user has a cat"}, memories)
+
+		// Three saves and one search, since the duplicate save isn't embedded
+		requests := embedder.Requests()
+		is.Equal(t, 4, len(requests))
+		for _, req := range requests[:3] {
+			is.Equal(t, gai.EmbedTaskDocument, req.Task)
+		}
+		is.Equal(t, gai.EmbedTaskSearchQuery, requests[3].Task)
+	})
+
+	t.Run("leaves out memories below the minimum score", func(t *testing.T) {
+		s := tools.NewSemanticMemoryStore(tools.NewSemanticMemoryStoreOptions[float64]{
+			Embedder: gaitest.NewEmbedder[float64](64),
+			MinScore: 0.99,
+		})
+
+		is.NotError(t, s.SaveMemory(t.Context(), "The user likes tea"))
+		is.NotError(t, s.SaveMemory(t.Context(), "The user has a cat"))
+
+		memories, err := s.SearchMemories(t.Context(), "The user has a cat")
+		is.NotError(t, err)
+		is.EqualSlice(t, []string{"The user has a cat"}, memories)
+	})
+
+	t.Run("skips memories and queries with a zero embedding", func(t *testing.T) {
+		s := tools.NewSemanticMemoryStore(tools.NewSemanticMemoryStoreOptions[float64]{
+			Embedder: &zeroEmbedder{Embedder: gaitest.NewEmbedder[float64](64), zero: "nothing"},
+		})
+
+		is.NotError(t, s.SaveMemory(t.Context(), "The user likes tea"))
+		is.NotError(t, s.SaveMemory(t.Context(), "nothing"))
+
+		memories, err := s.SearchMemories(t.Context(), "The user likes tea")
+		is.NotError(t, err)
+		is.EqualSlice(t, []string{"The user likes tea"}, memories)
+
+		memories, err = s.SearchMemories(t.Context(), "nothing")
+		is.NotError(t, err)
+		is.Equal(t, 0, len(memories))
+	})
+
+	t.Run("updates and deletes memories", func(t *testing.T) {
+		embedder := gaitest.NewEmbedder[float32](64)
+		s := tools.NewSemanticMemoryStore(tools.NewSemanticMemoryStoreOptions[float32]{Embedder: embedder})
+
+		is.NotError(t, s.SaveMemory(t.Context(), "The user likes tea"))
+		is.NotError(t, s.SaveMemory(t.Context(), "The user has a cat"))
+
+		is.NotError(t, s.UpdateMemory(t.Context(), "The user likes tea", "The user likes coffee"))
+		requests := embedder.Requests()
+		is.Equal(t, gai.EmbedTaskDocument, requests[len(requests)-1].Task)
+		is.NotError(t, s.DeleteMemory(t.Context(), "The user has a cat"))
+		is.True(t, errors.Is(s.DeleteMemory(t.Context(), "The user has a cat"), tools.ErrMemoryNotFound))
+		is.True(t, errors.Is(s.UpdateMemory(t.Context(), "Nope", "Yes"), tools.ErrMemoryNotFound))
+
+		memories, err := s.SearchMemories(t.Context(), "The user likes coffee")
+		is.NotError(t, err)
+		is.EqualSlice(t, []string{"The user likes coffee"}, memories)
+	})
+}
+
+// zeroEmbedder embeds the text zero to a zero vector, and everything else with the wrapped embedder.
+type zeroEmbedder struct {
+	*gaitest.Embedder[float64]
+	zero string
+}
+
+func (e *zeroEmbedder) Embed(ctx context.Context, req gai.EmbedRequest) (gai.EmbedResponse[float64], error) {
+	if req.Parts[0].Text() == e.zero {
+		return gai.EmbedResponse[float64]{Embedding: make([]float64, 64)}, nil
+	}
+	return e.Embedder.Embed(ctx, req)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"slices"
 	"strings"
 	"testing"
 
@@ -48,6 +49,30 @@ func (m *mockMemoryStore) SearchMemories(_ context.Context, query string) ([]str
 	return results, nil
 }
 
+func (m *mockMemoryStore) DeleteMemory(_ context.Context, memory string) error {
+	if m.failMode {
+		return errors.New("mock memory store fail")
+	}
+	i := slices.Index(m.memories, memory)
+	if i < 0 {
+		return tools.ErrMemoryNotFound
+	}
+	m.memories = slices.Delete(m.memories, i, i+1)
+	return nil
+}
+
+func (m *mockMemoryStore) UpdateMemory(_ context.Context, memory, newMemory string) error {
+	if m.failMode {
+		return errors.New("mock memory store fail")
+	}
+	i := slices.Index(m.memories, memory)
+	if i < 0 {
+		return tools.ErrMemoryNotFound
+	}
+	m.memories[i] = newMemory
+	return nil
+}
+
 func TestNewSaveMemory(t *testing.T) {
 	t.Run("saves a memory successfully", func(t *testing.T) {
 		store := &mockMemoryStore{}
@@ -85,7 +110,7 @@ func TestNewSaveMemory(t *testing.T) {
 		_, err := tool.Execute(t.Context(), json.RawMessage(`{invalid json`))
 
 		is.True(t, err != nil)
-		is.True(t, strings.Contains(err.Error(), "error unmarshaling"))
+		is.True(t, strings.HasPrefix(err.Error(), "invalid arguments for save_memory: "))
 	})
 
 	t.Run("summarize save_memory with short memory", func(t *testing.T) {
@@ -246,7 +271,7 @@ func TestNewSearchMemories(t *testing.T) {
 		_, err := tool.Execute(t.Context(), json.RawMessage(`{invalid json`))
 
 		is.True(t, err != nil)
-		is.True(t, strings.Contains(err.Error(), "error unmarshaling"))
+		is.True(t, strings.HasPrefix(err.Error(), "invalid arguments for search_memories: "))
 	})
 
 	t.Run("summarize search_memories", func(t *testing.T) {
@@ -271,3 +296,86 @@ func TestNewSearchMemories(t *testing.T) {
 		is.Equal(t, "error parsing arguments", summary)
 	})
 }
+
+func TestNewDeleteMemory(t *testing.T) {
+	t.Run("deletes a memory successfully", func(t *testing.T) {
+		store := &mockMemoryStore{memories: []string{"Memory 1", "Memory 2"}}
+		tool := tools.NewDeleteMemory(store)
+
+		is.Equal(t, "delete_memory", tool.Name)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.DeleteMemoryArgs{
+			Memory: "Memory 1",
+		}))
+
+		is.NotError(t, err)
+		is.Equal(t, "OK", result)
+		is.EqualSlice(t, []string{"Memory 2"}, store.memories)
+	})
+
+	t.Run("returns error when the memory is not found", func(t *testing.T) {
+		store := &mockMemoryStore{}
+		tool := tools.NewDeleteMemory(store)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.DeleteMemoryArgs{
+			Memory: "Memory 1",
+		}))
+
+		is.True(t, err != nil)
+		is.Equal(t, "error deleting memory: memory not found", err.Error())
+	})
+
+	t.Run("summarize delete_memory", func(t *testing.T) {
+		tool := tools.NewDeleteMemory(&mockMemoryStore{})
+
+		summary, err := tool.Summarize(t.Context(), mustMarshalJSON(tools.DeleteMemoryArgs{
+			Memory: "This is a very long memory that should be truncated",
+		}))
+
+		is.NotError(t, err)
+		is.Equal(t, `memory="This is a very long memory tha..."`, summary)
+	})
+}
+
+func TestNewUpdateMemory(t *testing.T) {
+	t.Run("updates a memory successfully", func(t *testing.T) {
+		store := &mockMemoryStore{memories: []string{"Memory 1", "Memory 2"}}
+		tool := tools.NewUpdateMemory(store)
+
+		is.Equal(t, "update_memory", tool.Name)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.UpdateMemoryArgs{
+			Memory:    "Memory 1",
+			NewMemory: "Memory 3",
+		}))
+
+		is.NotError(t, err)
+		is.Equal(t, "OK", result)
+		is.EqualSlice(t, []string{"Memory 3", "Memory 2"}, store.memories)
+	})
+
+	t.Run("returns error when the memory is not found", func(t *testing.T) {
+		store := &mockMemoryStore{}
+		tool := tools.NewUpdateMemory(store)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.UpdateMemoryArgs{
+			Memory:    "Memory 1",
+			NewMemory: "Memory 3",
+		}))
+
+		is.True(t, err != nil)
+		is.Equal(t, "error updating memory: memory not found", err.Error())
+	})
+
+	t.Run("summarize update_memory", func(t *testing.T) {
+		tool := tools.NewUpdateMemory(&mockMemoryStore{})
+
+		summary, err := tool.Summarize(t.Context(), mustMarshalJSON(tools.UpdateMemoryArgs{
+			Memory:    "Memory 1",
+			NewMemory: "Memory 3",
+		}))
+
+		is.NotError(t, err)
+		is.Equal(t, `memory="Memory 1" new_memory="Memory 3"`, summary)
+	})
+}
@@ -0,0 +1,556 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"maragu.dev/gai"
+)
+
+// maxPatchFuzz is the maximum number of context lines dropped from the start and end of a hunk
+// when its full context is not found, like the fuzz factor of the patch command.
+const maxPatchFuzz = 2
+
+// ApplyPatchArgs holds the arguments for the ApplyPatch tool.
+type ApplyPatchArgs struct {
+	Patch string `json:"patch" jsonschema_description:"The patch in unified diff format, for one or more files."`
+}
+
+// NewApplyPatch creates a new tool that applies a unified diff to files relative to the given [os.Root].
+//
+// The patch can change, create, delete, and rename several files. Hunks are found near their line numbers,
+// also if the file has changed around them, and context lines that differ only in whitespace still match.
+// If a hunk can't be applied, no files are changed, and the error reports the status of every hunk.
+func NewApplyPatch(root *os.Root) gai.Tool {
+	return gai.NewTool(gai.NewToolOptions[ApplyPatchArgs]{
+		Name: "apply_patch",
+		Description: `Apply a patch in unified diff format to one or more files, like git apply.
+
+- Start each file with '--- a/path' and '+++ b/path' lines, using '--- /dev/null' to create a file and '+++ /dev/null' to delete one
+- Start each hunk with a '@@ -1,3 +1,4 @@' line, followed by context lines starting with ' ', removed lines with '-', and added lines with '+'
+- Include a few lines of unchanged context around each change, so the hunk can be found even if the line numbers are off
+- Either the whole patch is applied, or no files are changed. The result reports where each hunk was applied, or why it couldn't be`,
+		Summarize: func(ctx context.Context, args ApplyPatchArgs) (string, error) {
+			files, err := parsePatch(args.Patch)
+			if err != nil {
+				return "invalid patch", nil
+			}
+			var paths []string
+			for _, f := range files {
+				paths = append(paths, f.path())
+			}
+			return fmt.Sprintf("files=%v", paths), nil
+		},
+		Execute: func(ctx context.Context, args ApplyPatchArgs) (string, error) {
+			files, err := parsePatch(args.Patch)
+			if err != nil {
+				return "", fmt.Errorf("invalid patch: %w", err)
+			}
+
+			p := &patcher{root: root, files: map[string]*patchedFile{}}
+			var report strings.Builder
+			failed := false
+			for _, f := range files {
+				if !p.apply(f, &report) {
+					failed = true
+				}
+			}
+
+			if failed {
+				return "", fmt.Errorf("patch not applied, no files were changed:\n%v", report.String())
+			}
+
+			if err := p.write(); err != nil {
+				return "", err
+			}
+
+			return "Patch applied:\n" + report.String(), nil
+		},
+	})
+}
+
+// filePatch is the part of a patch for a single file.
+type filePatch struct {
+	// oldPath is empty for created files
+	oldPath string
+	// newPath is empty for deleted files
+	newPath string
+	hunks   []hunk
+}
+
+// path of the file for reports.
+func (f filePatch) path() string {
+	if f.newPath != "" {
+		return f.newPath
+	}
+	return f.oldPath
+}
+
+// hunk is a single change in a file.
+type hunk struct {
+	header   string
+	oldStart int
+	lines    []hunkLine
+	// newNoEOL is true if the new lines end the file without a trailing newline
+	newNoEOL bool
+}
+
+type hunkLine struct {
+	op   byte // ' ', '-', or '+'
+	text string
+}
+
+// old lines of the hunk, which are the context and removed lines.
+func (h hunk) oldLines() []string {
+	var lines []string
+	for _, l := range h.lines {
+		if l.op != '+' {
+			lines = append(lines, l.text)
+		}
+	}
+	return lines
+}
+
+var hunkHeaderRE = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+\d+(?:,\d+)? @@`)
+
+// parsePatch in unified diff format into the patches for each file.
+// Line counts in hunk headers are ignored, since they are often wrong in generated patches.
+// Instead, a hunk ends at the next hunk or file header.
+func parsePatch(patch string) ([]filePatch, error) {
+	lines := strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n")
+
+	var files []filePatch
+	var file *filePatch
+	var h *hunk
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			oldPath, newPath := parsePatchPath(line[4:]), parsePatchPath(lines[i+1][4:])
+			if oldPath == "" && newPath == "" {
+				return nil, fmt.Errorf("line %d: both paths are /dev/null", i+1)
+			}
+			if strings.HasPrefix(oldPath, "a/") && (newPath == "" || strings.HasPrefix(newPath, "b/")) ||
+				strings.HasPrefix(newPath, "b/") && oldPath == "" {
+				oldPath = strings.TrimPrefix(oldPath, "a/")
+				newPath = strings.TrimPrefix(newPath, "b/")
+			}
+			files = append(files, filePatch{oldPath: oldPath, newPath: newPath})
+			file = &files[len(files)-1]
+			h = nil
+			i++
+
+		case strings.HasPrefix(line, "@@"):
+			if file == nil {
+				return nil, fmt.Errorf("line %d: hunk before a file header", i+1)
+			}
+			m := hunkHeaderRE.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("line %d: invalid hunk header %q", i+1, line)
+			}
+			oldStart, _ := strconv.Atoi(m[1])
+			file.hunks = append(file.hunks, hunk{header: m[0], oldStart: oldStart})
+			h = &file.hunks[len(file.hunks)-1]
+
+		case h == nil:
+			// Anything outside hunks, like "diff --git" and "index" lines, is ignored
+
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file" applies to the line before it
+			if len(h.lines) > 0 && h.lines[len(h.lines)-1].op != '-' {
+				h.newNoEOL = true
+			}
+
+		case line == "":
+			// Generated patches often leave out the space of empty context lines, but the patch itself
+			// usually ends with a newline, so only count it if more hunk lines follow
+			if i == len(lines)-1 || !isHunkLine(lines[i+1]) {
+				h = nil
+				continue
+			}
+			h.lines = append(h.lines, hunkLine{op: ' '})
+
+		case line[0] == ' ' || line[0] == '-' || line[0] == '+':
+			h.lines = append(h.lines, hunkLine{op: line[0], text: line[1:]})
+
+		default:
+			h = nil
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, errors.New("no file headers found")
+	}
+	for _, f := range files {
+		if len(f.hunks) == 0 && f.newPath != "" && f.oldPath == f.newPath {
+			return nil, fmt.Errorf("no hunks for %v", f.path())
+		}
+	}
+	return files, nil
+}
+
+// isHunkLine reports whether the line could be in a hunk after an empty line.
+func isHunkLine(line string) bool {
+	if line == "" || strings.HasPrefix(line, `\`) {
+		return true
+	}
+	if strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ ") {
+		return false
+	}
+	return line[0] == ' ' || line[0] == '-' || line[0] == '+'
+}
+
+// parsePatchPath from a file header, without any timestamp, returning an empty path for /dev/null.
+func parsePatchPath(s string) string {
+	s, _, _ = strings.Cut(s, "\t")
+	s = strings.TrimSpace(s)
+	if s == "/dev/null" {
+		return ""
+	}
+	return s
+}
+
+// patchedFile is the state of a file while applying a patch.
+type patchedFile struct {
+	exists bool
+	lines  []string
+	// eol is true if the file ends with a newline
+	eol  bool
+	crlf bool
+	// changed is true if the file needs to be written or deleted
+	changed bool
+}
+
+// patcher applies file patches in memory, so files are only written if all patches apply.
+type patcher struct {
+	root  *os.Root
+	files map[string]*patchedFile
+	// order of the paths in files, to write them in patch order
+	order []string
+}
+
+// file state at the path, reading it from the root the first time.
+func (p *patcher) file(name string) (*patchedFile, error) {
+	if f, ok := p.files[name]; ok {
+		return f, nil
+	}
+
+	f := &patchedFile{}
+	content, err := p.root.ReadFile(name)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		s := string(content)
+		f.exists = true
+		f.crlf = strings.Contains(s, "\r\n")
+		s = strings.ReplaceAll(s, "\r\n", "\n")
+		f.eol = s == "" || strings.HasSuffix(s, "\n")
+		if s != "" {
+			f.lines = strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+		}
+	}
+
+	p.files[name] = f
+	p.order = append(p.order, name)
+	return f, nil
+}
+
+// apply the file patch in memory, writing a report of it and each hunk, and returning whether it applied.
+func (p *patcher) apply(fp filePatch, report *strings.Builder) bool {
+	switch {
+	case fp.oldPath == "":
+		fmt.Fprintf(report, "A %v\n", fp.newPath)
+	case fp.newPath == "":
+		fmt.Fprintf(report, "D %v\n", fp.oldPath)
+	case fp.oldPath != fp.newPath:
+		fmt.Fprintf(report, "R %v -> %v\n", fp.oldPath, fp.newPath)
+	default:
+		fmt.Fprintf(report, "M %v\n", fp.newPath)
+	}
+
+	fail := func(format string, a ...any) bool {
+		fmt.Fprintf(report, "  failed: %v\n", fmt.Sprintf(format, a...))
+		return false
+	}
+
+	// A created file starts out empty
+	source := &patchedFile{eol: true}
+	if fp.oldPath != "" {
+		f, err := p.file(fp.oldPath)
+		if err != nil {
+			return fail("%v", err)
+		}
+		if !f.exists {
+			return fail("file does not exist")
+		}
+		source = f
+	}
+
+	target := source
+	if fp.newPath != "" && fp.newPath != fp.oldPath {
+		f, err := p.file(fp.newPath)
+		if err != nil {
+			return fail("%v", err)
+		}
+		if f.exists {
+			return fail("file already exists")
+		}
+		target = f
+	}
+
+	lines := slices.Clone(source.lines)
+	eol := source.eol
+	ok := true
+	// offset is how far from their header line numbers hunks were found, which the following hunks
+	// are expected to be off by as well. next is the first line index the next hunk can start at.
+	offset, next := 0, 0
+	for i, h := range fp.hunks {
+		// A hunk without old lines is inserted after the line in its header, otherwise it starts at it
+		headerStart := h.oldStart
+		if len(h.oldLines()) > 0 {
+			headerStart = max(h.oldStart-1, 0)
+		}
+
+		m := findHunk(lines, h, headerStart+offset, next)
+		if !m.found {
+			ok = false
+			fmt.Fprintf(report, "  hunk %d (%v): failed, %v\n", i+1, h.header, m.reason)
+			continue
+		}
+
+		// Only removed and added lines change. Context lines, including those dropped as fuzz, are left
+		// as they are in the file, which matters if they only matched ignoring whitespace.
+		var newLines []string
+		end := m.start
+		for _, l := range h.lines[m.fuzzStart : len(h.lines)-m.fuzzEnd] {
+			switch l.op {
+			case ' ':
+				newLines = append(newLines, lines[end])
+				end++
+			case '-':
+				end++
+			case '+':
+				newLines = append(newLines, l.text)
+			}
+		}
+
+		if end == len(lines) && m.fuzzEnd == 0 {
+			eol = !h.newNoEOL
+		}
+		lines = slices.Concat(lines[:m.start], newLines, lines[end:])
+
+		offset = m.start - m.fuzzStart - headerStart
+		next = m.start + len(newLines)
+
+		fmt.Fprintf(report, "  hunk %d (%v): applied at line %d%v\n", i+1, h.header, m.start-m.fuzzStart+1, m.describe(offset))
+	}
+	if !ok {
+		return false
+	}
+
+	if fp.newPath == "" {
+		if len(lines) > 0 {
+			return fail("file still has %d lines after removing the lines in the patch", len(lines))
+		}
+		source.exists = false
+		source.lines = nil
+		source.changed = true
+		return true
+	}
+
+	if target != source {
+		target.crlf = source.crlf
+		source.exists = false
+		source.lines = nil
+		source.changed = true
+	}
+
+	target.exists = true
+	target.lines = lines
+	target.eol = eol
+	target.changed = true
+	return true
+}
+
+// write all changed files, restoring the written files if writing one fails.
+func (p *patcher) write() error {
+	type original struct {
+		name    string
+		content []byte
+		exists  bool
+	}
+	var written []original
+
+	restore := func() {
+		for _, o := range written {
+			if o.exists {
+				_ = p.root.WriteFile(o.name, o.content, 0644)
+			} else {
+				_ = p.root.Remove(o.name)
+			}
+		}
+	}
+
+	for _, name := range p.order {
+		f := p.files[name]
+		if !f.changed {
+			continue
+		}
+
+		content, err := p.root.ReadFile(name)
+		o := original{name: name, content: content, exists: err == nil}
+
+		if !f.exists {
+			if err := p.root.Remove(name); err != nil {
+				restore()
+				return fmt.Errorf("error deleting %v, no files were changed: %w", name, err)
+			}
+			written = append(written, o)
+			continue
+		}
+
+		if err := p.root.MkdirAll(path.Dir(name), 0755); err != nil {
+			restore()
+			return fmt.Errorf("error creating directory for %v, no files were changed: %w", name, err)
+		}
+
+		s := strings.Join(f.lines, "\n")
+		if f.eol && len(f.lines) > 0 {
+			s += "\n"
+		}
+		if f.crlf {
+			s = strings.ReplaceAll(s, "\n", "\r\n")
+		}
+
+		written = append(written, o)
+		if err := p.root.WriteFile(name, []byte(s), 0644); err != nil {
+			restore()
+			return fmt.Errorf("error writing %v, no files were changed: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// hunkMatch is where the old lines of a hunk were found in a file.
+type hunkMatch struct {
+	found bool
+	// start is the line index where the old lines, without the fuzz, start
+	start int
+	// fuzzStart and fuzzEnd are the number of context lines dropped from the start and end of the hunk
+	fuzzStart, fuzzEnd int
+	// whitespace is true if lines only matched when ignoring whitespace
+	whitespace bool
+	// reason the hunk was not found
+	reason string
+}
+
+// describe how the match differs from a perfect one, given its offset from the line in the hunk header.
+func (m hunkMatch) describe(offset int) string {
+	var notes []string
+	if offset != 0 {
+		notes = append(notes, fmt.Sprintf("offset %+d lines", offset))
+	}
+	if m.fuzzStart > 0 || m.fuzzEnd > 0 {
+		notes = append(notes, fmt.Sprintf("fuzz %d", max(m.fuzzStart, m.fuzzEnd)))
+	}
+	if m.whitespace {
+		notes = append(notes, "ignoring whitespace")
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(notes, ", ") + ")"
+}
+
+// findHunk finds the old lines of a hunk in the file lines, at or after line index from,
+// closest to the expected line index. It first looks for an exact match, then ignoring whitespace,
+// then with up to [maxPatchFuzz] context lines dropped from the start and end of the hunk.
+func findHunk(lines []string, h hunk, expected, from int) hunkMatch {
+	old := h.oldLines()
+	if len(old) == 0 {
+		return hunkMatch{found: true, start: max(from, min(expected, len(lines)))}
+	}
+
+	// Count the context lines at the start and end, which can be dropped as fuzz
+	var contextStart, contextEnd int
+	for contextStart < len(h.lines) && h.lines[contextStart].op == ' ' {
+		contextStart++
+	}
+	for contextEnd < len(h.lines)-contextStart && h.lines[len(h.lines)-1-contextEnd].op == ' ' {
+		contextEnd++
+	}
+
+	for fuzz := 0; fuzz <= maxPatchFuzz; fuzz++ {
+		fuzzStart, fuzzEnd := min(fuzz, contextStart), min(fuzz, contextEnd)
+		if fuzz > 0 && fuzzStart < fuzz && fuzzEnd < fuzz {
+			// Dropping more context changes nothing
+			break
+		}
+		trimmed := old[fuzzStart : len(old)-fuzzEnd]
+		if len(trimmed) == 0 {
+			break
+		}
+		for _, whitespace := range []bool{false, true} {
+			if start, ok := findLines(lines, trimmed, expected+fuzzStart, from, whitespace); ok {
+				return hunkMatch{found: true, start: start, fuzzStart: fuzzStart, fuzzEnd: fuzzEnd, whitespace: whitespace}
+			}
+		}
+	}
+
+	// Find the closest partial match, to help fix the hunk
+	best, bestCount := -1, 0
+	for start := from; start+len(old) <= len(lines); start++ {
+		count := 0
+		for i, l := range old {
+			if strings.TrimSpace(lines[start+i]) == strings.TrimSpace(l) {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = start, count
+		}
+	}
+	if best < 0 {
+		return hunkMatch{reason: "context and removed lines not found"}
+	}
+	return hunkMatch{reason: fmt.Sprintf("context and removed lines not found, closest match is at line %d with %d of %d lines matching", best+1, bestCount, len(old))}
+}
+
+// findLines finds the lines in the file lines at or after from, closest to the expected index.
+func findLines(lines, find []string, expected, from int, whitespace bool) (int, bool) {
+	matches := func(start int) bool {
+		for i, l := range find {
+			a, b := lines[start+i], l
+			if whitespace {
+				a, b = strings.Join(strings.Fields(a), " "), strings.Join(strings.Fields(b), " ")
+			}
+			if a != b {
+				return false
+			}
+		}
+		return true
+	}
+
+	last := len(lines) - len(find)
+	expected = max(from, min(expected, last))
+	for d := 0; expected-d >= from || expected+d <= last; d++ {
+		if start := expected - d; start >= from && start <= last && matches(start) {
+			return start, true
+		}
+		if start := expected + d; d > 0 && start <= last && start >= from && matches(start) {
+			return start, true
+		}
+	}
+	return 0, false
+}
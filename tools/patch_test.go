@@ -0,0 +1,211 @@
+package tools_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai/tools"
+)
+
+func TestNewApplyPatch(t *testing.T) {
+	t.Run("changes, creates, and deletes files", func(t *testing.T) {
+		root := newTestRoot(t, map[string]string{
+			"a.txt":   "one\ntwo\nthree\nfour\nfive\n",
+			"old.txt": "bye\n",
+		})
+		tool := tools.NewApplyPatch(root)
+
+		is.Equal(t, "apply_patch", tool.Name)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ApplyPatchArgs{Patch: `diff --git a/a.txt b/a.txt
+--- a/a.txt
++++ b/a.txt
+@@ -1,3 +1,3 @@
+ one
+-two
++2
+ three
+@@ -4,2 +4,3 @@
+ four
+ five
++six
+--- /dev/null
++++ b/dir/new.txt
+@@ -0,0 +1,2 @@
++hello
++world
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+`}))
+		is.NotError(t, err)
+		is.Equal(t, `Patch applied:
+M a.txt
+  hunk 1 (@@ -1,3 +1,3 @@): applied at line 1
+  hunk 2 (@@ -4,2 +4,3 @@): applied at line 4
+A dir/new.txt
+  hunk 1 (@@ -0,0 +1,2 @@): applied at line 1
+D old.txt
+  hunk 1 (@@ -1 +0,0 @@): applied at line 1
+`, result)
+
+		requireFileContent(t, root, "a.txt", "one\n2\nthree\nfour\nfive\nsix\n")
+		requireFileContent(t, root, "dir/new.txt", "hello\nworld\n")
+		_, err = root.Stat("old.txt")
+		is.True(t, errors.Is(err, fs.ErrNotExist))
+	})
+
+	t.Run("finds hunks with wrong line numbers, different whitespace, and changed context", func(t *testing.T) {
+		root := newTestRoot(t, map[string]string{
+			"a.go": "package a\n\n// added\n// lines\n\nfunc A() {\n\treturn\n}\n\nfunc B() {\n\treturn\n}\n",
+		})
+		tool := tools.NewApplyPatch(root)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ApplyPatchArgs{Patch: `--- a/a.go
++++ b/a.go
+@@ -3,3 +3,4 @@
+ func A() {
+-    return
++	println("A")
++	return
+ }
+@@ -8,4 +9,4 @@
+ changed context
+ func B() {
+-	return
++	println("B")
+ }
+`}))
+		is.NotError(t, err)
+		is.Equal(t, `Patch applied:
+M a.go
+  hunk 1 (@@ -3,3 +3,4 @@): applied at line 6 (offset +3 lines, ignoring whitespace)
+  hunk 2 (@@ -8,4 +9,4 @@): applied at line 10 (offset +2 lines, fuzz 1)
+`, result)
+
+		requireFileContent(t, root, "a.go", "package a\n\n// added\n// lines\n\nfunc A() {\n\tprintln(\"A\")\n\treturn\n}\n\nfunc B() {\n\tprintln(\"B\")\n}\n")
+	})
+
+	t.Run("keeps the whitespace of context lines that only matched ignoring whitespace", func(t *testing.T) {
+		root := newTestRoot(t, map[string]string{
+			"Makefile": "build:\n\tgo build ./...\n\ntest:\n\tgo test ./...\n",
+		})
+		tool := tools.NewApplyPatch(root)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ApplyPatchArgs{Patch: `--- a/Makefile
++++ b/Makefile
+@@ -1,5 +1,5 @@
+ build:
+-    go build ./...
++	go build -v ./...
+ 
+ test:
+     go test ./...
+`}))
+		is.NotError(t, err)
+
+		requireFileContent(t, root, "Makefile", "build:\n\tgo build -v ./...\n\ntest:\n\tgo test ./...\n")
+	})
+
+	t.Run("renames files and handles missing newlines at the end", func(t *testing.T) {
+		root := newTestRoot(t, map[string]string{"a.txt": "one\ntwo"})
+		tool := tools.NewApplyPatch(root)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ApplyPatchArgs{Patch: `--- a/a.txt
++++ b/b.txt
+@@ -1,2 +1,2 @@
+ one
+-two
+\ No newline at end of file
++2
+\ No newline at end of file
+`}))
+		is.NotError(t, err)
+
+		requireFileContent(t, root, "b.txt", "one\n2")
+		_, err = root.Stat("a.txt")
+		is.True(t, errors.Is(err, fs.ErrNotExist))
+	})
+
+	t.Run("changes no files if a hunk fails, and reports every hunk", func(t *testing.T) {
+		root := newTestRoot(t, map[string]string{
+			"a.txt": "one\ntwo\nthree\n",
+			"b.txt": "four\nfive\nsix\n",
+		})
+		tool := tools.NewApplyPatch(root)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ApplyPatchArgs{Patch: `--- a/a.txt
++++ b/a.txt
+@@ -1,2 +1,2 @@
+ one
+-two
++2
+--- a/b.txt
++++ b/b.txt
+@@ -1,3 +1,3 @@
+ four
+-5
++five
+ six
+`}))
+		is.True(t, err != nil)
+		is.Equal(t, `patch not applied, no files were changed:
+M a.txt
+  hunk 1 (@@ -1,2 +1,2 @@): applied at line 1
+M b.txt
+  hunk 1 (@@ -1,3 +1,3 @@): failed, context and removed lines not found, closest match is at line 1 with 2 of 3 lines matching
+`, err.Error())
+
+		requireFileContent(t, root, "a.txt", "one\ntwo\nthree\n")
+		requireFileContent(t, root, "b.txt", "four\nfive\nsix\n")
+	})
+
+	t.Run("errors when creating a file that exists, or changing one that doesn't", func(t *testing.T) {
+		root := newTestRoot(t, map[string]string{"a.txt": "one\n"})
+		tool := tools.NewApplyPatch(root)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ApplyPatchArgs{Patch: `--- /dev/null
++++ b/a.txt
+@@ -0,0 +1 @@
++one
+--- a/nonexistent.txt
++++ b/nonexistent.txt
+@@ -1 +1 @@
+-one
++two
+`}))
+		is.True(t, err != nil)
+		is.True(t, strings.Contains(err.Error(), "A a.txt\n  failed: file already exists\n"))
+		is.True(t, strings.Contains(err.Error(), "M nonexistent.txt\n  failed: file does not exist\n"))
+	})
+
+	t.Run("errors on a patch without file headers", func(t *testing.T) {
+		root := newTestRoot(t, nil)
+		tool := tools.NewApplyPatch(root)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ApplyPatchArgs{Patch: "just text"}))
+		is.Equal(t, "invalid patch: no file headers found", err.Error())
+	})
+
+	t.Run("summarizes the files in the patch", func(t *testing.T) {
+		tool := tools.NewApplyPatch(newTestRoot(t, nil))
+
+		summary, err := tool.Summarize(t.Context(), mustMarshalJSON(tools.ApplyPatchArgs{Patch: "--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n-a\n+b\n--- /dev/null\n+++ b/b.txt\n@@ -0,0 +1 @@\n+b\n"}))
+		is.NotError(t, err)
+		is.Equal(t, "files=[a.txt b.txt]", summary)
+	})
+}
+
+func requireFileContent(t *testing.T, root *os.Root, name, expected string) {
+	t.Helper()
+
+	content, err := root.ReadFile(name)
+	is.NotError(t, err)
+	is.Equal(t, expected, string(content))
+}
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// robotsRule is a single Allow or Disallow line from a robots.txt file.
+type robotsRule struct {
+	allow   bool
+	pattern string
+	re      *regexp.Regexp
+}
+
+// robotsRules for a single user agent, from a robots.txt file.
+// It supports the subset of the Robots Exclusion Protocol (RFC 9309) that matters for fetching:
+// user-agent groups, Allow and Disallow with "*" and "$" wildcards, and the longest match winning.
+type robotsRules struct {
+	rules []robotsRule
+}
+
+// parseRobots parses the rules in a robots.txt file for the given user agent product token.
+// The groups naming the user agent are used if there are any, otherwise the groups for "*".
+func parseRobots(data []byte, userAgent string) *robotsRules {
+	userAgent = strings.ToLower(userAgent)
+
+	var specific, general []robotsRule
+	var agents []string
+	inRules := false
+
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line, _, _ := strings.Cut(s.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// A user-agent line after rules starts a new group
+			if inRules {
+				agents = nil
+				inRules = false
+			}
+			agents = append(agents, strings.ToLower(value))
+
+		case "allow", "disallow":
+			inRules = true
+			// An empty disallow means nothing is disallowed
+			if value == "" {
+				continue
+			}
+			rule := robotsRule{allow: key == "allow", pattern: value, re: robotsPatternToRegexp(value)}
+			for _, agent := range agents {
+				switch {
+				case agent == "*":
+					general = append(general, rule)
+				case strings.HasPrefix(userAgent, agent):
+					specific = append(specific, rule)
+				}
+			}
+		}
+	}
+
+	if specific != nil {
+		return &robotsRules{rules: specific}
+	}
+	return &robotsRules{rules: general}
+}
+
+// robotsPatternToRegexp converts a path pattern, where "*" matches any characters
+// and a trailing "$" matches the end of the path, to a regular expression matching path prefixes.
+func robotsPatternToRegexp(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// allowed reports whether the path, including any query string, may be fetched.
+// The rule with the longest pattern wins, and allow wins between rules of the same length.
+func (r *robotsRules) allowed(path string) bool {
+	allowed := true
+	longest := -1
+	for _, rule := range r.rules {
+		if !rule.re.MatchString(path) {
+			continue
+		}
+		if len(rule.pattern) > longest || len(rule.pattern) == longest && rule.allow {
+			allowed = rule.allow
+			longest = len(rule.pattern)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"maragu.dev/gai"
+)
+
+// SearchResult is a single result from a [Searcher].
+type SearchResult struct {
+	Title   string
+	URL     string
+	Snippet string
+}
+
+// Searcher searches the web. See [BraveSearcher] and [TavilySearcher] for implementations,
+// and [FakeSearcher] for tests.
+type Searcher interface {
+	// Search for the query, returning at most count results in order of relevance.
+	Search(ctx context.Context, query string, count int) ([]SearchResult, error)
+}
+
+// Constants for the number of search results
+const (
+	defaultSearchCount = 5
+	maxSearchCount     = 20
+	// maxSnippetLength in runes, after which snippets are truncated
+	maxSnippetLength = 300
+)
+
+// WebSearchArgs holds the arguments for the WebSearch tool.
+type WebSearchArgs struct {
+	Query string `json:"query" jsonschema_description:"The search query."`
+	Count int    `json:"count,omitempty" jsonschema_description:"Optional number of results, at most 20. Default is 5."`
+}
+
+// NewWebSearch creates a new tool for searching the web with the given searcher.
+// Results are formatted as a numbered list of titles, URLs, and snippets.
+func NewWebSearch(s Searcher) gai.Tool {
+	return gai.NewTool(gai.NewToolOptions[WebSearchArgs]{
+		Name:        "web_search",
+		Description: "Search the web and get a list of results with their titles, URLs, and snippets. Use the fetch tool to read a result.",
+		Summarize: func(ctx context.Context, args WebSearchArgs) (string, error) {
+			summary := fmt.Sprintf(`query="%s"`, args.Query)
+			if args.Count > 0 {
+				summary += fmt.Sprintf(" count=%d", args.Count)
+			}
+			return summary, nil
+		},
+		Execute: func(ctx context.Context, args WebSearchArgs) (string, error) {
+			query := strings.TrimSpace(args.Query)
+			if query == "" {
+				return "", errors.New("query cannot be empty")
+			}
+
+			count := args.Count
+			if count <= 0 {
+				count = defaultSearchCount
+			}
+			count = min(count, maxSearchCount)
+
+			results, err := s.Search(ctx, query, count)
+			if err != nil {
+				return "", fmt.Errorf("error searching: %w", err)
+			}
+
+			return formatSearchResults(results, count), nil
+		},
+	})
+}
+
+// formatSearchResults as a numbered list, with the URL and snippet indented under each title.
+func formatSearchResults(results []SearchResult, count int) string {
+	if len(results) == 0 {
+		return "No results found."
+	}
+	if len(results) > count {
+		results = results[:count]
+	}
+
+	var b strings.Builder
+	for i, r := range results {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		title := strings.Join(strings.Fields(r.Title), " ")
+		if title == "" {
+			title = "(no title)"
+		}
+		fmt.Fprintf(&b, "%d. %v\n   %v\n", i+1, title, r.URL)
+
+		snippet := []rune(strings.Join(strings.Fields(r.Snippet), " "))
+		if len(snippet) > maxSnippetLength {
+			snippet = append(snippet[:maxSnippetLength], '…')
+		}
+		if len(snippet) > 0 {
+			fmt.Fprintf(&b, "   %v\n", string(snippet))
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BraveSearcher is a [Searcher] using the Brave Search API.
+type BraveSearcher struct {
+	baseURL string
+	client  *http.Client
+	key     string
+}
+
+// NewBraveSearcherOptions for [NewBraveSearcher].
+type NewBraveSearcherOptions struct {
+	// BaseURL defaults to https://api.search.brave.com/res/v1/.
+	BaseURL string
+	// Client for the requests. Nil means a client with a 30 second timeout.
+	Client *http.Client
+	// Key is the subscription token.
+	Key string
+}
+
+// NewBraveSearcher with the given options.
+func NewBraveSearcher(opts NewBraveSearcherOptions) *BraveSearcher {
+	if opts.BaseURL == "" {
+		opts.BaseURL = "https://api.search.brave.com/res/v1/"
+	}
+	if !strings.HasSuffix(opts.BaseURL, "/") {
+		opts.BaseURL += "/"
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &BraveSearcher{
+		baseURL: opts.BaseURL,
+		client:  opts.Client,
+		key:     opts.Key,
+	}
+}
+
+type braveSearchResponse struct {
+	Web struct {
+		Results []struct {
+			Title       string `json:"title"`
+			URL         string `json:"url"`
+			Description string `json:"description"`
+		} `json:"results"`
+	} `json:"web"`
+}
+
+// Search satisfies [Searcher].
+func (s *BraveSearcher) Search(ctx context.Context, query string, count int) ([]SearchResult, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("count", strconv.Itoa(count))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"web/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Subscription-Token", s.key)
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		resBody, _ := io.ReadAll(io.LimitReader(res.Body, 64*1024))
+		return nil, fmt.Errorf("GET web/search: %v: %s", res.Status, bytes.TrimSpace(resBody))
+	}
+
+	var resBody braveSearchResponse
+	if err := json.NewDecoder(res.Body).Decode(&resBody); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	var results []SearchResult
+	for _, r := range resBody.Web.Results {
+		results = append(results, SearchResult{
+			Title:   stripTags(r.Title),
+			URL:     r.URL,
+			Snippet: stripTags(r.Description),
+		})
+	}
+	return results, nil
+}
+
+var tagRE = regexp.MustCompile(`<[^>]*>`)
+
+// stripTags removes HTML tags, like the highlighting of query terms, and unescapes entities.
+func stripTags(s string) string {
+	return html.UnescapeString(tagRE.ReplaceAllString(s, ""))
+}
+
+var _ Searcher = (*BraveSearcher)(nil)
@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// FakeSearcher is a [Searcher] over a fixed list of results, for unit tests without calling a search API.
+// A result matches a query if every word of the query is in its title, URL, or snippet, ignoring case.
+// It is safe for concurrent use.
+type FakeSearcher struct {
+	mu      sync.Mutex
+	queries []string
+	results []SearchResult
+}
+
+// NewFakeSearcher over the given results, which are returned in the given order when they match.
+func NewFakeSearcher(results ...SearchResult) *FakeSearcher {
+	return &FakeSearcher{results: results}
+}
+
+// Queries returns a copy of the queries received so far, in order.
+func (s *FakeSearcher) Queries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.queries)
+}
+
+// Search satisfies [Searcher].
+func (s *FakeSearcher) Search(ctx context.Context, query string, count int) ([]SearchResult, error) {
+	s.mu.Lock()
+	s.queries = append(s.queries, query)
+	s.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	words := strings.Fields(strings.ToLower(query))
+
+	var results []SearchResult
+	for _, r := range s.results {
+		if len(results) >= count {
+			break
+		}
+		text := strings.ToLower(r.Title + " " + r.URL + " " + r.Snippet)
+		if !slices.ContainsFunc(words, func(word string) bool { return !strings.Contains(text, word) }) {
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
+var _ Searcher = (*FakeSearcher)(nil)
@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TavilySearcher is a [Searcher] using the Tavily Search API.
+type TavilySearcher struct {
+	baseURL string
+	client  *http.Client
+	key     string
+}
+
+// NewTavilySearcherOptions for [NewTavilySearcher].
+type NewTavilySearcherOptions struct {
+	// BaseURL defaults to https://api.tavily.com/.
+	BaseURL string
+	// Client for the requests. Nil means a client with a 30 second timeout.
+	Client *http.Client
+	// Key is the API key.
+	Key string
+}
+
+// NewTavilySearcher with the given options.
+func NewTavilySearcher(opts NewTavilySearcherOptions) *TavilySearcher {
+	if opts.BaseURL == "" {
+		opts.BaseURL = "https://api.tavily.com/"
+	}
+	if !strings.HasSuffix(opts.BaseURL, "/") {
+		opts.BaseURL += "/"
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &TavilySearcher{
+		baseURL: opts.BaseURL,
+		client:  opts.Client,
+		key:     opts.Key,
+	}
+}
+
+type tavilySearchRequest struct {
+	Query      string `json:"query"`
+	MaxResults int    `json:"max_results"`
+}
+
+type tavilySearchResponse struct {
+	Results []struct {
+		Title   string `json:"title"`
+		URL     string `json:"url"`
+		Content string `json:"content"`
+	} `json:"results"`
+}
+
+// Search satisfies [Searcher].
+func (s *TavilySearcher) Search(ctx context.Context, query string, count int) ([]SearchResult, error) {
+	data, err := json.Marshal(tavilySearchRequest{Query: query, MaxResults: count})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"search", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.key)
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		resBody, _ := io.ReadAll(io.LimitReader(res.Body, 64*1024))
+		return nil, fmt.Errorf("POST search: %v: %s", res.Status, bytes.TrimSpace(resBody))
+	}
+
+	var resBody tavilySearchResponse
+	if err := json.NewDecoder(res.Body).Decode(&resBody); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	var results []SearchResult
+	for _, r := range resBody.Results {
+		results = append(results, SearchResult{
+			Title:   r.Title,
+			URL:     r.URL,
+			Snippet: r.Content,
+		})
+	}
+	return results, nil
+}
+
+var _ Searcher = (*TavilySearcher)(nil)
@@ -0,0 +1,194 @@
+package tools_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai/tools"
+)
+
+func TestNewWebSearch(t *testing.T) {
+	searcher := tools.NewFakeSearcher(
+		tools.SearchResult{Title: "The Go  Programming Language", URL: "https://go.dev", Snippet: "Go is an open source\nprogramming language."},
+		tools.SearchResult{Title: "Go by Example", URL: "https://gobyexample.com", Snippet: ""},
+		tools.SearchResult{Title: "A Tour of Go", URL: "https://go.dev/tour", Snippet: strings.Repeat("go ", 200)},
+	)
+
+	t.Run("formats the results compactly", func(t *testing.T) {
+		tool := tools.NewWebSearch(searcher)
+
+		is.Equal(t, "web_search", tool.Name)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.WebSearchArgs{Query: "go", Count: 2}))
+		is.NotError(t, err)
+		is.Equal(t, "1. The Go Programming Language\n   https://go.dev\n   Go is an open source programming language.\n\n"+
+			"2. Go by Example\n   https://gobyexample.com", result)
+	})
+
+	t.Run("truncates long snippets", func(t *testing.T) {
+		tool := tools.NewWebSearch(searcher)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.WebSearchArgs{Query: "tour"}))
+		is.NotError(t, err)
+		is.Equal(t, "1. A Tour of Go\n   https://go.dev/tour\n   "+strings.Repeat("go ", 100)[:300]+"…", result)
+	})
+
+	t.Run("reports when there are no results", func(t *testing.T) {
+		tool := tools.NewWebSearch(searcher)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.WebSearchArgs{Query: "rust"}))
+		is.NotError(t, err)
+		is.Equal(t, "No results found.", result)
+	})
+
+	t.Run("returns error for empty query", func(t *testing.T) {
+		tool := tools.NewWebSearch(searcher)
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.WebSearchArgs{Query: " "}))
+		is.Equal(t, "query cannot be empty", err.Error())
+	})
+
+	t.Run("returns error for invalid arguments without searching", func(t *testing.T) {
+		tool := tools.NewWebSearch(searcher)
+
+		_, err := tool.Execute(t.Context(), json.RawMessage(`{"query":"go","count":"three"}`))
+		is.True(t, err != nil)
+		is.True(t, strings.HasPrefix(err.Error(), "invalid arguments for web_search: "))
+	})
+
+	t.Run("summarizes the query and count", func(t *testing.T) {
+		tool := tools.NewWebSearch(searcher)
+
+		summary, err := tool.Summarize(t.Context(), mustMarshalJSON(tools.WebSearchArgs{Query: "go generics", Count: 3}))
+		is.NotError(t, err)
+		is.Equal(t, `query="go generics" count=3`, summary)
+	})
+}
+
+func TestBraveSearcher_Search(t *testing.T) {
+	t.Run("searches and strips highlighting from the results", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			is.Equal(t, "/res/v1/web/search", r.URL.Path)
+			is.Equal(t, "go generics", r.URL.Query().Get("q"))
+			is.Equal(t, "3", r.URL.Query().Get("count"))
+			is.Equal(t, "secret", r.Header.Get("X-Subscription-Token"))
+
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"web":{"results":[{"title":"Tutorial: <strong>Generics</strong>","url":"https://go.dev/doc/tutorial/generics","description":"Get started with <strong>generics</strong> &amp; more."}]}}`))
+		}))
+		defer server.Close()
+
+		s := tools.NewBraveSearcher(tools.NewBraveSearcherOptions{BaseURL: server.URL + "/res/v1", Key: "secret"})
+
+		results, err := s.Search(t.Context(), "go generics", 3)
+		is.NotError(t, err)
+		is.Equal(t, 1, len(results))
+		is.Equal(t, tools.SearchResult{
+			Title:   "Tutorial: Generics",
+			URL:     "https://go.dev/doc/tutorial/generics",
+			Snippet: "Get started with generics & more.",
+		}, results[0])
+	})
+
+	t.Run("returns error for HTTP errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		s := tools.NewBraveSearcher(tools.NewBraveSearcherOptions{BaseURL: server.URL})
+
+		_, err := s.Search(t.Context(), "go", 3)
+		is.True(t, err != nil)
+		is.Equal(t, "GET web/search: 401 Unauthorized: invalid token", err.Error())
+	})
+}
+
+func TestTavilySearcher_Search(t *testing.T) {
+	t.Run("searches", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			is.Equal(t, http.MethodPost, r.Method)
+			is.Equal(t, "/search", r.URL.Path)
+			is.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+			var body struct {
+				Query      string `json:"query"`
+				MaxResults int    `json:"max_results"`
+			}
+			is.NotError(t, json.NewDecoder(r.Body).Decode(&body))
+			is.Equal(t, "go generics", body.Query)
+			is.Equal(t, 3, body.MaxResults)
+
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"results":[{"title":"Tutorial: Generics","url":"https://go.dev/doc/tutorial/generics","content":"Get started with generics."}]}`))
+		}))
+		defer server.Close()
+
+		s := tools.NewTavilySearcher(tools.NewTavilySearcherOptions{BaseURL: server.URL, Key: "secret"})
+
+		results, err := s.Search(t.Context(), "go generics", 3)
+		is.NotError(t, err)
+		is.Equal(t, 1, len(results))
+		is.Equal(t, tools.SearchResult{
+			Title:   "Tutorial: Generics",
+			URL:     "https://go.dev/doc/tutorial/generics",
+			Snippet: "Get started with generics.",
+		}, results[0])
+	})
+
+	t.Run("returns error for HTTP errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "invalid key", http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		s := tools.NewTavilySearcher(tools.NewTavilySearcherOptions{BaseURL: server.URL})
+
+		_, err := s.Search(t.Context(), "go", 3)
+		is.True(t, err != nil)
+		is.Equal(t, "POST search: 401 Unauthorized: invalid key", err.Error())
+	})
+}
+
+func TestFakeSearcher_Search(t *testing.T) {
+	results := []tools.SearchResult{
+		{Title: "Go", URL: "https://go.dev", Snippet: "The Go programming language."},
+		{Title: "Go by Example", URL: "https://gobyexample.com", Snippet: "Hands-on introduction to Go."},
+		{Title: "Rust", URL: "https://rust-lang.org", Snippet: "A language empowering everyone."},
+	}
+
+	t.Run("returns the results matching every word of the query, up to count", func(t *testing.T) {
+		s := tools.NewFakeSearcher(results...)
+
+		res, err := s.Search(t.Context(), "GO language", 10)
+		is.NotError(t, err)
+		is.Equal(t, 1, len(res))
+		is.Equal(t, "https://go.dev", res[0].URL)
+
+		res, err = s.Search(t.Context(), "go", 1)
+		is.NotError(t, err)
+		is.Equal(t, 1, len(res))
+
+		res, err = s.Search(t.Context(), "python", 10)
+		is.NotError(t, err)
+		is.Equal(t, 0, len(res))
+
+		is.EqualSlice(t, []string{"GO language", "go", "python"}, s.Queries())
+	})
+
+	t.Run("returns the context error", func(t *testing.T) {
+		s := tools.NewFakeSearcher(results...)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		_, err := s.Search(ctx, "go", 10)
+		is.Equal(t, context.Canceled, err)
+	})
+}
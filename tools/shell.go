@@ -0,0 +1,426 @@
+//go:build unix
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"maragu.dev/gai"
+)
+
+// shellInterruptGrace is how long a command has to stop after being interrupted, before the shell is killed.
+const shellInterruptGrace = 2 * time.Second
+
+// ShellSession is a long-running shell process, so the working directory, variables,
+// and other shell state persist between commands. Commands run one at a time.
+// Create it with [NewShellSession], and close it with [ShellSession.Close] when done.
+type ShellSession struct {
+	opts NewShellSessionOptions
+
+	// runLock is held while a command runs, so commands run one at a time
+	runLock sync.Mutex
+
+	// lock guards the fields below, which are replaced when the shell restarts
+	lock   sync.Mutex
+	closed bool
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	output chan []byte
+	exited chan struct{}
+}
+
+// NewShellSessionOptions for [NewShellSession].
+type NewShellSessionOptions struct {
+	// Shell to run. Defaults to bash if it is found, and sh otherwise.
+	Shell string
+	// Root is the initial working directory of the shell. Nil means the current working directory.
+	// The shell is not otherwise confined to it.
+	Root *os.Root
+	// Env of the shell in the form "key=value". Nil means the host environment.
+	Env []string
+	// MaxOutputBytes of each command. Output after that is dropped and marked as truncated.
+	// Zero means no limit.
+	MaxOutputBytes int
+}
+
+// NewShellSession creates a new [ShellSession]. The shell starts with the first command,
+// and starts again if it exits, for example because a command ran exit.
+func NewShellSession(opts NewShellSessionOptions) *ShellSession {
+	if opts.Shell == "" {
+		opts.Shell = "sh"
+		if _, err := exec.LookPath("bash"); err == nil {
+			opts.Shell = "bash"
+		}
+	}
+
+	return &ShellSession{opts: opts}
+}
+
+// ShellResult of running a command in a [ShellSession].
+type ShellResult struct {
+	// Output is stdout and stderr, interleaved as written.
+	Output string
+	// ExitCode of the command. It is -1 if the shell exited or was killed.
+	ExitCode int
+	// TimedOut is true if the command was interrupted because it ran for too long.
+	TimedOut bool
+	// Restarted is true if the shell exited or was killed, losing its state.
+	Restarted bool
+}
+
+// Run a command in the shell, waiting at most timeout for it to finish.
+// If the command times out, or ctx is cancelled, it is interrupted like with [ShellSession.Interrupt].
+// If it does not stop shortly after, the shell is killed and a new one starts with the next command.
+// If ctx is cancelled, the result is returned along with the context error.
+func (s *ShellSession) Run(ctx context.Context, command string, timeout time.Duration) (ShellResult, error) {
+	s.runLock.Lock()
+	defer s.runLock.Unlock()
+
+	if err := s.start(); err != nil {
+		return ShellResult{}, err
+	}
+
+	s.lock.Lock()
+	stdin, output, exited := s.stdin, s.output, s.exited
+	s.lock.Unlock()
+
+	sentinel, err := newSentinel()
+	if err != nil {
+		return ShellResult{}, err
+	}
+
+	// The command is evaluated so a syntax error does not break the sentinel line, and gets no stdin,
+	// so it can't read the lines that follow. The leading newline puts the sentinel on its own line.
+	script := fmt.Sprintf("eval %v </dev/null 2>&1\nprintf '\\n%v %%d\\n' \"$?\"\n", shellQuote(command), sentinel)
+	if _, err := io.WriteString(stdin, script); err != nil {
+		s.kill()
+		return ShellResult{Output: "Shell exited", ExitCode: -1, Restarted: true}, nil
+	}
+
+	out := &sentinelOutput{sentinel: []byte("\n" + sentinel + " "), max: s.opts.MaxOutputBytes}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var result ShellResult
+	var grace <-chan time.Time
+	done := ctx.Done()
+	for {
+		select {
+		case chunk, ok := <-output:
+			if !ok {
+				<-exited
+				s.kill()
+				result.Output = out.String()
+				result.ExitCode = -1
+				result.Restarted = true
+				return result, ctx.Err()
+			}
+			if exitCode, ok := out.write(chunk); ok {
+				result.Output = out.String()
+				result.ExitCode = exitCode
+				return result, ctx.Err()
+			}
+
+		case <-timer.C:
+			result.TimedOut = true
+			if err := s.Interrupt(); err != nil {
+				return result, err
+			}
+			grace = time.After(shellInterruptGrace)
+
+		case <-done:
+			if err := s.Interrupt(); err != nil {
+				return result, err
+			}
+			grace = time.After(shellInterruptGrace)
+			done = nil
+
+		case <-grace:
+			s.kill()
+			result.Output = out.String()
+			result.ExitCode = -1
+			result.Restarted = true
+			return result, ctx.Err()
+		}
+	}
+}
+
+// Interrupt the running command, if any, by sending SIGINT to it and the shell.
+// The shell itself ignores the interrupt.
+func (s *ShellSession) Interrupt() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.cmd == nil {
+		return nil
+	}
+	if err := syscall.Kill(-s.cmd.Process.Pid, syscall.SIGINT); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return fmt.Errorf("error interrupting shell: %w", err)
+	}
+	return nil
+}
+
+// Close the session, killing the shell and any running command.
+func (s *ShellSession) Close() error {
+	s.lock.Lock()
+	s.closed = true
+	s.lock.Unlock()
+
+	s.kill()
+	return nil
+}
+
+// start the shell if it is not running.
+func (s *ShellSession) start() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return errors.New("shell session is closed")
+	}
+	if s.cmd != nil {
+		return nil
+	}
+
+	cmd := exec.Command(s.opts.Shell)
+	// Run in a new process group, so interrupts and kills reach the shell and everything it started
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Env = s.opts.Env
+	if s.opts.Root != nil {
+		cmd.Dir = s.opts.Root.Name()
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("error creating shell stdin: %w", err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("error creating shell output: %w", err)
+	}
+	cmd.Stdout = w
+	cmd.Stderr = w
+
+	if err := cmd.Start(); err != nil {
+		_ = r.Close()
+		_ = w.Close()
+		return fmt.Errorf("error starting shell: %w", err)
+	}
+	_ = w.Close()
+
+	// A trapped signal is reset for commands, so they can still be interrupted, while the shell carries on
+	if _, err := io.WriteString(stdin, "trap ':' INT\n"); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		_ = r.Close()
+		return fmt.Errorf("error setting up shell: %w", err)
+	}
+
+	output := make(chan []byte)
+	exited := make(chan struct{})
+	go func() {
+		defer close(output)
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := r.Read(buf)
+			if n > 0 {
+				output <- bytes.Clone(buf[:n])
+			}
+			if err != nil {
+				_ = r.Close()
+				return
+			}
+		}
+	}()
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+
+	s.cmd = cmd
+	s.stdin = stdin
+	s.output = output
+	s.exited = exited
+	return nil
+}
+
+// kill the shell and everything it started, if it is running, so a new one starts with the next command.
+func (s *ShellSession) kill() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.cmd == nil {
+		return
+	}
+
+	_ = syscall.Kill(-s.cmd.Process.Pid, syscall.SIGKILL)
+	_ = s.stdin.Close()
+	<-s.exited
+	// Drain output, in case a process that left the process group still holds the pipe
+	go func(output chan []byte) {
+		for range output {
+		}
+	}(s.output)
+
+	s.cmd = nil
+	s.stdin = nil
+	s.output = nil
+	s.exited = nil
+}
+
+// newSentinel returns a random string that marks the end of a command's output.
+func newSentinel() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error creating sentinel: %w", err)
+	}
+	return "__gai_" + hex.EncodeToString(b), nil
+}
+
+// shellQuote a string in single quotes, so the shell takes it literally.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// sentinelOutput collects command output until the sentinel line with the exit code,
+// keeping at most max bytes of output. A max of zero means no limit.
+type sentinelOutput struct {
+	sentinel []byte
+	max      int
+	kept     bytes.Buffer
+	pending  []byte
+	dropped  int
+}
+
+// write a chunk of output, and return the exit code and true when the sentinel line is complete.
+func (o *sentinelOutput) write(chunk []byte) (int, bool) {
+	o.pending = append(o.pending, chunk...)
+
+	i := bytes.Index(o.pending, o.sentinel)
+	if i < 0 {
+		// Hold back what could be the start of the sentinel
+		n := max(len(o.pending)-len(o.sentinel)+1, 0)
+		o.keep(o.pending[:n])
+		o.pending = o.pending[n:]
+		return 0, false
+	}
+
+	o.keep(o.pending[:i])
+	o.pending = o.pending[i:]
+
+	rest := o.pending[len(o.sentinel):]
+	end := bytes.IndexByte(rest, '\n')
+	if end < 0 {
+		return 0, false
+	}
+	exitCode, err := strconv.Atoi(string(rest[:end]))
+	if err != nil {
+		exitCode = -1
+	}
+	return exitCode, true
+}
+
+func (o *sentinelOutput) keep(b []byte) {
+	if o.max > 0 && o.kept.Len()+len(b) > o.max {
+		n := o.max - o.kept.Len()
+		o.kept.Write(b[:n])
+		o.dropped += len(b) - n
+		return
+	}
+	o.kept.Write(b)
+}
+
+// String of the kept output, with a marker if output was truncated.
+func (o *sentinelOutput) String() string {
+	if o.dropped == 0 {
+		return o.kept.String()
+	}
+	return fmt.Sprintf("%v\n[output truncated, %d bytes dropped]\n", o.kept.String(), o.dropped)
+}
+
+// ShellArgs holds the arguments for the Shell tool.
+type ShellArgs struct {
+	Command string `json:"command" jsonschema_description:"The shell command to run."`
+	Timeout int    `json:"timeout,omitempty" jsonschema_description:"Optional timeout in seconds. Default is 30 seconds."`
+}
+
+// NewShell creates a new tool for running commands in the given [ShellSession],
+// so the working directory, variables, and other shell state persist between calls.
+func NewShell(session *ShellSession) gai.Tool {
+	return gai.NewTool(gai.NewToolOptions[ShellArgs]{
+		Name: "shell",
+		Description: `Run a command in a persistent shell session and capture its output.
+
+The shell keeps its state between calls, so changing directories, setting variables, and activating virtual environments carry over to the next command.
+- Commands get no stdin, so don't run interactive commands
+- Stdout and stderr are combined
+- Timeout can be specified in seconds (default is 30 seconds), after which the command is interrupted
+- If a command does not stop when interrupted, or the shell exits, the shell restarts and its state is lost`,
+		Summarize: func(ctx context.Context, args ShellArgs) (string, error) {
+			command := args.Command
+			if len(command) > 50 {
+				command = command[:50] + "..."
+			}
+			summary := fmt.Sprintf(`command="%s"`, command)
+
+			// Add timeout if different from default
+			if args.Timeout > 0 && args.Timeout != 30 {
+				summary += fmt.Sprintf(` timeout=%ds`, args.Timeout)
+			}
+
+			return summary, nil
+		},
+		Execute: func(ctx context.Context, args ShellArgs) (string, error) {
+			if strings.TrimSpace(args.Command) == "" {
+				return "", errors.New("command cannot be empty")
+			}
+
+			// Set default timeout if not provided
+			timeout := 30
+			if args.Timeout > 0 {
+				timeout = args.Timeout
+			}
+
+			res, err := session.Run(ctx, args.Command, time.Duration(timeout)*time.Second)
+			if err != nil {
+				return "", err
+			}
+
+			var result strings.Builder
+			if res.Output != "" {
+				result.WriteString(res.Output)
+				// Add newline if output doesn't end with one
+				if !strings.HasSuffix(res.Output, "\n") {
+					result.WriteString("\n")
+				}
+				result.WriteString("\n")
+			}
+
+			if res.TimedOut {
+				fmt.Fprintf(&result, "Command timed out after %d seconds and was interrupted\n", timeout)
+			}
+			if res.Restarted {
+				result.WriteString("The shell exited or was killed, so it restarts with the next command and its state is lost\n")
+			} else {
+				fmt.Fprintf(&result, "Command exited with status %d\n", res.ExitCode)
+			}
+
+			return result.String(), nil
+		},
+	})
+}
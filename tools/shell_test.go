@@ -0,0 +1,172 @@
+//go:build unix
+
+package tools_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai/tools"
+)
+
+func TestShellSession_Run(t *testing.T) {
+	t.Run("keeps state between commands", func(t *testing.T) {
+		dir := t.TempDir()
+		is.NotError(t, os.Mkdir(dir+"/sub", 0755))
+		root, err := os.OpenRoot(dir)
+		is.NotError(t, err)
+		t.Cleanup(func() { _ = root.Close() })
+
+		s := newShellSession(t, tools.NewShellSessionOptions{Root: root})
+
+		res, err := s.Run(t.Context(), "cd sub && export GREETING=hi", time.Second)
+		is.NotError(t, err)
+		is.Equal(t, 0, res.ExitCode)
+		is.Equal(t, "", res.Output)
+
+		res, err = s.Run(t.Context(), `basename "$PWD"; echo "$GREETING"`, time.Second)
+		is.NotError(t, err)
+		is.Equal(t, "sub\nhi\n", res.Output)
+	})
+
+	t.Run("reports the exit status and combines stdout and stderr", func(t *testing.T) {
+		s := newShellSession(t, tools.NewShellSessionOptions{})
+
+		res, err := s.Run(t.Context(), "echo out; echo err >&2; false", time.Second)
+		is.NotError(t, err)
+		is.Equal(t, 1, res.ExitCode)
+		is.Equal(t, "out\nerr\n", res.Output)
+	})
+
+	t.Run("does not give commands stdin", func(t *testing.T) {
+		s := newShellSession(t, tools.NewShellSessionOptions{})
+
+		res, err := s.Run(t.Context(), "cat", time.Second)
+		is.NotError(t, err)
+		is.Equal(t, 0, res.ExitCode)
+		is.Equal(t, "", res.Output)
+	})
+
+	t.Run("interrupts a command that times out, keeping the shell", func(t *testing.T) {
+		s := newShellSession(t, tools.NewShellSessionOptions{})
+
+		_, err := s.Run(t.Context(), "export GREETING=hi", time.Second)
+		is.NotError(t, err)
+
+		start := time.Now()
+		res, err := s.Run(t.Context(), "echo before; sleep 10", 200*time.Millisecond)
+		is.NotError(t, err)
+		is.True(t, res.TimedOut)
+		is.True(t, !res.Restarted)
+		is.Equal(t, 130, res.ExitCode)
+		is.Equal(t, "before\n", res.Output)
+		is.True(t, time.Since(start) < 2*time.Second)
+
+		res, err = s.Run(t.Context(), `echo "$GREETING"`, time.Second)
+		is.NotError(t, err)
+		is.Equal(t, "hi\n", res.Output)
+	})
+
+	t.Run("restarts the shell if a command does not stop when interrupted", func(t *testing.T) {
+		s := newShellSession(t, tools.NewShellSessionOptions{})
+
+		res, err := s.Run(t.Context(), "trap '' INT; sleep 10", 200*time.Millisecond)
+		is.NotError(t, err)
+		is.True(t, res.TimedOut)
+		is.True(t, res.Restarted)
+		is.Equal(t, -1, res.ExitCode)
+
+		res, err = s.Run(t.Context(), "echo hi", time.Second)
+		is.NotError(t, err)
+		is.Equal(t, "hi\n", res.Output)
+	})
+
+	t.Run("interrupts a command when the context is cancelled", func(t *testing.T) {
+		s := newShellSession(t, tools.NewShellSessionOptions{})
+
+		ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+		defer cancel()
+
+		res, err := s.Run(ctx, "sleep 10", 10*time.Second)
+		is.Equal(t, context.DeadlineExceeded, err)
+		is.Equal(t, 130, res.ExitCode)
+	})
+
+	t.Run("restarts the shell if it exits", func(t *testing.T) {
+		s := newShellSession(t, tools.NewShellSessionOptions{})
+
+		res, err := s.Run(t.Context(), "exit 3", time.Second)
+		is.NotError(t, err)
+		is.True(t, res.Restarted)
+
+		res, err = s.Run(t.Context(), "echo hi", time.Second)
+		is.NotError(t, err)
+		is.Equal(t, "hi\n", res.Output)
+	})
+
+	t.Run("truncates output over the limit", func(t *testing.T) {
+		s := newShellSession(t, tools.NewShellSessionOptions{MaxOutputBytes: 5})
+
+		res, err := s.Run(t.Context(), "echo Hello, World!", time.Second)
+		is.NotError(t, err)
+		is.Equal(t, 0, res.ExitCode)
+		is.Equal(t, "Hello\n[output truncated, 9 bytes dropped]\n", res.Output)
+	})
+
+	t.Run("errors after close", func(t *testing.T) {
+		s := tools.NewShellSession(tools.NewShellSessionOptions{})
+		is.NotError(t, s.Close())
+
+		_, err := s.Run(t.Context(), "echo hi", time.Second)
+		is.Equal(t, "shell session is closed", err.Error())
+	})
+}
+
+func TestNewShell(t *testing.T) {
+	t.Run("runs commands and reports the exit status", func(t *testing.T) {
+		tool := tools.NewShell(newShellSession(t, tools.NewShellSessionOptions{}))
+
+		is.Equal(t, "shell", tool.Name)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ShellArgs{Command: "echo hi; exit_status=2; (exit $exit_status)"}))
+		is.NotError(t, err)
+		is.Equal(t, "hi\n\nCommand exited with status 2\n", result)
+	})
+
+	t.Run("returns error for empty command", func(t *testing.T) {
+		tool := tools.NewShell(newShellSession(t, tools.NewShellSessionOptions{}))
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(tools.ShellArgs{Command: " "}))
+		is.Equal(t, "command cannot be empty", err.Error())
+	})
+
+	t.Run("returns error for invalid arguments without running anything", func(t *testing.T) {
+		tool := tools.NewShell(newShellSession(t, tools.NewShellSessionOptions{}))
+
+		_, err := tool.Execute(t.Context(), json.RawMessage(`{"timeout":"soon"}`))
+		is.True(t, err != nil)
+		is.True(t, strings.HasPrefix(err.Error(), "invalid arguments for shell: "))
+	})
+
+	t.Run("summarizes the command and timeout", func(t *testing.T) {
+		tool := tools.NewShell(newShellSession(t, tools.NewShellSessionOptions{}))
+
+		summary, err := tool.Summarize(t.Context(), mustMarshalJSON(tools.ShellArgs{Command: "go test ./...", Timeout: 60}))
+		is.NotError(t, err)
+		is.Equal(t, `command="go test ./..." timeout=60s`, summary)
+	})
+}
+
+func newShellSession(t *testing.T, opts tools.NewShellSessionOptions) *tools.ShellSession {
+	t.Helper()
+
+	s := tools.NewShellSession(opts)
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
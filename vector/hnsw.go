@@ -0,0 +1,208 @@
+package vector
+
+import (
+	"cmp"
+	"container/heap"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// hnsw is a Hierarchical Navigable Small World graph for approximate nearest neighbor search,
+// as described by Malkov and Yashunin in https://arxiv.org/abs/1603.09320.
+// Nodes are identified by the slot of their document in the store, and distances are given by the store,
+// so the graph doesn't know about vectors at all. Deleted documents stay in the graph to keep it connected,
+// and are skipped in results by the caller, until the store rebuilds the graph.
+type hnsw struct {
+	// m is the number of neighbors per node on the upper layers, and 2*m on the bottom layer
+	m              int
+	efConstruction int
+	// ml normalizes the random level of new nodes
+	ml       float64
+	rand     *rand.Rand
+	nodes    []*hnswNode
+	entry    int
+	maxLevel int
+	// distance between the documents in two slots
+	distance func(a, b int) float64
+}
+
+type hnswNode struct {
+	// neighbors on each layer the node is on, from the bottom
+	neighbors [][]int
+}
+
+func newHNSW(m, efConstruction int, distance func(a, b int) float64) *hnsw {
+	return &hnsw{
+		m:              m,
+		efConstruction: efConstruction,
+		ml:             1 / math.Log(float64(m)),
+		// A fixed seed, so the same documents added in the same order always give the same graph
+		rand:     rand.New(rand.NewPCG(1, 2)),
+		entry:    -1,
+		distance: distance,
+	}
+}
+
+// candidate node and its distance to the query.
+type candidate struct {
+	node     int
+	distance float64
+}
+
+// insert the document in the slot into the graph.
+func (h *hnsw) insert(slot int) {
+	level := int(math.Floor(-math.Log(1-h.rand.Float64()) * h.ml))
+	node := &hnswNode{neighbors: make([][]int, level+1)}
+	for len(h.nodes) <= slot {
+		h.nodes = append(h.nodes, nil)
+	}
+	h.nodes[slot] = node
+
+	if h.entry < 0 {
+		h.entry = slot
+		h.maxLevel = level
+		return
+	}
+
+	distance := func(other int) float64 { return h.distance(slot, other) }
+
+	// Descend greedily to the level of the new node
+	entries := []candidate{{node: h.entry, distance: distance(h.entry)}}
+	for l := h.maxLevel; l > level; l-- {
+		entries = h.searchLayer(entries, 1, l, distance)
+	}
+
+	// Connect the node to its nearest neighbors on each of its layers
+	for l := min(level, h.maxLevel); l >= 0; l-- {
+		candidates := h.searchLayer(entries, h.efConstruction, l, distance)
+
+		maxNeighbors := h.maxNeighbors(l)
+		for _, c := range candidates[:min(len(candidates), h.m)] {
+			node.neighbors[l] = append(node.neighbors[l], c.node)
+
+			neighbor := h.nodes[c.node]
+			neighbor.neighbors[l] = append(neighbor.neighbors[l], slot)
+			if len(neighbor.neighbors[l]) > maxNeighbors {
+				h.prune(c.node, l, maxNeighbors)
+			}
+		}
+
+		entries = candidates
+	}
+
+	if level > h.maxLevel {
+		h.entry = slot
+		h.maxLevel = level
+	}
+}
+
+func (h *hnsw) maxNeighbors(level int) int {
+	if level == 0 {
+		return 2 * h.m
+	}
+	return h.m
+}
+
+// prune the neighbors of the node on the level to the closest ones.
+func (h *hnsw) prune(slot, level, maxNeighbors int) {
+	neighbors := h.nodes[slot].neighbors[level]
+	candidates := make([]candidate, len(neighbors))
+	for i, n := range neighbors {
+		candidates[i] = candidate{node: n, distance: h.distance(slot, n)}
+	}
+	slices.SortFunc(candidates, compareCandidates)
+
+	neighbors = neighbors[:0]
+	for _, c := range candidates[:maxNeighbors] {
+		neighbors = append(neighbors, c.node)
+	}
+	h.nodes[slot].neighbors[level] = neighbors
+}
+
+// search for the ef nearest nodes to the query given by distance, nearest first.
+func (h *hnsw) search(ef int, distance func(slot int) float64) []candidate {
+	if h.entry < 0 {
+		return nil
+	}
+
+	entries := []candidate{{node: h.entry, distance: distance(h.entry)}}
+	for l := h.maxLevel; l > 0; l-- {
+		entries = h.searchLayer(entries, 1, l, distance)
+	}
+	return h.searchLayer(entries, ef, 0, distance)
+}
+
+// searchLayer for the ef nearest nodes on the level, starting from the entries, nearest first.
+func (h *hnsw) searchLayer(entries []candidate, ef, level int, distance func(slot int) float64) []candidate {
+	visited := make(map[int]bool, ef*4)
+	var candidates minHeap
+	var results maxHeap
+	for _, e := range entries {
+		visited[e.node] = true
+		heap.Push(&candidates, e)
+		heap.Push(&results, e)
+		if results.Len() > ef {
+			heap.Pop(&results)
+		}
+	}
+
+	for candidates.Len() > 0 {
+		c := heap.Pop(&candidates).(candidate)
+		if c.distance > results[0].distance && results.Len() >= ef {
+			break
+		}
+
+		for _, n := range h.nodes[c.node].neighbors[level] {
+			if visited[n] {
+				continue
+			}
+			visited[n] = true
+
+			d := distance(n)
+			if results.Len() < ef || d < results[0].distance {
+				heap.Push(&candidates, candidate{node: n, distance: d})
+				heap.Push(&results, candidate{node: n, distance: d})
+				if results.Len() > ef {
+					heap.Pop(&results)
+				}
+			}
+		}
+	}
+
+	sorted := []candidate(results)
+	slices.SortFunc(sorted, compareCandidates)
+	return sorted
+}
+
+func compareCandidates(a, b candidate) int {
+	return cmp.Or(cmp.Compare(a.distance, b.distance), cmp.Compare(a.node, b.node))
+}
+
+// minHeap of candidates, nearest first.
+type minHeap []candidate
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return h[i].distance < h[j].distance }
+func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *minHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// maxHeap of candidates, farthest first.
+type maxHeap []candidate
+
+func (h maxHeap) Len() int           { return len(h) }
+func (h maxHeap) Less(i, j int) bool { return h[i].distance > h[j].distance }
+func (h maxHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *maxHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
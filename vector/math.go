@@ -0,0 +1,159 @@
+package vector
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+
+	"maragu.dev/gai"
+)
+
+// Float vector component, for the functions that only make sense with fractional components.
+type Float interface {
+	~float32 | ~float64
+}
+
+// Dot product of a and b, computed in float64 so integer components can't overflow.
+// Panics if the vectors have different lengths.
+func Dot[T gai.VectorComponent](a, b []T) float64 {
+	requireEqualLengths(a, b)
+	return dot(a, b)
+}
+
+// Norm is the Euclidean (L2) norm of v.
+func Norm[T gai.VectorComponent](v []T) float64 {
+	return norm(v)
+}
+
+// Cosine similarity of a and b, from -1 to 1. Vectors with a zero norm have a similarity of 0 to everything.
+// Panics if the vectors have different lengths.
+// Unlike [maragu.dev/gai/eval.CosineSimilarity], the result is not mapped to a score from 0 to 1,
+// and zero vectors don't panic.
+func Cosine[T gai.VectorComponent](a, b []T) float64 {
+	requireEqualLengths(a, b)
+	normA, normB := norm(a), norm(b)
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot(a, b) / (normA * normB)
+}
+
+// EuclideanDistance between a and b. Panics if the vectors have different lengths.
+func EuclideanDistance[T gai.VectorComponent](a, b []T) float64 {
+	requireEqualLengths(a, b)
+	return l2(a, b)
+}
+
+// Normalize v to unit length, returning a new vector. A vector with a zero norm is returned as a copy.
+func Normalize[T Float](v []T) []T {
+	normalized := make([]T, len(v))
+	n := norm(v)
+	if n == 0 {
+		copy(normalized, v)
+		return normalized
+	}
+	for i, c := range v {
+		normalized[i] = T(float64(c) / n)
+	}
+	return normalized
+}
+
+// Truncate a matryoshka embedding to its first dimensions, and normalize it.
+// Models trained with matryoshka representation learning, like OpenAI's text-embedding-3 models and Google's
+// Gemini embedding models, put the most important information in the first dimensions, so truncated embeddings
+// keep most of their quality while being cheaper to store and compare. Only compare embeddings truncated to
+// the same dimensions. Panics if dimensions is not positive or larger than the length of v.
+func Truncate[T Float](v []T, dimensions int) []T {
+	if dimensions <= 0 || dimensions > len(v) {
+		panic(fmt.Sprintf("dimensions must be between 1 and %v, but is %v", len(v), dimensions))
+	}
+	return Normalize(v[:dimensions])
+}
+
+// Convert the components of v to another type, like float64 embeddings to float32 to halve their size.
+// Conversions to integer types truncate toward zero and don't check for overflow, so use [QuantizeInt8]
+// to turn fractional components into integers.
+func Convert[To, From gai.VectorComponent](v []From) []To {
+	converted := make([]To, len(v))
+	for i, c := range v {
+		converted[i] = To(c)
+	}
+	return converted
+}
+
+// QuantizeInt8 scales v so that its largest absolute component is 127, and rounds the components to int8,
+// for a quarter of the size of float32 embeddings. It returns the scale to multiply the quantized components
+// by to approximate the original ones. Cosine similarity is mostly preserved, so quantized embeddings can be
+// searched with [MetricCosine] in a [Store]. A vector of zeros has a scale of 0.
+func QuantizeInt8[T Float](v []T) ([]int8, float64) {
+	var largest float64
+	for _, c := range v {
+		largest = max(largest, math.Abs(float64(c)))
+	}
+
+	quantized := make([]int8, len(v))
+	if largest == 0 {
+		return quantized, 0
+	}
+
+	scale := largest / 127
+	for i, c := range v {
+		quantized[i] = int8(max(-127, min(127, math.Round(float64(c)/scale))))
+	}
+	return quantized, scale
+}
+
+// QuantizeBinary keeps the sign of each component of v as a bit, set for positive components, packing eight
+// components into each byte with the first component in the most significant bit. That's a 32nd of the size
+// of float32 embeddings. Compare binary embeddings with [HammingDistance], typically to find candidates that
+// are then rescored with the full embeddings.
+func QuantizeBinary[T gai.VectorComponent](v []T) []byte {
+	quantized := make([]byte, (len(v)+7)/8)
+	for i, c := range v {
+		if c > 0 {
+			quantized[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return quantized
+}
+
+// HammingDistance is the number of differing bits between a and b, like binary embeddings from [QuantizeBinary].
+// Lower is more similar. Panics if the vectors have different lengths.
+func HammingDistance(a, b []byte) int {
+	requireEqualLengths(a, b)
+	var distance int
+	for i := range a {
+		distance += bits.OnesCount8(a[i] ^ b[i])
+	}
+	return distance
+}
+
+// dot product of a and b, computed in float64 so integer components can't overflow.
+func dot[T gai.VectorComponent](a, b []T) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+// norm is the Euclidean (L2) norm of v.
+func norm[T gai.VectorComponent](v []T) float64 {
+	return math.Sqrt(dot(v, v))
+}
+
+// l2 is the Euclidean distance between a and b.
+func l2[T gai.VectorComponent](a, b []T) float64 {
+	var sum float64
+	for i := range a {
+		d := float64(a[i]) - float64(b[i])
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+func requireEqualLengths[T any](a, b []T) {
+	if len(a) != len(b) {
+		panic(fmt.Sprintf("vectors must have equal length, but are lengths %v and %v", len(a), len(b)))
+	}
+}
@@ -0,0 +1,114 @@
+package vector_test
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai/vector"
+)
+
+func TestDot(t *testing.T) {
+	t.Run("computes the dot product without overflowing integer components", func(t *testing.T) {
+		is.Equal(t, 11.0, vector.Dot([]float32{1, 2}, []float32{3, 4}))
+		is.Equal(t, 2*127.0*127.0, vector.Dot([]int8{127, 127}, []int8{127, 127}))
+	})
+
+	t.Run("panics on different lengths", func(t *testing.T) {
+		defer func() {
+			is.Equal(t, "vectors must have equal length, but are lengths 2 and 1", recover())
+		}()
+		vector.Dot([]float64{1, 2}, []float64{1})
+	})
+}
+
+func TestCosine(t *testing.T) {
+	t.Run("computes the cosine similarity", func(t *testing.T) {
+		is.Equal(t, 1.0, vector.Cosine([]float64{1, 0}, []float64{2, 0}))
+		is.Equal(t, 0.0, vector.Cosine([]float64{1, 0}, []float64{0, 3}))
+		is.Equal(t, -1.0, vector.Cosine([]int{2, 0}, []int{-1, 0}))
+	})
+
+	t.Run("returns 0 for zero vectors", func(t *testing.T) {
+		is.Equal(t, 0.0, vector.Cosine([]float64{0, 0}, []float64{1, 0}))
+	})
+}
+
+func TestEuclideanDistance(t *testing.T) {
+	t.Run("computes the distance", func(t *testing.T) {
+		is.Equal(t, 5.0, vector.EuclideanDistance([]float64{0, 0}, []float64{3, 4}))
+		is.Equal(t, 5.0, vector.Norm([]float64{3, 4}))
+	})
+}
+
+func TestNormalize(t *testing.T) {
+	t.Run("normalizes to unit length without changing the input", func(t *testing.T) {
+		v := []float64{3, 4}
+		is.EqualSlice(t, []float64{0.6, 0.8}, vector.Normalize(v))
+		is.EqualSlice(t, []float64{3, 4}, v)
+	})
+
+	t.Run("returns zero vectors as is", func(t *testing.T) {
+		is.EqualSlice(t, []float32{0, 0}, vector.Normalize([]float32{0, 0}))
+	})
+}
+
+func TestTruncate(t *testing.T) {
+	t.Run("keeps the first dimensions and normalizes them", func(t *testing.T) {
+		is.EqualSlice(t, []float64{0.6, 0.8}, vector.Truncate([]float64{3, 4, 12}, 2))
+	})
+
+	t.Run("panics on invalid dimensions", func(t *testing.T) {
+		defer func() {
+			is.Equal(t, "dimensions must be between 1 and 3, but is 4", recover())
+		}()
+		vector.Truncate([]float64{3, 4, 12}, 4)
+	})
+}
+
+func TestConvert(t *testing.T) {
+	t.Run("converts between component types", func(t *testing.T) {
+		is.EqualSlice(t, []float32{0.5, -1}, vector.Convert[float32]([]float64{0.5, -1}))
+		is.EqualSlice(t, []float64{1, -2}, vector.Convert[float64]([]int8{1, -2}))
+	})
+}
+
+func TestQuantizeInt8(t *testing.T) {
+	t.Run("scales the largest component to 127", func(t *testing.T) {
+		q, scale := vector.QuantizeInt8([]float64{0.5, -1, 0.25, 0})
+		is.EqualSlice(t, []int8{64, -127, 32, 0}, q)
+		is.Equal(t, 1.0/127, scale)
+	})
+
+	t.Run("returns a zero scale for zero vectors", func(t *testing.T) {
+		q, scale := vector.QuantizeInt8([]float32{0, 0})
+		is.EqualSlice(t, []int8{0, 0}, q)
+		is.Equal(t, 0.0, scale)
+	})
+
+	t.Run("mostly preserves cosine similarity", func(t *testing.T) {
+		r := rand.New(rand.NewPCG(5, 6))
+		for range 100 {
+			a, b := randomVector[float32](r, 256), randomVector[float32](r, 256)
+			qa, _ := vector.QuantizeInt8(a)
+			qb, _ := vector.QuantizeInt8(b)
+			is.True(t, math.Abs(vector.Cosine(a, b)-vector.Cosine(qa, qb)) < 0.01)
+		}
+	})
+}
+
+func TestQuantizeBinary(t *testing.T) {
+	t.Run("packs the signs into bits, first component in the most significant bit", func(t *testing.T) {
+		q := vector.QuantizeBinary([]float64{1, -1, 0, 0.5, 0, 0, 0, 0.1, 2})
+		is.EqualSlice(t, []byte{0b10010001, 0b10000000}, q)
+	})
+
+	t.Run("compares with the Hamming distance", func(t *testing.T) {
+		a := vector.QuantizeBinary([]float32{1, 1, 1, 1})
+		b := vector.QuantizeBinary([]float32{1, -1, 1, -1})
+		is.Equal(t, 2, vector.HammingDistance(a, b))
+		is.Equal(t, 0, vector.HammingDistance(a, a))
+	})
+}
@@ -0,0 +1,361 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/chunk"
+)
+
+// Retriever indexes and searches text in a [Store], embedding documents and queries with a [gai.Embedder].
+type Retriever[T gai.VectorComponent] struct {
+	concurrency      int
+	embedder         gai.Embedder[T]
+	formatDocument   func(title, content string) string
+	formatQuery      func(query string) string
+	queryTask        gai.EmbedTask
+	split            func(text string) []chunk.Chunk
+	rerankCandidates int
+	reranker         gai.Reranker
+	store            *Store[T]
+}
+
+// NewRetrieverOptions for [NewRetriever].
+type NewRetrieverOptions[T gai.VectorComponent] struct {
+	// Embedder for documents and queries. Required.
+	Embedder gai.Embedder[T]
+	// Store for the documents. Nil means a new store with the default options.
+	Store *Store[T]
+	// QueryTask of the embed requests for queries. Defaults to [gai.EmbedTaskSearchQuery].
+	// Documents are embedded with [gai.EmbedTaskDocument] and their title, so embedders that support
+	// tasks, like the ones in clients/google and clients/cohere, optimize the embeddings for retrieval.
+	QueryTask gai.EmbedTask
+	// FormatDocument formats document content with its title before embedding it, for embedding models
+	// that expect documents in a format their embedder doesn't apply from the task, like a prefix.
+	// The stored content is not formatted. Defaults to the content as is.
+	FormatDocument func(title, content string) string
+	// FormatQuery formats a query before embedding it, like FormatDocument. Defaults to the query as is.
+	FormatQuery func(query string) string
+	// Split source text into chunks in [Retriever.Ingest]. Defaults to [chunk.Recursive] with the default options.
+	Split func(text string) []chunk.Chunk
+	// Concurrency is the maximum number of chunks embedded at the same time in [Retriever.Ingest]. Defaults to 4.
+	Concurrency int
+	// Reranker, if not nil, reranks the candidates found in the store in [Retriever.Search].
+	Reranker gai.Reranker
+	// RerankCandidates is the number of candidates from the store to rerank. Defaults to four times the search limit.
+	RerankCandidates int
+}
+
+// NewRetriever with the given options. Panics if the embedder is nil.
+func NewRetriever[T gai.VectorComponent](opts NewRetrieverOptions[T]) *Retriever[T] {
+	if opts.Embedder == nil {
+		panic("embedder cannot be nil")
+	}
+	if opts.Store == nil {
+		opts.Store = NewStore[T](NewStoreOptions{})
+	}
+	if opts.QueryTask == "" {
+		opts.QueryTask = gai.EmbedTaskSearchQuery
+	}
+	if opts.FormatDocument == nil {
+		opts.FormatDocument = func(_, content string) string {
+			return content
+		}
+	}
+	if opts.FormatQuery == nil {
+		opts.FormatQuery = func(query string) string {
+			return query
+		}
+	}
+	if opts.Split == nil {
+		opts.Split = func(text string) []chunk.Chunk {
+			return chunk.Recursive(text, chunk.Options{})
+		}
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	return &Retriever[T]{
+		concurrency:      opts.Concurrency,
+		embedder:         opts.Embedder,
+		formatDocument:   opts.FormatDocument,
+		formatQuery:      opts.FormatQuery,
+		queryTask:        opts.QueryTask,
+		rerankCandidates: opts.RerankCandidates,
+		reranker:         opts.Reranker,
+		split:            opts.Split,
+		store:            opts.Store,
+	}
+}
+
+// Store the retriever uses.
+func (r *Retriever[T]) Store() *Store[T] {
+	return r.store
+}
+
+// Upsert a text document, embedding its content and replacing any document with the same ID.
+func (r *Retriever[T]) Upsert(ctx context.Context, id, content string, metadata map[string]string) error {
+	res, err := r.embedDocument(ctx, "", content)
+	if err != nil {
+		return fmt.Errorf("error embedding document %v: %w", id, err)
+	}
+
+	return r.store.Upsert(Document[T]{
+		ID:        id,
+		Embedding: res.Embedding,
+		Content:   content,
+		Metadata:  metadata,
+	})
+}
+
+// embedDocument content with its title.
+func (r *Retriever[T]) embedDocument(ctx context.Context, title, content string) (gai.EmbedResponse[T], error) {
+	return r.embedder.Embed(ctx, gai.EmbedRequest{
+		Parts: []gai.Part{gai.TextPart(r.formatDocument(title, content))},
+		Task:  gai.EmbedTaskDocument,
+		Title: title,
+	})
+}
+
+// Search for the documents most similar to the query, embedding it first. See [Store.Search].
+// With a reranker, the candidates from the store are reranked, and results have the reranker scores.
+// The minimum score then applies to the candidates from the store.
+func (r *Retriever[T]) Search(ctx context.Context, query string, opts SearchOptions) ([]Result[T], error) {
+	res, err := r.embedder.Embed(ctx, gai.EmbedRequest{
+		Parts: []gai.Part{gai.TextPart(r.formatQuery(query))},
+		Task:  r.queryTask,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error embedding query: %w", err)
+	}
+
+	if r.reranker == nil {
+		return r.store.Search(res.Embedding, opts)
+	}
+
+	if opts.Limit <= 0 {
+		opts.Limit = 10
+	}
+	limit := opts.Limit
+	opts.Limit = r.rerankCandidates
+	if opts.Limit <= 0 {
+		opts.Limit = 4 * limit
+	}
+
+	candidates, err := r.store.Search(res.Embedding, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	documents := make([]string, len(candidates))
+	for i, c := range candidates {
+		documents[i] = c.Document.Content
+	}
+	reranked, err := r.reranker.Rerank(ctx, gai.RerankRequest{Query: query, Documents: documents})
+	if err != nil {
+		return nil, fmt.Errorf("error reranking: %w", err)
+	}
+
+	var results []Result[T]
+	for _, rr := range reranked.Results {
+		if len(results) == limit {
+			break
+		}
+		if rr.Index < 0 || rr.Index >= len(candidates) {
+			return nil, fmt.Errorf("reranker returned index %v out of range", rr.Index)
+		}
+		results = append(results, Result[T]{Document: candidates[rr.Index].Document, Score: rr.Score})
+	}
+	return results, nil
+}
+
+// Source text for [Retriever.Ingest].
+type Source struct {
+	// ID of the source, such as a file path or URL.
+	ID string
+	// Title of the source, passed to the document formatter with the headings of each chunk.
+	Title string
+	// Text to split into chunks.
+	Text string
+	// Metadata copied to every chunk.
+	Metadata map[string]string
+}
+
+// Ingest sources by splitting their text into chunks, embedding the chunks concurrently, and replacing
+// any chunks previously ingested from the same sources, which are the documents with the same "source" metadata. Chunks have IDs like "{source ID}#{chunk index}".
+//
+// Besides the source metadata, the metadata of each chunk has the source ID as "source", the chunk index as "chunk",
+// the byte offsets of the chunk in the source text as "start" and "end", and, if the chunk has any, its
+// Markdown headings joined by " > " as "headings". The title of each chunk, which is sent with the embed
+// request and passed to the document formatter, is the source title and the headings, joined the same way.
+//
+// Embedding errors are not retried, so use an embedder that retries, such as the one from the robust package.
+// If embedding or storing a chunk fails, nothing is changed in the store.
+func (r *Retriever[T]) Ingest(ctx context.Context, sources ...Source) error {
+	var docs [][]Document[T]
+	var titles [][]string
+	for _, source := range sources {
+		var sourceDocs []Document[T]
+		var sourceTitles []string
+		for i, c := range r.split(source.Text) {
+			metadata := maps.Clone(source.Metadata)
+			if metadata == nil {
+				metadata = map[string]string{}
+			}
+			metadata["source"] = source.ID
+			metadata["chunk"] = strconv.Itoa(i)
+			metadata["start"] = strconv.Itoa(c.Start)
+			metadata["end"] = strconv.Itoa(c.End)
+			if len(c.Headings) > 0 {
+				metadata["headings"] = strings.Join(c.Headings, " > ")
+			}
+
+			sourceDocs = append(sourceDocs, Document[T]{
+				ID:       source.ID + "#" + strconv.Itoa(i),
+				Content:  c.Text,
+				Metadata: metadata,
+			})
+
+			var title []string
+			if source.Title != "" {
+				title = append(title, source.Title)
+			}
+			sourceTitles = append(sourceTitles, strings.Join(append(title, c.Headings...), " > "))
+		}
+		docs = append(docs, sourceDocs)
+		titles = append(titles, sourceTitles)
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.SetLimit(r.concurrency)
+	for i := range docs {
+		for j := range docs[i] {
+			eg.Go(func() error {
+				doc := &docs[i][j]
+				res, err := r.embedDocument(ctx, titles[i][j], doc.Content)
+				if err != nil {
+					return fmt.Errorf("error embedding document %v: %w", doc.ID, err)
+				}
+				doc.Embedding = res.Embedding
+				return nil
+			})
+		}
+	}
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+
+	// Upsert all chunks before deleting stale ones, so a failed upsert leaves the previous chunks in place
+	if err := r.store.Upsert(slices.Concat(docs...)...); err != nil {
+		return fmt.Errorf("error storing chunks: %w", err)
+	}
+
+	for i, source := range sources {
+		var chunks []string
+		for _, doc := range docs[i] {
+			chunks = append(chunks, doc.Metadata["chunk"])
+		}
+		r.store.DeleteWhere(And(Eq("source", source.ID), Not(In("chunk", chunks...))))
+	}
+	return nil
+}
+
+// SearchArgs holds the arguments for the search tool.
+type SearchArgs struct {
+	Query string `json:"query" jsonschema_description:"What to search for, as a question or a description of the information needed."`
+	Limit int    `json:"limit,omitempty" jsonschema_description:"Optional number of results, at most 20. Default is 5."`
+}
+
+// NewSearchToolOptions for [NewSearchTool].
+type NewSearchToolOptions struct {
+	// Name of the tool. Defaults to "search_documents". Give each tool a different name
+	// when exposing several collections to the same model.
+	Name string
+	// Description of the tool. Describe what's in the collection, so the model knows when to search it.
+	Description string
+	// Filter, if not nil, restricts all searches to the documents it returns true for.
+	Filter Filter
+	// MinScore, if not nil, leaves out results with a lower score.
+	MinScore *float64
+}
+
+// Constants for the number of search results
+const (
+	defaultSearchLimit = 5
+	maxSearchLimit     = 20
+)
+
+// NewSearchTool creates a new tool that searches the documents of the retriever by similarity to a query.
+// Results are formatted with their ID, score, and metadata, followed by their content.
+func NewSearchTool[T gai.VectorComponent](r *Retriever[T], opts NewSearchToolOptions) gai.Tool {
+	if opts.Name == "" {
+		opts.Name = "search_documents"
+	}
+	if opts.Description == "" {
+		opts.Description = "Search the document collection for passages relevant to a query, most relevant first."
+	}
+
+	return gai.NewTool(gai.NewToolOptions[SearchArgs]{
+		Name:        opts.Name,
+		Description: opts.Description,
+		Summarize: func(ctx context.Context, args SearchArgs) (string, error) {
+			summary := fmt.Sprintf(`query="%s"`, args.Query)
+			if args.Limit > 0 {
+				summary += fmt.Sprintf(" limit=%d", args.Limit)
+			}
+			return summary, nil
+		},
+		Execute: func(ctx context.Context, args SearchArgs) (string, error) {
+			if strings.TrimSpace(args.Query) == "" {
+				return "", errors.New("query cannot be empty")
+			}
+
+			limit := args.Limit
+			if limit <= 0 {
+				limit = defaultSearchLimit
+			}
+			limit = min(limit, maxSearchLimit)
+
+			results, err := r.Search(ctx, args.Query, SearchOptions{Limit: limit, Filter: opts.Filter, MinScore: opts.MinScore})
+			if err != nil {
+				return "", fmt.Errorf("error searching: %w", err)
+			}
+
+			return formatResults(results), nil
+		},
+	})
+}
+
+// formatResults as a numbered list, with the ID, score, and sorted metadata of each document before its content.
+func formatResults[T gai.VectorComponent](results []Result[T]) string {
+	if len(results) == 0 {
+		return "No documents found."
+	}
+
+	var b strings.Builder
+	for i, r := range results {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "%d. %v (score %.3f)", i+1, r.Document.ID, r.Score)
+		for _, key := range slices.Sorted(maps.Keys(r.Document.Metadata)) {
+			fmt.Fprintf(&b, "\n%v: %v", key, r.Document.Metadata[key])
+		}
+		if content := strings.TrimSpace(r.Document.Content); content != "" {
+			b.WriteString("\n\n" + content)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,274 @@
+package vector_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/chunk"
+	"maragu.dev/gai/gaitest"
+	"maragu.dev/gai/rerank"
+	"maragu.dev/gai/vector"
+)
+
+func TestRetriever(t *testing.T) {
+	t.Run("upserts and searches text documents", func(t *testing.T) {
+		embedder := gaitest.NewEmbedder[float32](64)
+		r := vector.NewRetriever(vector.NewRetrieverOptions[float32]{Embedder: embedder})
+
+		is.NotError(t, r.Upsert(t.Context(), "1", "Cats are small carnivorous mammals.", map[string]string{"source": "cats.md"}))
+		is.NotError(t, r.Upsert(t.Context(), "2", "Dogs are domesticated descendants of wolves.", nil))
+
+		// The fake embedder embeds equal texts to equal vectors, so an exact match is the most similar
+		results, err := r.Search(t.Context(), "Dogs are domesticated descendants of wolves.", vector.SearchOptions{Limit: 1})
+		is.NotError(t, err)
+		is.Equal(t, 1, len(results))
+		is.Equal(t, "2", results[0].Document.ID)
+		is.Equal(t, "Dogs are domesticated descendants of wolves.", results[0].Document.Content)
+		is.Equal(t, 2, r.Store().Len())
+		is.Equal(t, 3, len(embedder.Requests()))
+	})
+
+	t.Run("formats documents and queries before embedding them", func(t *testing.T) {
+		embedder := gaitest.NewEmbedder[float32](64)
+		r := vector.NewRetriever(vector.NewRetrieverOptions[float32]{
+			Embedder: embedder,
+			FormatDocument: func(title, content string) string {
+				return "title: " + title + " | text: " + content
+			},
+			FormatQuery: func(query string) string {
+				return "query: " + query
+			},
+		})
+
+		is.NotError(t, r.Upsert(t.Context(), "1", "Cats.", nil))
+		_, err := r.Search(t.Context(), "cats", vector.SearchOptions{})
+		is.NotError(t, err)
+
+		requests := embedder.Requests()
+		is.Equal(t, "title:  | text: Cats.", requests[0].Parts[0].Text())
+		is.Equal(t, "query: cats", requests[1].Parts[0].Text())
+		is.Equal(t, gai.EmbedTaskDocument, requests[0].Task)
+		is.Equal(t, gai.EmbedTaskSearchQuery, requests[1].Task)
+
+		doc, ok := r.Store().Get("1")
+		is.True(t, ok)
+		is.Equal(t, "Cats.", doc.Content)
+	})
+
+	t.Run("reranks the candidates from the store", func(t *testing.T) {
+		cc := gaitest.NewChatCompleter(gaitest.Text(`{"scores":[{"index":0,"score":1},{"index":1,"score":8},{"index":2,"score":4}]}`))
+		r := vector.NewRetriever(vector.NewRetrieverOptions[float32]{
+			Embedder:         gaitest.NewEmbedder[float32](64),
+			Reranker:         rerank.NewLLM(rerank.NewLLMOptions{Completer: cc}),
+			RerankCandidates: 3,
+		})
+
+		is.NotError(t, r.Upsert(t.Context(), "1", "Cats eat mice.", nil))
+		is.NotError(t, r.Upsert(t.Context(), "2", "Dogs bark.", nil))
+		is.NotError(t, r.Upsert(t.Context(), "3", "Birds sing.", nil))
+
+		results, err := r.Search(t.Context(), "Cats eat mice.", vector.SearchOptions{Limit: 2})
+		is.NotError(t, err)
+		is.Equal(t, 2, len(results))
+		is.Equal(t, 0.8, results[0].Score)
+		is.Equal(t, 0.4, results[1].Score)
+
+		// The exact match is the first candidate, which the reranker scored lowest
+		prompt := cc.Requests()[0].Messages[0].Parts[0].Text()
+		is.True(t, strings.Contains(prompt, "<document index=\"0\">\nCats eat mice.\n</document>"))
+		is.True(t, results[0].Document.ID != "1")
+		is.True(t, results[1].Document.ID != "1")
+	})
+}
+
+func TestRetriever_Ingest(t *testing.T) {
+	t.Run("splits, embeds, and stores chunks with metadata", func(t *testing.T) {
+		embedder := gaitest.NewEmbedder[float32](64)
+		r := vector.NewRetriever(vector.NewRetrieverOptions[float32]{
+			Embedder: embedder,
+			FormatDocument: func(title, content string) string {
+				return "title: " + title + " | text: " + content
+			},
+			Split: func(text string) []chunk.Chunk {
+				return chunk.Markdown(text, chunk.Options{Size: 60})
+			},
+			Concurrency: 2,
+		})
+
+		text := "# Cats\n\nCats are small carnivorous mammals.\n\n## Food\n\nCats eat mice."
+		err := r.Ingest(t.Context(), vector.Source{ID: "cats.md", Title: "Animals", Text: text, Metadata: map[string]string{"lang": "en"}})
+		is.NotError(t, err)
+		is.Equal(t, 2, r.Store().Len())
+
+		doc, ok := r.Store().Get("cats.md#1")
+		is.True(t, ok)
+		is.Equal(t, "## Food\n\nCats eat mice.", doc.Content)
+		is.Equal(t, "en", doc.Metadata["lang"])
+		is.Equal(t, "cats.md", doc.Metadata["source"])
+		is.Equal(t, "1", doc.Metadata["chunk"])
+		is.Equal(t, doc.Content, text[mustAtoi(doc.Metadata["start"]):mustAtoi(doc.Metadata["end"])])
+		is.Equal(t, "Cats > Food", doc.Metadata["headings"])
+
+		var inputs []string
+		for _, req := range embedder.Requests() {
+			inputs = append(inputs, req.Parts[0].Text())
+			is.Equal(t, gai.EmbedTaskDocument, req.Task)
+			is.True(t, strings.HasPrefix(req.Title, "Animals > Cats"))
+		}
+		slices.Sort(inputs)
+		is.EqualSlice(t, []string{
+			"title: Animals > Cats > Food | text: ## Food\n\nCats eat mice.",
+			"title: Animals > Cats | text: # Cats\n\nCats are small carnivorous mammals.",
+		}, inputs)
+
+		results, err := r.Search(t.Context(), "title: Animals > Cats > Food | text: ## Food\n\nCats eat mice.", vector.SearchOptions{Limit: 1})
+		is.NotError(t, err)
+		is.Equal(t, "cats.md#1", results[0].Document.ID)
+	})
+
+	t.Run("replaces chunks from a previous ingest of the same source", func(t *testing.T) {
+		r := vector.NewRetriever(vector.NewRetrieverOptions[float32]{
+			Embedder: gaitest.NewEmbedder[float32](64),
+			Split: func(text string) []chunk.Chunk {
+				return chunk.Recursive(text, chunk.Options{Size: 20})
+			},
+		})
+
+		err := r.Ingest(t.Context(),
+			vector.Source{ID: "a", Text: "First paragraph.\n\nSecond paragraph.\n\nThird paragraph."},
+			vector.Source{ID: "b", Text: "Other source."},
+		)
+		is.NotError(t, err)
+		is.Equal(t, 4, r.Store().Len())
+
+		is.NotError(t, r.Ingest(t.Context(), vector.Source{ID: "a", Text: "Only paragraph."}))
+		is.Equal(t, 2, r.Store().Len())
+
+		_, ok := r.Store().Get("a#1")
+		is.True(t, !ok)
+		doc, ok := r.Store().Get("a#0")
+		is.True(t, ok)
+		is.Equal(t, "Only paragraph.", doc.Content)
+	})
+
+	t.Run("returns error and changes nothing if embedding fails", func(t *testing.T) {
+		r := vector.NewRetriever(vector.NewRetrieverOptions[float32]{Embedder: &failingEmbedder{}})
+
+		err := r.Ingest(t.Context(), vector.Source{ID: "a", Text: "Hello."})
+		is.Equal(t, "error embedding document a#0: oh no", err.Error())
+		is.Equal(t, 0, r.Store().Len())
+	})
+
+	t.Run("returns error and keeps the previous chunks if storing fails", func(t *testing.T) {
+		store := vector.NewStore[float32](vector.NewStoreOptions{})
+		r := vector.NewRetriever(vector.NewRetrieverOptions[float32]{Embedder: gaitest.NewEmbedder[float32](64), Store: store})
+		is.NotError(t, r.Ingest(t.Context(), vector.Source{ID: "a", Text: "Hello."}))
+
+		r = vector.NewRetriever(vector.NewRetrieverOptions[float32]{Embedder: gaitest.NewEmbedder[float32](32), Store: store})
+		err := r.Ingest(t.Context(), vector.Source{ID: "a", Text: "Goodbye."})
+		is.Equal(t, "error storing chunks: embedding of document a#0 has 32 dimensions, but the store has 64", err.Error())
+
+		doc, ok := store.Get("a#0")
+		is.True(t, ok)
+		is.Equal(t, "Hello.", doc.Content)
+	})
+}
+
+type failingEmbedder struct{}
+
+func (f *failingEmbedder) Embed(ctx context.Context, req gai.EmbedRequest) (gai.EmbedResponse[float32], error) {
+	return gai.EmbedResponse[float32]{}, errors.New("oh no")
+}
+
+func TestNewSearchTool(t *testing.T) {
+	newRetriever := func(t *testing.T) *vector.Retriever[float64] {
+		t.Helper()
+
+		r := vector.NewRetriever(vector.NewRetrieverOptions[float64]{Embedder: gaitest.NewEmbedder[float64](64)})
+		is.NotError(t, r.Upsert(t.Context(), "cats", "Cats are small carnivorous mammals.", map[string]string{"source": "cats.md", "animal": "cat"}))
+		is.NotError(t, r.Upsert(t.Context(), "dogs", "Dogs are domesticated descendants of wolves.", map[string]string{"animal": "dog"}))
+		return r
+	}
+
+	t.Run("searches and formats the results", func(t *testing.T) {
+		tool := vector.NewSearchTool(newRetriever(t), vector.NewSearchToolOptions{})
+
+		is.Equal(t, "search_documents", tool.Name)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(vector.SearchArgs{Query: "Cats are small carnivorous mammals.", Limit: 1}))
+		is.NotError(t, err)
+		is.Equal(t, "1. cats (score 1.000)\nanimal: cat\nsource: cats.md\n\nCats are small carnivorous mammals.", result)
+	})
+
+	t.Run("restricts searches with the filter", func(t *testing.T) {
+		tool := vector.NewSearchTool(newRetriever(t), vector.NewSearchToolOptions{
+			Name:   "search_dogs",
+			Filter: vector.Eq("animal", "dog"),
+		})
+
+		is.Equal(t, "search_dogs", tool.Name)
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(vector.SearchArgs{Query: "Cats are small carnivorous mammals."}))
+		is.NotError(t, err)
+		is.True(t, strings.HasPrefix(result, "1. dogs (score "))
+		is.True(t, !strings.Contains(result, "2. "))
+	})
+
+	t.Run("reports when there are no results", func(t *testing.T) {
+		tool := vector.NewSearchTool(newRetriever(t), vector.NewSearchToolOptions{Filter: vector.Eq("animal", "bird")})
+
+		result, err := tool.Execute(t.Context(), mustMarshalJSON(vector.SearchArgs{Query: "birds"}))
+		is.NotError(t, err)
+		is.Equal(t, "No documents found.", result)
+	})
+
+	t.Run("returns error for empty query", func(t *testing.T) {
+		tool := vector.NewSearchTool(newRetriever(t), vector.NewSearchToolOptions{})
+
+		_, err := tool.Execute(t.Context(), mustMarshalJSON(vector.SearchArgs{Query: " "}))
+		is.Equal(t, "query cannot be empty", err.Error())
+	})
+
+	t.Run("returns error for invalid arguments", func(t *testing.T) {
+		tool := vector.NewSearchTool(newRetriever(t), vector.NewSearchToolOptions{})
+
+		_, err := tool.Execute(t.Context(), json.RawMessage(`{"query":"cats","limit":"many"}`))
+		is.True(t, strings.HasPrefix(err.Error(), "invalid arguments for search_documents: "))
+	})
+
+	t.Run("summarizes the query and limit", func(t *testing.T) {
+		tool := vector.NewSearchTool(newRetriever(t), vector.NewSearchToolOptions{})
+
+		summary, err := tool.Summarize(t.Context(), mustMarshalJSON(vector.SearchArgs{Query: "cats", Limit: 3}))
+		is.NotError(t, err)
+		is.Equal(t, `query="cats" limit=3`, summary)
+
+		summary, err = tool.Summarize(t.Context(), []byte(`{invalid json`))
+		is.NotError(t, err)
+		is.Equal(t, "error parsing arguments", summary)
+	})
+}
+
+func mustAtoi(s string) int {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		panic(err)
+	}
+	return i
+}
+
+func mustMarshalJSON(v any) json.RawMessage {
+	d, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
@@ -0,0 +1,472 @@
+package vector
+
+import (
+	"cmp"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"maragu.dev/gai"
+)
+
+// Index type of a [Store], which decides how it searches.
+type Index string
+
+const (
+	// IndexFlat compares the query to every document, which is exact, and fast enough for tens of thousands of documents.
+	IndexFlat = Index("flat")
+	// IndexHNSW searches a Hierarchical Navigable Small World graph, which is approximate, but scales to millions of documents.
+	IndexHNSW = Index("hnsw")
+)
+
+// ErrDocumentExists is returned by [Store.Add] when a document with the same ID is already in the store.
+var ErrDocumentExists = errors.New("document already exists")
+
+// Store of [Document]s, searchable by embedding similarity. It is safe for concurrent use.
+type Store[T gai.VectorComponent] struct {
+	dimensions int
+	hnsw       *hnsw
+	index      Index
+	lock       sync.RWMutex
+	metric     Metric
+	opts       NewStoreOptions
+	// slots of documents in insertion order, with deleted documents left as tombstones until compaction
+	slots []slot[T]
+	// ids to slots of the documents in the store
+	ids map[string]int
+}
+
+type slot[T gai.VectorComponent] struct {
+	doc     Document[T]
+	norm    float64
+	deleted bool
+}
+
+// NewStoreOptions for [NewStore].
+type NewStoreOptions struct {
+	// Dimensions of the embeddings. Zero means the dimensions of the first document added.
+	Dimensions int
+	// Metric to compare embeddings by. Defaults to [MetricCosine].
+	Metric Metric
+	// Index to search with. Defaults to [IndexFlat].
+	Index Index
+
+	// M is the number of neighbors of each node in the [IndexHNSW] graph. Higher is more accurate,
+	// but uses more memory and is slower to build. Defaults to 16.
+	M int
+	// EfConstruction is the number of candidates considered when adding a document to the [IndexHNSW] graph.
+	// Higher is more accurate, but slower to build. Defaults to 200.
+	EfConstruction int
+	// EfSearch is the number of candidates considered when searching the [IndexHNSW] graph,
+	// if more than the search limit. Higher is more accurate, but slower. Defaults to 64.
+	EfSearch int
+}
+
+// NewStore with the given options. Panics on an unknown metric or index.
+func NewStore[T gai.VectorComponent](opts NewStoreOptions) *Store[T] {
+	s, err := newStore[T](opts)
+	if err != nil {
+		panic(err.Error())
+	}
+	return s
+}
+
+// newStore with the given options, returning an error on invalid options.
+func newStore[T gai.VectorComponent](opts NewStoreOptions) (*Store[T], error) {
+	if opts.Dimensions < 0 {
+		return nil, errors.New("dimensions cannot be negative")
+	}
+
+	if opts.Metric == "" {
+		opts.Metric = MetricCosine
+	}
+	if !slices.Contains([]Metric{MetricCosine, MetricDot, MetricL2}, opts.Metric) {
+		return nil, errors.New("unknown metric " + string(opts.Metric))
+	}
+
+	if opts.Index == "" {
+		opts.Index = IndexFlat
+	}
+	if !slices.Contains([]Index{IndexFlat, IndexHNSW}, opts.Index) {
+		return nil, errors.New("unknown index " + string(opts.Index))
+	}
+
+	if opts.M <= 0 {
+		opts.M = 16
+	}
+	if opts.M < 2 {
+		return nil, errors.New("m must be at least 2")
+	}
+	if opts.EfConstruction <= 0 {
+		opts.EfConstruction = 200
+	}
+	if opts.EfSearch <= 0 {
+		opts.EfSearch = 64
+	}
+
+	s := &Store[T]{
+		dimensions: opts.Dimensions,
+		index:      opts.Index,
+		metric:     opts.Metric,
+		opts:       opts,
+		ids:        map[string]int{},
+	}
+	s.resetIndex()
+	return s, nil
+}
+
+// resetIndex to an empty graph, if the store uses one.
+func (s *Store[T]) resetIndex() {
+	if s.index != IndexHNSW {
+		return
+	}
+	s.hnsw = newHNSW(s.opts.M, s.opts.EfConstruction, func(a, b int) float64 {
+		return -s.score(s.slots[a].doc.Embedding, s.slots[b].doc.Embedding, s.slots[a].norm, s.slots[b].norm)
+	})
+}
+
+// score the similarity of two embeddings with the given norms, higher is more similar.
+func (s *Store[T]) score(a, b []T, normA, normB float64) float64 {
+	switch s.metric {
+	case MetricDot:
+		return dot(a, b)
+	case MetricL2:
+		return -l2(a, b)
+	default:
+		if normA == 0 || normB == 0 {
+			return 0
+		}
+		return dot(a, b) / (normA * normB)
+	}
+}
+
+// Add documents to the store. It returns [ErrDocumentExists] if a document with the same ID is already
+// in the store, and an error if an embedding has the wrong dimensions, in which case nothing is added.
+func (s *Store[T]) Add(docs ...Document[T]) error {
+	return s.add(docs, false)
+}
+
+// Upsert documents into the store, replacing documents with the same ID.
+// It returns an error if an embedding has the wrong dimensions, in which case nothing is changed.
+func (s *Store[T]) Upsert(docs ...Document[T]) error {
+	return s.add(docs, true)
+}
+
+func (s *Store[T]) add(docs []Document[T], replace bool) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	dimensions := s.dimensions
+	seen := map[string]bool{}
+	for _, doc := range docs {
+		if doc.ID == "" {
+			return errors.New("document ID cannot be empty")
+		}
+		if dimensions == 0 {
+			dimensions = len(doc.Embedding)
+		}
+		if len(doc.Embedding) == 0 || len(doc.Embedding) != dimensions {
+			return fmt.Errorf("embedding of document %v has %v dimensions, but the store has %v", doc.ID, len(doc.Embedding), dimensions)
+		}
+		if !replace {
+			if _, ok := s.ids[doc.ID]; ok || seen[doc.ID] {
+				return fmt.Errorf("%w: %v", ErrDocumentExists, doc.ID)
+			}
+		}
+		seen[doc.ID] = true
+	}
+	s.dimensions = dimensions
+
+	for _, doc := range docs {
+		if i, ok := s.ids[doc.ID]; ok {
+			s.remove(i)
+		}
+
+		// Copy, so changes to the document after adding it don't change the store
+		doc.Embedding = slices.Clone(doc.Embedding)
+		doc.Metadata = maps.Clone(doc.Metadata)
+
+		i := len(s.slots)
+		s.slots = append(s.slots, slot[T]{doc: doc, norm: norm(doc.Embedding)})
+		s.ids[doc.ID] = i
+		if s.hnsw != nil {
+			s.hnsw.insert(i)
+		}
+	}
+
+	s.compactIfNeeded()
+	return nil
+}
+
+// Delete the documents with the given IDs from the store, returning the number deleted.
+// IDs not in the store are ignored.
+func (s *Store[T]) Delete(ids ...string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var deleted int
+	for _, id := range ids {
+		if i, ok := s.ids[id]; ok {
+			s.remove(i)
+			deleted++
+		}
+	}
+
+	s.compactIfNeeded()
+	return deleted
+}
+
+// DeleteWhere deletes the documents whose metadata the filter returns true for, returning the number deleted.
+func (s *Store[T]) DeleteWhere(filter Filter) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var deleted int
+	for i, sl := range s.slots {
+		if !sl.deleted && filter(sl.doc.Metadata) {
+			s.remove(i)
+			deleted++
+		}
+	}
+
+	s.compactIfNeeded()
+	return deleted
+}
+
+// remove the document in the slot, leaving a tombstone. The caller must hold the write lock.
+func (s *Store[T]) remove(i int) {
+	delete(s.ids, s.slots[i].doc.ID)
+	s.slots[i].deleted = true
+	// Free the content and metadata now, but keep the embedding for the graph until compaction
+	s.slots[i].doc.Content = ""
+	s.slots[i].doc.Metadata = nil
+}
+
+// compactIfNeeded removes the tombstones and rebuilds the index, when at least half of the slots are tombstones.
+// The caller must hold the write lock.
+func (s *Store[T]) compactIfNeeded() {
+	tombstones := len(s.slots) - len(s.ids)
+	if tombstones == 0 || tombstones*2 < len(s.slots) {
+		return
+	}
+
+	slots := make([]slot[T], 0, len(s.ids))
+	for _, sl := range s.slots {
+		if !sl.deleted {
+			slots = append(slots, sl)
+		}
+	}
+	s.slots = slots
+
+	s.resetIndex()
+	for i, sl := range s.slots {
+		s.ids[sl.doc.ID] = i
+		if s.hnsw != nil {
+			s.hnsw.insert(i)
+		}
+	}
+}
+
+// Get the document with the given ID, and whether it exists.
+func (s *Store[T]) Get(id string) (Document[T], bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	i, ok := s.ids[id]
+	if !ok {
+		return Document[T]{}, false
+	}
+	return copyDocument(s.slots[i].doc), true
+}
+
+// Len is the number of documents in the store.
+func (s *Store[T]) Len() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return len(s.ids)
+}
+
+// SearchOptions for [Store.Search].
+type SearchOptions struct {
+	// Limit of results. Zero means 10.
+	Limit int
+	// Filter, if not nil, restricts the search to the documents it returns true for.
+	Filter Filter
+	// MinScore, if not nil, leaves out results with a lower score.
+	MinScore *float64
+}
+
+// Search for the documents most similar to the query embedding, most similar first.
+// With [IndexHNSW] the results are approximate. If the filter leaves fewer results from the graph than
+// the limit, the search falls back to comparing the query to every document, so heavily filtered
+// searches are exact, but as slow as with [IndexFlat].
+func (s *Store[T]) Search(query []T, opts SearchOptions) ([]Result[T], error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 10
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if len(s.ids) == 0 {
+		return nil, nil
+	}
+	if len(query) != s.dimensions {
+		return nil, fmt.Errorf("query has %v dimensions, but the store has %v", len(query), s.dimensions)
+	}
+
+	queryNorm := norm(query)
+
+	// accept the document in the slot with its score, or report whether it was skipped
+	// for being deleted or filtered out, as opposed to having too low a score
+	accept := func(i int) (score float64, ok bool, skipped bool) {
+		sl := s.slots[i]
+		if sl.deleted || opts.Filter != nil && !opts.Filter(sl.doc.Metadata) {
+			return 0, false, true
+		}
+		score = s.score(query, sl.doc.Embedding, queryNorm, sl.norm)
+		if opts.MinScore != nil && score < *opts.MinScore {
+			return 0, false, false
+		}
+		return score, true, false
+	}
+
+	var results []Result[T]
+	if s.hnsw != nil {
+		candidates := s.hnsw.search(max(s.opts.EfSearch, opts.Limit), func(i int) float64 {
+			return -s.score(query, s.slots[i].doc.Embedding, queryNorm, s.slots[i].norm)
+		})
+
+		var skipped int
+		for _, c := range candidates {
+			score, ok, skip := accept(c.node)
+			if ok {
+				results = append(results, Result[T]{Document: s.slots[c.node].doc, Score: score})
+			}
+			if skip {
+				skipped++
+			}
+		}
+
+		// Fall back to comparing every document only if deleted or filtered out documents took up the candidates
+		if len(results) >= opts.Limit || skipped == 0 {
+			return copyResults(results[:min(len(results), opts.Limit)]), nil
+		}
+		results = nil
+	}
+
+	for i := range s.slots {
+		if score, ok, _ := accept(i); ok {
+			results = append(results, Result[T]{Document: s.slots[i].doc, Score: score})
+		}
+	}
+	// Stable, so documents with equal scores are in the order they were added
+	slices.SortStableFunc(results, func(a, b Result[T]) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	return copyResults(results[:min(len(results), opts.Limit)]), nil
+}
+
+// copyResults, so changes to them don't change the store.
+func copyResults[T gai.VectorComponent](results []Result[T]) []Result[T] {
+	for i := range results {
+		results[i].Document = copyDocument(results[i].Document)
+	}
+	return results
+}
+
+func copyDocument[T gai.VectorComponent](doc Document[T]) Document[T] {
+	doc.Embedding = slices.Clone(doc.Embedding)
+	doc.Metadata = maps.Clone(doc.Metadata)
+	return doc
+}
+
+// storeFile is the format of a saved store. The index is rebuilt when loading.
+type storeFile[T gai.VectorComponent] struct {
+	Version   int
+	Options   NewStoreOptions
+	Documents []Document[T]
+}
+
+// Save the options and documents of the store to w, in a binary format readable by [Load].
+func (s *Store[T]) Save(w io.Writer) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	f := storeFile[T]{Version: 1, Options: s.opts}
+	f.Options.Dimensions = s.dimensions
+	for _, sl := range s.slots {
+		if !sl.deleted {
+			f.Documents = append(f.Documents, sl.doc)
+		}
+	}
+
+	if err := gob.NewEncoder(w).Encode(f); err != nil {
+		return fmt.Errorf("error encoding store: %w", err)
+	}
+	return nil
+}
+
+// Load a store saved with [Store.Save], with the options it was saved with.
+// Returns an error if the saved options are invalid.
+func Load[T gai.VectorComponent](r io.Reader) (*Store[T], error) {
+	var f storeFile[T]
+	if err := gob.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("error decoding store: %w", err)
+	}
+	if f.Version != 1 {
+		return nil, fmt.Errorf("unsupported store version %v", f.Version)
+	}
+
+	s, err := newStore[T](f.Options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid store options: %w", err)
+	}
+	if err := s.Add(f.Documents...); err != nil {
+		return nil, fmt.Errorf("error adding documents: %w", err)
+	}
+	return s, nil
+}
+
+// SaveFile saves the store to the file at path with [Store.Save], replacing the file atomically.
+func (s *Store[T]) SaveFile(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating store file: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+
+	if err := s.Save(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing store file: %w", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("error writing store file: %w", err)
+	}
+	return nil
+}
+
+// LoadFile loads a store from the file at path with [Load].
+func LoadFile[T gai.VectorComponent](path string) (*Store[T], error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening store file: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	return Load[T](f)
+}
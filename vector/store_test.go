@@ -0,0 +1,316 @@
+package vector_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"path/filepath"
+	"testing"
+
+	"maragu.dev/is"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/vector"
+)
+
+func TestStore_Search(t *testing.T) {
+	docs := []vector.Document[float64]{
+		{ID: "a", Embedding: []float64{1, 0}, Content: "A", Metadata: map[string]string{"type": "letter"}},
+		{ID: "b", Embedding: []float64{3, 1}, Content: "B", Metadata: map[string]string{"type": "letter", "case": "upper"}},
+		{ID: "c", Embedding: []float64{0, 1}, Content: "C", Metadata: map[string]string{"type": "number"}},
+	}
+
+	for _, index := range []vector.Index{vector.IndexFlat, vector.IndexHNSW} {
+		t.Run(fmt.Sprintf("finds the most similar documents by cosine similarity with %v index", index), func(t *testing.T) {
+			s := vector.NewStore[float64](vector.NewStoreOptions{Index: index})
+			is.NotError(t, s.Add(docs...))
+
+			results, err := s.Search([]float64{1, 0.1}, vector.SearchOptions{Limit: 2})
+			is.NotError(t, err)
+			is.Equal(t, 2, len(results))
+			is.Equal(t, "a", results[0].Document.ID)
+			is.Equal(t, "b", results[1].Document.ID)
+			is.Equal(t, "A", results[0].Document.Content)
+			is.True(t, results[0].Score > results[1].Score)
+		})
+
+		t.Run(fmt.Sprintf("filters by metadata with %v index", index), func(t *testing.T) {
+			s := vector.NewStore[float64](vector.NewStoreOptions{Index: index})
+			is.NotError(t, s.Add(docs...))
+
+			results, err := s.Search([]float64{1, 0}, vector.SearchOptions{Filter: vector.Eq("type", "number")})
+			is.NotError(t, err)
+			is.Equal(t, 1, len(results))
+			is.Equal(t, "c", results[0].Document.ID)
+
+			results, err = s.Search([]float64{1, 0}, vector.SearchOptions{Filter: vector.And(vector.Has("case"), vector.Not(vector.In("type", "number")))})
+			is.NotError(t, err)
+			is.Equal(t, 1, len(results))
+			is.Equal(t, "b", results[0].Document.ID)
+
+			results, err = s.Search([]float64{1, 0}, vector.SearchOptions{Filter: vector.Or(vector.Eq("case", "lower"), vector.Eq("type", "unknown"))})
+			is.NotError(t, err)
+			is.Equal(t, 0, len(results))
+		})
+	}
+
+	t.Run("scores by dot product and negated euclidean distance", func(t *testing.T) {
+		s := vector.NewStore[float64](vector.NewStoreOptions{Metric: vector.MetricDot})
+		is.NotError(t, s.Add(docs...))
+
+		results, err := s.Search([]float64{1, 0}, vector.SearchOptions{Limit: 1})
+		is.NotError(t, err)
+		is.Equal(t, "b", results[0].Document.ID)
+		is.Equal(t, 3.0, results[0].Score)
+
+		s = vector.NewStore[float64](vector.NewStoreOptions{Metric: vector.MetricL2})
+		is.NotError(t, s.Add(docs...))
+
+		results, err = s.Search([]float64{0, 2}, vector.SearchOptions{Limit: 1})
+		is.NotError(t, err)
+		is.Equal(t, "c", results[0].Document.ID)
+		is.Equal(t, -1.0, results[0].Score)
+	})
+
+	t.Run("leaves out results below the minimum score", func(t *testing.T) {
+		s := vector.NewStore[float64](vector.NewStoreOptions{})
+		is.NotError(t, s.Add(docs...))
+
+		results, err := s.Search([]float64{1, 0}, vector.SearchOptions{MinScore: gai.Ptr(0.9)})
+		is.NotError(t, err)
+		is.Equal(t, 2, len(results))
+	})
+
+	t.Run("works with integer components", func(t *testing.T) {
+		s := vector.NewStore[int8](vector.NewStoreOptions{Metric: vector.MetricDot})
+		is.NotError(t, s.Add(
+			vector.Document[int8]{ID: "a", Embedding: []int8{127, 127}},
+			vector.Document[int8]{ID: "b", Embedding: []int8{-128, 127}},
+		))
+
+		results, err := s.Search([]int8{127, 127}, vector.SearchOptions{})
+		is.NotError(t, err)
+		is.Equal(t, "a", results[0].Document.ID)
+		is.Equal(t, 2*127.0*127.0, results[0].Score)
+	})
+
+	t.Run("returns error for a query with the wrong dimensions", func(t *testing.T) {
+		s := vector.NewStore[float64](vector.NewStoreOptions{})
+		is.NotError(t, s.Add(docs...))
+
+		_, err := s.Search([]float64{1, 0, 0}, vector.SearchOptions{})
+		is.Equal(t, "query has 3 dimensions, but the store has 2", err.Error())
+	})
+
+	t.Run("returns no results from an empty store", func(t *testing.T) {
+		s := vector.NewStore[float64](vector.NewStoreOptions{})
+
+		results, err := s.Search([]float64{1, 0}, vector.SearchOptions{})
+		is.NotError(t, err)
+		is.Equal(t, 0, len(results))
+	})
+
+	t.Run("finds nearly the same nearest neighbors with HNSW as with a flat index", func(t *testing.T) {
+		r := rand.New(rand.NewPCG(3, 4))
+		flat := vector.NewStore[float32](vector.NewStoreOptions{})
+		graph := vector.NewStore[float32](vector.NewStoreOptions{Index: vector.IndexHNSW})
+
+		for i := range 2000 {
+			doc := vector.Document[float32]{ID: fmt.Sprint(i), Embedding: randomVector[float32](r, 32)}
+			is.NotError(t, flat.Add(doc))
+			is.NotError(t, graph.Add(doc))
+		}
+
+		var found, total int
+		for range 50 {
+			query := randomVector[float32](r, 32)
+			expected, err := flat.Search(query, vector.SearchOptions{Limit: 10})
+			is.NotError(t, err)
+			actual, err := graph.Search(query, vector.SearchOptions{Limit: 10})
+			is.NotError(t, err)
+			is.Equal(t, 10, len(actual))
+
+			ids := map[string]bool{}
+			for _, result := range actual {
+				ids[result.Document.ID] = true
+			}
+			for _, result := range expected {
+				if ids[result.Document.ID] {
+					found++
+				}
+				total++
+			}
+		}
+
+		recall := float64(found) / float64(total)
+		is.True(t, recall > 0.95, "recall is", recall)
+	})
+}
+
+func TestStore_Add(t *testing.T) {
+	t.Run("returns error for duplicate IDs and wrong dimensions, adding nothing", func(t *testing.T) {
+		s := vector.NewStore[float64](vector.NewStoreOptions{})
+		is.NotError(t, s.Add(vector.Document[float64]{ID: "a", Embedding: []float64{1, 0}}))
+
+		err := s.Add(vector.Document[float64]{ID: "b", Embedding: []float64{1, 0}}, vector.Document[float64]{ID: "a", Embedding: []float64{0, 1}})
+		is.True(t, errors.Is(err, vector.ErrDocumentExists))
+
+		err = s.Add(vector.Document[float64]{ID: "c", Embedding: []float64{1, 0, 0}})
+		is.Equal(t, "embedding of document c has 3 dimensions, but the store has 2", err.Error())
+
+		is.Equal(t, 1, s.Len())
+	})
+
+	t.Run("copies documents, so changing them doesn't change the store", func(t *testing.T) {
+		s := vector.NewStore[float64](vector.NewStoreOptions{})
+		doc := vector.Document[float64]{ID: "a", Embedding: []float64{1, 0}, Metadata: map[string]string{"type": "letter"}}
+		is.NotError(t, s.Add(doc))
+
+		doc.Embedding[0] = 0
+		doc.Metadata["type"] = "number"
+
+		doc, ok := s.Get("a")
+		is.True(t, ok)
+		is.EqualSlice(t, []float64{1, 0}, doc.Embedding)
+		is.Equal(t, "letter", doc.Metadata["type"])
+	})
+}
+
+func TestStore_Upsert(t *testing.T) {
+	for _, index := range []vector.Index{vector.IndexFlat, vector.IndexHNSW} {
+		t.Run(fmt.Sprintf("replaces documents with the same ID with %v index", index), func(t *testing.T) {
+			s := vector.NewStore[float64](vector.NewStoreOptions{Index: index})
+			is.NotError(t, s.Upsert(vector.Document[float64]{ID: "a", Embedding: []float64{1, 0}, Content: "old"}))
+			is.NotError(t, s.Upsert(vector.Document[float64]{ID: "b", Embedding: []float64{0, 1}}))
+			is.NotError(t, s.Upsert(vector.Document[float64]{ID: "a", Embedding: []float64{0, 1}, Content: "new"}))
+
+			is.Equal(t, 2, s.Len())
+
+			doc, ok := s.Get("a")
+			is.True(t, ok)
+			is.Equal(t, "new", doc.Content)
+
+			results, err := s.Search([]float64{1, 0}, vector.SearchOptions{Limit: 1, MinScore: gai.Ptr(0.5)})
+			is.NotError(t, err)
+			is.Equal(t, 0, len(results))
+		})
+	}
+}
+
+func TestStore_Delete(t *testing.T) {
+	for _, index := range []vector.Index{vector.IndexFlat, vector.IndexHNSW} {
+		t.Run(fmt.Sprintf("deletes documents so they're not found with %v index", index), func(t *testing.T) {
+			s := vector.NewStore[float64](vector.NewStoreOptions{Index: index})
+			r := rand.New(rand.NewPCG(1, 2))
+			for i := range 100 {
+				is.NotError(t, s.Add(vector.Document[float64]{ID: fmt.Sprint(i), Embedding: randomVector[float64](r, 8)}))
+			}
+
+			var ids []string
+			for i := range 90 {
+				ids = append(ids, fmt.Sprint(i))
+			}
+			is.Equal(t, 90, s.Delete(append(ids, "nope")...))
+			is.Equal(t, 10, s.Len())
+
+			_, ok := s.Get("0")
+			is.True(t, !ok)
+
+			results, err := s.Search(randomVector[float64](r, 8), vector.SearchOptions{Limit: 100})
+			is.NotError(t, err)
+			is.Equal(t, 10, len(results))
+		})
+	}
+}
+
+func TestStore_Save(t *testing.T) {
+	t.Run("saves and loads the documents and options", func(t *testing.T) {
+		s := vector.NewStore[float32](vector.NewStoreOptions{Metric: vector.MetricL2, Index: vector.IndexHNSW, M: 8})
+		is.NotError(t, s.Add(
+			vector.Document[float32]{ID: "a", Embedding: []float32{1, 0}, Content: "A", Metadata: map[string]string{"type": "letter"}},
+			vector.Document[float32]{ID: "b", Embedding: []float32{0, 1}, Content: "B"},
+			vector.Document[float32]{ID: "c", Embedding: []float32{1, 1}, Content: "C"},
+		))
+		s.Delete("b")
+
+		var buf bytes.Buffer
+		is.NotError(t, s.Save(&buf))
+
+		loaded, err := vector.Load[float32](&buf)
+		is.NotError(t, err)
+		is.Equal(t, 2, loaded.Len())
+
+		doc, ok := loaded.Get("a")
+		is.True(t, ok)
+		is.Equal(t, "A", doc.Content)
+		is.Equal(t, "letter", doc.Metadata["type"])
+
+		results, err := loaded.Search([]float32{1, 0}, vector.SearchOptions{})
+		is.NotError(t, err)
+		is.Equal(t, "a", results[0].Document.ID)
+		is.Equal(t, 0.0, results[0].Score)
+	})
+
+	t.Run("saves to and loads from a file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "store.gob")
+
+		s := vector.NewStore[float64](vector.NewStoreOptions{})
+		is.NotError(t, s.Add(vector.Document[float64]{ID: "a", Embedding: []float64{1, 0}}))
+		is.NotError(t, s.SaveFile(path))
+
+		loaded, err := vector.LoadFile[float64](path)
+		is.NotError(t, err)
+		is.Equal(t, 1, loaded.Len())
+	})
+
+	t.Run("returns error when loading a store with another component type", func(t *testing.T) {
+		s := vector.NewStore[float64](vector.NewStoreOptions{})
+		is.NotError(t, s.Add(vector.Document[float64]{ID: "a", Embedding: []float64{1, 0}}))
+
+		var buf bytes.Buffer
+		is.NotError(t, s.Save(&buf))
+
+		_, err := vector.Load[int8](&buf)
+		is.True(t, err != nil)
+	})
+
+	t.Run("returns error when loading a store with invalid options", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			options vector.NewStoreOptions
+			err     string
+		}{
+			{"unknown metric", vector.NewStoreOptions{Metric: "manhattan"}, "invalid store options: unknown metric manhattan"},
+			{"unknown index", vector.NewStoreOptions{Index: "ivf"}, "invalid store options: unknown index ivf"},
+			{"m of one", vector.NewStoreOptions{M: 1}, "invalid store options: m must be at least 2"},
+			{"negative dimensions", vector.NewStoreOptions{Dimensions: -1}, "invalid store options: dimensions cannot be negative"},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				// Same field names as the saved format, which gob matches by name
+				f := struct {
+					Version   int
+					Options   vector.NewStoreOptions
+					Documents []vector.Document[float32]
+				}{Version: 1, Options: test.options}
+
+				var buf bytes.Buffer
+				is.NotError(t, gob.NewEncoder(&buf).Encode(f))
+
+				_, err := vector.Load[float32](&buf)
+				is.Equal(t, test.err, err.Error())
+			})
+		}
+	})
+}
+
+func randomVector[T float32 | float64](r *rand.Rand, dimensions int) []T {
+	v := make([]T, dimensions)
+	for i := range v {
+		v[i] = T(r.NormFloat64())
+	}
+	return v
+}
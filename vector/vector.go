@@ -0,0 +1,108 @@
+// Package vector provides an in-memory vector store and retrieval on top of [gai.Embedder].
+//
+// A [Store] holds [Document]s with embeddings of any [gai.VectorComponent] type, and finds the documents
+// most similar to a query embedding by [Metric], either exactly or approximately with an HNSW graph.
+// Documents carry metadata that searches can be restricted by with a [Filter], and stores can be saved to
+// and loaded from disk. A [Retriever] combines a store with an embedder to index and search text, and
+// [NewSearchTool] exposes a retriever to a model as a [gai.Tool].
+//
+// The package also has functions for working with embeddings directly: similarity and distance measures,
+// normalization, truncation of matryoshka embeddings, conversion between component types, and int8 and
+// binary quantization.
+package vector
+
+import (
+	"slices"
+
+	"maragu.dev/gai"
+)
+
+// Metric for the similarity between two vectors.
+type Metric string
+
+const (
+	// MetricCosine is the cosine of the angle between the vectors, from -1 to 1.
+	// Vectors with a zero norm have a similarity of 0 to everything.
+	MetricCosine = Metric("cosine")
+	// MetricDot is the dot product of the vectors. For normalized vectors it is the same as [MetricCosine], but cheaper.
+	MetricDot = Metric("dot")
+	// MetricL2 is the negated Euclidean distance between the vectors, so that higher scores are more similar.
+	MetricL2 = Metric("l2")
+)
+
+// Document in a [Store].
+type Document[T gai.VectorComponent] struct {
+	// ID is unique in the store.
+	ID string
+	// Embedding of the document. All embeddings in a store have the same dimensions.
+	Embedding []T
+	// Content is the text of the document, if any.
+	Content string
+	// Metadata to filter searches by, such as the source or type of the document.
+	Metadata map[string]string
+}
+
+// Result of a search, with the document and its similarity score to the query by the store's [Metric].
+// Higher scores are more similar.
+type Result[T gai.VectorComponent] struct {
+	Document Document[T]
+	Score    float64
+}
+
+// Filter on document metadata, used to restrict searches to the documents it returns true for.
+type Filter func(metadata map[string]string) bool
+
+// Eq is a [Filter] for documents with the metadata key set to value.
+func Eq(key, value string) Filter {
+	return func(metadata map[string]string) bool {
+		v, ok := metadata[key]
+		return ok && v == value
+	}
+}
+
+// In is a [Filter] for documents with the metadata key set to one of the values.
+func In(key string, values ...string) Filter {
+	return func(metadata map[string]string) bool {
+		v, ok := metadata[key]
+		return ok && slices.Contains(values, v)
+	}
+}
+
+// Has is a [Filter] for documents with the metadata key set to anything.
+func Has(key string) Filter {
+	return func(metadata map[string]string) bool {
+		_, ok := metadata[key]
+		return ok
+	}
+}
+
+// And is a [Filter] for documents matching all the filters.
+func And(filters ...Filter) Filter {
+	return func(metadata map[string]string) bool {
+		for _, f := range filters {
+			if !f(metadata) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or is a [Filter] for documents matching any of the filters.
+func Or(filters ...Filter) Filter {
+	return func(metadata map[string]string) bool {
+		for _, f := range filters {
+			if f(metadata) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not is a [Filter] for documents not matching the filter.
+func Not(filter Filter) Filter {
+	return func(metadata map[string]string) bool {
+		return !filter(metadata)
+	}
+}